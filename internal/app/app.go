@@ -11,13 +11,15 @@ import (
 	"github.com/ekbaya/asham/pkg/db"
 	"github.com/ekbaya/asham/pkg/db/migrations"
 	"github.com/ekbaya/asham/pkg/db/redis"
+	"github.com/ekbaya/asham/pkg/domain/services"
 	"gorm.io/gorm"
 )
 
 type App struct {
-	addr   string
-	db     *gorm.DB
-	server *http.Server
+	addr      string
+	db        *gorm.DB
+	server    *http.Server
+	scheduler *services.SchedulerService
 }
 
 func NewApp(cfg *config.Config) (*App, error) {
@@ -50,6 +52,11 @@ func NewApp(cfg *config.Config) (*App, error) {
 		panic(err)
 	}
 
+	// Register background jobs
+	if err := registerJobs(services); err != nil {
+		return nil, fmt.Errorf("failed to register background jobs: %w", err)
+	}
+
 	// Initialize router with dependencies
 	router, err := InitRoutes(services)
 	if err != nil {
@@ -69,15 +76,24 @@ func NewApp(cfg *config.Config) (*App, error) {
 		Handler: router,
 	}
 
-	return &App{
+	app := &App{
 		addr:   fmt.Sprintf(":%s", cfg.Server.Port),
 		db:     db,
 		server: server,
-	}, nil
+	}
+	if cfg.SCHEDULER_ENABLED {
+		app.scheduler = services.SchedulerService
+	}
+
+	return app, nil
 }
 
 // Run starts the HTTP server
 func (a *App) Run() error {
+	if a.scheduler != nil {
+		a.scheduler.Start()
+	}
+
 	fmt.Printf("Starting server on %s...\n", a.addr)
 
 	if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -90,5 +106,8 @@ func (a *App) Run() error {
 // Shutdown gracefully shuts down the server
 func (a *App) Shutdown(ctx context.Context) error {
 	fmt.Println("Shutting down server...")
+	if a.scheduler != nil {
+		a.scheduler.Stop(ctx)
+	}
 	return a.server.Shutdown(ctx)
 }
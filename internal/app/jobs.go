@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/services"
+)
+
+const (
+	notificationRetentionDays = 180
+	auditLogRetentionPeriod   = 2 * 365 * 24 * time.Hour
+	transitionSagaJobTimeout  = 15 * time.Minute
+)
+
+// registerJobs registers the periodic service methods with the scheduler
+func registerJobs(services *services.ServiceContainer) error {
+	scheduler := services.SchedulerService
+
+	jobs := []struct {
+		name        string
+		spec        string
+		description string
+		timeout     time.Duration
+		run         func(ctx context.Context) error
+	}{
+		{
+			name:        "process-scheduled-reports",
+			spec:        "*/15 * * * *",
+			description: "Generates reports for report schedules that are due",
+			timeout:     10 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.ReportsService.ProcessScheduledReports()
+			},
+		},
+		{
+			name:        "close-expired-ballots",
+			spec:        "*/5 * * * *",
+			description: "Closes ballots whose end date has passed and records their result",
+			timeout:     10 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.BallotingService.CloseExpiredBallotings()
+			},
+		},
+		{
+			name:        "close-due-committee-ballots",
+			spec:        "*/5 * * * *",
+			description: "Closes committee internal ballots whose closing date has passed and records their result",
+			timeout:     10 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.BallotingService.CloseDueCommitteeBallots()
+			},
+		},
+		{
+			name:        "compile-closed-acceptances",
+			spec:        "*/15 * * * *",
+			description: "Compiles the NSB responses to proposals whose circulation has closed and opens SMC approval when the criteria are met",
+			timeout:     10 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.AcceptanceService.CompileDueAcceptances()
+			},
+		},
+		{
+			name:        "send-deadline-reminders",
+			spec:        "0 7 * * *",
+			description: "Sends reminders for upcoming project deadlines",
+			timeout:     15 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.NotificationService.SendDeadlineReminders()
+			},
+		},
+		{
+			name:        "escalate-overdue-tasks",
+			spec:        "0 8 * * *",
+			description: "Escalates overdue tasks to higher roles",
+			timeout:     15 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.NotificationService.EscalateOverdueTasks()
+			},
+		},
+		{
+			name:        "release-expired-number-reservations",
+			spec:        "0 * * * *",
+			description: "Releases reserved numbers that were not used before their reservation expired",
+			timeout:     5 * time.Minute,
+			run: func(ctx context.Context) error {
+				_, err := services.NumberingService.ReleaseExpiredReservations()
+				return err
+			},
+		},
+		{
+			name:        "process-transition-sagas",
+			spec:        "* * * * *",
+			description: "Retries the OneDrive document steps of stage transitions that have not completed",
+			timeout:     transitionSagaJobTimeout,
+			run: func(ctx context.Context) error {
+				return services.SagaService.ProcessDueSagas(ctx)
+			},
+		},
+		{
+			name:        "open-systematic-reviews",
+			spec:        "0 6 * * *",
+			description: "Opens a systematic review of every published standard that reached the review age",
+			timeout:     15 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.SystematicReviewService.OpenDueReviews()
+			},
+		},
+		{
+			name:        "close-systematic-reviews",
+			spec:        "30 * * * *",
+			description: "Closes systematic reviews whose closing date has passed and applies their outcome",
+			timeout:     15 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.SystematicReviewService.CloseDueReviews()
+			},
+		},
+		{
+			name:        "cleanup-old-notifications",
+			spec:        "0 2 * * *",
+			description: fmt.Sprintf("Deletes notifications older than %d days", notificationRetentionDays),
+			timeout:     30 * time.Minute,
+			run: func(ctx context.Context) error {
+				return services.NotificationService.CleanupOldNotifications(notificationRetentionDays)
+			},
+		},
+		{
+			name:        "cleanup-old-audit-logs",
+			spec:        "30 2 * * 0",
+			description: "Deletes audit logs older than the retention period",
+			timeout:     time.Hour,
+			run: func(ctx context.Context) error {
+				deleted, err := services.AuditLogService.CleanupOldLogs(auditLogRetentionPeriod)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("[Scheduler] Deleted %d old audit logs\n", deleted)
+				return nil
+			},
+		},
+	}
+
+	for _, job := range jobs {
+		if err := scheduler.RegisterJob(job.name, job.spec, job.description, job.timeout, job.run); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
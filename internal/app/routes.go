@@ -29,6 +29,10 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 	notificationHandler := handlers.NewNotificationHandler(*services.NotificationService)
 	reportsHandler := handlers.NewReportsHandler(services.ReportsService)
 	auditLogHandler := handlers.NewAuditLogHandler(services.AuditLogService)
+	schedulerHandler := handlers.NewSchedulerHandler(services.SchedulerService)
+	numberingHandler := handlers.NewNumberingHandler(services.NumberingService)
+	sagaHandler := handlers.NewSagaHandler(services.SagaService)
+	reviewHandler := handlers.NewSystematicReviewHandler(services.SystematicReviewService)
 
 	api := router.Group("/api")
 
@@ -230,20 +234,69 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 		projects.GET("/:id/with-stage-history", projectHandler.GetProjectWithStageHistory)
 		projects.GET("/:id/stage-history", projectHandler.GetProjectStageHistory)
 		projects.PUT("/:id/stage", projectHandler.UpdateProjectStage)
+		projects.GET("/:id/transitions", projectHandler.GetAvailableTransitions)
+
+		// Project cancellation, suspension and reinstatement
+		projects.POST("/:id/suspend", projectHandler.SuspendProject)
+		projects.POST("/:id/cancel", projectHandler.CancelProject)
+		projects.POST("/:id/withdraw", projectHandler.WithdrawProject)
+		projects.POST("/:id/reinstate", projectHandler.ReinstateProject)
+		projects.GET("/:id/status-history", projectHandler.GetProjectStatusHistory)
 
 		// Project analytics
 		projects.GET("/by-timeframe", projectHandler.GetProjectsByTimeframe)
 		projects.GET("/count-by-type", projectHandler.GetProjectCountByType)
 		projects.GET("/stage-transitions", projectHandler.GetProjectsWithStageTransitions)
 		projects.GET("/approaching-deadline", projectHandler.GetProjectsApproachingDeadline)
-		projects.GET("/stage-delayed", projectHandler.GetProjectsInStageForTooLong)
+		projects.GET("/sla-breaches", projectHandler.GetSLABreaches)
+		projects.GET("/sla-breaches/committees", projectHandler.GetCommitteeSLASummary)
+		projects.GET("/:id/schedule", projectHandler.GetProjectSLA)
+		projects.POST("/:id/schedule/replan", projectHandler.ReplanProjectStages)
 
 		// Project relationships
 		projects.GET("/by-reference-base", projectHandler.GetProjectsByReferenceBase)
+		projects.GET("/by-reference", projectHandler.GetProjectByReference)
+		projects.GET("/:id/references", projectHandler.GetProjectReferences)
 		projects.GET("/:id/related", projectHandler.GetRelatedProjects)
+		projects.GET("/:id/relationships", projectHandler.GetProjectRelationships)
+		projects.POST("/:id/relationships", projectHandler.CreateProjectRelationship)
+		projects.DELETE("/:id/relationships/:relationshipId", projectHandler.DeleteProjectRelationship)
 
 		// Project versioning
 		projects.POST("/:id/revision", projectHandler.CreateProjectRevision)
+		projects.POST("/:id/amendments", projectHandler.CreateAmendment)
+		projects.POST("/:id/corrigenda", projectHandler.CreateCorrigendum)
+		projects.GET("/:id/supplements", projectHandler.GetSupplements)
+
+		// Adoption of international documents
+		projects.POST("/adoptions", projectHandler.RegisterAdoption)
+		projects.GET("/adoptions", projectHandler.ListAdoptions)
+
+		// Import of legacy projects and published standards
+		projects.POST("/imports", projectHandler.ImportProjects)
+		projects.GET("/imports", projectHandler.ListImportBatches)
+		projects.GET("/imports/:batchId", projectHandler.GetImportBatch)
+		projects.POST("/imports/:batchId/undo", projectHandler.UndoImportBatch)
+
+		// Transfers between committees and working groups
+		projects.GET("/transfers/pending", projectHandler.GetPendingTransfers)
+		projects.POST("/transfers/:transferId/approve", projectHandler.ApproveProjectTransfer)
+		projects.POST("/transfers/:transferId/reject", projectHandler.RejectProjectTransfer)
+		projects.GET("/:id/transfers", projectHandler.GetProjectTransfers)
+		projects.POST("/:id/transfers", projectHandler.RequestProjectTransfer)
+
+		// Rollbacks to an earlier stage
+		projects.GET("/rollbacks/:rollbackId", projectHandler.GetProjectRollback)
+		projects.GET("/:id/rollbacks", projectHandler.GetProjectRollbacks)
+		projects.POST("/:id/rollbacks", projectHandler.RollbackProject)
+
+		// TC work programmes
+		projects.GET("/work-programmes/snapshots/:snapshotId", projectHandler.GetWorkProgrammeSnapshot)
+		projects.POST("/work-programmes/snapshots/:snapshotId/publish", projectHandler.PublishWorkProgrammeSnapshot)
+		projects.GET("/work-programmes/:committeeId", projectHandler.GetWorkProgramme)
+		projects.GET("/work-programmes/:committeeId/published", projectHandler.GetPublishedWorkProgramme)
+		projects.GET("/work-programmes/:committeeId/snapshots", projectHandler.GetWorkProgrammeSnapshots)
+		projects.POST("/work-programmes/:committeeId/snapshots", projectHandler.CreateWorkProgrammeSnapshot)
 
 		// Dashboard and statistics
 		projects.GET("/statistics", projectHandler.GetDashboardStats)
@@ -289,6 +342,34 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 		acceptance.GET("/submission/compilation/:id/calculate-stats", acceptanceHandler.CalculateNSBResponseStats)
 		acceptance.POST("/submission/compilation/approve", acceptanceHandler.SetNSBResponseacceptanceApproval)
 		acceptance.GET("/submission/compilation/:id/results", acceptanceHandler.GetAcceptanceResults)
+
+		// Changes to NSB responses, decided by the TC secretariat
+		acceptance.POST("/submission/:id/changes", acceptanceHandler.SubmitNSBResponseChange)
+		acceptance.GET("/submission/:id/changes", acceptanceHandler.GetNSBResponseChanges)
+		acceptance.GET("/changes/pending", acceptanceHandler.GetPendingNSBResponseChanges)
+		acceptance.GET("/changes/project/:id", acceptanceHandler.GetProjectNSBResponseChanges)
+		acceptance.GET("/changes/:changeId", acceptanceHandler.GetNSBResponseChange)
+		acceptance.POST("/changes/:changeId/approve", acceptanceHandler.ApproveNSBResponseChange)
+		acceptance.POST("/changes/:changeId/reject", acceptanceHandler.RejectNSBResponseChange)
+
+		// Circulation windows and the rules deciding the acceptance of proposals
+		acceptance.POST("/circulation/:projectId", acceptanceHandler.OpenCirculation)
+		acceptance.POST("/circulation/:projectId/overrides", acceptanceHandler.GrantCirculationOverride)
+		acceptance.GET("/circulation/:projectId/overrides", acceptanceHandler.GetCirculationOverrides)
+		acceptance.POST("/rules", acceptanceHandler.SaveAcceptanceRule)
+		acceptance.GET("/rules", acceptanceHandler.GetActiveAcceptanceRules)
+		acceptance.GET("/rules/versions", acceptanceHandler.GetAcceptanceRuleVersions)
+		acceptance.GET("/rules/applicable", acceptanceHandler.GetApplicableAcceptanceRule)
+
+		// Development track scheduling
+		acceptance.PUT("/track/:projectId", acceptanceHandler.ChangeDevelopmentTrack)
+		acceptance.POST("/non-working-periods", acceptanceHandler.CreateNonWorkingPeriod)
+		acceptance.GET("/non-working-periods", acceptanceHandler.GetNonWorkingPeriods)
+		acceptance.DELETE("/non-working-periods/:id", acceptanceHandler.DeleteNonWorkingPeriod)
+
+		// NSB response matrix circulated to the SMC
+		acceptance.GET("/matrix/:projectId", acceptanceHandler.GetAcceptanceMatrix)
+		acceptance.POST("/matrix/:projectId/documents", acceptanceHandler.ExportAcceptanceMatrix)
 	}
 
 	// Comments and  Observations Route
@@ -301,6 +382,8 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 		comment.PUT("/:comment_id", commentHandler.UpdateComment)
 		comment.DELETE("/:id", commentHandler.DeleteComment)
 		comment.GET("/project/:id", commentHandler.GetCommentsByProjectID)
+		comment.POST("/project/:id/template", commentHandler.ImportCommentTemplate)
+		comment.GET("/project/:id/template", commentHandler.ExportCommentTemplate)
 
 		// Public comments
 		comment.POST("/public/", publicCommentHandler.CreateNationalConsultation)
@@ -309,6 +392,8 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 		comment.PUT("/public/:comment_id", publicCommentHandler.UpdateNationalConsultation)
 		comment.DELETE("/public/:id", publicCommentHandler.DeleteNationalConsultation)
 		comment.GET("/public/project/:id", publicCommentHandler.GetNationalConsultationsByProjectID)
+		comment.POST("/public/project/:id/template", publicCommentHandler.ImportCommentTemplate)
+		comment.GET("/public/project/:id/template", publicCommentHandler.ExportCommentTemplate)
 	}
 
 	// Balloting and  Observations Route
@@ -319,7 +404,8 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 		balloting.GET("/votes/:id", voteHandler.GetVoteByID)
 		balloting.GET("/votes/ballot/:id", voteHandler.GetVotesByBallotingID)
 		balloting.GET("/votes/project/:id", voteHandler.GetVotesByProjectID)
-		balloting.PUT("/votes", voteHandler.UpdateVote)
+		balloting.PUT("/votes/:id", voteHandler.UpdateVote)
+		balloting.GET("/votes/:id/revisions", voteHandler.GetVoteRevisions)
 		balloting.DELETE("/votes/:id", voteHandler.DeleteVote)
 		balloting.GET("/votes/all", voteHandler.GetAllVotesWithAssociations)
 		balloting.GET("/votes/count", voteHandler.CountVotesByBalloting)
@@ -332,6 +418,23 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 		balloting.POST("/recommendation", ballotingHandler.RecommendFDARS)
 		balloting.GET("/recommendation/verify/:project_id", ballotingHandler.VerifyFDARSRecommendation)
 		balloting.POST("/approve", projectHandler.ApproveFDARS)
+
+		// Voting rules
+		balloting.POST("/rules", ballotingHandler.SaveVotingRule)
+		balloting.GET("/rules", ballotingHandler.GetActiveVotingRules)
+		balloting.GET("/rules/applicable", ballotingHandler.GetApplicableVotingRule)
+		balloting.GET("/rules/versions", ballotingHandler.GetVotingRuleVersions)
+		balloting.GET("/rules/:id", ballotingHandler.GetVotingRuleByID)
+		balloting.DELETE("/rules/procedure/:procedure", ballotingHandler.DeactivateVotingRule)
+
+		// Committee internal ballots
+		balloting.POST("/committee", ballotingHandler.CreateCommitteeBallot)
+		balloting.GET("/committee/tc/:committeeId", ballotingHandler.GetCommitteeBallots)
+		balloting.GET("/committee/project/:projectId", ballotingHandler.GetProjectCommitteeBallots)
+		balloting.GET("/committee/:id", ballotingHandler.GetCommitteeBallot)
+		balloting.POST("/committee/:id/votes", ballotingHandler.CastCommitteeBallotVote)
+		balloting.POST("/committee/:id/close", ballotingHandler.CloseCommitteeBallot)
+		balloting.POST("/committee/:id/cancel", ballotingHandler.CancelCommitteeBallot)
 	}
 
 	// Meeting Route
@@ -465,5 +568,55 @@ func InitRoutes(services *services.ServiceContainer) (*gin.Engine, error) {
 		auditLogs.POST("/export", auditLogHandler.ExportAuditLogs)
 	}
 
+	// Background Jobs API
+	jobs := api.Group("/jobs")
+	jobs.Use(middleware.AuthMiddleware())
+	jobs.Use(middleware.DynamicAuthorize(services.PermissionResourceService))
+	{
+		jobs.GET("/", schedulerHandler.ListJobs)
+		jobs.GET("/:name/runs", schedulerHandler.GetJobRuns)
+		jobs.POST("/:name/pause", schedulerHandler.PauseJob)
+		jobs.POST("/:name/resume", schedulerHandler.ResumeJob)
+		jobs.POST("/:name/trigger", schedulerHandler.TriggerJob)
+	}
+
+	// Numbering Registry API
+	numbering := api.Group("/numbering")
+	numbering.Use(middleware.AuthMiddleware())
+	numbering.Use(middleware.DynamicAuthorize(services.PermissionResourceService))
+	{
+		numbering.GET("/sequences", numberingHandler.GetSequences)
+		numbering.GET("/scopes/:scope/next", numberingHandler.PeekNextNumber)
+		numbering.POST("/scopes/:scope/next", numberingHandler.NextNumber)
+		numbering.GET("/scopes/:scope/reservations", numberingHandler.GetReservations)
+		numbering.POST("/scopes/:scope/reservations", numberingHandler.ReserveNumber)
+		numbering.POST("/reservations/:id/use", numberingHandler.UseReservation)
+		numbering.POST("/reservations/:id/release", numberingHandler.ReleaseReservation)
+	}
+
+	// Transition Sagas API
+	sagas := api.Group("/transition-sagas")
+	sagas.Use(middleware.AuthMiddleware())
+	sagas.Use(middleware.DynamicAuthorize(services.PermissionResourceService))
+	{
+		sagas.GET("/", sagaHandler.ListSagas)
+		sagas.GET("/stuck", sagaHandler.GetStuckSagas)
+		sagas.GET("/:id", sagaHandler.GetSaga)
+		sagas.POST("/:id/resume", sagaHandler.ResumeSaga)
+		sagas.POST("/:id/compensate", sagaHandler.CompensateSaga)
+	}
+
+	// Systematic reviews of published standards
+	reviews := api.Group("/systematic-reviews")
+	reviews.Use(middleware.AuthMiddleware())
+	reviews.Use(middleware.DynamicAuthorize(services.PermissionResourceService))
+	{
+		reviews.GET("/", reviewHandler.ListReviews)
+		reviews.POST("/", reviewHandler.OpenReview)
+		reviews.GET("/:id", reviewHandler.GetReview)
+		reviews.POST("/:id/responses", reviewHandler.SubmitResponse)
+		reviews.POST("/:id/close", reviewHandler.CloseReview)
+	}
+
 	return router, nil
 }
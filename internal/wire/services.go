@@ -29,12 +29,14 @@ var ServiceSet = wire.NewSet(
 	repository.NewProposalRepository,
 	services.NewProposalService,
 	repository.NewAcceptanceRepository,
+	repository.NewNSBResponseStatusChangeRepository,
 	services.NewAcceptanceService,
 	repository.NewCommentRepository,
 	services.NewCommentService,
 	repository.NewConsultationRepository,
 	services.NewNationalConsultationService,
 	repository.NewBallotingRepository,
+	repository.NewVotingRuleRepository,
 	services.NewBallotingService,
 	repository.NewMeetingRepository,
 	services.NewMeetingService,
@@ -52,6 +54,14 @@ var ServiceSet = wire.NewSet(
 	services.NewNotificationService,
 	repository.NewReportsRepository,
 	services.NewReportsService,
+	repository.NewSchedulerRepository,
+	services.NewSchedulerService,
+	repository.NewNumberingRepository,
+	services.NewNumberingService,
+	repository.NewSagaRepository,
+	services.NewSagaService,
+	repository.NewSystematicReviewRepository,
+	services.NewSystematicReviewService,
 )
 
 func GetEmailConfigurations() *services.EmailConfig {
@@ -29,31 +29,41 @@ func InitializeServices(db *gorm.DB) (*services.ServiceContainer, error) {
 	auditLogRepository := repository.NewAuditLogRepository(db)
 	auditLogService := services.NewAuditLogService(auditLogRepository, memberRepository)
 	documentService := services.NewDocumentService(documentRepository, projectRepository, graphServiceClient, tokenManager, auditLogService)
-	projectService := services.NewProjectService(projectRepository, documentService, auditLogService)
+	sagaRepository := repository.NewSagaRepository(db)
+	sagaService := services.NewSagaService(sagaRepository, documentService)
+	rbacRepository := repository.NewRbacRepository(db)
+	notificationRepository := repository.NewNotificationRepository(db)
+	ballotingRepository := repository.NewBallotingRepository(db)
+	notificationService := services.NewNotificationService(notificationRepository, memberRepository, rbacRepository, ballotingRepository, projectRepository, emailService, db)
+	projectService := services.NewProjectService(projectRepository, documentService, auditLogService, sagaService, notificationService)
 	proposalRepository := repository.NewProposalRepository(db)
 	proposalService := services.NewProposalService(proposalRepository)
 	acceptanceRepository := repository.NewAcceptanceRepository(db)
-	acceptanceService := services.NewAcceptanceService(acceptanceRepository, documentService, projectService)
+	nsbResponseStatusChangeRepository := repository.NewNSBResponseStatusChangeRepository(db)
+	acceptanceService := services.NewAcceptanceService(acceptanceRepository, nsbResponseStatusChangeRepository, sagaService, auditLogService, notificationService)
 	commentRepository := repository.NewCommentRepository(db)
 	commentService := services.NewCommentService(commentRepository)
 	consultationRepository := repository.NewConsultationRepository(db)
 	nationalConsultationService := services.NewNationalConsultationService(consultationRepository)
-	ballotingRepository := repository.NewBallotingRepository(db)
-	ballotingService := services.NewBallotingService(ballotingRepository, auditLogService)
 	meetingRepository := repository.NewMeetingRepository(db)
 	meetingService := services.NewMeetingService(meetingRepository)
 	libraryRepository := repository.NewLibraryRepository(db)
 	libraryService := services.NewLibraryService(libraryRepository, memberService)
 	standardRepository := repository.NewStandardRepository(db)
 	standardService := services.NewStandardService(standardRepository)
-	rbacRepository := repository.NewRbacRepository(db)
 	rbacService := services.NewRbacService(rbacRepository)
 	permissionResourceRepository := repository.NewPermissionResourceRepository(db)
 	permissionResourceService := services.NewPermissionResourceService(permissionResourceRepository, memberService)
-	notificationRepository := repository.NewNotificationRepository(db)
-	notificationService := services.NewNotificationService(notificationRepository, memberRepository, rbacRepository, ballotingRepository, emailService, db)
+	votingRuleRepository := repository.NewVotingRuleRepository(db)
+	ballotingService := services.NewBallotingService(ballotingRepository, votingRuleRepository, auditLogService, notificationService)
 	reportsRepository := repository.NewReportsRepository(db)
 	reportsService := services.NewReportsService(reportsRepository, projectRepository, memberRepository)
-	serviceContainer := services.NewServiceContainer(organizationService, memberService, projectService, documentService, proposalService, acceptanceService, commentService, emailService, nationalConsultationService, ballotingService, meetingService, libraryService, standardService, rbacService, tokenManager, permissionResourceService, notificationService, reportsService, auditLogService)
+	schedulerRepository := repository.NewSchedulerRepository(db)
+	schedulerService := services.NewSchedulerService(schedulerRepository)
+	numberingRepository := repository.NewNumberingRepository(db)
+	numberingService := services.NewNumberingService(numberingRepository)
+	systematicReviewRepository := repository.NewSystematicReviewRepository(db)
+	systematicReviewService := services.NewSystematicReviewService(systematicReviewRepository, auditLogService)
+	serviceContainer := services.NewServiceContainer(organizationService, memberService, projectService, documentService, proposalService, acceptanceService, commentService, emailService, nationalConsultationService, ballotingService, meetingService, libraryService, standardService, rbacService, tokenManager, permissionResourceService, notificationService, reportsService, auditLogService, schedulerService, numberingService, sagaService, systematicReviewService)
 	return serviceContainer, nil
 }
@@ -46,7 +46,7 @@ func (h *AcceptanceHandler) CreateNSBResponse(c *gin.Context) {
 
 	err := h.AcceptanceService.CreateNSBResponse(&payload)
 	if err != nil {
-		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		showAcceptanceError(c, err)
 		return
 	}
 
@@ -89,7 +89,7 @@ func (h *AcceptanceHandler) UpdateNSBResponse(c *gin.Context) {
 
 	err := h.AcceptanceService.UpdateNSBResponse(&payload)
 	if err != nil {
-		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		showAcceptanceError(c, err)
 		return
 	}
 
@@ -99,7 +99,7 @@ func (h *AcceptanceHandler) UpdateNSBResponse(c *gin.Context) {
 func (h *AcceptanceHandler) DeleteNSBResponse(c *gin.Context) {
 	err := h.AcceptanceService.DeleteNSBResponse(c.Param("id"))
 	if err != nil {
-		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		showAcceptanceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, "Response deleted successfully")
@@ -212,7 +212,7 @@ func (h *AcceptanceHandler) SetNSBResponseacceptanceApproval(c *gin.Context) {
 
 	err := h.AcceptanceService.SetAcceptanceApproval(payload)
 	if err != nil {
-		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		showAcceptanceError(c, err)
 		return
 	}
 
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type circulationPayload struct {
+	CirculationDate *time.Time `json:"circulation_date"`
+	ClosingDate     time.Time  `json:"closing_date" binding:"required"`
+}
+
+type circulationOverridePayload struct {
+	NationalStandardBodyID string    `json:"national_standard_body_id" binding:"required,uuid"`
+	Reason                 string    `json:"reason" binding:"required"`
+	ExpiresAt              time.Time `json:"expires_at" binding:"required"`
+}
+
+// OpenCirculation sets the window in which the NSBs can respond to a project's proposal
+// @Summary Open the circulation of a proposal
+// @Description Sets the circulation and closing dates of the proposal. Responses are only accepted within the window, and the results are compiled automatically once it closes. Only the TC secretary may open the circulation.
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID"
+// @Param payload body circulationPayload true "Circulation window, the circulation date defaults to now"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /acceptance/circulation/{projectId} [post]
+func (h *AcceptanceHandler) OpenCirculation(c *gin.Context) {
+	var payload circulationPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	circulationDate := time.Now()
+	if payload.CirculationDate != nil {
+		circulationDate = *payload.CirculationDate
+	}
+	if !payload.ClosingDate.After(circulationDate) {
+		utilities.ShowMessage(c, http.StatusBadRequest, "The closing date must be after the circulation date")
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	acceptance, err := h.AcceptanceService.OpenCirculation(c.Param("projectId"), userIDStr, circulationDate, payload.ClosingDate, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showAcceptanceError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "acceptance", acceptance)
+}
+
+// GrantCirculationOverride lets an NSB respond to a proposal outside its circulation window
+// @Summary Allow a late NSB response
+// @Description Lets the NSB respond to the proposal outside its circulation window until the override expires. Only the TC secretary may grant overrides.
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID"
+// @Param payload body circulationOverridePayload true "NSB, reason and expiry"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /acceptance/circulation/{projectId}/overrides [post]
+func (h *AcceptanceHandler) GrantCirculationOverride(c *gin.Context) {
+	var payload circulationOverridePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !payload.ExpiresAt.After(time.Now()) {
+		utilities.ShowMessage(c, http.StatusBadRequest, "The override must expire in the future")
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	override := models.CirculationOverride{
+		NationalStandardBodyID: payload.NationalStandardBodyID,
+		Reason:                 payload.Reason,
+		ExpiresAt:              payload.ExpiresAt,
+	}
+	if err := h.AcceptanceService.GrantCirculationOverride(c.Param("projectId"), &override, userIDStr, ipAddress, userAgent, sessionID, requestID); err != nil {
+		showAcceptanceError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "override", override)
+}
+
+// GetCirculationOverrides lists the overrides granted on a project's proposal
+// @Summary List the late response overrides of a proposal
+// @Tags acceptance
+// @Produce json
+// @Param projectId path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /acceptance/circulation/{projectId}/overrides [get]
+func (h *AcceptanceHandler) GetCirculationOverrides(c *gin.Context) {
+	overrides, err := h.AcceptanceService.GetCirculationOverrides(c.Param("projectId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "overrides", overrides)
+}
+
+// SaveAcceptanceRule stores a new version of the acceptance rule for a basis
+// @Summary Save an acceptance rule
+// @Description Creates the next version of the rule deciding proposals of the basis. The previous version is kept for past compilations.
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param payload body models.AcceptanceRule true "Acceptance rule"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /acceptance/rules [post]
+func (h *AcceptanceHandler) SaveAcceptanceRule(c *gin.Context) {
+	var payload models.AcceptanceRule
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if err := h.AcceptanceService.SaveAcceptanceRule(&payload, &userIDStr, ipAddress, userAgent, sessionID, requestID); err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "rule", payload)
+}
+
+// GetActiveAcceptanceRules lists the acceptance rules in force
+// @Summary List the active acceptance rules
+// @Tags acceptance
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /acceptance/rules [get]
+func (h *AcceptanceHandler) GetActiveAcceptanceRules(c *gin.Context) {
+	rules, err := h.AcceptanceService.GetActiveAcceptanceRules()
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rules", rules)
+}
+
+// GetAcceptanceRuleVersions lists every version of the acceptance rule for a basis
+// @Summary List the versions of an acceptance rule
+// @Tags acceptance
+// @Produce json
+// @Param basis query string true "ADOPTION or DEVELOPMENT"
+// @Success 200 {object} map[string]interface{}
+// @Router /acceptance/rules/versions [get]
+func (h *AcceptanceHandler) GetAcceptanceRuleVersions(c *gin.Context) {
+	basis, ok := acceptanceBasisQuery(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.AcceptanceService.GetAcceptanceRuleVersions(basis)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rules", rules)
+}
+
+// GetApplicableAcceptanceRule returns the rule that decides proposals of a basis, which is the
+// built-in default when none has been saved
+// @Summary Get the applicable acceptance rule
+// @Tags acceptance
+// @Produce json
+// @Param basis query string true "ADOPTION or DEVELOPMENT"
+// @Success 200 {object} map[string]interface{}
+// @Router /acceptance/rules/applicable [get]
+func (h *AcceptanceHandler) GetApplicableAcceptanceRule(c *gin.Context) {
+	basis, ok := acceptanceBasisQuery(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.AcceptanceService.GetApplicableAcceptanceRule(basis)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rule", rule)
+}
+
+func acceptanceBasisQuery(c *gin.Context) (models.AcceptanceBasis, bool) {
+	basis := models.AcceptanceBasis(c.Query("basis"))
+	if basis != models.AcceptanceBasisAdoption && basis != models.AcceptanceBasisDevelopment {
+		utilities.ShowMessage(c, http.StatusBadRequest, "basis must be ADOPTION or DEVELOPMENT")
+		return "", false
+	}
+	return basis, true
+}
+
+func showAcceptanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Project, proposal or NSB not found")
+	case errors.Is(err, repository.ErrNotCirculationSecretary):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrOutsideCirculationWindow),
+		errors.Is(err, repository.ErrSMCApprovalNotOpen),
+		errors.Is(err, repository.ErrAcceptanceDecided):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAcceptanceMatrix returns the compilation of the NSB responses to a project's proposal
+// @Summary Get the NSB response matrix of a proposal
+// @Description Returns the per-NSB responses, totals and evaluation of the acceptance criteria as JSON, or as an XLSX, DOCX or PDF download in the layout of the compilation template.
+// @Tags acceptance
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Produce application/pdf
+// @Param projectId path string true "Project ID"
+// @Param format query string false "json (default), xlsx, docx or pdf"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /acceptance/matrix/{projectId} [get]
+func (h *AcceptanceHandler) GetAcceptanceMatrix(c *gin.Context) {
+	matrix, err := h.AcceptanceService.GetAcceptanceMatrix(c.Param("projectId"))
+	if err != nil {
+		showAcceptanceMatrixError(c, err)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format == "json" {
+		utilities.Show(c, http.StatusOK, "matrix", matrix)
+		return
+	}
+
+	file, err := h.AcceptanceService.RenderAcceptanceMatrix(matrix, format)
+	if err != nil {
+		showAcceptanceMatrixError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// ExportAcceptanceMatrix stores the NSB response matrix as a document of the project
+// @Summary Store the NSB response matrix of a proposal
+// @Description Renders the current NSB response matrix and adds it to the project's related documents, ready to be circulated to the SMC.
+// @Tags acceptance
+// @Produce json
+// @Param projectId path string true "Project ID"
+// @Param format query string false "pdf (default), xlsx or docx"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /acceptance/matrix/{projectId}/documents [post]
+func (h *AcceptanceHandler) ExportAcceptanceMatrix(c *gin.Context) {
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	doc, err := h.AcceptanceService.ExportAcceptanceMatrix(c.Param("projectId"), c.DefaultQuery("format", "pdf"), userIDStr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showAcceptanceMatrixError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "document", doc)
+}
+
+func showAcceptanceMatrixError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Project, proposal or acceptance not found")
+	case errors.Is(err, services.ErrUnsupportedAcceptanceMatrixFormat):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
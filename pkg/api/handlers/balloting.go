@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/ekbaya/asham/pkg/db/repository"
 	"github.com/ekbaya/asham/pkg/domain/models"
 	"github.com/ekbaya/asham/pkg/domain/services"
 	"github.com/ekbaya/asham/pkg/utilities"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // BallotingHandler handles HTTP requests related to balloting
@@ -240,3 +243,97 @@ func (h *BallotingHandler) VerifyFDARSRecommendation(c *gin.Context) {
 
 	utilities.ShowMessage(c, http.StatusOK, "Recommendation verified updated successfully")
 }
+
+// SaveVotingRule creates a new version of the voting rule for a procedure
+func (h *BallotingHandler) SaveVotingRule(c *gin.Context) {
+	var payload models.VotingRule
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			formattedErrors := utilities.FormatValidationErrors(validationErrors)
+			utilities.ShowError(c, http.StatusBadRequest, formattedErrors)
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	err := h.ballotingService.SaveVotingRule(&payload, &userIDStr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "rule", payload)
+}
+
+// GetActiveVotingRules lists the voting rule currently in force for each procedure
+func (h *BallotingHandler) GetActiveVotingRules(c *gin.Context) {
+	rules, err := h.ballotingService.GetActiveVotingRules()
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rules", rules)
+}
+
+// GetApplicableVotingRule returns the rule that decides ballots for the procedure in the query
+func (h *BallotingHandler) GetApplicableVotingRule(c *gin.Context) {
+	rule, err := h.ballotingService.GetApplicableVotingRule(models.Procedure(c.Query("procedure")))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rule", rule)
+}
+
+// GetVotingRuleVersions lists every version of the rule for the procedure in the query
+func (h *BallotingHandler) GetVotingRuleVersions(c *gin.Context) {
+	rules, err := h.ballotingService.GetVotingRuleVersions(models.Procedure(c.Query("procedure")))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rules", rules)
+}
+
+// GetVotingRuleByID retrieves a single rule version, e.g. the one referenced by a ballot result
+func (h *BallotingHandler) GetVotingRuleByID(c *gin.Context) {
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	rule, err := h.ballotingService.GetVotingRuleByID(ruleID)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rule", rule)
+}
+
+// DeactivateVotingRule removes a procedure specific rule so the default rule applies
+func (h *BallotingHandler) DeactivateVotingRule(c *gin.Context) {
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	err := h.ballotingService.DeactivateVotingRule(models.Procedure(c.Param("procedure")), &userIDStr, ipAddress, userAgent, sessionID, requestID)
+	if errors.Is(err, repository.ErrDefaultVotingRule) {
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utilities.ShowMessage(c, http.StatusNotFound, "No active voting rule for this procedure")
+		return
+	}
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.ShowMessage(c, http.StatusOK, "Voting rule deactivated successfully")
+}
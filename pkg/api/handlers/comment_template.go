@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImportCommentTemplate imports the comments an NSB prepared in the Word or Excel comment template
+// @Summary Import a comment template
+// @Description Reads the ISO comment template (.docx or .xlsx) and adds its comments to the project. Every line is validated first, must be the NSB's own and must leave the observations of the secretariat blank; the errors are reported by row, and nothing is imported while there are errors or on a dry run.
+// @Tags comments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param file formData file true "Comment template (.docx or .xlsx)"
+// @Param dry_run formData bool false "Only validate the template"
+// @Success 201 {object} map[string]interface{}
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /comments/project/{id}/template [post]
+func (h *CommentHandler) ImportCommentTemplate(c *gin.Context) {
+	projectID, fileName, data, dryRun, memberID, ok := readCommentTemplateForm(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.commentService.ImportCommentTemplate(projectID, fileName, data, dryRun, memberID)
+	if err != nil {
+		showCommentTemplateError(c, err)
+		return
+	}
+	showCommentTemplateReport(c, report)
+}
+
+// ExportCommentTemplate compiles the comments on a project's draft into the comment template
+// @Summary Export the comments of a project
+// @Description Returns the comments on the project's draft, sorted by clause, in the ISO comment template. A project without comments gives a blank template.
+// @Tags comments
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Param id path string true "Project ID"
+// @Param format query string false "xlsx (default) or docx"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /comments/project/{id}/template [get]
+func (h *CommentHandler) ExportCommentTemplate(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	file, err := h.commentService.ExportCommentTemplate(projectID.String(), c.DefaultQuery("format", "xlsx"))
+	if err != nil {
+		showCommentTemplateError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// ImportCommentTemplate imports the public consultation comments an NSB prepared in the Word or
+// Excel comment template
+// @Summary Import a public consultation comment template
+// @Description Reads the ISO comment template (.docx or .xlsx) and adds its comments to the public consultation on the project's DARS. Every line is validated first, must be the NSB's own and must leave the observations of the secretariat blank; the errors are reported by row, and nothing is imported while there are errors or on a dry run. Comments are only imported while the public review of the DARS is open.
+// @Tags comments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param file formData file true "Comment template (.docx or .xlsx)"
+// @Param dry_run formData bool false "Only validate the template"
+// @Success 201 {object} map[string]interface{}
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /comments/public/project/{id}/template [post]
+func (h *NationalConsultationHandler) ImportCommentTemplate(c *gin.Context) {
+	projectID, fileName, data, dryRun, memberID, ok := readCommentTemplateForm(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.NationalConsultationService.ImportCommentTemplate(projectID, fileName, data, dryRun, memberID)
+	if err != nil {
+		showCommentTemplateError(c, err)
+		return
+	}
+	showCommentTemplateReport(c, report)
+}
+
+// ExportCommentTemplate compiles the public consultation comments on a project's draft into the
+// comment template
+// @Summary Export the public consultation comments of a project
+// @Description Returns the public consultation comments on the project's draft, sorted by clause, in the ISO comment template.
+// @Tags comments
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Param id path string true "Project ID"
+// @Param format query string false "xlsx (default) or docx"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /comments/public/project/{id}/template [get]
+func (h *NationalConsultationHandler) ExportCommentTemplate(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	file, err := h.NationalConsultationService.ExportCommentTemplate(projectID.String(), c.DefaultQuery("format", "xlsx"))
+	if err != nil {
+		showCommentTemplateError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// readCommentTemplateForm reads the project, the uploaded template and the importing member of a
+// template import, answering the request itself when one of them is missing
+func readCommentTemplateForm(c *gin.Context) (projectID, fileName string, data []byte, dryRun bool, memberID string, ok bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Error retrieving template: "+err.Error())
+		return
+	}
+	data, err = readFormFile(header)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Error reading template: "+err.Error())
+		return
+	}
+
+	dryRun, _ = strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+	return id.String(), header.Filename, data, dryRun, userID.(string), true
+}
+
+func showCommentTemplateReport(c *gin.Context, report *models.CommentTemplateReport) {
+	switch {
+	case report.Imported > 0:
+		utilities.Show(c, http.StatusCreated, "report", report)
+	case report.DryRun:
+		utilities.Show(c, http.StatusOK, "report", report)
+	default:
+		utilities.Show(c, http.StatusUnprocessableEntity, "report", report)
+	}
+}
+
+func showCommentTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Project not found")
+	case errors.Is(err, services.ErrUnsupportedCommentTemplate),
+		errors.Is(err, services.ErrInvalidCommentTemplate):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrProjectNotActive),
+		errors.Is(err, repository.ErrPublicReviewClosed):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
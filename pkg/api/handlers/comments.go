@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/ekbaya/asham/pkg/db/repository"
 	"github.com/ekbaya/asham/pkg/domain/models"
 	"github.com/ekbaya/asham/pkg/domain/services"
 	"github.com/ekbaya/asham/pkg/utilities"
@@ -49,6 +51,10 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 
 	err := h.commentService.Create(&payload)
 	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotActive) {
+			utilities.ShowMessage(c, http.StatusConflict, err.Error())
+			return
+		}
 		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tallying rules applied when the secretary does not set them: the leading option needs half of
+// the counted votes and half of the P-members must vote
+const (
+	defaultCommitteeBallotThreshold = 0.5
+	defaultCommitteeBallotQuorum    = 0.5
+)
+
+type committeeBallotPayload struct {
+	TechnicalCommitteeID string                        `json:"technical_committee_id" binding:"required,uuid"`
+	Subject              models.CommitteeBallotSubject `json:"subject" binding:"required,oneof=NEW_WORK_ITEM CONVENOR CD_REGISTRATION OTHER"`
+	Title                string                        `json:"title" binding:"required"`
+	Question             string                        `json:"question" binding:"required"`
+	Options              []string                      `json:"options" binding:"required,min=2,dive,required"`
+	ProjectID            *string                       `json:"project_id" binding:"omitempty,uuid"`
+	MeetingID            *string                       `json:"meeting_id" binding:"omitempty,uuid"`
+	OpensAt              *time.Time                    `json:"opens_at"` // Defaults to now
+	ClosesAt             time.Time                     `json:"closes_at" binding:"required"`
+	ApprovalThreshold    float64                       `json:"approval_threshold" binding:"omitempty,gt=0,lte=1"`
+	QuorumShare          float64                       `json:"quorum_share" binding:"omitempty,gt=0,lte=1"`
+	ExcludeAbstentions   bool                          `json:"exclude_abstentions"`
+}
+
+type committeeBallotVotePayload struct {
+	OptionID *string `json:"option_id" binding:"required_without=Abstain,omitempty,uuid"`
+	Abstain  bool    `json:"abstain"`
+	Comment  string  `json:"comment"`
+}
+
+type committeeBallotCancelPayload struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CreateCommitteeBallot opens an internal ballot of a technical committee
+// @Summary Open a committee internal ballot
+// @Description Opens a vote by correspondence of the committee's P-members, e.g. on a new work item or a convenor. Only the TC secretary may open a ballot.
+// @Tags balloting
+// @Accept json
+// @Produce json
+// @Param payload body committeeBallotPayload true "Question, options and tallying rules"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /balloting/committee [post]
+func (h *BallotingHandler) CreateCommitteeBallot(c *gin.Context) {
+	var payload committeeBallotPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	opensAt := time.Now()
+	if payload.OpensAt != nil {
+		opensAt = *payload.OpensAt
+	}
+	if !payload.ClosesAt.After(opensAt) || !payload.ClosesAt.After(time.Now()) {
+		utilities.ShowMessage(c, http.StatusBadRequest, "The closing date must be in the future and after the opening date")
+		return
+	}
+
+	ballot := models.CommitteeBallot{
+		TechnicalCommitteeID: payload.TechnicalCommitteeID,
+		Subject:              payload.Subject,
+		Title:                payload.Title,
+		Question:             payload.Question,
+		ProjectID:            payload.ProjectID,
+		MeetingID:            payload.MeetingID,
+		OpensAt:              opensAt,
+		ClosesAt:             payload.ClosesAt,
+		ApprovalThreshold:    payload.ApprovalThreshold,
+		QuorumShare:          payload.QuorumShare,
+		ExcludeAbstentions:   payload.ExcludeAbstentions,
+	}
+	if ballot.ApprovalThreshold == 0 {
+		ballot.ApprovalThreshold = defaultCommitteeBallotThreshold
+	}
+	if ballot.QuorumShare == 0 {
+		ballot.QuorumShare = defaultCommitteeBallotQuorum
+	}
+	for _, label := range payload.Options {
+		ballot.Options = append(ballot.Options, models.CommitteeBallotOption{Label: label})
+	}
+
+	created, err := h.ballotingService.CreateCommitteeBallot(&ballot, userIDStr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showCommitteeBallotError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "ballot", created)
+}
+
+// CastCommitteeBallotVote records the vote of the member's NSB on an internal ballot
+// @Summary Vote on a committee internal ballot
+// @Description Records or changes the vote of the NSB whose national TC secretary is calling. Send an option or abstain.
+// @Tags balloting
+// @Accept json
+// @Produce json
+// @Param id path string true "Ballot ID"
+// @Param payload body committeeBallotVotePayload true "Option or abstention"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /balloting/committee/{id}/votes [post]
+func (h *BallotingHandler) CastCommitteeBallotVote(c *gin.Context) {
+	ballotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid ballot ID")
+		return
+	}
+
+	var payload committeeBallotVotePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	vote := models.CommitteeBallotVote{
+		BallotID: ballotID.String(),
+		OptionID: payload.OptionID,
+		Abstain:  payload.Abstain,
+		Comment:  payload.Comment,
+	}
+	if err := h.ballotingService.CastCommitteeBallotVote(&vote, userIDStr, ipAddress, userAgent, sessionID, requestID); err != nil {
+		showCommitteeBallotError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "vote", vote)
+}
+
+// CloseCommitteeBallot closes an internal ballot before its closing date
+// @Summary Close a committee internal ballot
+// @Tags balloting
+// @Produce json
+// @Param id path string true "Ballot ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /balloting/committee/{id}/close [post]
+func (h *BallotingHandler) CloseCommitteeBallot(c *gin.Context) {
+	ballotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid ballot ID")
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ballot, err := h.ballotingService.CloseCommitteeBallot(ballotID, &userIDStr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showCommitteeBallotError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "ballot", ballot)
+}
+
+// CancelCommitteeBallot withdraws an internal ballot without a result
+// @Summary Cancel a committee internal ballot
+// @Tags balloting
+// @Accept json
+// @Produce json
+// @Param id path string true "Ballot ID"
+// @Param payload body committeeBallotCancelPayload true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /balloting/committee/{id}/cancel [post]
+func (h *BallotingHandler) CancelCommitteeBallot(c *gin.Context) {
+	ballotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid ballot ID")
+		return
+	}
+
+	var payload committeeBallotCancelPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ballot, err := h.ballotingService.CancelCommitteeBallot(ballotID, userIDStr, payload.Reason, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showCommitteeBallotError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "ballot", ballot)
+}
+
+// GetCommitteeBallot returns an internal ballot with its voters, votes, result and trail of events
+// @Summary Get a committee internal ballot
+// @Tags balloting
+// @Produce json
+// @Param id path string true "Ballot ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /balloting/committee/{id} [get]
+func (h *BallotingHandler) GetCommitteeBallot(c *gin.Context) {
+	ballotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid ballot ID")
+		return
+	}
+
+	ballot, err := h.ballotingService.GetCommitteeBallot(ballotID)
+	if err != nil {
+		showCommitteeBallotError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "ballot", ballot)
+}
+
+// GetCommitteeBallots lists the internal ballots of a technical committee
+// @Summary List the internal ballots of a committee
+// @Tags balloting
+// @Produce json
+// @Param committeeId path string true "Technical committee ID"
+// @Param status query string false "OPEN, CLOSED or CANCELLED"
+// @Param project_id query string false "Only ballots linked to this project"
+// @Success 200 {object} map[string]interface{}
+// @Router /balloting/committee/tc/{committeeId} [get]
+func (h *BallotingHandler) GetCommitteeBallots(c *gin.Context) {
+	committeeID, err := uuid.Parse(c.Param("committeeId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid committee ID")
+		return
+	}
+
+	ballots, err := h.ballotingService.GetCommitteeBallots(committeeID.String(), models.CommitteeBallotStatus(c.Query("status")), c.Query("project_id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "ballots", ballots)
+}
+
+// GetProjectCommitteeBallots lists the internal ballots linked to a project
+// @Summary List the internal ballots linked to a project
+// @Tags balloting
+// @Produce json
+// @Param projectId path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /balloting/committee/project/{projectId} [get]
+func (h *BallotingHandler) GetProjectCommitteeBallots(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	ballots, err := h.ballotingService.GetProjectCommitteeBallots(projectID.String())
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "ballots", ballots)
+}
+
+func showCommitteeBallotError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Ballot, committee, project or meeting not found")
+	case errors.Is(err, repository.ErrCommitteeBallotLink),
+		errors.Is(err, repository.ErrCommitteeBallotOption),
+		errors.Is(err, repository.ErrNoCommitteeBallotVoters):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotCommitteeBallotSecretary),
+		errors.Is(err, repository.ErrNotEligibleToVote):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrCommitteeBallotNotOpen):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
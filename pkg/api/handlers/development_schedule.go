@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type developmentTrackPayload struct {
+	DevelopmentTrack models.DevelopmentTrack `json:"development_track" binding:"required,oneof=DEFAULT INTERNATIONAL FAST_TRACK"`
+}
+
+// ChangeDevelopmentTrack moves an accepted project to another development track
+// @Summary Change the development track of a project
+// @Description Recomputes the CD, DARS and FDARS target dates from the SMC approval date, skipping non-working periods, and replans the project's stages. Only the TC secretary may change the track.
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "Project ID"
+// @Param payload body developmentTrackPayload true "New development track"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /acceptance/track/{projectId} [put]
+func (h *AcceptanceHandler) ChangeDevelopmentTrack(c *gin.Context) {
+	var payload developmentTrackPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	acceptance, err := h.AcceptanceService.ChangeDevelopmentTrack(c.Param("projectId"), userIDStr, payload.DevelopmentTrack, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showDevelopmentScheduleError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "acceptance", acceptance)
+}
+
+// CreateNonWorkingPeriod adds a holiday or other non-working period to the development calendar
+// @Summary Add a non-working period
+// @Description Days in the period do not count towards the development time of a standard. Recurring periods fall on the same dates every year. Target dates already computed are not recomputed.
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param payload body models.NonWorkingPeriod true "Non-working period"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /acceptance/non-working-periods [post]
+func (h *AcceptanceHandler) CreateNonWorkingPeriod(c *gin.Context) {
+	var payload models.NonWorkingPeriod
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if err := h.AcceptanceService.CreateNonWorkingPeriod(&payload, userIDStr, ipAddress, userAgent, sessionID, requestID); err != nil {
+		showDevelopmentScheduleError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "period", payload)
+}
+
+// GetNonWorkingPeriods lists the non-working periods of the development calendar
+// @Summary List the non-working periods
+// @Description Returns the non-working periods of the development calendar, earliest first.
+// @Tags acceptance
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /acceptance/non-working-periods [get]
+func (h *AcceptanceHandler) GetNonWorkingPeriods(c *gin.Context) {
+	periods, err := h.AcceptanceService.GetNonWorkingPeriods()
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "periods", periods)
+}
+
+// DeleteNonWorkingPeriod removes a non-working period from the development calendar
+// @Summary Delete a non-working period
+// @Description Removes the period from the development calendar. Target dates already computed are not recomputed.
+// @Tags acceptance
+// @Produce json
+// @Param id path string true "Non-working period ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /acceptance/non-working-periods/{id} [delete]
+func (h *AcceptanceHandler) DeleteNonWorkingPeriod(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid non-working period ID")
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if err := h.AcceptanceService.DeleteNonWorkingPeriod(id, userIDStr, ipAddress, userAgent, sessionID, requestID); err != nil {
+		showDevelopmentScheduleError(c, err)
+		return
+	}
+
+	utilities.ShowMessage(c, http.StatusOK, "Non-working period deleted successfully")
+}
+
+func showDevelopmentScheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Project, acceptance or non-working period not found")
+	case errors.Is(err, repository.ErrNotTrackSecretary):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrInvalidDevelopmentTrack),
+		errors.Is(err, repository.ErrInvalidNonWorkingPeriod):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
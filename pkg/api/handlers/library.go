@@ -196,6 +196,10 @@ func (h *LibraryHandler) FindStandards(c *gin.Context) {
 		params["year"] = year
 	}
 
+	if equivalence := c.Query("equivalence"); equivalence != "" {
+		params["equivalence"] = equivalence
+	}
+
 	if sortBy := c.Query("sortBy"); sortBy != "" {
 		params["sortBy"] = sortBy
 	}
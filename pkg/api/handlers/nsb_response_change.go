@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type nsbResponseChangePayload struct {
+	Response                 models.Response `json:"response" binding:"required,oneof=AGREE_ADVANCE AGREE_ACCEPT_WORKING_DRAFT AGREE_CIRCULATE_CD AGREE_CIRCULATE_DARS NO_AGREEMENT ABSTENTION"`
+	IsCommittedToParticipate bool            `json:"is_committed_to_participate"`
+	Reason                   string          `json:"reason" binding:"required"`
+}
+
+type nsbResponseChangeDecisionPayload struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+// Helper function to extract audit parameters from Gin context
+func (h *AcceptanceHandler) getAuditParams(c *gin.Context) (string, string, string, string, string) {
+	userID, exists := c.Get("user_id")
+	var userIDStr string
+	if exists {
+		userIDStr = userID.(string)
+	}
+
+	return userIDStr, c.ClientIP(), c.GetHeader("User-Agent"), c.GetHeader("X-Session-ID"), c.GetHeader("X-Request-ID")
+}
+
+// SubmitNSBResponseChange asks the TC secretariat to change an NSB response
+// @Summary Request a change of an NSB response
+// @Description Submits a new answer for an NSB's response to a proposal. The change applies once the TC secretariat approves it.
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param id path string true "NSB response ID"
+// @Param payload body nsbResponseChangePayload true "New answer and reason"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /acceptance/submission/{id}/changes [post]
+func (h *AcceptanceHandler) SubmitNSBResponseChange(c *gin.Context) {
+	responseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid NSB response ID")
+		return
+	}
+
+	var payload nsbResponseChangePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	change := models.NSBResponseStatusChange{
+		InitialResponseID:        responseID.String(),
+		Response:                 payload.Response,
+		IsCommittedToParticipate: payload.IsCommittedToParticipate,
+		Reason:                   payload.Reason,
+	}
+	submitted, err := h.AcceptanceService.SubmitNSBResponseChange(&change, userIDStr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showNSBResponseChangeError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "change", submitted)
+}
+
+// ApproveNSBResponseChange applies a requested change to an NSB response
+// @Summary Approve a change of an NSB response
+// @Description Replaces the NSB's answer with the requested one and recalculates the acceptance statistics. Only the TC secretary may approve.
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param changeId path string true "Change request ID"
+// @Param payload body nsbResponseChangeDecisionPayload true "Comment of the secretariat"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /acceptance/changes/{changeId}/approve [post]
+func (h *AcceptanceHandler) ApproveNSBResponseChange(c *gin.Context) {
+	h.decideNSBResponseChange(c, true)
+}
+
+// RejectNSBResponseChange rejects a requested change to an NSB response
+// @Summary Reject a change of an NSB response
+// @Tags acceptance
+// @Accept json
+// @Produce json
+// @Param changeId path string true "Change request ID"
+// @Param payload body nsbResponseChangeDecisionPayload true "Comment of the secretariat"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /acceptance/changes/{changeId}/reject [post]
+func (h *AcceptanceHandler) RejectNSBResponseChange(c *gin.Context) {
+	h.decideNSBResponseChange(c, false)
+}
+
+func (h *AcceptanceHandler) decideNSBResponseChange(c *gin.Context, approve bool) {
+	changeID, err := uuid.Parse(c.Param("changeId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid change request ID")
+		return
+	}
+
+	var payload nsbResponseChangeDecisionPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDStr == "" {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	decide := h.AcceptanceService.RejectNSBResponseChange
+	if approve {
+		decide = h.AcceptanceService.ApproveNSBResponseChange
+	}
+	change, err := decide(changeID, userIDStr, payload.Comment, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		showNSBResponseChangeError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "change", change)
+}
+
+// GetNSBResponseChange returns a change request with the response it changes
+// @Summary Get a change request of an NSB response
+// @Tags acceptance
+// @Produce json
+// @Param changeId path string true "Change request ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /acceptance/changes/{changeId} [get]
+func (h *AcceptanceHandler) GetNSBResponseChange(c *gin.Context) {
+	changeID, err := uuid.Parse(c.Param("changeId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid change request ID")
+		return
+	}
+
+	change, err := h.AcceptanceService.GetNSBResponseChange(changeID)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "change", change)
+}
+
+// GetNSBResponseChanges lists the change requests on an NSB response
+// @Summary List the change requests of an NSB response
+// @Tags acceptance
+// @Produce json
+// @Param id path string true "NSB response ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /acceptance/submission/{id}/changes [get]
+func (h *AcceptanceHandler) GetNSBResponseChanges(c *gin.Context) {
+	changes, err := h.AcceptanceService.GetNSBResponseChanges(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "changes", changes)
+}
+
+// GetProjectNSBResponseChanges lists the change requests on the responses to a project's proposal
+// @Summary List the NSB response change requests of a project
+// @Tags acceptance
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /acceptance/changes/project/{id} [get]
+func (h *AcceptanceHandler) GetProjectNSBResponseChanges(c *gin.Context) {
+	changes, err := h.AcceptanceService.GetProjectNSBResponseChanges(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "changes", changes)
+}
+
+// GetPendingNSBResponseChanges lists the change requests awaiting a decision on the projects of the
+// committees the user is the secretary of, oldest first
+// @Summary List the NSB response change requests awaiting the user's decision
+// @Tags acceptance
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /acceptance/changes/pending [get]
+func (h *AcceptanceHandler) GetPendingNSBResponseChanges(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	changes, err := h.AcceptanceService.GetPendingNSBResponseChanges(userID.(string))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "changes", changes)
+}
+
+func showNSBResponseChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "NSB response or change request not found")
+	case errors.Is(err, repository.ErrNotResponseOwner),
+		errors.Is(err, repository.ErrNotResponseSecretariat):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrChangeRequestPending),
+		errors.Is(err, repository.ErrChangeRequestNotPending),
+		errors.Is(err, repository.ErrAcceptanceDecided):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NumberingHandler handles HTTP requests for the numbering registry
+type NumberingHandler struct {
+	numberingService *services.NumberingService
+}
+
+// NewNumberingHandler creates a new numbering handler instance
+func NewNumberingHandler(numberingService *services.NumberingService) *NumberingHandler {
+	return &NumberingHandler{
+		numberingService: numberingService,
+	}
+}
+
+type reserveNumberRequest struct {
+	Purpose   string     `json:"purpose" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// GetSequences returns the last number issued in every scope
+// @Summary List numbering sequences
+// @Tags numbering
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /numbering/sequences [get]
+func (h *NumberingHandler) GetSequences(c *gin.Context) {
+	sequences, err := h.numberingService.GetSequences()
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "sequences", sequences)
+}
+
+// PeekNextNumber returns the number a scope will issue next
+// @Summary Preview the next number of a scope
+// @Tags numbering
+// @Produce json
+// @Param scope path string true "Numbering scope"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /numbering/scopes/{scope}/next [get]
+func (h *NumberingHandler) PeekNextNumber(c *gin.Context) {
+	number, err := h.numberingService.PeekNextNumber(c.Param("scope"))
+	if err != nil {
+		h.showNumberingError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "number", number)
+}
+
+// NextNumber issues the next number of a scope
+// @Summary Issue the next number of a scope
+// @Tags numbering
+// @Produce json
+// @Param scope path string true "Numbering scope"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /numbering/scopes/{scope}/next [post]
+func (h *NumberingHandler) NextNumber(c *gin.Context) {
+	number, err := h.numberingService.NextNumber(c.Param("scope"))
+	if err != nil {
+		h.showNumberingError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "number", number)
+}
+
+// ReserveNumber holds the next number of a scope until it is used or released
+// @Summary Reserve a number
+// @Tags numbering
+// @Accept json
+// @Produce json
+// @Param scope path string true "Numbering scope"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /numbering/scopes/{scope}/reservations [post]
+func (h *NumberingHandler) ReserveNumber(c *gin.Context) {
+	var payload reserveNumberRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID := c.GetString("user_id")
+	reservation, err := h.numberingService.Reserve(c.Param("scope"), &userID, payload.Purpose, payload.ExpiresAt)
+	if err != nil {
+		h.showNumberingError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "reservation", reservation)
+}
+
+// GetReservations lists the reservations of a scope
+// @Summary List number reservations
+// @Tags numbering
+// @Produce json
+// @Param scope path string true "Numbering scope"
+// @Param status query string false "RESERVED, USED or RELEASED"
+// @Success 200 {object} map[string]interface{}
+// @Router /numbering/scopes/{scope}/reservations [get]
+func (h *NumberingHandler) GetReservations(c *gin.Context) {
+	status := models.NumberReservationStatus(c.Query("status"))
+	reservations, err := h.numberingService.GetReservations(c.Param("scope"), status)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "reservations", reservations)
+}
+
+// UseReservation marks a reserved number as used
+// @Summary Use a reserved number
+// @Tags numbering
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /numbering/reservations/{id}/use [post]
+func (h *NumberingHandler) UseReservation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	reservation, err := h.numberingService.UseReservation(id)
+	if err != nil {
+		h.showNumberingError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "reservation", reservation)
+}
+
+// ReleaseReservation returns an unused reserved number to its scope
+// @Summary Release a reserved number
+// @Tags numbering
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /numbering/reservations/{id}/release [post]
+func (h *NumberingHandler) ReleaseReservation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	reservation, err := h.numberingService.ReleaseReservation(id)
+	if err != nil {
+		h.showNumberingError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "reservation", reservation)
+}
+
+func (h *NumberingHandler) showNumberingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidNumberingScope):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Reservation not found")
+	case errors.Is(err, repository.ErrNumberNotReserved):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,17 +1,20 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/ekbaya/asham/pkg/db/repository"
 	"github.com/ekbaya/asham/pkg/domain/models"
 	"github.com/ekbaya/asham/pkg/domain/services"
 	"github.com/ekbaya/asham/pkg/utilities"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ProjectHandler struct {
@@ -42,7 +45,10 @@ func (h *ProjectHandler) getAuditParams(c *gin.Context) (*string, string, string
 }
 
 func (h *ProjectHandler) CreateProject(c *gin.Context) {
-	var payload models.Project
+	var payload struct {
+		models.Project
+		ReservationID *uuid.UUID `json:"reservation_id"` // Number reserved in the registry to number the project with
+	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		validationErrors, ok := err.(validator.ValidationErrors)
 		if ok {
@@ -67,9 +73,18 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	payload.MemberID = userIDStr
 
 	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
-	err := h.projectService.CreateProject(&payload, userIDPtr, ipAddress, userAgent, sessionID, requestID)
-	if err != nil {
-		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	err := h.projectService.CreateProject(&payload.Project, payload.ReservationID, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNumberNotReserved):
+			utilities.ShowMessage(c, http.StatusConflict, err.Error())
+		case errors.Is(err, models.ErrSupplementOutsideWorkflow):
+			utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utilities.ShowMessage(c, http.StatusNotFound, "Technical committee, stage or number reservation not found")
+		default:
+			utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -476,6 +491,44 @@ func (h *ProjectHandler) GetProjectsByReferenceBase(c *gin.Context) {
 	utilities.Show(c, http.StatusOK, "projects", projects)
 }
 
+// GetProjectByReference handles retrieving a project by its current or a past reference
+func (h *ProjectHandler) GetProjectByReference(c *gin.Context) {
+	reference := c.Query("reference")
+	if reference == "" {
+		utilities.ShowMessage(c, http.StatusBadRequest, "reference is required")
+		return
+	}
+
+	project, err := h.projectService.GetProjectByReference(reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utilities.ShowMessage(c, http.StatusNotFound, "Project not found")
+			return
+		}
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "project", project)
+}
+
+// GetProjectReferences handles retrieving the references a project has carried
+func (h *ProjectHandler) GetProjectReferences(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	references, err := h.projectService.GetProjectReferences(projectID)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "references", references)
+}
+
 // CreateProjectRevision handles creating a new revision of an existing project
 func (h *ProjectHandler) CreateProjectRevision(c *gin.Context) {
 	baseProjectID, err := uuid.Parse(c.Param("id"))
@@ -511,28 +564,68 @@ func (h *ProjectHandler) GetProjectsApproachingDeadline(c *gin.Context) {
 	utilities.Show(c, http.StatusOK, "projects", projects)
 }
 
-// GetProjectsInStageForTooLong handles retrieving projects that have been in a stage for too long
-func (h *ProjectHandler) GetProjectsInStageForTooLong(c *gin.Context) {
-	stageID, err := uuid.Parse(c.Query("stage_id"))
+// GetProjectSLA handles comparing a project's stage plan with its actual stage dates
+func (h *ProjectHandler) GetProjectSLA(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid stage_id")
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
 		return
 	}
 
-	dayThresholdStr := c.DefaultQuery("day_threshold", "30")
-	dayThreshold, err := strconv.Atoi(dayThresholdStr)
+	sla, err := h.projectService.GetProjectSLA(projectID)
 	if err != nil {
-		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid day_threshold")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utilities.ShowMessage(c, http.StatusNotFound, "Project not found")
+			return
+		}
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	projects, err := h.projectService.GetProjectsInStageForTooLong(stageID, dayThreshold)
+	utilities.Show(c, http.StatusOK, "schedule", sla)
+}
+
+// ReplanProjectStages handles recomputing a project's stage plan from its current track
+func (h *ProjectHandler) ReplanProjectStages(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	plans, err := h.projectService.ReplanProjectStages(projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utilities.ShowMessage(c, http.StatusNotFound, "Project not found")
+			return
+		}
 		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	utilities.Show(c, http.StatusOK, "projects", projects)
+	utilities.Show(c, http.StatusOK, "stage_plans", plans)
+}
+
+// GetSLABreaches handles retrieving active projects with stages in breach of their SLA
+func (h *ProjectHandler) GetSLABreaches(c *gin.Context) {
+	breaches, err := h.projectService.GetSLABreaches(c.Query("technical_committee_id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "breaches", breaches)
+}
+
+// GetCommitteeSLASummary handles retrieving SLA breaches grouped by technical committee
+func (h *ProjectHandler) GetCommitteeSLASummary(c *gin.Context) {
+	summary, err := h.projectService.GetCommitteeSLASummary()
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "committees", summary)
 }
 
 // GetRelatedProjects handles retrieving projects related to a given project
@@ -570,7 +663,12 @@ func (h *ProjectHandler) UpdateProjectStage(c *gin.Context) {
 		return
 	}
 
-	err = h.projectService.UpdateProjectStage(projectID, payload.StageID, payload.Notes)
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	err = h.projectService.UpdateProjectStage(projectID, payload.StageID, payload.Notes, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if errors.Is(err, repository.ErrIllegalStageTransition) || errors.Is(err, repository.ErrStageTransitionGuard) {
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
 		return
@@ -579,6 +677,23 @@ func (h *ProjectHandler) UpdateProjectStage(c *gin.Context) {
 	utilities.ShowMessage(c, http.StatusOK, "Project stage updated successfully")
 }
 
+// GetAvailableTransitions handles listing the workflow transitions currently available to a project
+func (h *ProjectHandler) GetAvailableTransitions(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	transitions, err := h.projectService.GetAvailableTransitions(projectID)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "transitions", transitions)
+}
+
 // GetProjectWithStageHistory handles retrieving a project with its stage history
 func (h *ProjectHandler) GetProjectWithStageHistory(c *gin.Context) {
 	projectID, err := uuid.Parse(c.Param("id"))
@@ -768,7 +883,8 @@ func (h *ProjectHandler) ApproveFDARS(c *gin.Context) {
 
 	userIDStr := userID.(string)
 
-	err := h.projectService.ApproveFDARS(userIDStr, payload.Project, payload.Approve, payload.Action)
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	err := h.projectService.ApproveFDARS(userIDStr, payload.Project, payload.Approve, payload.Action, userIDPtr, ipAddress, userAgent, sessionID, requestID)
 	if err != nil {
 		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
 		return
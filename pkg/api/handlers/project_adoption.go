@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type adoptionPayload struct {
+	Title                string                    `json:"title" binding:"required"`
+	Description          string                    `json:"description" binding:"required"`
+	Language             string                    `json:"language"`
+	TechnicalCommitteeID string                    `json:"technical_committee_id" binding:"required,uuid"`
+	WorkingGroupID       *string                   `json:"working_group_id"`
+	Timeframe            int                       `json:"time_frame"`
+	IsEmergency          bool                      `json:"is_emergency"`
+	Organisation         models.SourceOrganisation `json:"organisation" binding:"required"`
+	SourceReference      string                    `json:"source_reference" binding:"required"`
+	SourceTitle          string                    `json:"source_title"`
+	PublicationYear      int                       `json:"publication_year"`
+	Equivalence          models.EquivalenceDegree  `json:"equivalence" binding:"required"`
+	Deviations           string                    `json:"deviations"`
+	DocumentID           *string                   `json:"document_id"`
+}
+
+// RegisterAdoption registers the adoption of an ISO, IEC or Codex document. The project skips the
+// preparatory and committee stages and goes straight to enquiry.
+// @Summary Register the adoption of an international document
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param payload body adoptionPayload true "Project and adopted document"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /projects/adoptions [post]
+func (h *ProjectHandler) RegisterAdoption(c *gin.Context) {
+	var payload adoptionPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	project := models.Project{
+		Title:                payload.Title,
+		Description:          payload.Description,
+		Language:             payload.Language,
+		TechnicalCommitteeID: payload.TechnicalCommitteeID,
+		WorkingGroupID:       payload.WorkingGroupID,
+		Timeframe:            payload.Timeframe,
+		IsEmergency:          payload.IsEmergency,
+		VisibleOnLibrary:     false, // Not visible until published
+	}
+	adoption := models.AdoptedStandard{
+		Organisation:    payload.Organisation,
+		SourceReference: payload.SourceReference,
+		SourceTitle:     payload.SourceTitle,
+		PublicationYear: payload.PublicationYear,
+		Equivalence:     payload.Equivalence,
+		Deviations:      payload.Deviations,
+		DocumentID:      payload.DocumentID,
+	}
+
+	if err := h.projectService.RegisterAdoption(&project, &adoption, *userIDPtr, ipAddress, userAgent, sessionID, requestID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidEquivalence),
+			errors.Is(err, models.ErrInvalidSourceOrganisation):
+			utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, repository.ErrNotAdoptionSecretary):
+			utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utilities.ShowMessage(c, http.StatusNotFound, "Technical committee or document not found")
+		default:
+			utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "project", project)
+}
+
+// ListAdoptions lists the international documents adopted by projects
+// @Summary List adoptions of international documents
+// @Tags projects
+// @Produce json
+// @Param equivalence query string false "IDT, MOD or NEQ"
+// @Param organisation query string false "ISO, IEC, ISO/IEC or CODEX"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/adoptions [get]
+func (h *ProjectHandler) ListAdoptions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	equivalence := models.EquivalenceDegree(c.Query("equivalence"))
+	organisation := models.SourceOrganisation(c.Query("organisation"))
+	adoptions, total, err := h.projectService.GetAdoptions(equivalence, organisation, limit, (page-1)*limit)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "adoptions", gin.H{
+		"data":       adoptions,
+		"pagination": utilities.GeneratePaginationData(limit, page, int(total)),
+	})
+}
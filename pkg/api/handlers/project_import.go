@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImportProjects imports legacy projects and published standards from a CSV or XLSX manifest and
+// a ZIP of the PDFs it names. The manifest has a header row naming its columns: title and tc_code
+// are required; description, language, working_group, sector, stage, type, procedure,
+// standard_number, part_number, edition_number, time_frame, reference, stage_started_at,
+// published_date and file are optional. Rows with a published_date are imported as published
+// standards. Dates are written YYYY-MM-DD.
+// @Summary Import legacy projects and published standards
+// @Tags projects
+// @Accept multipart/form-data
+// @Produce json
+// @Param manifest formData file true "CSV or XLSX manifest"
+// @Param files formData file false "ZIP of the PDFs named in the file column"
+// @Param dry_run formData bool false "Validate the manifest without importing it"
+// @Success 200 {object} map[string]interface{} "Dry run report"
+// @Success 201 {object} map[string]interface{} "Import report with the batch"
+// @Failure 422 {object} map[string]interface{} "Report with the row errors"
+// @Router /projects/imports [post]
+func (h *ProjectHandler) ImportProjects(c *gin.Context) {
+	if err := c.Request.ParseMultipartForm(100 << 20); err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Unable to parse form: "+err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	manifestHeader, err := c.FormFile("manifest")
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Error retrieving manifest: "+err.Error())
+		return
+	}
+	manifest, err := readFormFile(manifestHeader)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Error reading manifest: "+err.Error())
+		return
+	}
+
+	var archiveName string
+	var archive []byte
+	if archiveHeader, err := c.FormFile("files"); err == nil {
+		archiveName = archiveHeader.Filename
+		if archive, err = readFormFile(archiveHeader); err != nil {
+			utilities.ShowMessage(c, http.StatusBadRequest, "Error reading archive: "+err.Error())
+			return
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+	report, err := h.projectService.ImportProjects(manifestHeader.Filename, manifest, archiveName, archive, dryRun, *userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showImportError(c, err)
+		return
+	}
+
+	switch {
+	case report.Batch != nil:
+		utilities.Show(c, http.StatusCreated, "report", report)
+	case dryRun:
+		utilities.Show(c, http.StatusOK, "report", report)
+	default:
+		utilities.Show(c, http.StatusUnprocessableEntity, "report", report)
+	}
+}
+
+// ListImportBatches lists the import batches, most recent first
+// @Summary List import batches
+// @Tags projects
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/imports [get]
+func (h *ProjectHandler) ListImportBatches(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	batches, total, err := h.projectService.GetImportBatches(limit, (page-1)*limit)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "batches", gin.H{
+		"data":       batches,
+		"pagination": utilities.GeneratePaginationData(limit, page, int(total)),
+	})
+}
+
+// GetImportBatch returns an import batch with the projects it created
+// @Summary Get an import batch
+// @Tags projects
+// @Produce json
+// @Param batchId path string true "Import batch ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/imports/{batchId} [get]
+func (h *ProjectHandler) GetImportBatch(c *gin.Context) {
+	batchID, err := uuid.Parse(c.Param("batchId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid import batch ID")
+		return
+	}
+
+	batch, err := h.projectService.GetImportBatch(batchID)
+	if err != nil {
+		h.showImportError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "batch", batch)
+}
+
+// UndoImportBatch removes every project, standard and document created by an import batch
+// @Summary Undo an import batch
+// @Tags projects
+// @Produce json
+// @Param batchId path string true "Import batch ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/imports/{batchId}/undo [post]
+func (h *ProjectHandler) UndoImportBatch(c *gin.Context) {
+	batchID, err := uuid.Parse(c.Param("batchId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid import batch ID")
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	batch, err := h.projectService.UndoImportBatch(batchID, *userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showImportError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "batch", batch)
+}
+
+func (h *ProjectHandler) showImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Import batch not found")
+	case errors.Is(err, services.ErrUnsupportedManifest),
+		errors.Is(err, services.ErrInvalidManifest),
+		errors.Is(err, services.ErrInvalidArchive),
+		errors.Is(err, services.ErrImportFileTooLarge):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrImportInvalid),
+		errors.Is(err, repository.ErrImportBatchUndone),
+		errors.Is(err, repository.ErrImportBatchInUse):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type projectRelationshipPayload struct {
+	TargetID string                  `json:"target_id" binding:"required,uuid"`
+	Type     models.RelationshipType `json:"type" binding:"required"`
+	Note     string                  `json:"note"`
+}
+
+// GetProjectRelationships returns the relationship graph of a project in both directions
+// @Summary Get the relationship graph of a project
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param depth query int false "Number of links to follow, at most 3" default(1)
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/relationships [get]
+func (h *ProjectHandler) GetProjectRelationships(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	depth, _ := strconv.Atoi(c.DefaultQuery("depth", "1"))
+	graph, err := h.projectService.GetRelationshipGraph(projectID.String(), depth)
+	if err != nil {
+		h.showRelationshipError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "relationships", graph)
+}
+
+// CreateProjectRelationship links a project to another project or published standard
+// @Summary Link a project to another project or standard
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param payload body projectRelationshipPayload true "Target and type of the relationship"
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/relationships [post]
+func (h *ProjectHandler) CreateProjectRelationship(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var payload projectRelationshipPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	relationship := models.ProjectRelationship{
+		SourceID:    projectID.String(),
+		TargetID:    payload.TargetID,
+		Type:        payload.Type,
+		Note:        payload.Note,
+		CreatedByID: userIDPtr,
+	}
+
+	warnings, err := h.projectService.CreateRelationship(&relationship, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showRelationshipError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "relationship", gin.H{
+		"relationship": relationship,
+		"warnings":     warnings,
+	})
+}
+
+// DeleteProjectRelationship removes a link from a project
+// @Summary Remove a relationship of a project
+// @Description Links recorded by the workflow, such as those of revisions, amendments and superseded standards, cannot be removed.
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param relationshipId path string true "Relationship ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/relationships/{relationshipId} [delete]
+func (h *ProjectHandler) DeleteProjectRelationship(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+	relationshipID, err := uuid.Parse(c.Param("relationshipId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid relationship ID")
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if err := h.projectService.DeleteRelationship(projectID.String(), relationshipID, userIDPtr, ipAddress, userAgent, sessionID, requestID); err != nil {
+		h.showRelationshipError(c, err)
+		return
+	}
+
+	utilities.ShowMessage(c, http.StatusOK, "Relationship removed")
+}
+
+func (h *ProjectHandler) showRelationshipError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Project, standard or relationship not found")
+	case errors.Is(err, models.ErrInvalidRelationshipType),
+		errors.Is(err, repository.ErrSelfRelationship):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrRelationshipExists),
+		errors.Is(err, repository.ErrRelationshipTargetNotPublished),
+		errors.Is(err, repository.ErrSystemRelationship):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type projectRollbackPayload struct {
+	ToStage *int   `json:"to_stage" binding:"required,min=0"`
+	Reason  string `json:"reason" binding:"required"`
+}
+
+// RollbackProject returns a project to an earlier stage
+// @Summary Roll a project back to an earlier stage
+// @Description Closes the open ballot or enquiry, keeps the drafts of the abandoned stages as superseded versions, resets the reviews and reissues the draft reference. Only the TC secretary may roll back a project.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param payload body projectRollbackPayload true "Stage to return to and reason"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/rollbacks [post]
+func (h *ProjectHandler) RollbackProject(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var payload projectRollbackPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rollback, err := h.projectService.RollbackProject(projectID.String(), *payload.ToStage, payload.Reason, *userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showRollbackError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "rollback", rollback)
+}
+
+// GetProjectRollbacks lists the rollbacks of a project
+// @Summary List the rollbacks of a project
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/{id}/rollbacks [get]
+func (h *ProjectHandler) GetProjectRollbacks(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	rollbacks, err := h.projectService.GetProjectRollbacks(projectID.String())
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rollbacks", rollbacks)
+}
+
+// GetProjectRollback returns a rollback with the ballot, enquiry and drafts it superseded
+// @Summary Get a rollback of a project
+// @Tags projects
+// @Produce json
+// @Param rollbackId path string true "Rollback ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/rollbacks/{rollbackId} [get]
+func (h *ProjectHandler) GetProjectRollback(c *gin.Context) {
+	rollbackID, err := uuid.Parse(c.Param("rollbackId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid rollback ID")
+		return
+	}
+
+	rollback, err := h.projectService.GetRollback(rollbackID)
+	if err != nil {
+		h.showRollbackError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "rollback", rollback)
+}
+
+func (h *ProjectHandler) showRollbackError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Project, stage or rollback not found")
+	case errors.Is(err, repository.ErrRollbackTarget):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotRollbackSecretary):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrRollbackApproved),
+		errors.Is(err, repository.ErrRollbackSagaInProgress),
+		errors.Is(err, repository.ErrProjectNotActive):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type projectStatusPayload struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// SuspendProject puts a project on hold, freezing its open ballots, comment windows and meetings
+// @Summary Put a project on hold
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param payload body projectStatusPayload true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/suspend [post]
+func (h *ProjectHandler) SuspendProject(c *gin.Context) {
+	h.changeProjectStatus(c, models.ProjectOnHold)
+}
+
+// CancelProject cancels a project, freezing its open ballots, comment windows and meetings
+// @Summary Cancel a project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param payload body projectStatusPayload true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/cancel [post]
+func (h *ProjectHandler) CancelProject(c *gin.Context) {
+	h.changeProjectStatus(c, models.ProjectCancelled)
+}
+
+// WithdrawProject withdraws a project at the request of its proposer
+// @Summary Withdraw a project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param payload body projectStatusPayload true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/withdraw [post]
+func (h *ProjectHandler) WithdrawProject(c *gin.Context) {
+	h.changeProjectStatus(c, models.ProjectWithdrawn)
+}
+
+// ReinstateProject returns a stopped project to the stage it was in
+// @Summary Reinstate a project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param payload body projectStatusPayload true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/reinstate [post]
+func (h *ProjectHandler) ReinstateProject(c *gin.Context) {
+	h.changeProjectStatus(c, models.ProjectActive)
+}
+
+// GetProjectStatusHistory lists the periods in which a project was on hold, cancelled or withdrawn
+// @Summary Get the status history of a project
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/{id}/status-history [get]
+func (h *ProjectHandler) GetProjectStatusHistory(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	changes, err := h.projectService.GetProjectStatusChanges(projectID.String())
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "status_history", changes)
+}
+
+func (h *ProjectHandler) changeProjectStatus(c *gin.Context, status models.ProjectStatus) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var payload projectStatusPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+
+	var change *models.ProjectStatusChange
+	switch status {
+	case models.ProjectOnHold:
+		change, err = h.projectService.SuspendProject(projectID.String(), payload.Reason, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	case models.ProjectCancelled:
+		change, err = h.projectService.CancelProject(projectID.String(), payload.Reason, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	case models.ProjectWithdrawn:
+		change, err = h.projectService.WithdrawProject(projectID.String(), payload.Reason, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	default:
+		change, err = h.projectService.ReinstateProject(projectID.String(), payload.Reason, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utilities.ShowMessage(c, http.StatusNotFound, "Project not found")
+		case errors.Is(err, repository.ErrProjectNotActive),
+			errors.Is(err, repository.ErrProjectAlreadyActive),
+			errors.Is(err, repository.ErrProjectAlreadyStopped),
+			errors.Is(err, repository.ErrReinstatementWindowClosed):
+			utilities.ShowMessage(c, http.StatusConflict, err.Error())
+		default:
+			utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "status_change", change)
+}
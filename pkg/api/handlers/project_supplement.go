@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type projectSupplementPayload struct {
+	Title       string `json:"title"` // Defaults to the title of the standard
+	Description string `json:"description" binding:"required"`
+}
+
+// CreateAmendment opens an amendment of a published standard
+// @Summary Amend a published standard
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID of the standard"
+// @Param payload body projectSupplementPayload true "Title and description of the amendment"
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/amendments [post]
+func (h *ProjectHandler) CreateAmendment(c *gin.Context) {
+	h.createSupplement(c, models.AMENDMENT)
+}
+
+// CreateCorrigendum opens a technical corrigendum of a published standard
+// @Summary Correct a published standard
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID of the standard"
+// @Param payload body projectSupplementPayload true "Title and description of the corrigendum"
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/corrigenda [post]
+func (h *ProjectHandler) CreateCorrigendum(c *gin.Context) {
+	h.createSupplement(c, models.CORRIGENDUM)
+}
+
+// GetSupplements lists the amendments and corrigenda of a standard
+// @Summary List the amendments and corrigenda of a standard
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID of the standard"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/{id}/supplements [get]
+func (h *ProjectHandler) GetSupplements(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	supplements, err := h.projectService.GetSupplements(projectID)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "supplements", supplements)
+}
+
+func (h *ProjectHandler) createSupplement(c *gin.Context, supplementType models.ProjectType) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var payload projectSupplementPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var supplement *models.Project
+	if supplementType == models.CORRIGENDUM {
+		supplement, err = h.projectService.CreateCorrigendum(projectID, *userIDPtr, payload.Title, payload.Description, ipAddress, userAgent, sessionID, requestID)
+	} else {
+		supplement, err = h.projectService.CreateAmendment(projectID, *userIDPtr, payload.Title, payload.Description, ipAddress, userAgent, sessionID, requestID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utilities.ShowMessage(c, http.StatusNotFound, "Standard not found")
+		case errors.Is(err, repository.ErrNotSupplementable):
+			utilities.ShowMessage(c, http.StatusConflict, err.Error())
+		default:
+			utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "project", supplement)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type projectTransferPayload struct {
+	ToCommitteeID    string  `json:"to_committee_id" binding:"required,uuid"`
+	ToWorkingGroupID *string `json:"to_working_group_id" binding:"omitempty,uuid"`
+	Reason           string  `json:"reason" binding:"required"`
+}
+
+type rejectTransferPayload struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RequestProjectTransfer asks to move a project to another technical committee or working group
+// @Summary Request the transfer of a project to another committee or working group
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param payload body projectTransferPayload true "Destination and reason of the transfer"
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/transfers [post]
+func (h *ProjectHandler) RequestProjectTransfer(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var payload projectTransferPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	transfer := models.ProjectTransfer{
+		ProjectID:        projectID.String(),
+		ToCommitteeID:    payload.ToCommitteeID,
+		ToWorkingGroupID: payload.ToWorkingGroupID,
+		Reason:           payload.Reason,
+		RequestedByID:    *userIDPtr,
+	}
+
+	result, err := h.projectService.RequestTransfer(&transfer, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showTransferError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "transfer", result)
+}
+
+// GetProjectTransfers lists the transfers of a project
+// @Summary List the transfers of a project
+// @Tags projects
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/{id}/transfers [get]
+func (h *ProjectHandler) GetProjectTransfers(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	transfers, err := h.projectService.GetProjectTransfers(projectID.String())
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "transfers", transfers)
+}
+
+// GetPendingTransfers lists the pending transfers awaiting the approval of the current user's
+// secretariats
+// @Summary List the transfers awaiting my approval
+// @Tags projects
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/transfers/pending [get]
+func (h *ProjectHandler) GetPendingTransfers(c *gin.Context) {
+	userIDPtr, _, _, _, _ := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	transfers, err := h.projectService.GetPendingTransfers(*userIDPtr)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "transfers", transfers)
+}
+
+// ApproveProjectTransfer records the approval of the secretary of either committee
+// @Summary Approve the transfer of a project
+// @Tags projects
+// @Produce json
+// @Param transferId path string true "Transfer ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /projects/transfers/{transferId}/approve [post]
+func (h *ProjectHandler) ApproveProjectTransfer(c *gin.Context) {
+	transferID, err := uuid.Parse(c.Param("transferId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid transfer ID")
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	transfer, err := h.projectService.ApproveTransfer(transferID, *userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showTransferError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "transfer", transfer)
+}
+
+// RejectProjectTransfer ends a pending transfer at the request of the secretary of either committee
+// @Summary Reject the transfer of a project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param transferId path string true "Transfer ID"
+// @Param payload body rejectTransferPayload true "Reason for the rejection"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /projects/transfers/{transferId}/reject [post]
+func (h *ProjectHandler) RejectProjectTransfer(c *gin.Context) {
+	transferID, err := uuid.Parse(c.Param("transferId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid transfer ID")
+		return
+	}
+
+	var payload rejectTransferPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if ok {
+			utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+			return
+		}
+
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	transfer, err := h.projectService.RejectTransfer(transferID, *userIDPtr, payload.Reason, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showTransferError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "transfer", transfer)
+}
+
+func (h *ProjectHandler) showTransferError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Project, committee, working group or transfer not found")
+	case errors.Is(err, repository.ErrWorkingGroupNotInCommittee):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotTransferSecretary):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrTransferPending),
+		errors.Is(err, repository.ErrTransferNotPending),
+		errors.Is(err, repository.ErrTransferUnchanged),
+		errors.Is(err, repository.ErrProjectPublished),
+		errors.Is(err, repository.ErrProjectNotActive):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
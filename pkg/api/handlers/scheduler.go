@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+)
+
+// SchedulerHandler handles HTTP requests for managing background jobs
+type SchedulerHandler struct {
+	schedulerService *services.SchedulerService
+}
+
+// NewSchedulerHandler creates a new scheduler handler instance
+func NewSchedulerHandler(schedulerService *services.SchedulerService) *SchedulerHandler {
+	return &SchedulerHandler{
+		schedulerService: schedulerService,
+	}
+}
+
+// ListJobs returns all registered background jobs with their state
+// @Summary List background jobs
+// @Tags jobs
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /jobs [get]
+func (h *SchedulerHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.schedulerService.ListJobs()
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "jobs", jobs)
+}
+
+// GetJobRuns returns the run history of a job
+// @Summary Get job run history
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /jobs/{name}/runs [get]
+func (h *SchedulerHandler) GetJobRuns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	runs, total, err := h.schedulerService.GetJobRuns(c.Param("name"), limit, (page-1)*limit)
+	if err != nil {
+		h.showJobError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "runs", gin.H{
+		"data":       runs,
+		"pagination": utilities.GeneratePaginationData(limit, page, int(total)),
+	})
+}
+
+// PauseJob stops a job from running on its schedule on every replica
+// @Summary Pause a background job
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /jobs/{name}/pause [post]
+func (h *SchedulerHandler) PauseJob(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.schedulerService.PauseJob(c.Param("name"), &userID); err != nil {
+		h.showJobError(c, err)
+		return
+	}
+
+	utilities.ShowMessage(c, http.StatusOK, "Job paused successfully")
+}
+
+// ResumeJob puts a paused job back on its schedule
+// @Summary Resume a background job
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /jobs/{name}/resume [post]
+func (h *SchedulerHandler) ResumeJob(c *gin.Context) {
+	if err := h.schedulerService.ResumeJob(c.Param("name")); err != nil {
+		h.showJobError(c, err)
+		return
+	}
+
+	utilities.ShowMessage(c, http.StatusOK, "Job resumed successfully")
+}
+
+// TriggerJob starts a job immediately
+// @Summary Trigger a background job
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 202 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /jobs/{name}/trigger [post]
+func (h *SchedulerHandler) TriggerJob(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	run, err := h.schedulerService.TriggerJob(c.Param("name"), &userID)
+	if err != nil {
+		h.showJobError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusAccepted, "Job triggered successfully", run)
+}
+
+func (h *SchedulerHandler) showJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrJobAlreadyRunning):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SystematicReviewHandler handles HTTP requests for the systematic reviews of published standards
+type SystematicReviewHandler struct {
+	reviewService *services.SystematicReviewService
+}
+
+// NewSystematicReviewHandler creates a new systematic review handler instance
+func NewSystematicReviewHandler(reviewService *services.SystematicReviewService) *SystematicReviewHandler {
+	return &SystematicReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+func (h *SystematicReviewHandler) getAuditParams(c *gin.Context) (*string, string, string, string, string) {
+	userID, exists := c.Get("user_id")
+	var userIDPtr *string
+	if exists {
+		userIDStr := userID.(string)
+		userIDPtr = &userIDStr
+	}
+
+	return userIDPtr, c.ClientIP(), c.GetHeader("User-Agent"), c.GetHeader("X-Session-ID"), c.GetHeader("X-Request-ID")
+}
+
+// ListReviews lists systematic reviews, optionally by status or standard
+// @Summary List systematic reviews
+// @Tags systematic-reviews
+// @Produce json
+// @Param status query string false "OPEN or CLOSED"
+// @Param project_id query string false "Project ID of the standard"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Router /systematic-reviews [get]
+func (h *SystematicReviewHandler) ListReviews(c *gin.Context) {
+	if projectID := c.Query("project_id"); projectID != "" {
+		reviews, err := h.reviewService.GetProjectReviews(projectID)
+		if err != nil {
+			utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		utilities.Show(c, http.StatusOK, "reviews", reviews)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	status := models.SystematicReviewStatus(c.Query("status"))
+	reviews, total, err := h.reviewService.GetReviews(status, limit, (page-1)*limit)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "reviews", gin.H{
+		"data":       reviews,
+		"pagination": utilities.GeneratePaginationData(limit, page, int(total)),
+	})
+}
+
+// OpenReview opens a systematic review of a published standard ahead of its review age
+// @Summary Open a systematic review
+// @Tags systematic-reviews
+// @Accept json
+// @Produce json
+// @Param payload body object true "Project ID of the standard"
+// @Success 201 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /systematic-reviews [post]
+func (h *SystematicReviewHandler) OpenReview(c *gin.Context) {
+	var payload struct {
+		ProjectID string `json:"project_id" binding:"required,uuid"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.showBindingError(c, err)
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	review, err := h.reviewService.OpenReview(payload.ProjectID, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showReviewError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "review", review)
+}
+
+// GetReview returns a review with the responses of the NSBs
+// @Summary Get a systematic review
+// @Tags systematic-reviews
+// @Produce json
+// @Param id path string true "Review ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /systematic-reviews/{id} [get]
+func (h *SystematicReviewHandler) GetReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	review, err := h.reviewService.GetReview(id)
+	if err != nil {
+		h.showReviewError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "review", review)
+}
+
+// SubmitResponse records the response of the caller's NSB to an open review
+// @Summary Respond to a systematic review
+// @Tags systematic-reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Review ID"
+// @Param payload body object true "CONFIRM, REVISE, AMEND or WITHDRAW with an optional comment"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /systematic-reviews/{id}/responses [post]
+func (h *SystematicReviewHandler) SubmitResponse(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	var payload struct {
+		Decision models.ReviewDecision `json:"decision" binding:"required"`
+		Comment  string                `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.showBindingError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	response := models.SystematicReviewResponse{
+		ReviewID: id,
+		MemberID: userID.(string),
+		Decision: payload.Decision,
+		Comment:  payload.Comment,
+	}
+	if err := h.reviewService.SubmitResponse(&response); err != nil {
+		h.showReviewError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "response", response)
+}
+
+// CloseReview closes a review before its closing date and applies its outcome
+// @Summary Close a systematic review
+// @Tags systematic-reviews
+// @Produce json
+// @Param id path string true "Review ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /systematic-reviews/{id}/close [post]
+func (h *SystematicReviewHandler) CloseReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	review, err := h.reviewService.CloseReview(id, userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showReviewError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "review", review)
+}
+
+func (h *SystematicReviewHandler) showBindingError(c *gin.Context, err error) {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if ok {
+		utilities.ShowError(c, http.StatusBadRequest, utilities.FormatValidationErrors(validationErrors))
+		return
+	}
+
+	utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+}
+
+func (h *SystematicReviewHandler) showReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Systematic review or standard not found")
+	case errors.Is(err, models.ErrInvalidReviewDecision):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotEligibleToVote):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrReviewClosed),
+		errors.Is(err, repository.ErrReviewAlreadyOpen),
+		errors.Is(err, repository.ErrAmendmentOpen),
+		errors.Is(err, repository.ErrNotReviewable):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SagaHandler handles HTTP requests for the transition sagas of stage transitions
+type SagaHandler struct {
+	sagaService *services.SagaService
+}
+
+// NewSagaHandler creates a new saga handler instance
+func NewSagaHandler(sagaService *services.SagaService) *SagaHandler {
+	return &SagaHandler{
+		sagaService: sagaService,
+	}
+}
+
+// ListSagas lists transition sagas, optionally by status or project
+// @Summary List transition sagas
+// @Tags transition-sagas
+// @Produce json
+// @Param status query string false "PENDING, RUNNING, COMPLETED, FAILED or COMPENSATED"
+// @Param project_id query string false "Project ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Router /transition-sagas [get]
+func (h *SagaHandler) ListSagas(c *gin.Context) {
+	if projectID := c.Query("project_id"); projectID != "" {
+		sagas, err := h.sagaService.GetProjectSagas(projectID)
+		if err != nil {
+			utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		utilities.Show(c, http.StatusOK, "sagas", sagas)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	status := models.TransitionSagaStatus(c.Query("status"))
+	sagas, total, err := h.sagaService.GetSagas(status, limit, (page-1)*limit)
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "sagas", gin.H{
+		"data":       sagas,
+		"pagination": utilities.GeneratePaginationData(limit, page, int(total)),
+	})
+}
+
+// GetStuckSagas lists failed sagas, sagas abandoned by their worker and sagas still being retried
+// @Summary List stuck or failed transition sagas
+// @Tags transition-sagas
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /transition-sagas/stuck [get]
+func (h *SagaHandler) GetStuckSagas(c *gin.Context) {
+	sagas, err := h.sagaService.GetStuckSagas()
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "sagas", sagas)
+}
+
+// GetSaga returns a saga with the history of its steps
+// @Summary Get a transition saga
+// @Tags transition-sagas
+// @Produce json
+// @Param id path string true "Saga ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /transition-sagas/{id} [get]
+func (h *SagaHandler) GetSaga(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid saga ID")
+		return
+	}
+
+	saga, err := h.sagaService.GetSaga(id)
+	if err != nil {
+		h.showSagaError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "saga", saga)
+}
+
+// ResumeSaga retries a pending or failed saga straight away
+// @Summary Resume a transition saga
+// @Tags transition-sagas
+// @Produce json
+// @Param id path string true "Saga ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /transition-sagas/{id}/resume [post]
+func (h *SagaHandler) ResumeSaga(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid saga ID")
+		return
+	}
+
+	saga, err := h.sagaService.Resume(c.Request.Context(), id)
+	if err != nil {
+		h.showSagaError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "saga", saga)
+}
+
+// CompensateSaga undoes the completed steps of a pending or failed saga
+// @Summary Compensate a transition saga
+// @Tags transition-sagas
+// @Produce json
+// @Param id path string true "Saga ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /transition-sagas/{id}/compensate [post]
+func (h *SagaHandler) CompensateSaga(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid saga ID")
+		return
+	}
+
+	saga, err := h.sagaService.Compensate(c.Request.Context(), id)
+	if err != nil {
+		h.showSagaError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "saga", saga)
+}
+
+func (h *SagaHandler) showSagaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Saga not found")
+	case errors.Is(err, repository.ErrSagaNotClaimable):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
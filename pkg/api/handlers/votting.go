@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/ekbaya/asham/pkg/db/repository"
 	"github.com/ekbaya/asham/pkg/domain/models"
 	"github.com/ekbaya/asham/pkg/domain/services"
 	"github.com/ekbaya/asham/pkg/utilities"
@@ -71,7 +73,7 @@ func (h *VoteHandler) CreateVote(c *gin.Context) {
 	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
 	err := h.ballotingService.CreateVote(&payload, userIDStr, ipAddress, userAgent, sessionID, requestID)
 	if err != nil {
-		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		h.showVoteError(c, err)
 		return
 	}
 
@@ -168,19 +170,52 @@ func (h *VoteHandler) UpdateVote(c *gin.Context) {
 	payload.ID = voteID
 
 	// Preserve fields that shouldn't be updated
-	payload.MemberID = existingVote.MemberID
 	payload.ProjectID = existingVote.ProjectID
 	payload.BallotingID = existingVote.BallotingID
 	payload.CreatedAt = existingVote.CreatedAt
 
 	userIDStr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+
+	// The amendment is cast by the authenticated secretary
+	payload.MemberID = userIDStr
+
 	err = h.ballotingService.UpdateVote(&payload, userIDStr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showVoteError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "vote", payload)
+}
+
+// GetVoteRevisions retrieves the amendment history of a vote
+func (h *VoteHandler) GetVoteRevisions(c *gin.Context) {
+	voteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid vote ID")
+		return
+	}
+
+	revisions, err := h.ballotingService.FindVoteRevisions(voteID)
 	if err != nil {
 		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	utilities.ShowMessage(c, http.StatusOK, "Vote updated successfully")
+	utilities.Show(c, http.StatusOK, "revisions", revisions)
+}
+
+func (h *VoteHandler) showVoteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidVote):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotEligibleToVote), errors.Is(err, repository.ErrVoteOwnerMismatch):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrBallotClosed), errors.Is(err, repository.ErrAlreadyVoted):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
 }
 
 // DeleteVote deletes a vote
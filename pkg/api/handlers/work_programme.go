@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/ekbaya/asham/pkg/domain/services"
+	"github.com/ekbaya/asham/pkg/utilities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetWorkProgramme returns the live work programme of a technical committee, as JSON or as a
+// DOCX or PDF document
+// @Summary Get the work programme of a technical committee
+// @Tags projects
+// @Produce json
+// @Produce application/pdf
+// @Param committeeId path string true "Technical committee ID"
+// @Param format query string false "json (default), docx or pdf"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/work-programmes/{committeeId} [get]
+func (h *ProjectHandler) GetWorkProgramme(c *gin.Context) {
+	committeeID, err := uuid.Parse(c.Param("committeeId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid committee ID")
+		return
+	}
+
+	programme, err := h.projectService.GetWorkProgramme(committeeID.String())
+	if err != nil {
+		h.showWorkProgrammeError(c, err)
+		return
+	}
+
+	h.showWorkProgramme(c, programme, 0)
+}
+
+// CreateWorkProgrammeSnapshot saves the current work programme of a committee as a new draft version
+// @Summary Save a version of the work programme of a technical committee
+// @Tags projects
+// @Produce json
+// @Param committeeId path string true "Technical committee ID"
+// @Success 201 {object} map[string]interface{}
+// @Router /projects/work-programmes/{committeeId}/snapshots [post]
+func (h *ProjectHandler) CreateWorkProgrammeSnapshot(c *gin.Context) {
+	committeeID, err := uuid.Parse(c.Param("committeeId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid committee ID")
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	snapshot, err := h.projectService.CreateWorkProgrammeSnapshot(committeeID.String(), *userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showWorkProgrammeError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusCreated, "snapshot", snapshot)
+}
+
+// GetWorkProgrammeSnapshots lists the saved versions of a committee's work programme
+// @Summary List the versions of the work programme of a technical committee
+// @Tags projects
+// @Produce json
+// @Param committeeId path string true "Technical committee ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/work-programmes/{committeeId}/snapshots [get]
+func (h *ProjectHandler) GetWorkProgrammeSnapshots(c *gin.Context) {
+	committeeID, err := uuid.Parse(c.Param("committeeId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid committee ID")
+		return
+	}
+
+	snapshots, err := h.projectService.GetWorkProgrammeSnapshots(committeeID.String())
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "snapshots", snapshots)
+}
+
+// GetPublishedWorkProgramme returns the published version of a committee's work programme
+// @Summary Get the published work programme of a technical committee
+// @Tags projects
+// @Produce json
+// @Produce application/pdf
+// @Param committeeId path string true "Technical committee ID"
+// @Param format query string false "json (default), docx or pdf"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/work-programmes/{committeeId}/published [get]
+func (h *ProjectHandler) GetPublishedWorkProgramme(c *gin.Context) {
+	committeeID, err := uuid.Parse(c.Param("committeeId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid committee ID")
+		return
+	}
+
+	snapshot, err := h.projectService.GetPublishedWorkProgramme(committeeID.String())
+	if err != nil {
+		h.showWorkProgrammeError(c, err)
+		return
+	}
+
+	h.showWorkProgrammeSnapshot(c, snapshot)
+}
+
+// GetWorkProgrammeSnapshot returns a saved version of a work programme, as JSON or as a DOCX or
+// PDF document
+// @Summary Get a version of a work programme
+// @Tags projects
+// @Produce json
+// @Produce application/pdf
+// @Param snapshotId path string true "Snapshot ID"
+// @Param format query string false "json (default), docx or pdf"
+// @Success 200 {object} map[string]interface{}
+// @Router /projects/work-programmes/snapshots/{snapshotId} [get]
+func (h *ProjectHandler) GetWorkProgrammeSnapshot(c *gin.Context) {
+	snapshotID, err := uuid.Parse(c.Param("snapshotId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid snapshot ID")
+		return
+	}
+
+	snapshot, err := h.projectService.GetWorkProgrammeSnapshot(snapshotID)
+	if err != nil {
+		h.showWorkProgrammeError(c, err)
+		return
+	}
+
+	h.showWorkProgrammeSnapshot(c, snapshot)
+}
+
+// PublishWorkProgrammeSnapshot publishes a draft version of a work programme. Only the committee
+// secretary may publish.
+// @Summary Publish a version of a work programme
+// @Tags projects
+// @Produce json
+// @Param snapshotId path string true "Snapshot ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/work-programmes/snapshots/{snapshotId}/publish [post]
+func (h *ProjectHandler) PublishWorkProgrammeSnapshot(c *gin.Context) {
+	snapshotID, err := uuid.Parse(c.Param("snapshotId"))
+	if err != nil {
+		utilities.ShowMessage(c, http.StatusBadRequest, "Invalid snapshot ID")
+		return
+	}
+
+	userIDPtr, ipAddress, userAgent, sessionID, requestID := h.getAuditParams(c)
+	if userIDPtr == nil {
+		utilities.ShowMessage(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	snapshot, err := h.projectService.PublishWorkProgrammeSnapshot(snapshotID, *userIDPtr, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		h.showWorkProgrammeError(c, err)
+		return
+	}
+
+	utilities.Show(c, http.StatusOK, "snapshot", snapshot)
+}
+
+func (h *ProjectHandler) showWorkProgrammeSnapshot(c *gin.Context, snapshot *models.WorkProgrammeSnapshot) {
+	if c.DefaultQuery("format", "json") == "json" {
+		utilities.Show(c, http.StatusOK, "snapshot", snapshot)
+		return
+	}
+	h.showWorkProgramme(c, &snapshot.Programme, snapshot.Version)
+}
+
+// showWorkProgramme writes the work programme in the format asked for, as a download unless JSON
+func (h *ProjectHandler) showWorkProgramme(c *gin.Context, programme *models.WorkProgramme, version int64) {
+	format := c.DefaultQuery("format", "json")
+	if format == "json" {
+		utilities.Show(c, http.StatusOK, "work_programme", programme)
+		return
+	}
+
+	file, err := h.projectService.RenderWorkProgramme(programme, version, format)
+	if err != nil {
+		h.showWorkProgrammeError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+func (h *ProjectHandler) showWorkProgrammeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utilities.ShowMessage(c, http.StatusNotFound, "Committee or work programme not found")
+	case errors.Is(err, services.ErrUnsupportedWorkProgrammeFormat):
+		utilities.ShowMessage(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotWorkProgrammeSecretary):
+		utilities.ShowMessage(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrWorkProgrammeNotDraft):
+		utilities.ShowMessage(c, http.StatusConflict, err.Error())
+	default:
+		utilities.ShowMessage(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -20,7 +21,14 @@ type Config struct {
 	DOC_TEMPLATE_PATH    string
 	ONEDRIVE_FOLDER_NAME string
 	SEED_PERMISSIONS     bool
+	SCHEDULER_ENABLED    bool
 	Environment          string
+	// Days during which a cancelled or withdrawn project can be reinstated, zero for no limit
+	PROJECT_REINSTATEMENT_WINDOW_DAYS int
+	// Age in years at which a published standard is put under systematic review
+	SYSTEMATIC_REVIEW_INTERVAL_YEARS int
+	// Days NSBs have to respond to a systematic review
+	SYSTEMATIC_REVIEW_PERIOD_DAYS int
 }
 
 type DatabaseConfig struct {
@@ -92,12 +100,26 @@ func LoadConfig() (*Config, error) {
 		DOC_TEMPLATE_PATH:    "../templates/project_template.docx",
 		ONEDRIVE_FOLDER_NAME: "ASHAM_ARSO_PLATFORM",
 		SEED_PERMISSIONS:     false,
+		SCHEDULER_ENABLED:    os.Getenv("SCHEDULER_ENABLED") != "false",
 		Environment:          env,
+
+		PROJECT_REINSTATEMENT_WINDOW_DAYS: intEnv("PROJECT_REINSTATEMENT_WINDOW_DAYS", 180),
+		SYSTEMATIC_REVIEW_INTERVAL_YEARS:  intEnv("SYSTEMATIC_REVIEW_INTERVAL_YEARS", 5),
+		SYSTEMATIC_REVIEW_PERIOD_DAYS:     intEnv("SYSTEMATIC_REVIEW_PERIOD_DAYS", 90),
 	}
 
 	return config, nil
 }
 
+// intEnv reads a non-negative integer from the environment, falling back when it is unset or invalid
+func intEnv(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
 // GetConfig returns a singleton config instance
 func GetConfig() *Config {
 	once.Do(func() {
@@ -41,15 +41,42 @@ func RunMigrations(db *gorm.DB) error {
 		&models.Stage{},
 		&models.Project{},
 		&models.ProjectStageHistory{},
+		&models.ProjectStatusChange{},
+		&models.SystematicReview{},
+		&models.SystematicReviewResponse{},
+		&models.AdoptedStandard{},
+		&models.ProjectRelationship{},
+		&models.ProjectTransfer{},
+		&models.ProjectRollback{},
+		&models.SupersededDocument{},
+		&models.ProjectImportBatch{},
+		&models.WorkProgrammeSnapshot{},
+		&models.ProjectReference{},
+		&models.ProjectStagePlan{},
+		&models.NumberSequence{},
+		&models.NumberReservation{},
+		&models.TransitionSaga{},
+		&models.TransitionSagaStep{},
 		&models.Proposal{},
 		&models.Acceptance{},
 		&models.NSBResponse{},
 		&models.CommentObservation{},
 		&models.NSBResponseStatusChange{},
+		&models.AcceptanceRule{},
+		&models.CirculationOverride{},
+		&models.NonWorkingPeriod{},
 		&models.DARS{},
 		&models.NationalConsultation{},
 		&models.Balloting{},
 		&models.Vote{},
+		&models.VoteRevision{},
+		&models.VoteComment{},
+		&models.VotingRule{},
+		&models.CommitteeBallot{},
+		&models.CommitteeBallotOption{},
+		&models.CommitteeBallotVoter{},
+		&models.CommitteeBallotVote{},
+		&models.CommitteeBallotEvent{},
 		&models.Meeting{},
 		&models.User{},
 		&models.Standard{},
@@ -60,6 +87,8 @@ func RunMigrations(db *gorm.DB) error {
 		&models.NotificationTemplate{},
 		&models.NotificationPreference{},
 		&models.NotificationHistory{},
+		&models.ScheduledJob{},
+		&models.JobRun{},
 	)
 	
 	// Restore original config
@@ -73,6 +73,10 @@ func (r *AcceptanceRepository) CreateNSBResponse(response *models.NSBResponse) e
 		response.RespondingNSBID = *nsb
 		response.NationalTCSecretaryID = member.NationalStandardBody.NationalTCSecretaryID
 
+		if err := checkCirculationWindowWithTx(tx, &acceptance, response.RespondingNSBID, time.Now()); err != nil {
+			return err
+		}
+
 		// Process relevant standards from string IDs to Document associations
 		if len(response.RelevantStandards) > 0 {
 			var standardDocs []models.Document
@@ -118,12 +122,59 @@ func (r *AcceptanceRepository) GetNSBResponsesByProjectID(projectID string) ([]m
 	return NSBResponses, nil
 }
 
+// UpdateNSBResponse saves the changes an NSB makes to its response while the proposal is in
+// circulation. The project, acceptance and NSB of the response cannot be changed.
 func (r *AcceptanceRepository) UpdateNSBResponse(response *models.NSBResponse) error {
-	return r.db.Save(response).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.NSBResponse
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", response.ID).Error; err != nil {
+			return err
+		}
+
+		var acceptance models.Acceptance
+		if err := tx.First(&acceptance, "id = ?", current.AcceptanceID).Error; err != nil {
+			return err
+		}
+		if acceptance.IsApproved {
+			return ErrAcceptanceDecided
+		}
+		if err := checkCirculationWindowWithTx(tx, &acceptance, current.RespondingNSBID, time.Now()); err != nil {
+			return err
+		}
+
+		response.ProjectID = current.ProjectID
+		response.AcceptanceID = current.AcceptanceID
+		response.RespondingNSBID = current.RespondingNSBID
+		response.CreatedAt = current.CreatedAt
+		return tx.Save(response).Error
+	})
 }
 
-func (r *AcceptanceRepository) DeleteNSBResponse(id string) error {
-	return r.db.Where("id = ?", id).Delete(&models.NSBResponse{}).Error
+// DeleteNSBResponse deletes a response while its proposal is still circulating and returns it
+func (r *AcceptanceRepository) DeleteNSBResponse(id string) (*models.NSBResponse, error) {
+	var response models.NSBResponse
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&response, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		var acceptance models.Acceptance
+		if err := tx.First(&acceptance, "id = ?", response.AcceptanceID).Error; err != nil {
+			return err
+		}
+		if acceptance.IsApproved {
+			return ErrAcceptanceDecided
+		}
+		if err := checkCirculationWindowWithTx(tx, &acceptance, response.RespondingNSBID, time.Now()); err != nil {
+			return err
+		}
+
+		return tx.Delete(&response).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
 func (r *AcceptanceRepository) GetAcceptance(id string) (*models.Acceptance, error) {
@@ -150,8 +201,35 @@ func (r *AcceptanceRepository) GetAcceptances() (*[]models.Acceptance, error) {
 	return &acceptances, nil
 }
 
+// UpdateAcceptance saves the acceptance. When the results were compiled and put to the SMC is
+// kept as recorded. Changing the development track of an approved proposal recomputes its target
+// dates and stage plan.
 func (r *AcceptanceRepository) UpdateAcceptance(acceptance *models.Acceptance) error {
-	return r.db.Save(acceptance).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Acceptance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", acceptance.ID).Error; err != nil {
+			return err
+		}
+		// The circulation window, compilation and decision are set by their own operations
+		acceptance.CirculationDate = current.CirculationDate
+		acceptance.ClosingDate = current.ClosingDate
+		acceptance.CompiledAt = current.CompiledAt
+		acceptance.SMCApprovalOpenedAt = current.SMCApprovalOpenedAt
+		acceptance.ApprovalCriteriaMet = current.ApprovalCriteriaMet
+		acceptance.CriteriaComments = current.CriteriaComments
+		acceptance.AcceptanceRuleID = current.AcceptanceRuleID
+		acceptance.IsApproved = current.IsApproved
+		if err := tx.Omit(
+			"circulation_date", "closing_date", "compiled_at", "smc_approval_opened_at",
+			"approval_criteria_met", "criteria_comments", "acceptance_rule_id", "is_approved",
+		).Save(acceptance).Error; err != nil {
+			return err
+		}
+		if current.DevelopmentTrack != acceptance.DevelopmentTrack {
+			return scheduleAcceptanceWithTx(tx, acceptance)
+		}
+		return nil
+	})
 }
 
 func (r *AcceptanceRepository) GetAcceptanceWithResponses(id string) (*models.Acceptance, error) {
@@ -163,12 +241,16 @@ func (r *AcceptanceRepository) GetAcceptanceWithResponses(id string) (*models.Ac
 }
 
 func (r *AcceptanceRepository) CountNSBResponsesByType(projectID string) (map[models.Response]int, error) {
+	return countNSBResponsesByTypeWithTx(r.db, projectID)
+}
+
+func countNSBResponsesByTypeWithTx(db *gorm.DB, projectID string) (map[models.Response]int, error) {
 	var results []struct {
 		Response models.Response
 		Count    int
 	}
 
-	if err := r.db.Model(&models.NSBResponse{}).
+	if err := db.Model(&models.NSBResponse{}).
 		Select("response, count(*) as count").
 		Where("project_id = ?", projectID).
 		Group("response").
@@ -185,8 +267,12 @@ func (r *AcceptanceRepository) CountNSBResponsesByType(projectID string) (map[mo
 }
 
 func (r *AcceptanceRepository) CalculateNSBResponseStats(projectID string) error {
+	return calculateNSBResponseStatsWithTx(r.db, projectID)
+}
+
+func calculateNSBResponseStatsWithTx(db *gorm.DB, projectID string) error {
 	// Count responses by type
-	counts, err := r.CountNSBResponsesByType(projectID)
+	counts, err := countNSBResponsesByTypeWithTx(db, projectID)
 	if err != nil {
 		return err
 	}
@@ -220,7 +306,7 @@ func (r *AcceptanceRepository) CalculateNSBResponseStats(projectID string) error
 		SET total_responses = ?, agreement_count = ?, disagreement_count = ?, abstention_count = ?
 		WHERE project_id = ?
 	`
-	result := r.db.Exec(query, totalResponses, agreementCount, disagreementCount, abstentionCount, projectID)
+	result := db.Exec(query, totalResponses, agreementCount, disagreementCount, abstentionCount, projectID)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -228,8 +314,11 @@ func (r *AcceptanceRepository) CalculateNSBResponseStats(projectID string) error
 	return nil
 }
 
-func (r *AcceptanceRepository) SetAcceptanceApproval(results models.Acceptance) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// SetAcceptanceApproval approves the proposal and returns the saga that copies the draft as the
+// project's working draft
+func (r *AcceptanceRepository) SetAcceptanceApproval(results models.Acceptance) (*models.TransitionSaga, error) {
+	var saga *models.TransitionSaga
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var project models.Project
 		if err := tx.Where("id = ?", results.ProjectID).Preload("TechnicalCommittee").First(&project).Error; err != nil {
 			return err
@@ -245,6 +334,9 @@ func (r *AcceptanceRepository) SetAcceptanceApproval(results models.Acceptance)
 			tx.Rollback()
 			return err
 		}
+		if acceptance.HasCirculationWindow() && acceptance.SMCApprovalOpenedAt == nil {
+			return ErrSMCApprovalNotOpen
+		}
 
 		acceptance.IsApproved = true
 		acceptance.ApprovalCriteriaMet = true
@@ -272,34 +364,40 @@ func (r *AcceptanceRepository) SetAcceptanceApproval(results models.Acceptance)
 			tx.Rollback()
 			return err
 		}
-
-		var stage models.Stage
-		nextStage := getNextStage(project.Procedure)
-		if err := tx.Where("number = ?", nextStage).First(&stage).Error; err != nil {
-			tx.Rollback()
+		if err := scheduleAcceptanceWithTx(tx, &acceptance); err != nil {
 			return err
 		}
 
 		// Update the project stage
-		if err := UpdateProjectStageWithTx(tx, results.ProjectID, stage.ID.String(), "Proposal Accepted", "NWIP", stage.Abbreviation); err != nil {
+		if err := AdvanceProjectStageWithTx(tx, results.ProjectID, results.TCSecretaryID, "Proposal Accepted"); err != nil {
 			tx.Rollback()
 			return err
 		}
 
-		return nil
+		var err error
+		saga, err = enqueueTransitionSagaWithTx(tx, results.ProjectID, "Proposal Accepted", "WD")
+		return err
 	})
+	return saga, err
 }
 
 func (r *AcceptanceRepository) GetAcceptanceResults(id string) (*models.AcceptanceResults, error) {
+	results, _, err := acceptanceResultsWithTx(r.db, id)
+	return results, err
+}
+
+// acceptanceResultsWithTx tabulates the NSB responses to a project's proposal and applies the
+// acceptance rule for the proposal's basis. The rule applied is returned with the results.
+func acceptanceResultsWithTx(db *gorm.DB, id string) (*models.AcceptanceResults, *models.AcceptanceRule, error) {
 	// Get Project Proposal
 	var proposal models.Proposal
-	if err := r.db.Where("project_id = ?", id).First(&proposal).Error; err != nil {
-		return nil, err
+	if err := db.Where("project_id = ?", id).First(&proposal).Error; err != nil {
+		return nil, nil, err
 	}
 	// Find the Acceptance by Project ID
 	var acceptance models.Acceptance
-	if err := r.db.Where("project_id = ?", id).Preload("Submissions").Preload("Submissions.RespondingNSB").First(&acceptance).Error; err != nil {
-		return nil, err
+	if err := db.Where("project_id = ?", id).Preload("Submissions").Preload("Submissions.RespondingNSB").First(&acceptance).Error; err != nil {
+		return nil, nil, err
 	}
 
 	// Initialize results structure
@@ -323,6 +421,7 @@ func (r *AcceptanceRepository) GetAcceptanceResults(id string) (*models.Acceptan
 			AcceptedAsCD:           "N",
 			AcceptedAsDARF:         "N",
 			CommentsEnclosed:       response.Comments != "",
+			Comments:               response.Comments,
 			Participation:          response.IsCommittedToParticipate,
 		}
 
@@ -372,92 +471,13 @@ func (r *AcceptanceRepository) GetAcceptanceResults(id string) (*models.Acceptan
 	results.Totals.TotalResponses = len(*acceptance.Submissions)
 	results.Totals.ValidResponses = results.Totals.TotalResponses - results.Totals.AbstentionCount
 
-	criteriaMet, comment, err := checkIfCriteriaIsMet(proposal.ExistingIntlStandard, results.IndividualNSBResponses)
+	rule, err := findApplicableAcceptanceRule(db, models.ProposalAcceptanceBasis(&proposal))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if criteria is met
-	results.CriterialMet = criteriaMet
-	results.CriterialComments = comment
-
-	return results, nil
-}
-
-func checkIfCriteriaIsMet(internationalStandard bool, responses []models.IndividualNSBResponse) (bool, string, error) {
-	// Count total P-members voting (excluding abstentions)
-	var totalVotingCount int
-	var favorableVoteCount int
-	var favorableParticipationCount int // Participants who voted in favor
-	var generalParticipationCount int   // All participants regardless of vote
-
-	for _, response := range responses {
-		// Skip abstentions when counting total voting members
-		if response.Abstention {
-			continue
-		}
-
-		totalVotingCount++
-
-		// Count members willing to participate actively
-		if response.Participation {
-			generalParticipationCount++
+	results.CriterialMet, results.CriterialComments = rule.Evaluate(results.IndividualNSBResponses)
 
-			// Count participating members who also voted favorably
-			if response.FeasibleYes {
-				favorableParticipationCount++
-			}
-		}
-
-		// Count favorable votes (FeasibleYes)
-		if response.FeasibleYes {
-			favorableVoteCount++
-		}
-	}
-
-	// If there are no votes, return false
-	if totalVotingCount < 6 {
-		return false, "At least 6 votes are required", nil
-	}
-
-	if internationalStandard {
-		// For international standards: MORE than 50% of P-members voting in favor
-		percentageInFavor := float64(favorableVoteCount) / float64(totalVotingCount) * 100
-
-		// Check if more than 50% voted in favor
-		if percentageInFavor <= 50.0 {
-			return false, fmt.Sprintf("Criteria not met: Only %.1f%% of P-members voted in favor (more than 50%% required)", percentageInFavor), nil
-		}
-
-		// Verify there are participants who voted in favor
-		if favorableParticipationCount == 0 {
-			return false, "Criteria not met: No members who voted in favor committed to participate", nil
-		}
-
-		return true, fmt.Sprintf("Criteria met: %.1f%% of P-members voted in favor with %d favorable members committed to participate", percentageInFavor, favorableParticipationCount), nil
-	} else {
-		// For projects requiring preparatory/committee stages:
-		// Need at least 6 P-members voting and at least 3 members willing to participate actively
-
-		if totalVotingCount < 6 {
-			return false, fmt.Sprintf("Criteria not met: Only %d P-members voted (minimum 6 required)", totalVotingCount), nil
-		}
-
-		if generalParticipationCount < 3 {
-			return false, fmt.Sprintf("Criteria not met: Only %d members willing to participate actively (minimum 3 required)", generalParticipationCount), nil
-		}
-
-		return true, fmt.Sprintf("Criteria met: %d P-members voted and %d members willing to participate actively", totalVotingCount, generalParticipationCount), nil
-	}
-}
-
-func getNextStage(procedure models.Procedure) int {
-	switch procedure {
-	case models.Normal, models.DraftSubmittedWithProposal: // Next is Preparatory
-		return 2
-	case models.FastTrack: // Next stage is Enquiry
-		return 4
-	default:
-		return 2
-	}
+	return results, rule, nil
 }
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOutsideCirculationWindow = errors.New("the proposal is not circulating for responses")
+	ErrNotCirculationSecretary  = errors.New("only the secretary of the technical committee may manage the circulation of the proposal")
+	ErrSMCApprovalNotOpen       = errors.New("the proposal can only be approved once its circulation has closed and the results met the acceptance criteria")
+)
+
+// checkCirculationWindowWithTx accepts a response from the NSB while the proposal circulates, or
+// outside the circulation window when the secretariat has granted the NSB an override
+func checkCirculationWindowWithTx(tx *gorm.DB, acceptance *models.Acceptance, nsbID string, now time.Time) error {
+	if acceptance.InCirculationWindow(now) {
+		return nil
+	}
+
+	var overrides int64
+	if err := tx.Model(&models.CirculationOverride{}).
+		Where("acceptance_id = ? AND national_standard_body_id = ? AND expires_at > ?", acceptance.ID, nsbID, now).
+		Count(&overrides).Error; err != nil {
+		return err
+	}
+	if overrides == 0 {
+		return fmt.Errorf("%w: responses are accepted from %s to %s", ErrOutsideCirculationWindow,
+			acceptance.CirculationDate.Format("2006-01-02"), acceptance.ClosingDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// OpenCirculation sets the window in which the NSBs can respond to a project's proposal. Setting a
+// new window after the results were compiled reopens the circulation, and the results are compiled
+// again when the new window closes.
+func (r *AcceptanceRepository) OpenCirculation(projectID, secretary string, circulationDate, closingDate time.Time) (*models.Acceptance, error) {
+	var acceptance models.Acceptance
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectSecretaryWithTx(tx, projectID, secretary, ErrNotCirculationSecretary); err != nil {
+			return err
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", projectID).First(&acceptance).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			acceptance = models.Acceptance{
+				ID:            uuid.New(),
+				ProjectID:     projectID,
+				DraftStatus:   models.DraftNone,
+				TCSecretaryID: &secretary,
+				CreatedAt:     time.Now(),
+			}
+		case err != nil:
+			return err
+		case acceptance.IsApproved:
+			return ErrAcceptanceDecided
+		}
+
+		acceptance.CirculationDate = circulationDate
+		acceptance.ClosingDate = closingDate
+		acceptance.CompiledAt = nil
+		acceptance.SMCApprovalOpenedAt = nil
+		return tx.Omit(clause.Associations).Save(&acceptance).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &acceptance, nil
+}
+
+// GrantCirculationOverride lets an NSB respond to a project's proposal outside its circulation
+// window until the override expires
+func (r *AcceptanceRepository) GrantCirculationOverride(projectID string, override *models.CirculationOverride) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectSecretaryWithTx(tx, projectID, override.GrantedByID, ErrNotCirculationSecretary); err != nil {
+			return err
+		}
+
+		var acceptance models.Acceptance
+		if err := tx.Where("project_id = ?", projectID).First(&acceptance).Error; err != nil {
+			return err
+		}
+		if acceptance.IsApproved {
+			return ErrAcceptanceDecided
+		}
+
+		var nsb models.NationalStandardBody
+		if err := tx.Select("id").First(&nsb, "id = ?", override.NationalStandardBodyID).Error; err != nil {
+			return err
+		}
+
+		override.ID = uuid.New()
+		override.AcceptanceID = acceptance.ID.String()
+		override.CreatedAt = time.Now()
+		return tx.Omit(clause.Associations).Create(override).Error
+	})
+}
+
+// checkProjectSecretaryWithTx returns denied unless the member is the secretary of the project's
+// technical committee
+func checkProjectSecretaryWithTx(tx *gorm.DB, projectID, secretary string, denied error) error {
+	var project models.Project
+	if err := tx.Select("id", "technical_committee_id").First(&project, "id = ?", projectID).Error; err != nil {
+		return err
+	}
+	isSecretary, err := isSecretaryOfWithTx(tx, secretary, project.TechnicalCommitteeID)
+	if err != nil {
+		return err
+	}
+	if !isSecretary {
+		return denied
+	}
+	return nil
+}
+
+// GetCirculationOverrides lists the overrides granted on a project's proposal, latest first
+func (r *AcceptanceRepository) GetCirculationOverrides(projectID string) ([]models.CirculationOverride, error) {
+	var overrides []models.CirculationOverride
+	err := r.db.Preload("NationalStandardBody").
+		Preload("GrantedBy").
+		Joins("JOIN acceptances ON acceptances.id = circulation_overrides.acceptance_id").
+		Where("acceptances.project_id = ?", projectID).
+		Order("circulation_overrides.created_at DESC").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// CompileAcceptance refreshes the response totals of a project's proposal and applies the
+// acceptance rule to the responses. The SMC approval step opens the first time the criteria are
+// met; opened reports whether this compilation opened it.
+func (r *AcceptanceRepository) CompileAcceptance(projectID string) (acceptance *models.Acceptance, opened bool, err error) {
+	acceptance = &models.Acceptance{}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", projectID).First(acceptance).Error; err != nil {
+			return err
+		}
+		if err := calculateNSBResponseStatsWithTx(tx, projectID); err != nil {
+			return err
+		}
+
+		results, rule, err := acceptanceResultsWithTx(tx, projectID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"approval_criteria_met": results.CriterialMet,
+			"criteria_comments":     results.CriterialComments,
+			"acceptance_rule_id":    nil,
+			"compiled_at":           now,
+		}
+		if rule.ID != uuid.Nil {
+			updates["acceptance_rule_id"] = rule.ID.String()
+		}
+		if results.CriterialMet && acceptance.SMCApprovalOpenedAt == nil && !acceptance.IsApproved {
+			updates["smc_approval_opened_at"] = now
+			opened = true
+		}
+		if err := tx.Model(acceptance).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Project").First(acceptance, "id = ?", acceptance.ID).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return acceptance, opened, nil
+}
+
+// FindAcceptancesDueForCompilation returns the undecided proposals whose circulation window has
+// closed and whose results have not been compiled since
+func (r *AcceptanceRepository) FindAcceptancesDueForCompilation() ([]models.Acceptance, error) {
+	var acceptances []models.Acceptance
+	err := r.db.Where("closing_date > ? AND closing_date <= ? AND compiled_at IS NULL AND is_approved = ?", time.Time{}, time.Now(), false).
+		Order("closing_date ASC").
+		Find(&acceptances).Error
+	return acceptances, err
+}
+
+// SaveAcceptanceRule stores the rule as the next version for its basis and deactivates the version
+// it replaces
+func (r *AcceptanceRepository) SaveAcceptanceRule(rule *models.AcceptanceRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current []models.AcceptanceRule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("basis = ?", rule.Basis).
+			Order("version DESC").Limit(1).Find(&current).Error; err != nil {
+			return err
+		}
+
+		rule.ID = uuid.New()
+		rule.Version = 1
+		rule.Active = true
+		if len(current) > 0 {
+			rule.Version = current[0].Version + 1
+		}
+
+		if err := tx.Model(&models.AcceptanceRule{}).
+			Where("basis = ? AND active = ?", rule.Basis, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(rule).Error
+	})
+}
+
+func (r *AcceptanceRepository) GetActiveAcceptanceRules() ([]models.AcceptanceRule, error) {
+	var rules []models.AcceptanceRule
+	err := r.db.Preload("CreatedBy").Where("active = ?", true).Order("basis ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *AcceptanceRepository) GetAcceptanceRuleVersions(basis models.AcceptanceBasis) ([]models.AcceptanceRule, error) {
+	var rules []models.AcceptanceRule
+	err := r.db.Preload("CreatedBy").Where("basis = ?", basis).Order("version DESC").Find(&rules).Error
+	return rules, err
+}
+
+// GetApplicableAcceptanceRule returns the rule used to decide proposals of the basis
+func (r *AcceptanceRepository) GetApplicableAcceptanceRule(basis models.AcceptanceBasis) (*models.AcceptanceRule, error) {
+	return findApplicableAcceptanceRule(r.db, basis)
+}
+
+// findApplicableAcceptanceRule returns the active rule for the basis, falling back to the built-in
+// default
+func findApplicableAcceptanceRule(db *gorm.DB, basis models.AcceptanceBasis) (*models.AcceptanceRule, error) {
+	var rules []models.AcceptanceRule
+	if err := db.Where("active = ? AND basis = ?", true, basis).Limit(1).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load acceptance rules: %w", err)
+	}
+	if len(rules) > 0 {
+		return &rules[0], nil
+	}
+
+	rule := models.DefaultAcceptanceRule(basis)
+	return &rule, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetAcceptanceMatrix compiles the NSB responses to a project's proposal, listed by NSB, with the
+// rule they are evaluated against
+func (r *AcceptanceRepository) GetAcceptanceMatrix(projectID string) (*models.AcceptanceMatrix, error) {
+	var project models.Project
+	if err := r.db.Preload("TechnicalCommittee").First(&project, "id = ?", projectID).Error; err != nil {
+		return nil, err
+	}
+
+	var acceptance models.Acceptance
+	if err := r.db.Where("project_id = ?", projectID).First(&acceptance).Error; err != nil {
+		return nil, err
+	}
+
+	results, rule, err := acceptanceResultsWithTx(r.db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(results.IndividualNSBResponses, func(i, j int) bool {
+		return results.IndividualNSBResponses[i].NSB < results.IndividualNSBResponses[j].NSB
+	})
+
+	matrix := &models.AcceptanceMatrix{
+		ProjectID:       projectID,
+		Reference:       project.Reference,
+		Title:           project.Title,
+		CirculationDate: acceptance.CirculationDate,
+		ClosingDate:     acceptance.ClosingDate,
+		Results:         *results,
+		Rule:            *rule,
+		GeneratedAt:     time.Now(),
+	}
+	if project.TechnicalCommittee != nil {
+		matrix.CommitteeCode = project.TechnicalCommittee.Code
+		matrix.CommitteeName = project.TechnicalCommittee.Name
+	}
+	return matrix, nil
+}
+
+// SaveAcceptanceMatrixDocument stores a rendered matrix as a related document of the project
+func (r *AcceptanceRepository) SaveAcceptanceMatrixDocument(projectID string, doc *models.Document) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.Select("id").First(&project, "id = ?", projectID).Error; err != nil {
+			return err
+		}
+
+		doc.ID = uuid.New()
+		doc.CreatedAt = time.Now()
+		if err := tx.Omit(clause.Associations).Create(doc).Error; err != nil {
+			return err
+		}
+		return tx.Model(&project).Omit("RelatedDocuments.*").Association("RelatedDocuments").Append(doc)
+	})
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrNotAdoptionSecretary = errors.New("you must login as Secretary of the TC undertaking this project")
+
+// RegisterAdoption creates a project that adopts an international document and moves it straight
+// to enquiry, opening the public review of the DARS. Only the secretary of the TC undertaking the
+// project may register adoptions.
+func (r *ProjectRepository) RegisterAdoption(project *models.Project, adoption *models.AdoptedStandard, secretary string) error {
+	if err := adoption.Equivalence.Validate(); err != nil {
+		return err
+	}
+	if err := adoption.Organisation.Validate(); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var committee models.TechnicalCommittee
+		if err := tx.First(&committee, "id = ?", project.TechnicalCommitteeID).Error; err != nil {
+			return err
+		}
+		if committee.SecretaryId == nil || *committee.SecretaryId != secretary {
+			return ErrNotAdoptionSecretary
+		}
+
+		if adoption.DocumentID != nil {
+			if err := tx.Select("id").First(&models.Document{}, "id = ?", *adoption.DocumentID).Error; err != nil {
+				return err
+			}
+		}
+
+		var stage models.Stage
+		if err := tx.Where("number = ?", 0).First(&stage).Error; err != nil {
+			return err
+		}
+
+		number, err := nextNumberWithTx(tx, models.ProjectNumberScope)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		project.ID = uuid.New()
+		project.MemberID = secretary
+		project.Number = number
+		project.StageID = stage.ID.String()
+		project.Type = models.INTERNATIONAL
+		project.Procedure = models.Adoption
+		project.DARSDocID = adoption.DocumentID
+		project.SubmissionDate = &now
+		project.CreatedAt = now
+		project.UpdatedAt = now
+		if project.Reference, err = draftReferenceWithTx(tx, project, &stage); err != nil {
+			return err
+		}
+		if err := createProjectWithTx(tx, project, "Project created"); err != nil {
+			return err
+		}
+
+		adoption.ID = uuid.New()
+		adoption.ProjectID = project.ID.String()
+		adoption.RegisteredByID = secretary
+		adoption.CreatedAt = now
+		adoption.UpdatedAt = now
+		if err := tx.Create(adoption).Error; err != nil {
+			return err
+		}
+
+		dars := models.NewDARS(project.ID.String(), now)
+		if err := tx.Create(&dars).Error; err != nil {
+			return err
+		}
+
+		if err := TransitionProjectStageWithTx(tx, project.ID.String(), 4, &secretary, "Adoption registered"); err != nil {
+			return err
+		}
+
+		// Reload to pick up the stage and reference of the enquiry
+		return tx.Preload("Stage").Preload("Adoption").Preload("DARS").First(project, "id = ?", project.ID).Error
+	})
+}
+
+// GetAdoptions lists adoptions of international documents, optionally by degree of equivalence and
+// source organisation, most recent first
+func (r *ProjectRepository) GetAdoptions(equivalence models.EquivalenceDegree, organisation models.SourceOrganisation, limit, offset int) ([]models.AdoptedStandard, int64, error) {
+	query := r.db.Model(&models.AdoptedStandard{})
+	if equivalence != "" {
+		query = query.Where("equivalence = ?", equivalence)
+	}
+	if organisation != "" {
+		query = query.Where("organisation = ?", organisation)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var adoptions []models.AdoptedStandard
+	err := query.Preload("Project").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&adoptions).Error
+	return adoptions, total, err
+}
@@ -11,6 +11,13 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrBallotClosed      = errors.New("balloting is closed")
+	ErrNotEligibleToVote = errors.New("member is not eligible to vote")
+	ErrAlreadyVoted      = errors.New("the national standards body has already voted on this ballot")
+	ErrVoteOwnerMismatch = errors.New("vote belongs to another national standards body")
+)
+
 type BallotingRepository struct {
 	db *gorm.DB
 }
@@ -19,109 +26,139 @@ func NewBallotingRepository(db *gorm.DB) *BallotingRepository {
 	return &BallotingRepository{db: db}
 }
 
+// CreateVote records the first vote of the member's NSB on the project ballot. Amendments must go
+// through UpdateVote.
 func (r *BallotingRepository) CreateVote(vote *models.Vote) error {
-	var ballot models.Balloting
-	if err := r.db.Where("project_id = ?", vote.ProjectID).First(&ballot).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Get Project
-			var project models.Project
-			if err := r.db.Where("id = ?", vote.ProjectID).First(&project).Error; err != nil {
+	if err := vote.PrepareDecision(); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ballot models.Balloting
+		if err := tx.Where("project_id = ?", vote.ProjectID).First(&ballot).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Get Project
+				var project models.Project
+				if err := tx.Where("id = ?", vote.ProjectID).First(&project).Error; err != nil {
+					return err
+				}
+
+				now := time.Now()
+				// Create a new Balloting if it does not exist
+				ballot = models.Balloting{
+					ID:        uuid.New(),
+					ProjectID: vote.ProjectID,
+					Active:    true,
+					CreatedAt: now,
+					UpdatedAt: now,
+					StartDate: now,
+					EndDate:   now.AddDate(0, 0, 30), // Set end date to 30 days from now
+				}
+
+				if err := tx.Create(&ballot).Error; err != nil {
+					return err
+				}
+			} else {
 				return err
 			}
+		}
 
-			now := time.Now()
-			// Create a new Balloting if it does not exist
-			ballot = models.Balloting{
-				ID:        uuid.New(),
-				ProjectID: vote.ProjectID,
-				CreatedAt: now,
-				UpdatedAt: now,
-				StartDate: now,
-				EndDate:   now.AddDate(0, 0, 30), // Set end date to 30 days from now
-			}
+		nsb, err := checkVoteEligibility(tx, vote.MemberID, &ballot)
+		if err != nil {
+			return err
+		}
 
-			if err := r.db.Create(&ballot).Error; err != nil {
-				return err
-			}
-		} else {
+		var existing int64
+		if err := tx.Model(&models.Vote{}).
+			Where("balloting_id = ? AND national_standard_body_id = ?", ballot.ID, nsb.ID.String()).
+			Count(&existing).Error; err != nil {
 			return err
 		}
-	}
-	// isEligible, err := r.IsEligibleToVote(vote.MemberID, vote.ProjectID)
-	// if err != nil {
-	// 	return err
-	// }
+		if existing > 0 {
+			return ErrAlreadyVoted
+		}
 
-	// if !isEligible {
-	// 	return errors.New("member is not eligible to vote")
-	// }
-	vote.BallotingID = ballot.ID
-	return r.db.Create(vote).Error
+		nsbID := nsb.ID.String()
+		vote.BallotingID = ballot.ID
+		vote.NationalStandardBodyID = &nsbID
+		vote.Revision = 1
+		for i := range vote.TechnicalComments {
+			vote.TechnicalComments[i].ID = uuid.New()
+		}
+		if err := tx.Create(vote).Error; err != nil {
+			return err
+		}
+
+		revision := models.NewVoteRevision(vote)
+		return tx.Create(&revision).Error
+	})
 }
 
+// IsEligibleToVote reports whether the member may cast their NSB's vote on the project ballot
 func (r *BallotingRepository) IsEligibleToVote(memberID string, projectID string) (bool, error) {
-	// Load member to get NSB ID
-	var member models.Member
-	if err := r.db.Where("id = ?", memberID).
-		First(&member).Error; err != nil {
+	var balloting models.Balloting
+	if err := r.db.Where("project_id = ?", projectID).First(&balloting).Error; err != nil {
 		return false, err
 	}
 
-	// Check if any member from the same NSB has already voted
-	var nsbVoteCount int64
-	if err := r.db.Model(&models.Vote{}).
-		Joins("JOIN members ON members.id = votes.member_id").
-		Where("members.national_standard_body_id = ? AND votes.project_id = ?",
-			member.NationalStandardBodyID, projectID).
-		Count(&nsbVoteCount).Error; err != nil {
+	if _, err := checkVoteEligibility(r.db, memberID, &balloting); err != nil {
+		if errors.Is(err, ErrNotEligibleToVote) || errors.Is(err, ErrBallotClosed) {
+			return false, nil
+		}
 		return false, err
 	}
-	if nsbVoteCount > 0 {
-		return false, nil
-	}
 
-	// Check if the balloting is active
-	var balloting models.Balloting
-	if err := r.db.Where("project_id = ?", projectID).
-		First(&balloting).Error; err != nil {
-		return false, err
+	return true, nil
+}
+
+// checkVoteEligibility returns the NSB the member votes for. Only the NSB's designated national TC
+// secretary may vote, only while the ballot is open and only if the NSB's member state is a
+// participating country of the project's technical committee.
+func checkVoteEligibility(db *gorm.DB, memberID string, ballot *models.Balloting) (*models.NationalStandardBody, error) {
+	if ballot.IsClosed() {
+		return nil, ErrBallotClosed
 	}
-	if time.Now().After(balloting.EndDate) {
-		return false, nil
+	return participatingNSBWithTx(db, memberID, ballot.ProjectID)
+}
+
+// participatingNSBWithTx returns the NSB the member speaks for on the project: the member must be
+// the NSB's national TC secretary and the NSB's member state a participating country of the
+// project's technical committee
+func participatingNSBWithTx(db *gorm.DB, memberID, projectID string) (*models.NationalStandardBody, error) {
+	var member models.Member
+	if err := db.Preload("NationalStandardBody").Where("id = ?", memberID).First(&member).Error; err != nil {
+		return nil, err
 	}
 
-	// Check involvement in committee stage
-	var committeeCount int64
-	if err := r.db.Model(&models.CommentObservation{}).
-		Joins("JOIN members ON members.id = comment_observations.national_secretary_id").
-		Joins("JOIN national_standard_bodies ON national_standard_bodies.id = members.national_standard_body_id").
-		Where("national_standard_bodies.id = ? AND comment_observations.project_id = ?",
-			member.NationalStandardBodyID, projectID).
-		Count(&committeeCount).Error; err != nil {
-		return false, err
+	nsb := member.NationalStandardBody
+	if nsb == nil {
+		return nil, fmt.Errorf("%w: member does not belong to a national standards body", ErrNotEligibleToVote)
+	}
+	if nsb.NationalTCSecretaryID == nil || *nsb.NationalTCSecretaryID != memberID {
+		return nil, fmt.Errorf("%w: only the national TC secretary of %s may vote", ErrNotEligibleToVote, nsb.Name)
 	}
 
-	// Check involvement in enquiry stage
-	var enquiryCount int64
-	if err := r.db.Model(&models.NationalConsultation{}).
-		Joins("JOIN members ON members.id = national_consultations.national_secretary_id").
-		Joins("JOIN national_standard_bodies ON national_standard_bodies.id = members.national_standard_body_id").
-		Where("national_standard_bodies.id = ? AND national_consultations.project_id = ?",
-			member.NationalStandardBodyID, projectID).
-		Count(&enquiryCount).Error; err != nil {
-		return false, err
+	var project models.Project
+	if err := db.Select("id", "technical_committee_id").First(&project, "id = ?", projectID).Error; err != nil {
+		return nil, err
 	}
 
-	if committeeCount == 0 && enquiryCount == 0 {
-		return false, nil
+	var participating int64
+	if err := db.Table("participating_countries").
+		Where("technical_committee_id = ? AND member_state_id = ?", project.TechnicalCommitteeID, nsb.MemberStateID).
+		Count(&participating).Error; err != nil {
+		return nil, err
+	}
+	if participating == 0 {
+		return nil, fmt.Errorf("%w: %s is not from a participating country of the technical committee", ErrNotEligibleToVote, nsb.Name)
 	}
 
-	return true, nil
+	return nsb, nil
 }
 
 func (r *BallotingRepository) FindVoteByID(id uuid.UUID) (*models.Vote, error) {
 	var vote models.Vote
-	err := r.db.Where("id = ?", id).First(&vote).Error
+	err := r.db.Where("id = ?", id).Preload("TechnicalComments").First(&vote).Error
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +171,7 @@ func (r *BallotingRepository) FindVotesByBallotingID(ballotingID uuid.UUID) ([]m
 		Preload("Member").
 		Preload("Member.NationalStandardBody").
 		Preload("Member.NationalStandardBody.MemberState").
+		Preload("TechnicalComments").
 		Find(&votes).Error
 	if err != nil {
 		return nil, err
@@ -147,6 +185,7 @@ func (r *BallotingRepository) FindByProjectID(projectID string) ([]models.Vote,
 		Preload("Member").
 		Preload("Member.NationalStandardBody").
 		Preload("Member.NationalStandardBody.MemberState").
+		Preload("TechnicalComments").
 		Find(&votes).Error
 	if err != nil {
 		return nil, err
@@ -163,8 +202,74 @@ func (r *BallotingRepository) FindVotesByMemberID(memberID string) ([]models.Vot
 	return votes, nil
 }
 
+// UpdateVote amends a vote. The amendment is cast by vote.MemberID, who must be the current
+// national TC secretary of the NSB that owns the vote, and is kept as a new revision.
 func (r *BallotingRepository) UpdateVote(vote *models.Vote) error {
-	return r.db.Save(vote).Error
+	if err := vote.PrepareDecision(); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.Vote
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&existing, "id = ?", vote.ID).Error; err != nil {
+			return err
+		}
+
+		var ballot models.Balloting
+		if err := tx.First(&ballot, "id = ?", existing.BallotingID).Error; err != nil {
+			return err
+		}
+
+		nsb, err := checkVoteEligibility(tx, vote.MemberID, &ballot)
+		if err != nil {
+			return err
+		}
+		nsbID := nsb.ID.String()
+		if existing.NationalStandardBodyID != nil && *existing.NationalStandardBodyID != nsbID {
+			return ErrVoteOwnerMismatch
+		}
+
+		existing.NationalStandardBodyID = &nsbID
+		existing.MemberID = vote.MemberID
+		existing.Decision = vote.Decision
+		existing.Acceptance = vote.Acceptance
+		existing.Comment = vote.Comment
+		existing.Revision++
+		if err := tx.Omit("TechnicalComments").Save(&existing).Error; err != nil {
+			return err
+		}
+
+		// The comments of the amendment replace the previous ones
+		if err := tx.Where("vote_id = ?", existing.ID).Delete(&models.VoteComment{}).Error; err != nil {
+			return err
+		}
+		existing.TechnicalComments = vote.TechnicalComments
+		for i := range existing.TechnicalComments {
+			existing.TechnicalComments[i].ID = uuid.New()
+			existing.TechnicalComments[i].VoteID = existing.ID
+		}
+		if len(existing.TechnicalComments) > 0 {
+			if err := tx.Create(&existing.TechnicalComments).Error; err != nil {
+				return err
+			}
+		}
+
+		revision := models.NewVoteRevision(&existing)
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		*vote = existing
+		return nil
+	})
+}
+
+// FindVoteRevisions returns every revision of a vote, latest first
+func (r *BallotingRepository) FindVoteRevisions(voteID uuid.UUID) ([]models.VoteRevision, error) {
+	var revisions []models.VoteRevision
+	err := r.db.Preload("Member").Where("vote_id = ?", voteID).Order("revision DESC").Find(&revisions).Error
+	return revisions, err
 }
 
 func (r *BallotingRepository) DeleteVote(id uuid.UUID) error {
@@ -186,48 +291,77 @@ func (r *BallotingRepository) CountVotesByBalloting(ballotingID uuid.UUID) (int6
 	return count, err
 }
 
-// CheckAcceptanceCriteria determines if a project has met the 75% acceptance threshold
+// CheckAcceptanceCriteria evaluates the project's votes against the voting rule for its procedure
 func (r *BallotingRepository) CheckAcceptanceCriteria(projectID string) (*models.AcceptanceCriteriaResult, error) {
-	const requiredRate float64 = 0.75 // 75% acceptance required
+	return checkAcceptanceCriteria(r.db, projectID)
+}
 
-	result := &models.AcceptanceCriteriaResult{
-		RequiredRate: requiredRate,
+func checkAcceptanceCriteria(db *gorm.DB, projectID string) (*models.AcceptanceCriteriaResult, error) {
+	var project models.Project
+	if err := db.Select("id", "procedure", "technical_committee_id").First(&project, "id = ?", projectID).Error; err != nil {
+		return nil, err
 	}
 
-	// Count total votes for this project
-	err := r.db.Model(&models.Vote{}).Where("project_id = ?", projectID).Count(&result.TotalVotes).Error
+	rule, err := findApplicableVotingRule(db, project.Procedure)
 	if err != nil {
 		return nil, err
 	}
 
-	// If no votes, criteria not met
-	if result.TotalVotes < 3 {
-		result.Message = "At least three votes are required."
-		return result, nil
+	tally, err := tallyVotes(db, &project)
+	if err != nil {
+		return nil, err
 	}
 
-	// Count accepted votes for this project
-	err = r.db.Model(&models.Vote{}).Where("project_id = ? AND acceptance = ?", projectID, true).Count(&result.AcceptedVotes).Error
-	if err != nil {
+	return rule.Evaluate(*tally), nil
+}
+
+// approvingVote matches approving votes, including votes cast before decisions were recorded
+const approvingVote = "votes.decision IN ('APPROVE', 'APPROVE_WITH_COMMENTS') OR (COALESCE(votes.decision, '') = '' AND votes.acceptance = true)"
+
+// tallyVotes counts the votes cast on the project. Votes count as P-member votes when the voter's
+// member state participates in the project's technical committee. A committee without participating
+// countries recorded is flagged, so that the rule does not ask for P-member votes.
+func tallyVotes(db *gorm.DB, project *models.Project) (*models.VoteTally, error) {
+	tally := &models.VoteTally{}
+
+	if err := db.Model(&models.Vote{}).Where("project_id = ?", project.ID).Count(&tally.TotalVotes).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&models.Vote{}).Where("project_id = ? AND decision = ?", project.ID, models.VoteAbstain).
+		Count(&tally.Abstentions).Error; err != nil {
 		return nil, err
 	}
 
-	// Calculate acceptance rate
-	result.AcceptanceRate = float64(result.AcceptedVotes) / float64(result.TotalVotes)
+	if err := db.Model(&models.Vote{}).Where("project_id = ?", project.ID).Where(approvingVote).
+		Count(&tally.ApprovalVotes).Error; err != nil {
+		return nil, err
+	}
+	tally.DisapprovalVotes = tally.TotalVotes - tally.ApprovalVotes - tally.Abstentions
 
-	// Check if acceptance threshold is met
-	result.CriteriaMet = result.AcceptanceRate >= requiredRate
+	var participatingCountries int64
+	if err := db.Table("participating_countries").
+		Where("technical_committee_id = ?", project.TechnicalCommitteeID).
+		Count(&participatingCountries).Error; err != nil {
+		return nil, err
+	}
 
-	// Generate appropriate message
-	if result.CriteriaMet {
-		result.Message = fmt.Sprintf("Project accepted with %.1f%% approval (required: %.1f%%)",
-			result.AcceptanceRate*100, requiredRate*100)
-	} else {
-		result.Message = fmt.Sprintf("Project not accepted. Current approval: %.1f%% (required: %.1f%%)",
-			result.AcceptanceRate*100, requiredRate*100)
+	// Without participating countries the committee has no eligible P-members
+	if participatingCountries == 0 {
+		tally.NoPMembers = true
+		return tally, nil
 	}
 
-	return result, nil
+	if err := db.Model(&models.Vote{}).
+		Joins("JOIN members ON members.id = votes.member_id").
+		Joins("JOIN national_standard_bodies ON national_standard_bodies.id = members.national_standard_body_id").
+		Joins("JOIN participating_countries ON participating_countries.member_state_id = national_standard_bodies.member_state_id").
+		Where("votes.project_id = ? AND participating_countries.technical_committee_id = ?", project.ID, project.TechnicalCommitteeID).
+		Count(&tally.PMemberVotes).Error; err != nil {
+		return nil, err
+	}
+
+	return tally, nil
 }
 
 func (r *BallotingRepository) FindBallotingByID(id uuid.UUID) (*models.Balloting, error) {
@@ -282,22 +416,81 @@ func (r *BallotingRepository) FindBallotingByPeriod(startDate, endDate time.Time
 	return ballotings, nil
 }
 
-func (r *BallotingRepository) RecommendFDARS(memberId, projectId string, recommended bool) error {
-	var ballot models.Balloting
-	if err := r.db.Where("project_id = ?", projectId).First(&ballot).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("balloting not found for project ID %s", projectId)
+// FindBallotingsDueForClosure returns active ballots whose end date has passed
+func (r *BallotingRepository) FindBallotingsDueForClosure() ([]models.Balloting, error) {
+	var ballotings []models.Balloting
+	err := r.db.Where("active = ? AND closed_at IS NULL AND end_date <= ?", true, time.Now()).
+		Order("end_date ASC").Find(&ballotings).Error
+	return ballotings, err
+}
+
+// CloseBalloting closes the ballot, runs the acceptance criteria check and stores the outcome
+// together with a proposed next course of action. Closing a ballot that is already closed
+// returns ErrBallotClosed.
+func (r *BallotingRepository) CloseBalloting(id uuid.UUID) (*models.Balloting, error) {
+	var balloting models.Balloting
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&balloting).Error; err != nil {
+			return err
 		}
+		if balloting.ClosedAt != nil {
+			return ErrBallotClosed
+		}
+		return closeBallotWithTx(tx, &balloting, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Preload("Project").First(&balloting, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &balloting, nil
+}
+
+// closeBallotWithTx closes the ballot, runs the acceptance criteria check and stores the outcome
+// together with a proposed next course of action
+func closeBallotWithTx(tx *gorm.DB, balloting *models.Balloting, now time.Time) error {
+	result, err := checkAcceptanceCriteria(tx, balloting.ProjectID)
+	if err != nil {
 		return err
 	}
 
-	now := time.Now()
+	balloting.Active = false
+	balloting.ClosedAt = &now
+	balloting.Result = result
+	balloting.NextCourseOfAction = models.ProposeNextCourseOfAction(result)
 
-	ballot.RecommendedByID = &memberId
-	ballot.Recommended = recommended
-	ballot.RecommendedAt = &now
-	ballot.Active = false
-	return r.db.Save(&ballot).Error
+	return tx.Model(balloting).Select("active", "closed_at", "result", "next_course_of_action", "updated_at").
+		Updates(balloting).Error
+}
+
+// RecommendFDARS records the recommendation on the project's FDARS ballot. A ballot still open is
+// closed with its result first.
+func (r *BallotingRepository) RecommendFDARS(memberId, projectId string, recommended bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ballot models.Balloting
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", projectId).First(&ballot).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("balloting not found for project ID %s", projectId)
+			}
+			return err
+		}
+
+		now := time.Now()
+		if ballot.ClosedAt == nil {
+			if err := closeBallotWithTx(tx, &ballot, now); err != nil {
+				return err
+			}
+		}
+
+		ballot.RecommendedByID = &memberId
+		ballot.Recommended = recommended
+		ballot.RecommendedAt = &now
+		return tx.Model(&ballot).Select("recommended_by_id", "recommended", "recommended_at", "updated_at").
+			Updates(&ballot).Error
+	})
 }
 
 func (r *BallotingRepository) VerifyFDARSRecommendation(memberId, projectId string) error {
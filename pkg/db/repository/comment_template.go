@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPublicReviewClosed = errors.New("the project has no DARS open for public review")
+)
+
+// ImportComments adds the comments read from a comment template to an active project, all or none
+func (r *CommentRepository) ImportComments(projectID string, comments []models.CommentObservation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCommentProjectWithTx(tx, projectID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range comments {
+			comments[i].ID = uuid.New()
+			comments[i].ProjectID = projectID
+			comments[i].CreatedAt = now
+			if err := tx.Omit("Project", "NationalSecretary").Create(&comments[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetCommentTemplate compiles the comments on a project's draft in the layout of the comment
+// template, sorted by clause
+func (r *CommentRepository) GetCommentTemplate(projectID string) (*models.CommentTemplate, error) {
+	template, err := newCommentTemplateWithTx(r.db, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []models.CommentObservation
+	if err := preloadCommentMemberBody(r.db).Where("project_id = ?", projectID).Order("created_at").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		template.Rows = append(template.Rows, comments[i].TemplateRow())
+	}
+	template.SortByClause()
+	return template, nil
+}
+
+// GetCommentMember returns the member with the NSB and member state their comments are filed under
+func (r *CommentRepository) GetCommentMember(memberID string) (*models.Member, error) {
+	return commentMemberWithTx(r.db, memberID)
+}
+
+// ImportConsultations adds the comments read from a comment template to the public consultation on
+// an active project's DARS while its public review is open, all or none
+func (r *ConsultationRepository) ImportConsultations(projectID string, consultations []models.NationalConsultation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkCommentProjectWithTx(tx, projectID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var dars models.DARS
+		if err := tx.Where("project_id = ?", projectID).First(&dars).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPublicReviewClosed
+			}
+			return err
+		}
+		if !dars.PublicReviewEndDate.After(now) {
+			return ErrPublicReviewClosed
+		}
+
+		for i := range consultations {
+			consultations[i].ID = uuid.New()
+			consultations[i].ProjectID = projectID
+			consultations[i].DARSID = dars.ID
+			consultations[i].CreatedAt = now
+			if err := tx.Omit("Project", "DARS", "NationalSecretary").Create(&consultations[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetCommentTemplate compiles the public consultation comments on a project's draft in the layout
+// of the comment template, sorted by clause
+func (r *ConsultationRepository) GetCommentTemplate(projectID string) (*models.CommentTemplate, error) {
+	template, err := newCommentTemplateWithTx(r.db, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var consultations []models.NationalConsultation
+	if err := preloadCommentMemberBody(r.db).Where("project_id = ?", projectID).Order("created_at").Find(&consultations).Error; err != nil {
+		return nil, err
+	}
+	for i := range consultations {
+		template.Rows = append(template.Rows, consultations[i].TemplateRow())
+	}
+	template.SortByClause()
+	return template, nil
+}
+
+// GetCommentMember returns the member with the NSB and member state their comments are filed under
+func (r *ConsultationRepository) GetCommentMember(memberID string) (*models.Member, error) {
+	return commentMemberWithTx(r.db, memberID)
+}
+
+func commentMemberWithTx(tx *gorm.DB, memberID string) (*models.Member, error) {
+	var member models.Member
+	if err := tx.Preload("NationalStandardBody.MemberState").First(&member, "id = ?", memberID).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func newCommentTemplateWithTx(tx *gorm.DB, projectID string) (*models.CommentTemplate, error) {
+	var project models.Project
+	if err := tx.Preload("TechnicalCommittee").First(&project, "id = ?", projectID).Error; err != nil {
+		return nil, err
+	}
+
+	template := &models.CommentTemplate{
+		ProjectID:   projectID,
+		Reference:   project.Reference,
+		Title:       project.Title,
+		Rows:        []models.CommentTemplateRow{},
+		GeneratedAt: time.Now(),
+	}
+	if project.TechnicalCommittee != nil {
+		template.CommitteeCode = project.TechnicalCommittee.Code
+	}
+	return template, nil
+}
+
+func preloadCommentMemberBody(tx *gorm.DB) *gorm.DB {
+	return tx.
+		Preload("NationalSecretary").
+		Preload("NationalSecretary.NationalStandardBody").
+		Preload("NationalSecretary.NationalStandardBody.MemberState")
+}
@@ -20,11 +20,28 @@ func NewCommentRepository(db *gorm.DB) *CommentRepository {
 
 // Create adds a new CommentObservation to the database
 func (r *CommentRepository) Create(comment *models.CommentObservation) error {
+	if err := checkCommentProjectWithTx(r.db, comment.ProjectID); err != nil {
+		return err
+	}
+
 	comment.ID = uuid.New()
 	comment.CreatedAt = time.Now()
 	return r.db.Create(&comment).Error
 }
 
+// checkCommentProjectWithTx ensures a project exists and is still active before comments are added
+// to it
+func checkCommentProjectWithTx(tx *gorm.DB, projectID string) error {
+	var project models.Project
+	if err := tx.Select("id", "cancelled", "status").Where("id = ?", projectID).First(&project).Error; err != nil {
+		return err
+	}
+	if !project.IsActive() {
+		return ErrProjectNotActive
+	}
+	return nil
+}
+
 // GetByID retrieves a CommentObservation by its ID
 func (r *CommentRepository) GetByID(id uuid.UUID) (*models.CommentObservation, error) {
 	var comment models.CommentObservation
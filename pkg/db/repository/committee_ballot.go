@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotCommitteeBallotSecretary = errors.New("only the secretary of the technical committee may open, close or cancel its internal ballots")
+	ErrCommitteeBallotLink         = errors.New("internal ballots can only be linked to projects and meetings of the same technical committee")
+	ErrNoCommitteeBallotVoters     = errors.New("the technical committee has no P-members with a national TC secretary to vote")
+	ErrCommitteeBallotNotOpen      = errors.New("the internal ballot is not open for voting")
+	ErrCommitteeBallotOption       = errors.New("the option does not belong to the internal ballot")
+)
+
+// CreateCommitteeBallot opens an internal ballot of a technical committee. The NSBs of the
+// committee's P-member countries are recorded as the voters, so later membership changes do not
+// affect the ballot.
+func (r *BallotingRepository) CreateCommitteeBallot(ballot *models.CommitteeBallot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		isSecretary, err := isSecretaryOfWithTx(tx, ballot.CreatedByID, ballot.TechnicalCommitteeID)
+		if err != nil {
+			return err
+		}
+		if !isSecretary {
+			return ErrNotCommitteeBallotSecretary
+		}
+		if err := checkCommitteeBallotLinksWithTx(tx, ballot); err != nil {
+			return err
+		}
+
+		var nsbs []models.NationalStandardBody
+		if err := tx.Joins("JOIN participating_countries ON participating_countries.member_state_id = national_standard_bodies.member_state_id").
+			Where("participating_countries.technical_committee_id = ? AND national_standard_bodies.national_tc_secretary_id IS NOT NULL", ballot.TechnicalCommitteeID).
+			Find(&nsbs).Error; err != nil {
+			return err
+		}
+		if len(nsbs) == 0 {
+			return ErrNoCommitteeBallotVoters
+		}
+
+		number, err := nextNumberWithTx(tx, models.CommitteeBallotScope(ballot.TechnicalCommitteeID))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		ballot.ID = uuid.New()
+		ballot.Number = number
+		ballot.Status = models.CommitteeBallotOpen
+		ballot.CreatedAt = now
+		ballot.UpdatedAt = now
+		for i := range ballot.Options {
+			ballot.Options[i].ID = uuid.New()
+			ballot.Options[i].BallotID = ballot.ID.String()
+			ballot.Options[i].Position = i + 1
+		}
+		ballot.Voters = make([]models.CommitteeBallotVoter, 0, len(nsbs))
+		for _, nsb := range nsbs {
+			ballot.Voters = append(ballot.Voters, models.CommitteeBallotVoter{
+				ID:                     uuid.New(),
+				BallotID:               ballot.ID.String(),
+				NationalStandardBodyID: nsb.ID.String(),
+			})
+		}
+
+		if err := tx.Omit(clause.Associations).Create(ballot).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&ballot.Options).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&ballot.Voters).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CommitteeBallotEvent{
+			ID:        uuid.New(),
+			BallotID:  ballot.ID.String(),
+			Type:      models.CommitteeBallotOpened,
+			ActorID:   &ballot.CreatedByID,
+			Notes:     fmt.Sprintf("%d voters, closes %s", len(ballot.Voters), ballot.ClosesAt.Format("2006-01-02 15:04")),
+			CreatedAt: now,
+		}).Error
+	})
+}
+
+// checkCommitteeBallotLinksWithTx checks that the project and meeting of the ballot belong to the
+// ballot's committee
+func checkCommitteeBallotLinksWithTx(tx *gorm.DB, ballot *models.CommitteeBallot) error {
+	if ballot.ProjectID != nil {
+		var project models.Project
+		if err := tx.Select("id", "technical_committee_id").First(&project, "id = ?", *ballot.ProjectID).Error; err != nil {
+			return err
+		}
+		if project.TechnicalCommitteeID != ballot.TechnicalCommitteeID {
+			return ErrCommitteeBallotLink
+		}
+	}
+	if ballot.MeetingID != nil {
+		var meeting models.Meeting
+		if err := tx.Select("id", "committee_id").First(&meeting, "id = ?", *ballot.MeetingID).Error; err != nil {
+			return err
+		}
+		if meeting.CommitteeID != ballot.TechnicalCommitteeID {
+			return ErrCommitteeBallotLink
+		}
+	}
+	return nil
+}
+
+// CastCommitteeBallotVote records the vote of the member's NSB on an internal ballot, replacing the
+// NSB's earlier vote. Only the national TC secretary of a voting NSB may vote.
+func (r *BallotingRepository) CastCommitteeBallotVote(vote *models.CommitteeBallotVote) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ballot models.CommitteeBallot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ballot, "id = ?", vote.BallotID).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if !ballot.AcceptsVotes(now) {
+			return ErrCommitteeBallotNotOpen
+		}
+
+		var nsb models.NationalStandardBody
+		if err := tx.Where("national_tc_secretary_id = ?", vote.MemberID).First(&nsb).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: only national TC secretaries may vote", ErrNotEligibleToVote)
+			}
+			return err
+		}
+		var voters int64
+		if err := tx.Model(&models.CommitteeBallotVoter{}).
+			Where("ballot_id = ? AND national_standard_body_id = ?", ballot.ID, nsb.ID).
+			Count(&voters).Error; err != nil {
+			return err
+		}
+		if voters == 0 {
+			return fmt.Errorf("%w: %s was not a P-member when the ballot opened", ErrNotEligibleToVote, nsb.Name)
+		}
+
+		if vote.Abstain {
+			vote.OptionID = nil
+		} else {
+			var options int64
+			if vote.OptionID != nil {
+				if err := tx.Model(&models.CommitteeBallotOption{}).
+					Where("id = ? AND ballot_id = ?", *vote.OptionID, ballot.ID).
+					Count(&options).Error; err != nil {
+					return err
+				}
+			}
+			if options == 0 {
+				return ErrCommitteeBallotOption
+			}
+		}
+
+		vote.NationalStandardBodyID = nsb.ID.String()
+		vote.UpdatedAt = now
+		event := models.CommitteeBallotEvent{
+			ID:                     uuid.New(),
+			BallotID:               ballot.ID.String(),
+			Type:                   models.CommitteeBallotVoted,
+			ActorID:                &vote.MemberID,
+			NationalStandardBodyID: &vote.NationalStandardBodyID,
+			OptionID:               vote.OptionID,
+			Abstain:                vote.Abstain,
+			Notes:                  vote.Comment,
+			CreatedAt:              now,
+		}
+
+		var existing models.CommitteeBallotVote
+		err := tx.Where("ballot_id = ? AND national_standard_body_id = ?", ballot.ID, nsb.ID).First(&existing).Error
+		switch {
+		case err == nil:
+			vote.ID = existing.ID
+			vote.CreatedAt = existing.CreatedAt
+			event.Type = models.CommitteeBallotVoteChanged
+			if err := tx.Model(&existing).Select("option_id", "abstain", "comment", "member_id", "updated_at").
+				Updates(vote).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			vote.ID = uuid.New()
+			vote.CreatedAt = now
+			if err := tx.Omit(clause.Associations).Create(vote).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		return tx.Create(&event).Error
+	})
+}
+
+// CloseCommitteeBallot closes an open internal ballot and stores its result. A nil memberID means
+// the ballot was closed by the scheduler at its closing date; otherwise the committee secretary is
+// closing it early. A carried ballot linked to a meeting is issued the committee's next meeting
+// resolution number.
+func (r *BallotingRepository) CloseCommitteeBallot(id uuid.UUID, memberID *string) (*models.CommitteeBallot, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ballot, err := lockOpenCommitteeBallotWithTx(tx, id, memberID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Order("position ASC").Find(&ballot.Options, "ballot_id = ?", ballot.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Find(&ballot.Voters, "ballot_id = ?", ballot.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Find(&ballot.Votes, "ballot_id = ?", ballot.ID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		ballot.Result = ballot.Tally()
+		ballot.Status = models.CommitteeBallotClosed
+		ballot.ClosedByID = memberID
+		ballot.ClosedAt = &now
+		ballot.UpdatedAt = now
+		if ballot.Result.Carried && ballot.MeetingID != nil {
+			number, err := nextNumberWithTx(tx, models.MeetingResolutionScope(ballot.TechnicalCommitteeID))
+			if err != nil {
+				return err
+			}
+			ballot.ResolutionNumber = &number
+		}
+
+		if err := tx.Model(ballot).Select("status", "result", "resolution_number", "closed_by_id", "closed_at", "updated_at").
+			Updates(ballot).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CommitteeBallotEvent{
+			ID:        uuid.New(),
+			BallotID:  ballot.ID.String(),
+			Type:      models.CommitteeBallotClosedEvent,
+			ActorID:   memberID,
+			OptionID:  optionIDString(ballot.Result.WinningOptionID),
+			Notes:     ballot.Result.Message,
+			CreatedAt: now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCommitteeBallot(id)
+}
+
+// CancelCommitteeBallot withdraws an open internal ballot without a result
+func (r *BallotingRepository) CancelCommitteeBallot(id uuid.UUID, memberID, reason string) (*models.CommitteeBallot, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ballot, err := lockOpenCommitteeBallotWithTx(tx, id, &memberID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(ballot).Updates(map[string]interface{}{
+			"status":              models.CommitteeBallotCancelled,
+			"cancellation_reason": reason,
+			"closed_by_id":        memberID,
+			"closed_at":           now,
+			"updated_at":          now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CommitteeBallotEvent{
+			ID:        uuid.New(),
+			BallotID:  ballot.ID.String(),
+			Type:      models.CommitteeBallotCancelEvent,
+			ActorID:   &memberID,
+			Notes:     reason,
+			CreatedAt: now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCommitteeBallot(id)
+}
+
+// lockOpenCommitteeBallotWithTx locks an open internal ballot for closing or cancelling. A member
+// acting on the ballot must be the secretary of its committee.
+func lockOpenCommitteeBallotWithTx(tx *gorm.DB, id uuid.UUID, memberID *string) (*models.CommitteeBallot, error) {
+	var ballot models.CommitteeBallot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ballot, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	if ballot.Status != models.CommitteeBallotOpen {
+		return nil, ErrCommitteeBallotNotOpen
+	}
+	if memberID != nil {
+		isSecretary, err := isSecretaryOfWithTx(tx, *memberID, ballot.TechnicalCommitteeID)
+		if err != nil {
+			return nil, err
+		}
+		if !isSecretary {
+			return nil, ErrNotCommitteeBallotSecretary
+		}
+	}
+	return &ballot, nil
+}
+
+func optionIDString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
+func (r *BallotingRepository) GetCommitteeBallot(id uuid.UUID) (*models.CommitteeBallot, error) {
+	var ballot models.CommitteeBallot
+	err := r.db.Preload("TechnicalCommittee").
+		Preload("Project").
+		Preload("Meeting").
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Voters.NationalStandardBody").
+		Preload("Votes.NationalStandardBody").
+		Preload("Votes.Member").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("CreatedBy").
+		Preload("ClosedBy").
+		First(&ballot, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ballot, nil
+}
+
+// GetCommitteeBallots lists the internal ballots of a technical committee, latest first. The
+// status and project filters are optional.
+func (r *BallotingRepository) GetCommitteeBallots(committeeID string, status models.CommitteeBallotStatus, projectID string) ([]models.CommitteeBallot, error) {
+	query := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("technical_committee_id = ?", committeeID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if projectID != "" {
+		query = query.Where("project_id = ?", projectID)
+	}
+
+	var ballots []models.CommitteeBallot
+	err := query.Order("number DESC").Find(&ballots).Error
+	return ballots, err
+}
+
+// GetProjectCommitteeBallots lists the internal ballots linked to a project, latest first
+func (r *BallotingRepository) GetProjectCommitteeBallots(projectID string) ([]models.CommitteeBallot, error) {
+	var ballots []models.CommitteeBallot
+	err := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("project_id = ?", projectID).
+		Order("created_at DESC").
+		Find(&ballots).Error
+	return ballots, err
+}
+
+// FindCommitteeBallotsDueForClosure returns open internal ballots whose closing date has passed
+func (r *BallotingRepository) FindCommitteeBallotsDueForClosure() ([]models.CommitteeBallot, error) {
+	var ballots []models.CommitteeBallot
+	err := r.db.Where("status = ? AND closes_at <= ?", models.CommitteeBallotOpen, time.Now()).
+		Order("closes_at ASC").Find(&ballots).Error
+	return ballots, err
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotTrackSecretary       = errors.New("only the secretary of the technical committee may change the development track of the project")
+	ErrInvalidDevelopmentTrack = errors.New("development track must be DEFAULT, INTERNATIONAL or FAST_TRACK")
+	ErrInvalidNonWorkingPeriod = errors.New("a non-working period must end on or after its start date")
+)
+
+// loadWorkingCalendarWithTx loads every non-working period
+func loadWorkingCalendarWithTx(tx *gorm.DB) (models.WorkingCalendar, error) {
+	var periods []models.NonWorkingPeriod
+	err := tx.Order("start_date ASC").Find(&periods).Error
+	return models.WorkingCalendar(periods), err
+}
+
+// scheduleAcceptanceWithTx computes the milestone target dates of an approved proposal and replans
+// the project's stages around them. Proposals not yet approved are left as they are.
+func scheduleAcceptanceWithTx(tx *gorm.DB, acceptance *models.Acceptance) error {
+	calendar, err := loadWorkingCalendarWithTx(tx)
+	if err != nil {
+		return err
+	}
+	if !acceptance.ScheduleTargets(calendar) {
+		return nil
+	}
+
+	if err := tx.Model(&models.Acceptance{}).Where("id = ?", acceptance.ID).Updates(map[string]interface{}{
+		"target_date_cd":    acceptance.TargetDateCD,
+		"target_date_dars":  acceptance.TargetDateDARS,
+		"target_date_fdars": acceptance.TargetDateFDARS,
+	}).Error; err != nil {
+		return err
+	}
+
+	var project models.Project
+	if err := tx.First(&project, "id = ?", acceptance.ProjectID).Error; err != nil {
+		return err
+	}
+	project.Acceptance = acceptance
+	_, err = planProjectStagesWithTx(tx, &project, project.CreatedAt)
+	return err
+}
+
+// ChangeDevelopmentTrack moves an accepted project to another development track and recomputes its
+// target dates and stage plan
+func (r *AcceptanceRepository) ChangeDevelopmentTrack(projectID, secretary string, track models.DevelopmentTrack) (*models.Acceptance, error) {
+	switch track {
+	case models.TrackDefault, models.TrackInternational, models.TrackFastTrack:
+	default:
+		return nil, ErrInvalidDevelopmentTrack
+	}
+
+	var acceptance models.Acceptance
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectSecretaryWithTx(tx, projectID, secretary, ErrNotTrackSecretary); err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", projectID).First(&acceptance).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&acceptance).Update("development_track", track).Error; err != nil {
+			return err
+		}
+
+		acceptance.DevelopmentTrack = track
+		return scheduleAcceptanceWithTx(tx, &acceptance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &acceptance, nil
+}
+
+// CreateNonWorkingPeriod adds a period to the working calendar. Target dates already computed are
+// kept; they take the period into account the next time they are computed.
+func (r *AcceptanceRepository) CreateNonWorkingPeriod(period *models.NonWorkingPeriod) error {
+	if period.EndDate.Before(period.StartDate) {
+		return ErrInvalidNonWorkingPeriod
+	}
+
+	period.ID = uuid.New()
+	period.CreatedAt = time.Now()
+	return r.db.Omit(clause.Associations).Create(period).Error
+}
+
+func (r *AcceptanceRepository) GetNonWorkingPeriods() ([]models.NonWorkingPeriod, error) {
+	var periods []models.NonWorkingPeriod
+	err := r.db.Preload("CreatedBy").Order("start_date ASC").Find(&periods).Error
+	return periods, err
+}
+
+// DeleteNonWorkingPeriod removes a non-working period and returns it as it was
+func (r *AcceptanceRepository) DeleteNonWorkingPeriod(id uuid.UUID) (*models.NonWorkingPeriod, error) {
+	var period models.NonWorkingPeriod
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&period, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&period).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
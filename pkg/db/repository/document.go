@@ -70,21 +70,34 @@ func (r *DocumentRepository) UploadStandard(doc *models.Document, project *model
 		return err
 	}
 
+	// Standards uploaded directly keep the designation they were published under
+	if err := tx.Create(&models.ProjectReference{
+		ID:        uuid.New(),
+		ProjectID: project.ID.String(),
+		Reference: project.Reference,
+		Reason:    "Standard uploaded",
+		CreatedAt: time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
 func (r *DocumentRepository) UpdateProjectDoc(projectId, docType, fileURL, member string) error {
-	tx := r.db.Begin() // Start a transaction
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		_, err := updateProjectDocWithTx(tx, projectId, docType, fileURL, member)
+		return err
+	})
+}
 
+// updateProjectDocWithTx records the file as the project document of the given type. Publishing the
+// ARS also marks the project published and gives it its designation.
+func updateProjectDocWithTx(tx *gorm.DB, projectId, docType, fileURL, member string) (*models.Document, error) {
 	var project models.Project
 	if err := tx.Where("id = ?", projectId).First(&project).Error; err != nil {
-		tx.Rollback()
-		return err
+		return nil, err
 	}
 
 	doc := models.Document{
@@ -97,43 +110,52 @@ func (r *DocumentRepository) UpdateProjectDoc(projectId, docType, fileURL, membe
 	}
 
 	if err := tx.Create(&doc).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if docType == "WD" {
-		docID := doc.ID.String()
-		project.WorkingDraftID = &docID
-	}
-	if docType == "CD" {
-		docID := doc.ID.String()
-		project.CommitteeDraftID = &docID
-	}
-
-	if docType == "DARS" {
-		docID := doc.ID.String()
-		project.DARSDocID = &docID
+		return nil, err
 	}
 
-	if docType == "FDARS" {
+	if field := projectDocField(&project, docType); field != nil {
 		docID := doc.ID.String()
-		project.FDARSDocID = &docID
+		*field = &docID
 	}
 
 	if docType == "ARS" {
-		docID := doc.ID.String()
-		project.StandardID = &docID
 		project.Published = true
 		now := time.Now()
 		project.PublishedDate = &now
 	}
 
 	if err := tx.Save(&project).Error; err != nil {
-		tx.Rollback()
-		return err
+		return nil, err
 	}
 
-	return tx.Commit().Error
+	// Published standards carry their ARS designation and supersede the standards they revise
+	if docType == "ARS" {
+		if err := assignStandardDesignationWithTx(tx, &project, *project.PublishedDate); err != nil {
+			return nil, err
+		}
+		if err := supersedeRevisedStandardsWithTx(tx, project.ID.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &doc, nil
+}
+
+// projectDocField returns the field of the project holding its document of the given type
+func projectDocField(project *models.Project, docType string) **string {
+	switch docType {
+	case "WD":
+		return &project.WorkingDraftID
+	case "CD":
+		return &project.CommitteeDraftID
+	case "DARS":
+		return &project.DARSDocID
+	case "FDARS":
+		return &project.FDARSDocID
+	case "ARS":
+		return &project.StandardID
+	}
+	return nil
 }
 
 func (r *DocumentRepository) UpdateProjectRelatedDoc(projectId, docTitle, docRef, docDescription, fileURL, member string) error {
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ekbaya/asham/pkg/domain/models"
@@ -8,11 +10,96 @@ import (
 	"gorm.io/gorm"
 )
 
-// Helper method to update project stage within a transaction
-func UpdateProjectStageWithTx(tx *gorm.DB, projectID string, newStageID string, notes, currentDoc, newDoc string) error {
+var (
+	ErrIllegalStageTransition = errors.New("illegal stage transition")
+	ErrStageTransitionGuard   = errors.New("stage transition conditions not met")
+)
+
+// LoadProjectForWorkflowWithTx loads a project with the associations needed to evaluate transition guards
+func LoadProjectForWorkflowWithTx(tx *gorm.DB, projectID string) (*models.Project, error) {
+	var project models.Project
+	err := tx.Preload("Stage").
+		Preload("Proposal").
+		Preload("Acceptance").
+		Preload("DARS").
+		Preload("Balloting").
+		Preload("Adoption").
+		First(&project, "id = ?", projectID).Error
+	if err != nil {
+		return nil, err
+	}
+	if project.Stage == nil {
+		return nil, fmt.Errorf("project %s has no current stage", projectID)
+	}
+	return &project, nil
+}
+
+// TransitionProjectStageWithTx moves a project to the stage with the given number. The move must be
+// declared in models.ProjectWorkflow for the project's procedure and all of its guards must hold.
+func TransitionProjectStageWithTx(tx *gorm.DB, projectID string, toStage int, actorID *string, reason string) error {
+	project, err := LoadProjectForWorkflowWithTx(tx, projectID)
+	if err != nil {
+		return err
+	}
+
+	transition, ok := models.FindStageTransition(project.Procedure, project.Stage.Number, toStage)
+	if !ok {
+		return fmt.Errorf("%w: project cannot move from stage %d (%s) to stage %d",
+			ErrIllegalStageTransition, project.Stage.Number, project.Stage.Abbreviation, toStage)
+	}
+
+	return applyStageTransitionWithTx(tx, project, transition, actorID, reason)
+}
+
+// AdvanceProjectStageWithTx moves a project along the single forward transition that the workflow
+// declares out of its current stage for its procedure
+func AdvanceProjectStageWithTx(tx *gorm.DB, projectID string, actorID *string, reason string) error {
+	project, err := LoadProjectForWorkflowWithTx(tx, projectID)
+	if err != nil {
+		return err
+	}
+
+	transitions := models.StageTransitionsFrom(project.Procedure, project.Stage.Number)
+	if len(transitions) != 1 {
+		return fmt.Errorf("%w: no single transition out of stage %d (%s) for procedure %q",
+			ErrIllegalStageTransition, project.Stage.Number, project.Stage.Abbreviation, project.Procedure)
+	}
+
+	return applyStageTransitionWithTx(tx, project, &transitions[0], actorID, reason)
+}
+
+// applyStageTransitionWithTx checks the transition guards and records the move in the stage history
+// together with the actor and reason. The project is given the draft reference of the new stage.
+func applyStageTransitionWithTx(tx *gorm.DB, project *models.Project, transition *models.StageTransition, actorID *string, reason string) error {
+	if unmet := transition.UnmetGuards(project); len(unmet) > 0 {
+		return fmt.Errorf("%w: %s requires %v", ErrStageTransitionGuard, transition.Name, unmet)
+	}
+
+	var stage models.Stage
+	if err := tx.Where("number = ?", transition.ToStage).First(&stage).Error; err != nil {
+		return err
+	}
+
+	fromStageID := project.StageID
+	if err := moveProjectStageWithTx(tx, models.ProjectStageHistory{
+		ProjectID:   project.ID.String(),
+		StageID:     stage.ID.String(),
+		FromStageID: &fromStageID,
+		Transition:  transition.Name,
+		ActorID:     actorID,
+		Notes:       reason,
+	}); err != nil {
+		return err
+	}
+
+	return assignDraftReferenceWithTx(tx, project.ID.String(), &stage, transition.Name)
+}
+
+// moveProjectStageWithTx updates the project stage and stage history within a transaction
+func moveProjectStageWithTx(tx *gorm.DB, stageHistory models.ProjectStageHistory) error {
 	// Direct SQL update to avoid fetching the project again
-	if err := tx.Exec("UPDATE projects SET reference = REPLACE(reference, ?, ?), stage_id = ?, updated_at = ? WHERE id = ?",
-		currentDoc, newDoc, newStageID, time.Now(), projectID).Error; err != nil {
+	if err := tx.Exec("UPDATE projects SET stage_id = ?, updated_at = ? WHERE id = ?",
+		stageHistory.StageID, time.Now(), stageHistory.ProjectID).Error; err != nil {
 		return err
 	}
 
@@ -21,20 +108,15 @@ func UpdateProjectStageWithTx(tx *gorm.DB, projectID string, newStageID string,
 
 	// Close previous stage
 	if err := tx.Exec("UPDATE project_stage_histories SET ended_at = ? WHERE project_id = ? AND ended_at IS NULL",
-		now, projectID).Error; err != nil {
+		now, stageHistory.ProjectID).Error; err != nil {
 		return err
 	}
 
 	// Add new stage history
-	stageHistory := models.ProjectStageHistory{
-		ID:        uuid.New(),
-		ProjectID: projectID,
-		StageID:   newStageID,
-		StartedAt: now,
-		Notes:     notes,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
+	stageHistory.ID = uuid.New()
+	stageHistory.StartedAt = now
+	stageHistory.CreatedAt = now
+	stageHistory.UpdatedAt = now
 
 	if err := tx.Create(&stageHistory).Error; err != nil {
 		return err
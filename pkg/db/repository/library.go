@@ -41,7 +41,7 @@ func (r *LibraryRepository) GetTopStandards(limit, offset int) ([]models.Project
 	var standards []models.Project
 	var total int64
 
-	query := r.db.Model(&models.Project{}).Where("published = ?", true)
+	query := r.db.Model(&models.Project{}).Where("published = ? AND base_standard_id IS NULL", true)
 	query.Count(&total)
 
 	result := query.
@@ -57,13 +57,14 @@ func (r *LibraryRepository) GetTopStandards(limit, offset int) ([]models.Project
 
 	for _, standard := range standards {
 		projects = append(projects, models.ProjectDTO{
-			ID:          standard.ID,
-			Title:       standard.Title,
-			Reference:   standard.Reference,
-			Published:   standard.Published,
-			Description: standard.Description,
-			CreatedAt:   standard.CreatedAt,
-			UpdatedAt:   standard.UpdatedAt,
+			ID:            standard.ID,
+			Title:         standard.Title,
+			Reference:     standard.Reference,
+			Published:     standard.Published,
+			LibraryStatus: standard.LibraryStatus,
+			Description:   standard.Description,
+			CreatedAt:     standard.CreatedAt,
+			UpdatedAt:     standard.UpdatedAt,
 		})
 	}
 
@@ -162,11 +163,13 @@ func (r *LibraryRepository) GetTopCommittees(limit, offset int) ([]models.Commit
 	return committeeDTOs, total, nil
 }
 
+// FindStandards searches the published standards. Amendments and corrigenda are not listed on their
+// own but with the standard they apply to.
 func (r *LibraryRepository) FindStandards(params map[string]any, limit, offset int) ([]models.Project, int64, error) {
 	var standards []models.Project
 	var total, filteredTotal int64
 
-	baseQuery := r.db.Model(&models.Project{}).Where("published = ?", true)
+	baseQuery := r.db.Model(&models.Project{}).Where("published = ? AND base_standard_id IS NULL", true)
 
 	// Count total standards before applying filters
 	if err := baseQuery.Count(&total).Error; err != nil {
@@ -193,6 +196,11 @@ func (r *LibraryRepository) FindStandards(params map[string]any, limit, offset i
 		query = query.Where("EXTRACT(YEAR FROM published_date) = ?", year)
 	}
 
+	if equivalence, ok := params["equivalence"].(string); ok && equivalence != "" {
+		adopted := r.db.Model(&models.AdoptedStandard{}).Select("project_id").Where("equivalence = ?", strings.ToUpper(equivalence))
+		query = query.Where("id IN (?)", adopted)
+	}
+
 	if sortBy, ok := params["sortBy"].(string); ok && sortBy != "" {
 		switch sortBy {
 		case "mostRecentAsc":
@@ -218,7 +226,10 @@ func (r *LibraryRepository) FindStandards(params map[string]any, limit, offset i
 	}
 
 	// Apply pagination
-	result := query.Limit(limit).Offset(offset).Preload(clause.Associations).Find(&standards)
+	result := query.Limit(limit).Offset(offset).
+		Preload(clause.Associations).
+		Preload("Supplements", publishedSupplements).
+		Find(&standards)
 
 	if result.Error != nil {
 		return nil, 0, result.Error
@@ -230,6 +241,7 @@ func (r *LibraryRepository) FindStandards(params map[string]any, limit, offset i
 func (r *LibraryRepository) GetProjectByID(id uuid.UUID) (*models.Project, error) {
 	var project models.Project
 	result := r.db.Where("id = ? AND published = ?", id, true).Preload("Standard").Preload("TechnicalCommittee").
+		Preload("BaseStandard").Preload("Supplements", publishedSupplements).Preload("Adoption").
 		First(&project)
 
 	if result.Error != nil {
@@ -241,11 +253,15 @@ func (r *LibraryRepository) GetProjectByID(id uuid.UUID) (*models.Project, error
 	return &project, nil
 }
 
+// GetProjectByReference finds a project by its current reference or by one it carried before
 func (r *LibraryRepository) GetProjectByReference(reference string) (*models.Project, error) {
 	var project models.Project
+	pastReferences := r.db.Model(&models.ProjectReference{}).Select("project_id").Where("reference = ?", reference)
 	result := r.db.Preload("Standard").Preload("TechnicalCommittee").Preload("WorkingGroup").
 		Preload("Stage").Preload("WorkingDraft").Preload("CommitteeDraft").
-		First(&project, "reference = ?", reference)
+		Where("reference = ? OR id IN (?)", reference, pastReferences).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "CASE WHEN reference = ? THEN 0 ELSE 1 END", Vars: []interface{}{reference}, WithoutParentheses: true}}).
+		First(&project)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("project not found")
@@ -331,7 +347,8 @@ func (r *LibraryRepository) GetCommitteeByID(id uuid.UUID) (*models.TechnicalCom
 	// Count active projects
 	var activeProjectCount int64
 	if err := r.db.Model(&models.Project{}).
-		Where("technical_committee_id = ? AND published = ? AND cancelled = ?", id, false, false).
+		Where("technical_committee_id = ? AND published = ?", id, false).
+		Where(activeProject).
 		Count(&activeProjectCount).Error; err != nil {
 		return nil, err
 	}
@@ -21,50 +21,44 @@ func NewConsultationRepository(db *gorm.DB) *ConsultationRepository {
 
 func (r *ConsultationRepository) Create(consultation *models.NationalConsultation) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		var dars models.DARS
-
-		// Check if DARS exists for the given project
-		if err := tx.Where("project_id = ?", consultation.ProjectID).First(&dars).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				// Get Project
-				var project models.Project
-				if err := tx.Where("id = ?", consultation.ProjectID).First(&project).Error; err != nil {
-					tx.Rollback()
-					return err
-				}
-
-				now := time.Now()
-
-				// Create a new DARS if it does not exist
-				dars = models.DARS{
-					ID:                    uuid.New(),
-					ProjectID:             consultation.ProjectID,
-					CreatedAt:             time.Now(),
-					PublicReviewStartDate: now,
-					PublicReviewEndDate:   now.AddDate(0, 2, 7),
-				}
-
-				if err := tx.Create(&dars).Error; err != nil {
-					tx.Rollback()
-					return err
-				}
-			} else {
-				tx.Rollback()
-				return err
-			}
+		dars, err := consultationDARSWithTx(tx, consultation.ProjectID)
+		if err != nil {
+			return err
 		}
 
 		consultation.DARSID = dars.ID
 		consultation.ID = uuid.New()
 		consultation.CreatedAt = time.Now()
 
-		if err := tx.Create(&consultation).Error; err != nil {
-			tx.Rollback()
-			return err
+		return tx.Create(&consultation).Error
+	})
+}
+
+// consultationDARSWithTx returns the DARS the consultation comments of a project are attached to,
+// opening it for public review when the project has none yet
+func consultationDARSWithTx(tx *gorm.DB, projectID string) (*models.DARS, error) {
+	var dars models.DARS
+
+	// Check if DARS exists for the given project
+	if err := tx.Where("project_id = ?", projectID).First(&dars).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
 		}
 
-		return nil
-	})
+		// Get Project
+		var project models.Project
+		if err := tx.Where("id = ?", projectID).First(&project).Error; err != nil {
+			return nil, err
+		}
+
+		// Create a new DARS if it does not exist
+		dars = models.NewDARS(projectID, time.Now())
+
+		if err := tx.Create(&dars).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &dars, nil
 }
 
 // GetByID retrieves a NationalConsultation by its ID
@@ -7,6 +7,15 @@ import (
 	"github.com/ekbaya/asham/pkg/domain/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotResponseOwner        = errors.New("only the responder or the national TC secretary of the NSB may ask to change its response")
+	ErrNotResponseSecretariat  = errors.New("only the secretary of the technical committee may decide on changes to NSB responses")
+	ErrChangeRequestPending    = errors.New("a change to this response is already awaiting a decision")
+	ErrChangeRequestNotPending = errors.New("the change request has already been decided")
+	ErrAcceptanceDecided       = errors.New("responses cannot be changed once the acceptance of the proposal has been approved")
 )
 
 type NSBResponseStatusChangeRepository struct {
@@ -38,15 +47,12 @@ func (r *NSBResponseStatusChangeRepository) GetByID(id uuid.UUID) (*models.NSBRe
 
 	result := r.db.
 		Preload("Responder").
-		Preload("InitialResponse").
+		Preload("InitialResponse.Project.TechnicalCommittee").
 		Preload("TCSecretariat").
 		Where("id = ?", id).
 		First(&change)
 
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("NSBResponseStatusChange not found")
-		}
 		return nil, result.Error
 	}
 
@@ -89,15 +95,20 @@ func (r *NSBResponseStatusChangeRepository) GetByResponderID(responderID string)
 	return changes, nil
 }
 
-func (r *NSBResponseStatusChangeRepository) GetPendingChanges() ([]models.NSBResponseStatusChange, error) {
+// GetPendingChanges lists the change requests awaiting a decision on the projects of the committees
+// the member is the secretary of
+func (r *NSBResponseStatusChangeRepository) GetPendingChanges(secretary string) ([]models.NSBResponseStatusChange, error) {
 	var changes []models.NSBResponseStatusChange
 
+	committees := r.db.Model(&models.TechnicalCommittee{}).Select("id").Where("secretary_id = ?", secretary)
 	result := r.db.
 		Preload("Responder").
 		Preload("InitialResponse").
 		Preload("TCSecretariat").
-		Where("status = ?", models.PENDING).
-		Order("created_at ASC").
+		Joins("JOIN nsb_responses ON nsb_responses.id = nsb_response_status_changes.initial_response_id").
+		Joins("JOIN projects ON projects.id = nsb_responses.project_id").
+		Where("nsb_response_status_changes.status = ? AND projects.technical_committee_id IN (?)", models.PENDING, committees).
+		Order("nsb_response_status_changes.created_at ASC").
 		Find(&changes)
 
 	if result.Error != nil {
@@ -138,48 +149,147 @@ func (r *NSBResponseStatusChangeRepository) GetByStatus(status models.Status) ([
 	return changes, nil
 }
 
-func (r *NSBResponseStatusChangeRepository) ApproveChange(id uuid.UUID, tcSecretariatID string) error {
-	// Start a transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
+// SubmitChange records an NSB's request to change its response. Only the member who responded or
+// the NSB's national TC secretary may ask for a change, and only while the acceptance of the
+// proposal is undecided and no other request on the response is pending.
+func (r *NSBResponseStatusChangeRepository) SubmitChange(change *models.NSBResponseStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var response models.NSBResponse
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("RespondingNSB").
+			First(&response, "id = ?", change.InitialResponseID).Error; err != nil {
+			return err
+		}
+		if !respondsForNSB(&response, change.ResponderID) {
+			return ErrNotResponseOwner
+		}
+
+		var acceptance models.Acceptance
+		err := tx.Select("id", "is_approved").Where("project_id = ?", response.ProjectID).First(&acceptance).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if acceptance.IsApproved {
+			return ErrAcceptanceDecided
+		}
+
+		var pending int64
+		if err := tx.Model(&models.NSBResponseStatusChange{}).
+			Where("initial_response_id = ? AND status = ?", response.ID, models.PENDING).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrChangeRequestPending
+		}
+
+		change.ID = uuid.New()
+		change.Status = models.PENDING
+		change.TCSecretariatID = nil
+		change.TCSecretariatComment = ""
+		change.DecidedAt = nil
+		change.CreatedAt = time.Now()
+		return tx.Omit(clause.Associations).Create(change).Error
+	})
+}
+
+// respondsForNSB reports whether the member may speak for the NSB that gave the response
+func respondsForNSB(response *models.NSBResponse, memberID string) bool {
+	if response.ResponderID == memberID {
+		return true
+	}
+	if response.NationalTCSecretaryID != nil && *response.NationalTCSecretaryID == memberID {
+		return true
 	}
+	nsb := response.RespondingNSB
+	return nsb != nil && nsb.NationalTCSecretaryID != nil && *nsb.NationalTCSecretaryID == memberID
+}
+
+// ApproveChange approves a pending change request and applies the requested answer to the initial
+// response. Only the secretary of the project's technical committee may approve a request.
+func (r *NSBResponseStatusChangeRepository) ApproveChange(id uuid.UUID, tcSecretariatID, comment string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		change, err := lockPendingChangeWithTx(tx, id, tcSecretariatID)
+		if err != nil {
+			return err
+		}
 
-	// Get the change request with initial response info
+		if err := decideChangeWithTx(tx, change, models.APPROVED, tcSecretariatID, comment); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.NSBResponse{}).Where("id = ?", change.InitialResponseID).Updates(map[string]interface{}{
+			"response":                    change.Response,
+			"is_committed_to_participate": change.IsCommittedToParticipate,
+		}).Error
+	})
+}
+
+// RejectChange rejects a pending change request, leaving the initial response as it was
+func (r *NSBResponseStatusChangeRepository) RejectChange(id uuid.UUID, tcSecretariatID, comment string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		change, err := lockPendingChangeWithTx(tx, id, tcSecretariatID)
+		if err != nil {
+			return err
+		}
+		return decideChangeWithTx(tx, change, models.REJECTED, tcSecretariatID, comment)
+	})
+}
+
+// lockPendingChangeWithTx locks a change request for a decision of the secretary of the technical
+// committee of the response's project
+func lockPendingChangeWithTx(tx *gorm.DB, id uuid.UUID, tcSecretariatID string) (*models.NSBResponseStatusChange, error) {
 	var change models.NSBResponseStatusChange
-	if err := tx.Preload("InitialResponse").Where("id = ?", id).First(&change).Error; err != nil {
-		tx.Rollback()
-		return err
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&change, "id = ?", id).Error; err != nil {
+		return nil, err
 	}
-
-	// Update the change request status
-	if err := tx.Model(&change).Updates(map[string]interface{}{
-		"status":            models.APPROVED,
-		"tc_secretariat_id": tcSecretariatID,
-	}).Error; err != nil {
-		tx.Rollback()
-		return err
+	if change.Status != models.PENDING {
+		return nil, ErrChangeRequestNotPending
 	}
 
-	// Update the initial NSBResponse with the new values
-	if err := tx.Model(&models.NSBResponse{}).Where("id = ?", change.InitialResponseID).Updates(map[string]interface{}{
-		"response":                    change.Response,
-		"is_committed_to_participate": change.IsCommittedToParticipate,
-	}).Error; err != nil {
-		tx.Rollback()
-		return err
+	var response models.NSBResponse
+	if err := tx.Select("id", "project_id").First(&response, "id = ?", change.InitialResponseID).Error; err != nil {
+		return nil, err
+	}
+	var project models.Project
+	if err := tx.Select("id", "technical_committee_id").First(&project, "id = ?", response.ProjectID).Error; err != nil {
+		return nil, err
+	}
+	isSecretary, err := isSecretaryOfWithTx(tx, tcSecretariatID, project.TechnicalCommitteeID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSecretary {
+		return nil, ErrNotResponseSecretariat
 	}
+	return &change, nil
+}
 
-	// Commit the transaction
-	return tx.Commit().Error
+func decideChangeWithTx(tx *gorm.DB, change *models.NSBResponseStatusChange, status models.Status, tcSecretariatID, comment string) error {
+	return tx.Model(change).Updates(map[string]interface{}{
+		"status":                 status,
+		"tc_secretariat_id":      tcSecretariatID,
+		"tc_secretariat_comment": comment,
+		"decided_at":             time.Now(),
+	}).Error
 }
 
-func (r *NSBResponseStatusChangeRepository) RejectChange(id uuid.UUID, tcSecretariatID, comment string) error {
-	return r.db.Model(&models.NSBResponseStatusChange{}).
-		Where("id = ?", id).
-		Updates(map[string]any{
-			"status":                 models.REJECTED,
-			"tc_secretariat_id":      tcSecretariatID,
-			"tc_secretariat_comment": comment,
-		}).Error
+// GetByProjectID lists the change requests on the responses to a project's proposal, latest first
+func (r *NSBResponseStatusChangeRepository) GetByProjectID(projectID string) ([]models.NSBResponseStatusChange, error) {
+	var changes []models.NSBResponseStatusChange
+
+	result := r.db.
+		Preload("Responder").
+		Preload("InitialResponse").
+		Preload("TCSecretariat").
+		Joins("JOIN nsb_responses ON nsb_responses.id = nsb_response_status_changes.initial_response_id").
+		Where("nsb_responses.project_id = ?", projectID).
+		Order("nsb_response_status_changes.created_at DESC").
+		Find(&changes)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return changes, nil
 }
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNumberNotReserved = errors.New("number is not reserved")
+
+// numberSequenceSeeds give the last number already in use when a scope is first used, so that
+// sequences continue from numbers issued before the registry existed
+var numberSequenceSeeds = map[string]string{
+	models.ProjectNumberScope: "SELECT COALESCE(MAX(number), 0) FROM projects",
+	models.StandardNumberScope: "SELECT COALESCE(MAX(GREATEST(standard_number, " +
+		"CASE WHEN published OR approved_for_publication THEN number ELSE 0 END)), 0) FROM projects",
+}
+
+type NumberingRepository struct {
+	db *gorm.DB
+}
+
+func NewNumberingRepository(db *gorm.DB) *NumberingRepository {
+	return &NumberingRepository{db: db}
+}
+
+// NextNumber issues the next number of the scope
+func (r *NumberingRepository) NextNumber(scope string) (int64, error) {
+	var number int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		number, err = nextNumberWithTx(tx, scope)
+		return err
+	})
+	return number, err
+}
+
+// PeekNextNumber returns the number the scope will issue next without issuing it
+func (r *NumberingRepository) PeekNextNumber(scope string) (int64, error) {
+	var released []models.NumberReservation
+	if err := r.db.Where("scope = ? AND status = ?", scope, models.NumberReleased).
+		Order("number ASC").Limit(1).Find(&released).Error; err != nil {
+		return 0, err
+	}
+	if len(released) > 0 {
+		return released[0].Number, nil
+	}
+
+	var sequences []models.NumberSequence
+	if err := r.db.Where("scope = ?", scope).Limit(1).Find(&sequences).Error; err != nil {
+		return 0, err
+	}
+	if len(sequences) > 0 {
+		return sequences[0].LastNumber + 1, nil
+	}
+
+	seed, err := seedNumberWithTx(r.db, scope)
+	return seed + 1, err
+}
+
+// Reserve holds the next number of the scope until it is used or released
+func (r *NumberingRepository) Reserve(scope string, memberID *string, purpose string, expiresAt *time.Time) (*models.NumberReservation, error) {
+	var reservation *models.NumberReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		released, err := takeReleasedNumberWithTx(tx, scope)
+		if err != nil {
+			return err
+		}
+
+		if released != nil {
+			reservation = released
+		} else {
+			number, err := incrementNumberSequenceWithTx(tx, scope)
+			if err != nil {
+				return err
+			}
+			reservation = &models.NumberReservation{ID: uuid.New(), Scope: scope, Number: number}
+		}
+
+		reservation.Status = models.NumberReserved
+		reservation.Purpose = purpose
+		reservation.ReservedByID = memberID
+		reservation.ExpiresAt = expiresAt
+		reservation.UsedAt = nil
+		reservation.ReleasedAt = nil
+		return tx.Save(reservation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// UseReservation marks a reserved number as used
+func (r *NumberingRepository) UseReservation(id uuid.UUID) (*models.NumberReservation, error) {
+	return r.updateReservation(id, func(reservation *models.NumberReservation, now time.Time) {
+		reservation.Status = models.NumberUsed
+		reservation.UsedAt = &now
+	})
+}
+
+// ReleaseReservation returns a reserved number that was never used to its scope
+func (r *NumberingRepository) ReleaseReservation(id uuid.UUID) (*models.NumberReservation, error) {
+	return r.updateReservation(id, func(reservation *models.NumberReservation, now time.Time) {
+		reservation.Status = models.NumberReleased
+		reservation.ReleasedAt = &now
+	})
+}
+
+func (r *NumberingRepository) updateReservation(id uuid.UUID, update func(*models.NumberReservation, time.Time)) (*models.NumberReservation, error) {
+	var reservation models.NumberReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if reservation.Status != models.NumberReserved {
+			return fmt.Errorf("%w: number %d of %s is %s", ErrNumberNotReserved, reservation.Number, reservation.Scope, reservation.Status)
+		}
+
+		update(&reservation, time.Now())
+		return tx.Save(&reservation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// useReservationWithTx marks a reserved number of the scope as used within the caller's
+// transaction, so the reservation stays open if the transaction rolls back
+func useReservationWithTx(tx *gorm.DB, id uuid.UUID, scope string) (*models.NumberReservation, error) {
+	var reservation models.NumberReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	if reservation.Scope != scope {
+		return nil, fmt.Errorf("%w: number %d is reserved for %s, not %s", ErrNumberNotReserved, reservation.Number, reservation.Scope, scope)
+	}
+	if reservation.Status != models.NumberReserved {
+		return nil, fmt.Errorf("%w: number %d of %s is %s", ErrNumberNotReserved, reservation.Number, reservation.Scope, reservation.Status)
+	}
+
+	now := time.Now()
+	reservation.Status = models.NumberUsed
+	reservation.UsedAt = &now
+	if err := tx.Save(&reservation).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// ReleaseExpiredReservations releases reservations that were not used before they expired
+func (r *NumberingRepository) ReleaseExpiredReservations(now time.Time) (int64, error) {
+	result := r.db.Model(&models.NumberReservation{}).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at < ?", models.NumberReserved, now).
+		Updates(map[string]interface{}{"status": models.NumberReleased, "released_at": now, "updated_at": now})
+	return result.RowsAffected, result.Error
+}
+
+func (r *NumberingRepository) GetReservations(scope string, status models.NumberReservationStatus) ([]models.NumberReservation, error) {
+	var reservations []models.NumberReservation
+	query := r.db.Preload("ReservedBy").Where("scope = ?", scope)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("number ASC").Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *NumberingRepository) GetSequences() ([]models.NumberSequence, error) {
+	var sequences []models.NumberSequence
+	err := r.db.Order("scope ASC").Find(&sequences).Error
+	return sequences, err
+}
+
+// nextNumberWithTx issues the next number of the scope within the caller's transaction, so the
+// number is given back if the transaction rolls back. Released numbers are issued first.
+func nextNumberWithTx(tx *gorm.DB, scope string) (int64, error) {
+	released, err := takeReleasedNumberWithTx(tx, scope)
+	if err != nil {
+		return 0, err
+	}
+	if released == nil {
+		return incrementNumberSequenceWithTx(tx, scope)
+	}
+
+	now := time.Now()
+	released.Status = models.NumberUsed
+	released.UsedAt = &now
+	if err := tx.Save(released).Error; err != nil {
+		return 0, err
+	}
+	return released.Number, nil
+}
+
+// takeReleasedNumberWithTx locks the lowest released number of the scope, if there is one
+func takeReleasedNumberWithTx(tx *gorm.DB, scope string) (*models.NumberReservation, error) {
+	var released []models.NumberReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("scope = ? AND status = ?", scope, models.NumberReleased).
+		Order("number ASC").Limit(1).Find(&released).Error; err != nil {
+		return nil, err
+	}
+	if len(released) == 0 {
+		return nil, nil
+	}
+	return &released[0], nil
+}
+
+// incrementNumberSequenceWithTx increments the scope's sequence. The row lock taken by the update
+// serialises concurrent allocations until the transaction ends.
+func incrementNumberSequenceWithTx(tx *gorm.DB, scope string) (int64, error) {
+	if err := models.ValidateNumberingScope(scope); err != nil {
+		return 0, err
+	}
+
+	var number int64
+	update := func() (int64, error) {
+		result := tx.Raw("UPDATE number_sequences SET last_number = last_number + 1, updated_at = ? WHERE scope = ? RETURNING last_number",
+			time.Now(), scope).Scan(&number)
+		return result.RowsAffected, result.Error
+	}
+
+	updated, err := update()
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		return number, nil
+	}
+
+	seed, err := seedNumberWithTx(tx, scope)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Exec("INSERT INTO number_sequences (scope, last_number, updated_at) VALUES (?, ?, ?) ON CONFLICT (scope) DO NOTHING",
+		scope, seed, time.Now()).Error; err != nil {
+		return 0, err
+	}
+
+	if _, err := update(); err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+// raiseNumberSequenceWithTx moves the scope's sequence on to the number if it is behind, so that
+// numbers recorded outside the registry, such as those of imported standards, are not issued again
+func raiseNumberSequenceWithTx(tx *gorm.DB, scope string, number int64) error {
+	if err := models.ValidateNumberingScope(scope); err != nil {
+		return err
+	}
+
+	seed, err := seedNumberWithTx(tx, scope)
+	if err != nil {
+		return err
+	}
+	if seed < number {
+		seed = number
+	}
+	return tx.Exec("INSERT INTO number_sequences (scope, last_number, updated_at) VALUES (?, ?, ?) "+
+		"ON CONFLICT (scope) DO UPDATE SET last_number = GREATEST(number_sequences.last_number, EXCLUDED.last_number), updated_at = EXCLUDED.updated_at",
+		scope, seed, time.Now()).Error
+}
+
+// seedNumberWithTx returns the last number in use in the scope before its sequence was created
+func seedNumberWithTx(tx *gorm.DB, scope string) (int64, error) {
+	query, ok := numberSequenceSeeds[scope]
+	if !ok {
+		return 0, nil
+	}
+
+	var seed int64
+	err := tx.Raw(query).Scan(&seed).Error
+	return seed, err
+}
@@ -3,7 +3,6 @@ package repository
 import (
 	"fmt"
 	"math"
-	"strings"
 	"time"
 
 	"github.com/ekbaya/asham/pkg/domain/models"
@@ -20,78 +19,125 @@ func NewProjectRepository(db *gorm.DB) *ProjectRepository {
 	return &ProjectRepository{db: db}
 }
 
-func (r *ProjectRepository) CreateProject(project *models.Project) error {
+// CreateProject allocates the project number and reference and stores the project. The number is
+// allocated in the same transaction so that a failed create does not use it up.
+func (r *ProjectRepository) CreateProject(project *models.Project, reservationID *uuid.UUID) error {
+	if project.IsSupplement() {
+		return models.ErrSupplementOutsideWorkflow
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if reservationID != nil {
+			reservation, err := useReservationWithTx(tx, *reservationID, models.ProjectNumberScope)
+			if err != nil {
+				return err
+			}
+			project.Number = reservation.Number
+		} else {
+			number, err := nextNumberWithTx(tx, models.ProjectNumberScope)
+			if err != nil {
+				return err
+			}
+			project.Number = number
+		}
+
+		var stage models.Stage
+		if err := tx.First(&stage, "id = ?", project.StageID).Error; err != nil {
+			return err
+		}
+		var err error
+		if project.Reference, err = draftReferenceWithTx(tx, project, &stage); err != nil {
+			return err
+		}
+
+		return createProjectWithTx(tx, project, "Project created")
+	})
+}
+
+// createProjectWithTx stores a new project together with its initial stage and reference history.
+// The reason is recorded against the first reference of the project.
+func createProjectWithTx(tx *gorm.DB, project *models.Project, reason string) error {
 	// Create initial stage history entry
 	now := time.Now()
 	stageHistory := models.ProjectStageHistory{
 		ID:        uuid.New(),
 		ProjectID: project.ID.String(),
 		StageID:   project.StageID,
+		ActorID:   &project.MemberID,
+		Notes:     "Project created",
 		StartedAt: now,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 	project.StageHistory = append(project.StageHistory, stageHistory)
+	project.ReferenceHistory = append(project.ReferenceHistory, models.ProjectReference{
+		ID:        uuid.New(),
+		ProjectID: project.ID.String(),
+		Reference: project.Reference,
+		StageID:   &stageHistory.StageID,
+		Reason:    reason,
+		CreatedAt: now,
+	})
 
-	return r.db.Create(project).Error
-}
-
-func (r *ProjectRepository) UpdateProjectStage(projectID uuid.UUID, newStageID uuid.UUID, notes string) error {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
+	if err := tx.Create(project).Error; err != nil {
+		return err
 	}
 
-	// Get current project
-	var project models.Project
-	if err := tx.Preload("StageHistory").First(&project, "id = ?", projectID).Error; err != nil {
-		tx.Rollback()
+	plans, err := planProjectStagesWithTx(tx, project, project.CreatedAt)
+	if err != nil {
 		return err
 	}
+	project.StagePlans = plans
+	return nil
+}
 
-	now := time.Now()
+// UpdateProjectStage moves a project to the given stage through the project workflow,
+// rejecting transitions that are not declared or whose guards do not hold
+func (r *ProjectRepository) UpdateProjectStage(projectID uuid.UUID, newStageID uuid.UUID, actorID *string, notes string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var stage models.Stage
+		if err := tx.First(&stage, "id = ?", newStageID).Error; err != nil {
+			return fmt.Errorf("stage with ID %s not found: %w", newStageID, err)
+		}
 
-	// Find the current active stage history entry and mark it as ended
-	if err := tx.Model(&models.ProjectStageHistory{}).
-		Where("project_id = ? AND stage_id = ? AND ended_at IS NULL", projectID, project.StageID).
-		Update("ended_at", now).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		return TransitionProjectStageWithTx(tx, projectID.String(), stage.Number, actorID, notes)
+	})
+}
 
-	// Add new stage to history
-	stageHistory := models.ProjectStageHistory{
-		ID:        uuid.New(),
-		ProjectID: projectID.String(),
-		StageID:   newStageID.String(),
-		StartedAt: now,
-		Notes:     notes,
-		CreatedAt: now,
-		UpdatedAt: now,
+// GetAvailableTransitions lists the workflow transitions out of the project's current stage
+// together with the guards that are not yet satisfied
+func (r *ProjectRepository) GetAvailableTransitions(projectID uuid.UUID) ([]models.AvailableTransition, error) {
+	project, err := LoadProjectForWorkflowWithTx(r.db, projectID.String())
+	if err != nil {
+		return nil, err
 	}
 
-	if err := tx.Create(&stageHistory).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+	transitions := []models.AvailableTransition{}
+	for _, transition := range models.StageTransitionsFrom(project.Procedure, project.Stage.Number) {
+		var stage models.Stage
+		if err := r.db.Where("number = ?", transition.ToStage).First(&stage).Error; err != nil {
+			return nil, err
+		}
 
-	// Update current stage of the project
-	if err := tx.Model(&models.Project{}).
-		Where("id = ?", projectID).
-		Updates(map[string]any{
-			"stage_id":   newStageID,
-			"updated_at": now,
-		}).Error; err != nil {
-		tx.Rollback()
-		return err
+		unmet := transition.UnmetGuards(project)
+		transitions = append(transitions, models.AvailableTransition{
+			Name:        transition.Name,
+			FromStage:   project.Stage,
+			ToStage:     &stage,
+			Guards:      transition.Guards,
+			UnmetGuards: unmet,
+			Allowed:     len(unmet) == 0,
+		})
 	}
 
-	return tx.Commit().Error
+	return transitions, nil
 }
 
 func (r *ProjectRepository) GetProjectWithStageHistory(projectID uuid.UUID) (*models.Project, error) {
 	var project models.Project
 	err := r.db.Preload("StageHistory.Stage").
+		Preload("StageHistory.FromStage").
+		Preload("StageHistory.Actor").
 		Preload("Stage").
 		Preload("TechnicalCommittee").
 		Preload("WorkingGroup").
@@ -103,6 +149,8 @@ func (r *ProjectRepository) GetProjectWithStageHistory(projectID uuid.UUID) (*mo
 func (r *ProjectRepository) GetProjectStageHistory(projectID uuid.UUID) ([]models.ProjectStageHistory, error) {
 	var stageHistory []models.ProjectStageHistory
 	err := r.db.Preload("Stage").
+		Preload("FromStage").
+		Preload("Actor").
 		Where("project_id = ?", projectID).
 		Order("started_at ASC").
 		Find(&stageHistory).Error
@@ -163,10 +211,9 @@ func (r *ProjectRepository) Exists(projectID string) (bool, error) {
 	return count > 0, err
 }
 
+// GetNextAvailableNumber returns the number the next project will receive
 func (r *ProjectRepository) GetNextAvailableNumber() (int64, error) {
-	var count int64
-	err := r.db.Model(&models.Project{}).Count(&count).Error
-	return count + 1, err
+	return NewNumberingRepository(r.db).PeekNextNumber(models.ProjectNumberScope)
 }
 
 // GetProjectByID retrieves a project by its ID
@@ -184,11 +231,22 @@ func (r *ProjectRepository) GetTCByID(id string) (*models.TechnicalCommittee, er
 }
 
 func (r *ProjectRepository) UpdateProject(project *models.Project) error {
-	return r.db.Save(project).Error
+	// Stage changes must go through the project workflow (UpdateProjectStage), committee changes
+	// through a transfer (RequestTransfer), and the lifecycle, library and supplement columns through
+	// their own operations
+	return r.db.Omit(
+		"stage_id", "technical_committee_id", "working_group_id",
+		"status", "cancelled", "cancelled_date", "withdrawn_date",
+		"published", "published_date", "library_status", "confirmed_date",
+		"base_standard_id", "supplement_no",
+	).Save(project).Error
 }
 
-func (r *ProjectRepository) ReviewWD(secretary, projectID, comment string, status models.WorkingDraftStatus) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// ReviewWD records the secretary's review of the working draft. Accepting it elevates the project to
+// a CD and returns the saga that copies the draft for the CD.
+func (r *ProjectRepository) ReviewWD(secretary, projectID, comment string, status models.WorkingDraftStatus) (*models.TransitionSaga, error) {
+	var saga *models.TransitionSaga
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		// First, fetch the entire project
 		var project models.Project
 		if err := tx.Where("id = ?", projectID).Preload("TechnicalCommittee").First(&project).Error; err != nil {
@@ -207,26 +265,23 @@ func (r *ProjectRepository) ReviewWD(secretary, projectID, comment string, statu
 
 		// If status is ACCEPTED, prepare additional changes
 		if status == models.ACCEPTED {
-			var stage models.Stage
-			nextStage := 3
-			if project.Procedure == models.WorkshopAgreement {
-				nextStage = 4 // We skip Committee and go to Enquiry
-			}
-			if err := tx.Where("number = ?", nextStage).First(&stage).Error; err != nil {
+			// First save initial changes to the project
+			if err := tx.Save(&project).Error; err != nil {
 				return err
 			}
 
-			// First save initial changes to the project
-			if err := tx.Save(&project).Error; err != nil {
+			// Update the stage (which will update the reference). Workshop agreements skip
+			// Committee and go to Enquiry.
+			if err := AdvanceProjectStageWithTx(tx, projectID, &secretary, "WD Elevated to a CD"); err != nil {
 				return err
 			}
 
-			// Update the stage (which will update the reference)
-			if err := UpdateProjectStageWithTx(tx, projectID, stage.ID.String(), "WD Elevated to a CD", "WD", stage.Abbreviation); err != nil {
+			// Fetch the updated project (after stage update)
+			if err := tx.Where("id = ?", projectID).First(&project).Error; err != nil {
 				return err
 			}
 
-			// Fetch document and create new one
+			// Fetch document and create new one under the reference of the new stage
 			var document models.Document
 			if err := tx.Where("id = ?", project.WorkingDraftID).First(&document).Error; err != nil {
 				return err
@@ -237,7 +292,7 @@ func (r *ProjectRepository) ReviewWD(secretary, projectID, comment string, statu
 				CreatedByID: document.CreatedByID,
 				Title:       document.Title,
 				Description: document.Description,
-				Reference:   strings.ReplaceAll(document.Reference, "WD", stage.Abbreviation),
+				Reference:   project.Reference,
 				FileURL:     document.FileURL,
 				CreatedAt:   time.Now(),
 			}
@@ -246,11 +301,6 @@ func (r *ProjectRepository) ReviewWD(secretary, projectID, comment string, statu
 				return err
 			}
 
-			// Fetch the updated project (after stage update)
-			if err := tx.Where("id = ?", projectID).First(&project).Error; err != nil {
-				return err
-			}
-
 			// Update document ID
 			docId := doc.ID.String()
 			project.CommitteeDraftID = &docId
@@ -259,6 +309,11 @@ func (r *ProjectRepository) ReviewWD(secretary, projectID, comment string, statu
 			if err := tx.Save(&project).Error; err != nil {
 				return err
 			}
+
+			var err error
+			if saga, err = enqueueTransitionSagaWithTx(tx, projectID, "WD Elevated to a CD", "CD"); err != nil {
+				return err
+			}
 		} else {
 			// For non-ACCEPTED status, just save the initial changes
 			if err := tx.Save(&project).Error; err != nil {
@@ -268,6 +323,7 @@ func (r *ProjectRepository) ReviewWD(secretary, projectID, comment string, statu
 
 		return nil
 	})
+	return saga, err
 }
 
 func (r *ProjectRepository) ApproveProject(projectID string, approved bool, comment, approvedBy, sharepointDocID string) error {
@@ -349,7 +405,8 @@ func (r *ProjectRepository) ApproveProjectProposal(projectID string, approved bo
 	project.ProposalApprovalComment = comment
 	project.ProposalApprovedByID = &approvedBy
 
-	if approved {
+	// Amendments and corrigenda keep their reduced workflow
+	if approved && !project.IsSupplement() {
 		project.Procedure = models.Procedure(procedure)
 	}
 
@@ -518,13 +575,32 @@ func (r *ProjectRepository) CreateProjectRevision(baseProjectID uuid.UUID) (*mod
 		return nil, err
 	}
 
+	var newProject *models.Project
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		newProject, err = createProjectRevisionWithTx(tx, baseProject)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newProject, nil
+}
+
+func createProjectRevisionWithTx(tx *gorm.DB, baseProject *models.Project) (*models.Project, error) {
+	// Revisions are numbered after the ARS number of the standard being revised
+	standardNumber := baseProject.StandardNumber
+	if standardNumber == 0 {
+		standardNumber = baseProject.Number
+	}
+
 	// Create a new project as a revision
 	newProject := models.Project{
 		ID:                   uuid.New(),
-		Number:               baseProject.Number,
+		MemberID:             baseProject.MemberID,
+		StandardNumber:       standardNumber,
 		PartNo:               baseProject.PartNo,
 		EditionNo:            baseProject.EditionNo + 1, // Increment edition number
-		Reference:            baseProject.Reference,
 		ReferenceSuffix:      baseProject.ReferenceSuffix,
 		Title:                baseProject.Title,
 		Description:          baseProject.Description,
@@ -538,45 +614,81 @@ func (r *ProjectRepository) CreateProjectRevision(baseProjectID uuid.UUID) (*mod
 		UpdatedAt:            time.Now(),
 	}
 
-	err = r.db.Create(&newProject).Error
+	var stage models.Stage
+	if err := tx.Where("number = ?", 0).First(&stage).Error; err != nil {
+		return nil, err
+	}
+	newProject.StageID = stage.ID.String()
+
+	number, err := nextNumberWithTx(tx, models.ProjectNumberScope)
 	if err != nil {
 		return nil, err
 	}
+	newProject.Number = number
+
+	if newProject.Reference, err = draftReferenceWithTx(tx, &newProject, &stage); err != nil {
+		return nil, err
+	}
 
+	if err := createProjectWithTx(tx, &newProject, fmt.Sprintf("Revision of %s", baseProject.Reference)); err != nil {
+		return nil, err
+	}
+	if err := linkProjectsWithTx(tx, newProject.ID.String(), baseProject.ID.String(), models.RelationRevises); err != nil {
+		return nil, err
+	}
 	return &newProject, nil
 }
 
-// GetProjectsApproachingDeadline finds projects nearing their expected completion date
+// GetProjectsApproachingDeadline finds projects whose current stage must be completed within the
+// given number of days according to the stage plan
 func (r *ProjectRepository) GetProjectsApproachingDeadline(daysThreshold int) ([]models.Project, error) {
-	var projects []models.Project
-
-	// Calculate the deadline based on created_at + timeframe
-	now := time.Now()
-	query := r.db.Preload("Stage").
-		Where("visible_on_library = ? AND DATE_PART('day', (created_at + (timeframe * interval '1 month')) - ?) <= ?",
-			false, now, daysThreshold).
-		Find(&projects)
+	deadlines, err := r.GetStageDeadlines()
+	if err != nil {
+		return nil, err
+	}
 
-	return projects, query.Error
+	approaching := []models.Project{}
+	for _, deadline := range deadlines {
+		if deadline.DaysLeft >= 0 && deadline.DaysLeft <= int64(daysThreshold) {
+			approaching = append(approaching, deadline.Project)
+		}
+	}
+	return approaching, nil
 }
 
-// GetProjectsInStageForTooLong finds projects that have been in a particular stage longer than expected
-func (r *ProjectRepository) GetProjectsInStageForTooLong(stageID uuid.UUID, dayThreshold int) ([]models.Project, error) {
-	var projects []models.Project
-
-	// Find projects that have been in the current stage for longer than the threshold
-	subquery := r.db.Table("project_stage_histories").
-		Select("project_id").
-		Where("stage_id = ? AND ended_at IS NULL AND DATE_PART('day', NOW() - started_at) > ?",
-			stageID, dayThreshold)
+// GetStageDeadlines returns when each active project must complete the stage it is in. Projects
+// in a stage without a due date are left out.
+func (r *ProjectRepository) GetStageDeadlines() ([]models.StageDeadline, error) {
+	projects, slas, err := r.evaluateActiveProjectSLAs("")
+	if err != nil {
+		return nil, err
+	}
 
-	err := r.db.Preload("Stage").
-		Preload("TechnicalCommittee").
-		Preload("WorkingGroup").
-		Where("stage_id = ? AND id IN (?)", stageID, subquery).
-		Find(&projects).Error
+	now := time.Now()
+	deadlines := []models.StageDeadline{}
+	for i, sla := range slas {
+		for _, stage := range sla.Stages {
+			if stage.Progress != models.StageInProgress {
+				continue
+			}
+			due := stage.DueDate(now)
+			if due == nil {
+				break
+			}
 
-	return projects, err
+			deadline := models.StageDeadline{
+				Project:  projects[i],
+				DueDate:  *due,
+				DaysLeft: int64(math.Ceil(due.Sub(now).Hours() / 24)),
+			}
+			if projects[i].Stage != nil {
+				deadline.Stage = projects[i].Stage.Name
+			}
+			deadlines = append(deadlines, deadline)
+			break
+		}
+	}
+	return deadlines, nil
 }
 
 // GetRelatedProjects finds projects related to the given project
@@ -623,8 +735,11 @@ func (repo *ProjectRepository) FindByDocumentID(documentID uuid.UUID) ([]models.
 	return projects, nil
 }
 
-func (r *ProjectRepository) ReviewCD(secretary, projectId string, isConsensusReached bool, action models.ProposalAction, meetingRequired bool) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// ReviewCD records the secretary's review of the committee draft. Reaching consensus moves the
+// project on to enquiry and returns the saga that copies the draft for the DARS.
+func (r *ProjectRepository) ReviewCD(secretary, projectId string, isConsensusReached bool, action models.ProposalAction, meetingRequired bool) (*models.TransitionSaga, error) {
+	var saga *models.TransitionSaga
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var project models.Project
 		if err := tx.Where("id = ?", projectId).Preload("TechnicalCommittee").First(&project).Error; err != nil {
 			return err
@@ -644,39 +759,27 @@ func (r *ProjectRepository) ReviewCD(secretary, projectId string, isConsensusRea
 			now := time.Now()
 			project.SubmissionDate = &now
 
-			var stage models.Stage
-			nextStage := 4
-			if project.Procedure == models.TechnicalSpecification || project.Procedure == models.TechnicalReport || project.Procedure == models.PublicAvailableSpecification {
-				nextStage = 6 // We are going directly to Approval stage
-			}
-			if err := tx.Where("number = ?", nextStage).First(&stage).Error; err != nil {
-				return err
-			}
-
 			// First save the current changes
 			if err := tx.Save(&project).Error; err != nil {
 				return err
 			}
 
-			dars := models.DARS{
-				ID:                    uuid.New(),
-				ProjectID:             projectId,
-				CreatedAt:             time.Now(),
-				PublicReviewStartDate: now,
-				PublicReviewEndDate:   now.AddDate(0, 2, 7),
-			}
+			dars := models.NewDARS(projectId, now)
 
 			if err := tx.Create(&dars).Error; err != nil {
 				return err
 			}
 
-			// Then update the stage (which will fetch and update the project again)
-			if err := UpdateProjectStageWithTx(tx, projectId, stage.ID.String(), "CD Consensus reached", "CD", stage.Abbreviation); err != nil {
+			// Then update the stage (which will fetch and update the project again). Technical
+			// specifications, reports and PAS go directly to the Approval stage.
+			if err := AdvanceProjectStageWithTx(tx, projectId, &secretary, "CD Consensus reached"); err != nil {
 				return err
 			}
 
 			// Don't save again after this point
-			return nil
+			var err error
+			saga, err = enqueueTransitionSagaWithTx(tx, projectId, "CD Consensus reached", "DARS")
+			return err
 		}
 
 		// Only save if we didn't reach consensus
@@ -686,15 +789,19 @@ func (r *ProjectRepository) ReviewCD(secretary, projectId string, isConsensusRea
 
 		return nil
 	})
+	return saga, err
 }
 
+// ReviewDARS records the secretary's review of the DARS. Approving it moves the project to balloting
+// and returns the saga that copies the draft for the FDARS.
 func (r *ProjectRepository) ReviewDARS(secretary,
 	projectId string,
 	wto_notification_notified bool,
 	unresolvedIssues,
 	alternativeDeliverable,
-	status string) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+	status string) (*models.TransitionSaga, error) {
+	var saga *models.TransitionSaga
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var project models.Project
 		if err := tx.Where("id = ?", projectId).Preload("TechnicalCommittee").First(&project).Error; err != nil {
 			return err
@@ -731,14 +838,6 @@ func (r *ProjectRepository) ReviewDARS(secretary,
 
 		if status != "" && status == string(models.DARSApproved) {
 			dars.MoveToBalloting = true
-			var stage models.Stage
-			nextStage := 5
-			if project.Procedure == models.GuidesAndGuidelines || project.Procedure == models.WorkshopAgreement {
-				nextStage = 6 // Skiping balloting
-			}
-			if err := tx.Where("number = ?", nextStage).First(&stage).Error; err != nil {
-				return err
-			}
 
 			// First save the current changes
 			if err := tx.Save(&dars).Error; err != nil {
@@ -758,13 +857,16 @@ func (r *ProjectRepository) ReviewDARS(secretary,
 				return err
 			}
 
-			// Then update the stage (which will fetch and update the project again)
-			if err := UpdateProjectStageWithTx(tx, projectId, stage.ID.String(), "DARS is accepted to advance to the balloting stage as an FDARS", "DARS", stage.Abbreviation); err != nil {
+			// Then update the stage (which will fetch and update the project again). Guides and
+			// workshop agreements skip balloting.
+			if err := AdvanceProjectStageWithTx(tx, projectId, &secretary, "DARS is accepted to advance to the balloting stage as an FDARS"); err != nil {
 				return err
 			}
 
 			// Don't save again after this point
-			return nil
+			var err error
+			saga, err = enqueueTransitionSagaWithTx(tx, projectId, "DARS is accepted to advance to the balloting stage as an FDARS", "FDARS")
+			return err
 		}
 
 		// Only save if we didn't reach consensus
@@ -774,11 +876,15 @@ func (r *ProjectRepository) ReviewDARS(secretary,
 
 		return nil
 	})
+	return saga, err
 }
 
+// ApproveFDARS records the decision on the FDARS ballot. The status change is returned when the
+// decision cancels the project.
 func (r *ProjectRepository) ApproveFDARS(secretary,
-	projectId string, approve bool, action string) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+	projectId string, approve bool, action string, reinstatableUntil *time.Time) (*models.ProjectStatusChange, error) {
+	var change *models.ProjectStatusChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var balloting models.Balloting
 		if err := tx.Where("project_id = ?", projectId).First(&balloting).Error; err != nil {
 			return err
@@ -794,42 +900,38 @@ func (r *ProjectRepository) ApproveFDARS(secretary,
 			balloting.NextCourseOfAction = models.FDARSAction(action)
 
 			if models.FDARSAction(action) == models.CANCELLED {
-				// get project and update it
-				var project models.Project
-				if err := tx.Where("id = ?", projectId).First(&project).Error; err != nil {
-					return err
-				}
-				project.Cancelled = true
-				project.CancelledDate = &now
-
-				if err := tx.Save(&project).Error; err != nil {
+				var err error
+				if change, err = stopProjectWithTx(tx, projectId, models.ProjectCancelled,
+					"Cancelled following the FDARS ballot", &secretary, reinstatableUntil); err != nil {
 					return err
 				}
 			}
 
 		}
 
-		if approve {
-			var stage models.Stage
-			if err := tx.Where("number = ?", 6).First(&stage).Error; err != nil {
-				return err
-			}
+		if err := tx.Save(&balloting).Error; err != nil {
+			return err
+		}
 
-			if err := UpdateProjectStageWithTx(tx, projectId, stage.ID.String(), "FDARS is approved in accordance with the conditions in 7.7.3", "FDARS", stage.Abbreviation); err != nil {
+		if approve {
+			if err := TransitionProjectStageWithTx(tx, projectId, 6, &secretary, "FDARS is approved in accordance with the conditions in 7.7.3"); err != nil {
 				return err
 			}
 		}
 
-		if err := tx.Save(&balloting).Error; err != nil {
-			return err
-		}
-
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
 }
 
-func (r *ProjectRepository) ApproveFDRSForPublication(secretary, projectId string, approve bool, comment string) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// ApproveFDRSForPublication records the decision on publishing the FDARS. Approval designates the
+// standard and returns the saga that copies the draft as the published ARS.
+func (r *ProjectRepository) ApproveFDRSForPublication(secretary, projectId string, approve bool, comment string) (*models.TransitionSaga, error) {
+	var saga *models.TransitionSaga
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var project models.Project
 		if err := tx.Where("id = ?", projectId).Preload("TechnicalCommittee").First(&project).Error; err != nil {
 			return err
@@ -847,16 +949,23 @@ func (r *ProjectRepository) ApproveFDRSForPublication(secretary, projectId strin
 		project.ApprovedForPublicationDate = &now
 		project.ApprovedForPublicationComment = comment
 
-		if approve {
-			project.Reference = fmt.Sprintf("ARS %03d:%d", project.Number, time.Now().Year())
+		if err := tx.Save(&project).Error; err != nil {
+			return err
 		}
 
-		if err := tx.Save(&project).Error; err != nil {
+		if approve {
+			if err := assignStandardDesignationWithTx(tx, &project, now); err != nil {
+				return err
+			}
+
+			var err error
+			saga, err = enqueueTransitionSagaWithTx(tx, projectId, "FDARS approved for publication", "ARS")
 			return err
 		}
 
 		return nil
 	})
+	return saga, err
 }
 
 func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
@@ -864,7 +973,7 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 
 	// Get total projects count
 	var totalCount int64
-	if err := r.db.Model(&models.Project{}).Where("cancelled = ?", false).Count(&totalCount).Error; err != nil {
+	if err := r.db.Model(&models.Project{}).Where(activeProject).Count(&totalCount).Error; err != nil {
 		return nil, err
 	}
 	stats["total"] = totalCount
@@ -874,7 +983,7 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 	stageRows, err := r.db.Model(&models.Project{}).
 		Select("s.name as stage_name, COUNT(projects.id) as count").
 		Joins("JOIN stages s ON projects.stage_id = s.id").
-		Where("projects.cancelled = ?", false).
+		Where(activeProject).
 		Group("s.name").
 		Rows()
 
@@ -897,7 +1006,7 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 	typeStats := make(map[string]int)
 	typeRows, err := r.db.Model(&models.Project{}).
 		Select("type, COUNT(id) as count").
-		Where("cancelled = ?", false).
+		Where(activeProject).
 		Group("type").
 		Rows()
 
@@ -920,7 +1029,7 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 	wdStats := make(map[string]int)
 	wdRows, err := r.db.Model(&models.Project{}).
 		Select("working_draft_status as status, COUNT(id) as count").
-		Where("cancelled = ?", false).
+		Where(activeProject).
 		Where("working_draft_status IS NOT NULL").
 		Group("working_draft_status").
 		Rows()
@@ -945,7 +1054,7 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 	tcRows, err := r.db.Model(&models.Project{}).
 		Select("tc.name as committee_name, COUNT(projects.id) as count").
 		Joins("JOIN technical_committees tc ON projects.technical_committee_id = tc.id").
-		Where("projects.cancelled = ?", false).
+		Where(activeProject).
 		Group("tc.name").
 		Rows()
 
@@ -967,7 +1076,7 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 	// Get counts for emergency projects
 	var emergencyCount int64
 	if err := r.db.Model(&models.Project{}).
-		Where("cancelled = ?", false).
+		Where(activeProject).
 		Where("is_emergency = ?", true).
 		Count(&emergencyCount).Error; err != nil {
 		return nil, err
@@ -977,7 +1086,7 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 	// Get counts for projects approved for publication
 	var publishedCount int64
 	if err := r.db.Model(&models.Project{}).
-		Where("cancelled = ?", false).
+		Where(activeProject).
 		Where("approved_for_publication = ?", true).
 		Count(&publishedCount).Error; err != nil {
 		return nil, err
@@ -990,10 +1099,10 @@ func (r *ProjectRepository) GetDashboardStats() (map[string]any, error) {
 func (r *ProjectRepository) GetAllDistributions() (map[string]map[string]float64, error) {
 	allDistributions := make(map[string]map[string]float64)
 
-	// Get total count of non-cancelled projects
+	// Get total count of active projects
 	var totalCount int64
 	if err := r.db.Model(&models.Project{}).
-		Where("cancelled = ?", false).
+		Where(activeProject).
 		Count(&totalCount).Error; err != nil {
 		return nil, err
 	}
@@ -1012,7 +1121,7 @@ func (r *ProjectRepository) GetAllDistributions() (map[string]map[string]float64
 	stageRows, err := r.db.Model(&models.Project{}).
 		Select("s.name as stage_name, COUNT(projects.id) as count").
 		Joins("JOIN stages s ON projects.stage_id = s.id").
-		Where("projects.cancelled = ?", false).
+		Where(activeProject).
 		Group("s.name").
 		Rows()
 
@@ -1037,7 +1146,7 @@ func (r *ProjectRepository) GetAllDistributions() (map[string]map[string]float64
 	typeDistribution := make(map[string]float64)
 	typeRows, err := r.db.Model(&models.Project{}).
 		Select("type, COUNT(id) as count").
-		Where("cancelled = ?", false).
+		Where(activeProject).
 		Group("type").
 		Rows()
 
@@ -1062,7 +1171,7 @@ func (r *ProjectRepository) GetAllDistributions() (map[string]map[string]float64
 	wdDistribution := make(map[string]float64)
 	wdRows, err := r.db.Model(&models.Project{}).
 		Select("working_draft_status as status, COUNT(id) as count").
-		Where("cancelled = ?", false).
+		Where(activeProject).
 		Where("working_draft_status IS NOT NULL").
 		Group("working_draft_status").
 		Rows()
@@ -1089,7 +1198,7 @@ func (r *ProjectRepository) GetAllDistributions() (map[string]map[string]float64
 	tcRows, err := r.db.Model(&models.Project{}).
 		Select("tc.name as committee_name, COUNT(projects.id) as count").
 		Joins("JOIN technical_committees tc ON projects.technical_committee_id = tc.id").
-		Where("projects.cancelled = ?", false).
+		Where(activeProject).
 		Group("tc.name").
 		Rows()
 
@@ -0,0 +1,494 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrImportInvalid     = errors.New("the manifest has errors; run a dry run to see them")
+	ErrImportBatchUndone = errors.New("the import batch has already been undone")
+	ErrImportBatchInUse  = errors.New("projects of the import batch have moved on since they were imported and the batch can no longer be undone")
+)
+
+// ValidateImportRows resolves the committee, working group, sector and stage of every row and
+// returns the problems found. Rows are checked against each other and against existing projects
+// for duplicate references and ARS numbers.
+func (r *ProjectRepository) ValidateImportRows(rows []models.ProjectImportRow) ([]models.ProjectImportError, error) {
+	return resolveImportRowsWithTx(r.db, rows)
+}
+
+func resolveImportRowsWithTx(tx *gorm.DB, rows []models.ProjectImportRow) ([]models.ProjectImportError, error) {
+	var committees []models.TechnicalCommittee
+	if err := tx.Select("id", "code").Find(&committees).Error; err != nil {
+		return nil, err
+	}
+	committeesByCode := map[string]models.TechnicalCommittee{}
+	for _, committee := range committees {
+		committeesByCode[strings.ToUpper(committee.Code)] = committee
+	}
+
+	var groups []models.WorkingGroup
+	if err := tx.Select("id", "name", "parent_tc_id").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	groupsByName := map[string]models.WorkingGroup{}
+	for _, group := range groups {
+		groupsByName[strings.ToLower(group.Name)] = group
+	}
+
+	var sectors []models.Sector
+	if err := tx.Find(&sectors).Error; err != nil {
+		return nil, err
+	}
+	sectorsByName := map[string]models.Sector{}
+	for _, sector := range sectors {
+		sectorsByName[strings.ToLower(sector.Title)] = sector
+		sectorsByName[strings.ToLower(sector.Slug)] = sector
+	}
+
+	var stages []models.Stage
+	if err := tx.Find(&stages).Error; err != nil {
+		return nil, err
+	}
+	stagesByNumber := map[int]models.Stage{}
+	for _, stage := range stages {
+		stagesByNumber[stage.Number] = stage
+	}
+
+	var errs []models.ProjectImportError
+	fail := func(row int, column, format string, args ...interface{}) {
+		errs = append(errs, models.ProjectImportError{Row: row, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	type standardNumber struct{ number, part int64 }
+	references := map[string]int{}
+	standardNumbers := map[standardNumber]int{}
+	for i := range rows {
+		row := &rows[i]
+		errs = append(errs, row.Validate()...)
+
+		// Published standards without a legacy reference are designated from their ARS number
+		if row.IsPublished() && row.Reference == "" && row.StandardNumber > 0 {
+			standard := models.Project{StandardNumber: row.StandardNumber, PartNo: row.PartNo}
+			row.Reference = models.StandardDesignation(&standard, row.PublishedDate.Year())
+		}
+
+		if committee, ok := committeesByCode[strings.ToUpper(row.CommitteeCode)]; ok {
+			row.CommitteeCode = committee.Code
+			row.TechnicalCommitteeID = committee.ID.String()
+		} else if row.CommitteeCode != "" {
+			fail(row.Row, "tc_code", "no technical committee has the code %q", row.CommitteeCode)
+		}
+
+		if row.WorkingGroup != "" {
+			group, ok := groupsByName[strings.ToLower(row.WorkingGroup)]
+			switch {
+			case !ok:
+				fail(row.Row, "working_group", "no working group is named %q", row.WorkingGroup)
+			case row.TechnicalCommitteeID != "" && group.ParentTCID != row.TechnicalCommitteeID:
+				fail(row.Row, "working_group", "working group %q does not belong to TC %s", row.WorkingGroup, row.CommitteeCode)
+			default:
+				groupID := group.ID.String()
+				row.WorkingGroupID = &groupID
+			}
+		}
+
+		if row.Sector != "" {
+			if sector, ok := sectorsByName[strings.ToLower(row.Sector)]; ok {
+				sectorID := sector.ID.String()
+				row.ProjectSectorID = &sectorID
+			} else {
+				fail(row.Row, "sector", "no sector is named %q", row.Sector)
+			}
+		}
+
+		if row.StageNumber != nil {
+			if stage, ok := stagesByNumber[*row.StageNumber]; ok {
+				row.Stage = &stage
+			} else {
+				fail(row.Row, "stage", "stage %d does not exist", *row.StageNumber)
+			}
+		}
+
+		if row.Reference != "" {
+			if first, ok := references[row.Reference]; ok {
+				fail(row.Row, "reference", "reference %s is also used by row %d", row.Reference, first)
+			} else {
+				references[row.Reference] = row.Row
+			}
+		}
+		if row.IsPublished() && row.StandardNumber > 0 {
+			key := standardNumber{row.StandardNumber, row.PartNo}
+			if first, ok := standardNumbers[key]; ok {
+				fail(row.Row, "standard_number", "the ARS number is also imported by row %d", first)
+			} else {
+				standardNumbers[key] = row.Row
+			}
+		}
+	}
+
+	if len(references) > 0 {
+		list := make([]string, 0, len(references))
+		for reference := range references {
+			list = append(list, reference)
+		}
+		var current, earlier []string
+		if err := tx.Model(&models.Project{}).Where("reference IN ?", list).Pluck("reference", &current).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Model(&models.ProjectReference{}).Where("reference IN ?", list).Pluck("reference", &earlier).Error; err != nil {
+			return nil, err
+		}
+		taken := map[string]bool{}
+		for _, reference := range append(current, earlier...) {
+			if !taken[reference] {
+				taken[reference] = true
+				fail(references[reference], "reference", "reference %s is already used by another project", reference)
+			}
+		}
+	}
+
+	for key, row := range standardNumbers {
+		var count int64
+		if err := tx.Model(&models.Project{}).
+			Where("published = ? AND standard_number = ? AND part_no = ? AND base_standard_id IS NULL", true, key.number, key.part).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			fail(row, "standard_number", "a published standard already has the ARS number %d", key.number)
+		}
+	}
+
+	return errs, nil
+}
+
+// ImportProjects creates the projects and published standards of the rows in a single transaction,
+// with their stage history, references and documents. Nothing is imported if any row is invalid.
+// Imported projects are not given stage plans; they can be replanned once imported.
+func (r *ProjectRepository) ImportProjects(batch *models.ProjectImportBatch, rows []models.ProjectImportRow) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		errs, err := resolveImportRowsWithTx(tx, rows)
+		if err != nil {
+			return err
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%w: %d problems found", ErrImportInvalid, len(errs))
+		}
+
+		now := time.Now()
+		batch.ID = uuid.New()
+		batch.Status = models.ImportBatchImported
+		batch.TotalRows = len(rows)
+		batch.CreatedAt = now
+		batch.UpdatedAt = now
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		var lastStandardNumber int64
+		for i := range rows {
+			row := &rows[i]
+			if err := importProjectRowWithTx(tx, batch, row, now); err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+
+			batch.ProjectsCreated++
+			if row.IsPublished() {
+				batch.StandardsCreated++
+			}
+			if row.FileURL != "" {
+				batch.DocumentsCreated++
+			}
+			if row.StandardNumber > lastStandardNumber {
+				lastStandardNumber = row.StandardNumber
+			}
+		}
+
+		if lastStandardNumber > 0 {
+			if err := raiseNumberSequenceWithTx(tx, models.StandardNumberScope, lastStandardNumber); err != nil {
+				return err
+			}
+		}
+		return tx.Omit(clause.Associations).Save(batch).Error
+	})
+}
+
+// importProjectRowWithTx creates the project of a validated row. Published standards keep the
+// designation they were published under; projects in progress keep their legacy reference or are
+// given the reference of their current stage.
+func importProjectRowWithTx(tx *gorm.DB, batch *models.ProjectImportBatch, row *models.ProjectImportRow, now time.Time) error {
+	batchID := batch.ID.String()
+	project := models.Project{
+		ID:                   uuid.New(),
+		MemberID:             batch.ImportedByID,
+		ProjectSectorID:      row.ProjectSectorID,
+		Procedure:            row.Procedure,
+		StandardNumber:       row.StandardNumber,
+		PartNo:               row.PartNo,
+		EditionNo:            row.EditionNo,
+		Title:                row.Title,
+		Language:             row.Language,
+		Description:          row.Description,
+		TechnicalCommitteeID: row.TechnicalCommitteeID,
+		WorkingGroupID:       row.WorkingGroupID,
+		StageID:              row.Stage.ID.String(),
+		Timeframe:            row.Timeframe,
+		Type:                 row.Type,
+		VisibleOnLibrary:     row.IsPublished(),
+		ImportBatchID:        &batchID,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	number, err := nextNumberWithTx(tx, models.ProjectNumberScope)
+	if err != nil {
+		return err
+	}
+	project.Number = number
+
+	startedAt := now
+	switch {
+	case row.StageStartedAt != nil:
+		startedAt = *row.StageStartedAt
+	case row.PublishedDate != nil:
+		startedAt = *row.PublishedDate
+	}
+
+	project.Reference = row.Reference
+	if project.Reference == "" {
+		var err error
+		if project.Reference, err = models.DraftReference(&project, row.Stage.Abbreviation, row.CommitteeCode, startedAt.Year()); err != nil {
+			return err
+		}
+	}
+
+	var referenceStageID *string
+	if row.IsPublished() {
+		project.Published = true
+		project.PublishedDate = row.PublishedDate
+		project.ApprovedForPublication = true
+		project.ApprovedForPublicationDate = row.PublishedDate
+		project.PWIApproved = true
+		project.ProposalApproved = true
+		project.IsConsensusReached = true
+	} else {
+		referenceStageID = &project.StageID
+	}
+
+	if row.FileURL != "" {
+		doc := models.Document{
+			ID:          uuid.New(),
+			CreatedByID: batch.ImportedByID,
+			Title:       project.Title,
+			Description: row.Stage.DocumentName,
+			Reference:   project.Reference,
+			FileURL:     row.FileURL,
+			CreatedAt:   now,
+		}
+		if row.IsPublished() {
+			doc.Description = "ARS"
+		}
+		if err := tx.Create(&doc).Error; err != nil {
+			return err
+		}
+
+		docID := doc.ID.String()
+		switch {
+		case row.IsPublished():
+			project.StandardID = &docID
+		case row.Stage.Number == 2:
+			project.WorkingDraftID = &docID
+		case row.Stage.Number == 3:
+			project.CommitteeDraftID = &docID
+		case row.Stage.Number == 4:
+			project.DARSDocID = &docID
+		default:
+			project.FDARSDocID = &docID
+		}
+	}
+
+	project.StageHistory = []models.ProjectStageHistory{{
+		ID:         uuid.New(),
+		ProjectID:  project.ID.String(),
+		StageID:    project.StageID,
+		Transition: "IMPORT",
+		ActorID:    &batch.ImportedByID,
+		Notes:      fmt.Sprintf("Imported from %s, row %d", batch.ManifestName, row.Row),
+		StartedAt:  startedAt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}}
+	project.ReferenceHistory = []models.ProjectReference{{
+		ID:        uuid.New(),
+		ProjectID: project.ID.String(),
+		Reference: project.Reference,
+		StageID:   referenceStageID,
+		Reason:    "Imported from legacy records",
+		CreatedAt: now,
+	}}
+
+	return tx.Create(&project).Error
+}
+
+// UndoImportBatch removes every project and document created by the batch and returns the URLs of
+// the files of the removed documents. Batches whose projects have moved on since they were imported
+// cannot be undone.
+func (r *ProjectRepository) UndoImportBatch(batchID uuid.UUID, memberID string) (*models.ProjectImportBatch, []string, error) {
+	var batch models.ProjectImportBatch
+	var fileURLs []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, "id = ?", batchID).Error; err != nil {
+			return err
+		}
+		if batch.Status == models.ImportBatchUndone {
+			return ErrImportBatchUndone
+		}
+
+		var projects []models.Project
+		if err := tx.Where("import_batch_id = ?", batchID).Find(&projects).Error; err != nil {
+			return err
+		}
+		projectIDs := make([]string, 0, len(projects))
+		var documentIDs []string
+		for _, project := range projects {
+			projectIDs = append(projectIDs, project.ID.String())
+			for _, id := range []*string{project.StandardID, project.WorkingDraftID, project.CommitteeDraftID, project.DARSDocID, project.FDARSDocID} {
+				if id != nil {
+					documentIDs = append(documentIDs, *id)
+				}
+			}
+		}
+
+		if len(projectIDs) > 0 {
+			inUse, err := importedProjectsInUseWithTx(tx, projectIDs)
+			if err != nil {
+				return err
+			}
+			if inUse {
+				return ErrImportBatchInUse
+			}
+
+			for _, model := range []interface{}{&models.ProjectReference{}, &models.ProjectStageHistory{}, &models.ProjectStagePlan{}} {
+				if err := tx.Where("project_id IN ?", projectIDs).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec("DELETE FROM project_related_documents WHERE project_id IN ?", projectIDs).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", projectIDs).Delete(&models.Project{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(documentIDs) > 0 {
+			var documents []models.Document
+			if err := tx.Where("id IN ?", documentIDs).Find(&documents).Error; err != nil {
+				return err
+			}
+			for _, doc := range documents {
+				if doc.FileURL != "" {
+					fileURLs = append(fileURLs, doc.FileURL)
+				}
+			}
+			if err := tx.Where("id IN ?", documentIDs).Delete(&models.Document{}).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		batch.Status = models.ImportBatchUndone
+		batch.UndoneByID = &memberID
+		batch.UndoneAt = &now
+		batch.UpdatedAt = now
+		return tx.Omit(clause.Associations).Save(&batch).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &batch, fileURLs, nil
+}
+
+// importedProjectsInUseWithTx reports whether any of the imported projects has changed stage, been
+// linked to, amended, transferred, reviewed or rolled back, or gathered proposals, comments,
+// ballots, votes, meetings or transition sagas since it was imported
+func importedProjectsInUseWithTx(tx *gorm.DB, projectIDs []string) (bool, error) {
+	var history int64
+	if err := tx.Model(&models.ProjectStageHistory{}).Where("project_id IN ?", projectIDs).Count(&history).Error; err != nil {
+		return false, err
+	}
+	if history > int64(len(projectIDs)) {
+		return true, nil
+	}
+
+	checks := []*gorm.DB{
+		tx.Model(&models.ProjectRelationship{}).Where("source_id IN ? OR target_id IN ?", projectIDs, projectIDs),
+		tx.Model(&models.Project{}).Where("base_standard_id IN ?", projectIDs),
+		tx.Model(&models.ProjectTransfer{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.ProjectStatusChange{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.Proposal{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.Acceptance{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.CommentObservation{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.NationalConsultation{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.DARS{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.Balloting{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.Vote{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.Meeting{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.CommitteeBallot{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.CirculationOverride{}).Where("acceptance_id IN (?)", tx.Model(&models.Acceptance{}).Select("id").Where("project_id IN ?", projectIDs)),
+		tx.Model(&models.SystematicReview{}).Where("project_id IN ? OR revision_project_id IN ?", projectIDs, projectIDs),
+		tx.Model(&models.SystematicReviewResponse{}).Where("review_id IN (?)", tx.Model(&models.SystematicReview{}).Select("id").Where("project_id IN ?", projectIDs)),
+		tx.Model(&models.TransitionSaga{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.ProjectRollback{}).Where("project_id IN ?", projectIDs),
+		tx.Model(&models.SupersededDocument{}).Where("project_id IN ?", projectIDs),
+	}
+	for _, check := range checks {
+		var count int64
+		if err := check.Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *ProjectRepository) GetImportBatch(id uuid.UUID) (*models.ProjectImportBatch, error) {
+	var batch models.ProjectImportBatch
+	err := r.db.Preload("ImportedBy").
+		Preload("UndoneBy").
+		Preload("Projects", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "title", "reference", "published", "stage_id", "import_batch_id").Order("reference ASC")
+		}).
+		First(&batch, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetImportBatches lists import batches, most recent first
+func (r *ProjectRepository) GetImportBatches(limit, offset int) ([]models.ProjectImportBatch, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.ProjectImportBatch{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var batches []models.ProjectImportBatch
+	err := r.db.Preload("ImportedBy").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&batches).Error
+	return batches, total, err
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRelationshipExists             = errors.New("the projects are already linked by this relationship")
+	ErrSelfRelationship               = errors.New("a project cannot be linked to itself")
+	ErrRelationshipTargetNotPublished = errors.New("the relationship must point at a published standard")
+	ErrSystemRelationship             = errors.New("relationships recorded by the workflow cannot be removed")
+)
+
+// CreateRelationship links a project to another project or published standard. Warnings are
+// returned when the link normatively references a standard that is no longer current.
+func (r *ProjectRepository) CreateRelationship(relationship *models.ProjectRelationship) ([]models.RelationshipWarning, error) {
+	if err := relationship.Type.Validate(); err != nil {
+		return nil, err
+	}
+	if relationship.SourceID == relationship.TargetID {
+		return nil, ErrSelfRelationship
+	}
+
+	var warnings []models.RelationshipWarning
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Project{}, "id = ?", relationship.SourceID).Error; err != nil {
+			return err
+		}
+
+		var target models.Project
+		if err := tx.First(&target, "id = ?", relationship.TargetID).Error; err != nil {
+			return err
+		}
+		if relationship.Type.RequiresPublishedTarget() && !target.Published {
+			return ErrRelationshipTargetNotPublished
+		}
+
+		var existing int64
+		if err := tx.Model(&models.ProjectRelationship{}).
+			Where("source_id = ? AND target_id = ? AND type = ?", relationship.SourceID, relationship.TargetID, relationship.Type).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrRelationshipExists
+		}
+
+		relationship.ID = uuid.New()
+		relationship.CreatedAt = time.Now()
+		if err := tx.Create(relationship).Error; err != nil {
+			return err
+		}
+
+		all, err := normativeReferenceWarningsWithTx(tx, relationship.SourceID)
+		if err != nil {
+			return err
+		}
+		for _, warning := range all {
+			if warning.RelationshipID == relationship.ID.String() {
+				warnings = append(warnings, warning)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// linkProjectsWithTx records a relationship established by the workflow, such as a revision and the
+// standard it revises. Existing links are left as they are.
+func linkProjectsWithTx(tx *gorm.DB, sourceID, targetID string, relationshipType models.RelationshipType) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ProjectRelationship{
+		ID:        uuid.New(),
+		SourceID:  sourceID,
+		TargetID:  targetID,
+		Type:      relationshipType,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// supersedeRevisedStandardsWithTx records that a newly published revision supersedes the standards
+// it revises
+func supersedeRevisedStandardsWithTx(tx *gorm.DB, projectID string) error {
+	var revised []models.ProjectRelationship
+	if err := tx.Where("source_id = ? AND type = ?", projectID, models.RelationRevises).Find(&revised).Error; err != nil {
+		return err
+	}
+	for _, relationship := range revised {
+		if err := linkProjectsWithTx(tx, projectID, relationship.TargetID, models.RelationSupersedes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRelationship removes a link a member made from the project and returns it. Links recorded
+// by the workflow, such as a revision and the standard it revises, are kept.
+func (r *ProjectRepository) DeleteRelationship(projectID string, relationshipID uuid.UUID) (*models.ProjectRelationship, error) {
+	var relationship models.ProjectRelationship
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&relationship, "id = ? AND source_id = ?", relationshipID, projectID).Error; err != nil {
+			return err
+		}
+		if relationship.CreatedByID == nil {
+			return ErrSystemRelationship
+		}
+		return tx.Delete(&relationship).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &relationship, nil
+}
+
+// GetRelationshipGraph returns the projects and standards reachable from the project within the
+// given number of links, following links in both directions, with warnings for the project's
+// normative references to standards that are no longer current
+func (r *ProjectRepository) GetRelationshipGraph(projectID string, depth int) (*models.RelationshipGraph, error) {
+	if err := r.db.Select("id").First(&models.Project{}, "id = ?", projectID).Error; err != nil {
+		return nil, err
+	}
+
+	depths := map[string]int{projectID: 0}
+	seen := map[uuid.UUID]bool{}
+	var relationships []models.ProjectRelationship
+	frontier := []string{projectID}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var found []models.ProjectRelationship
+		if err := r.db.Where("source_id IN ? OR target_id IN ?", frontier, frontier).
+			Order("created_at ASC").
+			Find(&found).Error; err != nil {
+			return nil, err
+		}
+
+		frontier = nil
+		for _, relationship := range found {
+			if seen[relationship.ID] {
+				continue
+			}
+			seen[relationship.ID] = true
+			relationships = append(relationships, relationship)
+
+			for _, id := range []string{relationship.SourceID, relationship.TargetID} {
+				if _, ok := depths[id]; !ok {
+					depths[id] = level
+					frontier = append(frontier, id)
+				}
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(depths))
+	for id := range depths {
+		ids = append(ids, id)
+	}
+	var projects []models.Project
+	if err := r.db.Select("id", "title", "reference", "type", "published", "library_status").
+		Where("id IN ?", ids).
+		Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	graph := models.RelationshipGraph{
+		ProjectID: projectID,
+		Nodes:     make([]models.RelationshipNode, 0, len(projects)),
+		Edges:     make([]models.RelationshipEdge, 0, len(relationships)),
+	}
+	for _, project := range projects {
+		graph.Nodes = append(graph.Nodes, models.RelationshipNode{
+			ID:            project.ID.String(),
+			Title:         project.Title,
+			Reference:     project.Reference,
+			Type:          project.Type,
+			Published:     project.Published,
+			LibraryStatus: project.LibraryStatus,
+			Depth:         depths[project.ID.String()],
+		})
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Depth != graph.Nodes[j].Depth {
+			return graph.Nodes[i].Depth < graph.Nodes[j].Depth
+		}
+		return graph.Nodes[i].Reference < graph.Nodes[j].Reference
+	})
+
+	for _, relationship := range relationships {
+		graph.Edges = append(graph.Edges, models.RelationshipEdge{
+			ID:       relationship.ID.String(),
+			SourceID: relationship.SourceID,
+			TargetID: relationship.TargetID,
+			Type:     relationship.Type,
+			Inverse:  relationship.Type.Inverse(),
+			Note:     relationship.Note,
+		})
+	}
+
+	warnings, err := normativeReferenceWarningsWithTx(r.db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	graph.Warnings = warnings
+	return &graph, nil
+}
+
+// normativeReferenceWarningsWithTx flags the project's normative references to standards that have
+// been withdrawn or superseded
+func normativeReferenceWarningsWithTx(tx *gorm.DB, projectID string) ([]models.RelationshipWarning, error) {
+	var references []models.ProjectRelationship
+	if err := tx.Preload("Target").
+		Where("source_id = ? AND type = ?", projectID, models.RelationNormativelyReferences).
+		Find(&references).Error; err != nil {
+		return nil, err
+	}
+
+	warnings := []models.RelationshipWarning{}
+	if len(references) == 0 {
+		return warnings, nil
+	}
+
+	targetIDs := make([]string, 0, len(references))
+	for _, reference := range references {
+		targetIDs = append(targetIDs, reference.TargetID)
+	}
+	var supersessions []models.ProjectRelationship
+	if err := tx.Preload("Source").
+		Where("type = ? AND target_id IN ?", models.RelationSupersedes, targetIDs).
+		Find(&supersessions).Error; err != nil {
+		return nil, err
+	}
+	supersededBy := map[string]string{}
+	for _, supersession := range supersessions {
+		if supersession.Source != nil {
+			supersededBy[supersession.TargetID] = supersession.Source.Reference
+		}
+	}
+
+	for _, reference := range references {
+		if reference.Target == nil {
+			continue
+		}
+
+		reason := ""
+		switch {
+		case reference.Target.LibraryStatus == models.LibraryWithdrawn:
+			reason = fmt.Sprintf("%s has been withdrawn", reference.Target.Reference)
+		case supersededBy[reference.TargetID] != "":
+			reason = fmt.Sprintf("%s has been superseded by %s", reference.Target.Reference, supersededBy[reference.TargetID])
+		default:
+			continue
+		}
+
+		warnings = append(warnings, models.RelationshipWarning{
+			RelationshipID: reference.ID.String(),
+			StandardID:     reference.TargetID,
+			Reference:      reference.Target.Reference,
+			Reason:         reason,
+		})
+	}
+	return warnings, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRollbackTarget         = errors.New("projects can only be rolled back to an earlier stage of their procedure, from the preparatory stage on")
+	ErrRollbackApproved       = errors.New("published standards and projects approved for publication cannot be rolled back")
+	ErrRollbackSagaInProgress = errors.New("the draft of the project's last stage transition is still being copied; try again once it has finished")
+	ErrNotRollbackSecretary   = errors.New("only the secretary of the technical committee may roll back the project")
+)
+
+// RollbackProject returns a project to an earlier stage of its procedure. The open ballot and
+// enquiry are closed and detached from the project, the drafts of the abandoned stages are kept as
+// superseded versions and the reviews from the stage returned to on are reset. Returning to the
+// enquiry or ballot stage opens a new enquiry or ballot on the resubmitted draft.
+func (r *ProjectRepository) RollbackProject(rollback *models.ProjectRollback, toStage int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, "id = ?", rollback.ProjectID).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("TechnicalCommittee").
+			Preload("Stage").
+			Preload("DARS").
+			Preload("Balloting").
+			First(&project, "id = ?", rollback.ProjectID).Error; err != nil {
+			return err
+		}
+
+		if project.TechnicalCommittee.SecretaryId == nil || *project.TechnicalCommittee.SecretaryId != rollback.RolledBackByID {
+			return ErrNotRollbackSecretary
+		}
+		if project.Published || project.ApprovedForPublication {
+			return ErrRollbackApproved
+		}
+		if !project.IsActive() {
+			return ErrProjectNotActive
+		}
+		if project.Stage == nil || toStage < models.MinRollbackStage || toStage >= project.Stage.Number ||
+			!slices.Contains(models.StagePath(project.Procedure), toStage) {
+			return ErrRollbackTarget
+		}
+
+		var sagas int64
+		if err := tx.Model(&models.TransitionSaga{}).
+			Where("project_id = ? AND status IN ?", project.ID, []models.TransitionSagaStatus{models.SagaPending, models.SagaRunning}).
+			Count(&sagas).Error; err != nil {
+			return err
+		}
+		if sagas > 0 {
+			return ErrRollbackSagaInProgress
+		}
+
+		var stage models.Stage
+		if err := tx.Where("number = ?", toStage).First(&stage).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		rollback.ID = uuid.New()
+		rollback.FromStageID = project.StageID
+		rollback.ToStageID = stage.ID.String()
+		rollback.PreviousReference = project.Reference
+		rollback.CreatedAt = now
+
+		if project.Balloting != nil {
+			if err := closeSupersededBallotWithTx(tx, project.Balloting, now); err != nil {
+				return err
+			}
+			ballotID := project.Balloting.ID.String()
+			rollback.SupersededBallotID = &ballotID
+		}
+		if project.DARS != nil && toStage <= 4 {
+			if err := closeSupersededEnquiryWithTx(tx, project.DARS, now); err != nil {
+				return err
+			}
+			darsID := project.DARS.ID.String()
+			rollback.SupersededDARSID = &darsID
+		}
+
+		superseded, err := supersedeDraftsWithTx(tx, &project, rollback.ID.String(), toStage, now)
+		if err != nil {
+			return err
+		}
+
+		resets := models.RollbackResets(toStage)
+		resets["updated_at"] = now
+		if err := tx.Model(&models.Project{}).Where("id = ?", project.ID).Updates(resets).Error; err != nil {
+			return err
+		}
+
+		fromStageID := project.StageID
+		if err := moveProjectStageWithTx(tx, models.ProjectStageHistory{
+			ProjectID:   project.ID.String(),
+			StageID:     stage.ID.String(),
+			FromStageID: &fromStageID,
+			Transition:  models.RollbackTransition,
+			ActorID:     &rollback.RolledBackByID,
+			Notes:       rollback.Reason,
+		}); err != nil {
+			return err
+		}
+		if err := assignDraftReferenceWithTx(tx, project.ID.String(), &stage, models.RollbackTransition); err != nil {
+			return err
+		}
+		if err := tx.Select("reference").First(&project, "id = ?", project.ID).Error; err != nil {
+			return err
+		}
+		rollback.NewReference = project.Reference
+
+		if err := reopenStageConsultationWithTx(tx, project.ID.String(), toStage, now); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(rollback).Error; err != nil {
+			return err
+		}
+		if len(superseded) > 0 {
+			return tx.Create(&superseded).Error
+		}
+		return nil
+	})
+}
+
+// closeSupersededBallotWithTx closes the ballot and detaches it and its votes from the project, so
+// that a later ballot on the project starts afresh
+func closeSupersededBallotWithTx(tx *gorm.DB, ballot *models.Balloting, now time.Time) error {
+	updates := map[string]interface{}{"active": false, "project_id": nil, "updated_at": now}
+	if ballot.ClosedAt == nil {
+		updates["closed_at"] = now
+	}
+	if err := tx.Model(&models.Balloting{}).Where("id = ?", ballot.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Vote{}).Where("balloting_id = ?", ballot.ID).Update("project_id", nil).Error
+}
+
+// closeSupersededEnquiryWithTx ends the public review of the DARS and detaches it and the NSB
+// submissions made on it from the project
+func closeSupersededEnquiryWithTx(tx *gorm.DB, dars *models.DARS, now time.Time) error {
+	updates := map[string]interface{}{"project_id": nil, "updated_at": now}
+	if dars.PublicReviewEndDate.After(now) {
+		updates["public_review_end_date"] = now
+	}
+	if err := tx.Model(&models.DARS{}).Where("id = ?", dars.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.NationalConsultation{}).Where("dars_id = ?", dars.ID).Update("project_id", nil).Error
+}
+
+// supersedeDraftsWithTx records the drafts of the stages after the given stage as superseded
+// versions. Versions are counted per document type and project.
+func supersedeDraftsWithTx(tx *gorm.DB, project *models.Project, rollbackID string, toStage int, now time.Time) ([]models.SupersededDocument, error) {
+	var superseded []models.SupersededDocument
+	for docType, documentID := range project.AbandonedDrafts(toStage) {
+		var versions int64
+		if err := tx.Model(&models.SupersededDocument{}).
+			Where("project_id = ? AND doc_type = ?", project.ID, docType).
+			Count(&versions).Error; err != nil {
+			return nil, err
+		}
+
+		superseded = append(superseded, models.SupersededDocument{
+			ID:         uuid.New(),
+			RollbackID: rollbackID,
+			ProjectID:  project.ID.String(),
+			DocumentID: *documentID,
+			DocType:    docType,
+			Version:    int(versions) + 1,
+			CreatedAt:  now,
+		})
+	}
+	return superseded, nil
+}
+
+// reopenStageConsultationWithTx opens a new enquiry or ballot when the project returns to the enquiry
+// or ballot stage, with the periods used when the stage is first reached
+func reopenStageConsultationWithTx(tx *gorm.DB, projectID string, toStage int, now time.Time) error {
+	switch toStage {
+	case 4:
+		dars := models.NewDARS(projectID, now)
+		return tx.Create(&dars).Error
+	case 5:
+		return tx.Create(&models.Balloting{
+			ID:        uuid.New(),
+			ProjectID: projectID,
+			CreatedAt: now,
+			UpdatedAt: now,
+			StartDate: now,
+			EndDate:   now.AddDate(0, 0, 30),
+		}).Error
+	}
+	return nil
+}
+
+func (r *ProjectRepository) GetRollback(id uuid.UUID) (*models.ProjectRollback, error) {
+	var rollback models.ProjectRollback
+	err := r.db.Preload("FromStage").
+		Preload("ToStage").
+		Preload("SupersededBallot").
+		Preload("SupersededBallot.Votes").
+		Preload("SupersededDARS").
+		Preload("SupersededDARS.Submissions").
+		Preload("SupersededDocuments.Document").
+		Preload("RolledBackBy").
+		First(&rollback, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rollback, nil
+}
+
+// GetProjectRollbacks lists the rollbacks of a project, latest first
+func (r *ProjectRepository) GetProjectRollbacks(projectID string) ([]models.ProjectRollback, error) {
+	var rollbacks []models.ProjectRollback
+	err := r.db.Preload("FromStage").
+		Preload("ToStage").
+		Preload("SupersededDocuments.Document").
+		Preload("RolledBackBy").
+		Where("project_id = ?", projectID).
+		Order("created_at DESC").
+		Find(&rollbacks).Error
+	return rollbacks, err
+}
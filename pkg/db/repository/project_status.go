@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProjectNotActive          = errors.New("project is on hold, cancelled or withdrawn")
+	ErrProjectAlreadyActive      = errors.New("project is already active")
+	ErrProjectAlreadyStopped     = errors.New("project is already cancelled or withdrawn")
+	ErrReinstatementWindowClosed = errors.New("the reinstatement window of the project has closed")
+)
+
+// activeProject matches the projects shown on active dashboards
+const activeProject = "projects.cancelled = false AND projects.status = 'ACTIVE'"
+
+// StopProject puts a project on hold, cancels or withdraws it, freezing its open ballots, comment
+// windows and upcoming meetings. A project on hold may still be cancelled or withdrawn.
+func (r *ProjectRepository) StopProject(projectID string, status models.ProjectStatus, reason string, actorID *string, reinstatableUntil *time.Time) (*models.ProjectStatusChange, error) {
+	var change *models.ProjectStatusChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		change, err = stopProjectWithTx(tx, projectID, status, reason, actorID, reinstatableUntil)
+		return err
+	})
+	return change, err
+}
+
+func stopProjectWithTx(tx *gorm.DB, projectID string, status models.ProjectStatus, reason string, actorID *string, reinstatableUntil *time.Time) (*models.ProjectStatusChange, error) {
+	var project models.Project
+	if err := tx.Where("id = ?", projectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	previousStatus := project.Status
+	if previousStatus == "" {
+		previousStatus = models.ProjectActive
+	}
+	if project.Cancelled || previousStatus == models.ProjectCancelled || previousStatus == models.ProjectWithdrawn {
+		return nil, ErrProjectAlreadyStopped
+	}
+	if previousStatus == status {
+		return nil, ErrProjectNotActive
+	}
+
+	now := time.Now()
+	change := models.ProjectStatusChange{
+		ID:             uuid.New(),
+		ProjectID:      projectID,
+		Status:         status,
+		PreviousStatus: previousStatus,
+		StageID:        project.StageID,
+		Reason:         reason,
+		ActorID:        actorID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if status != models.ProjectOnHold {
+		change.ReinstatableUntil = reinstatableUntil
+	}
+
+	if previousStatus == models.ProjectOnHold {
+		// Cancelling a project on hold takes over what the hold froze, so that reinstatement thaws it
+		var hold models.ProjectStatusChange
+		if err := tx.Where("project_id = ? AND ended_at IS NULL", projectID).
+			Order("created_at DESC").
+			First(&hold).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		} else if err == nil {
+			change.StageID = hold.StageID
+			change.FrozenActivities = hold.FrozenActivities
+			if err := cancelPostponedMeetingsWithTx(tx, change.FrozenActivities, reason); err != nil {
+				return nil, err
+			}
+
+			hold.EndedAt = &now
+			hold.UpdatedAt = now
+			if err := tx.Save(&hold).Error; err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		frozen, err := freezeProjectActivitiesWithTx(tx, projectID, status, reason, now)
+		if err != nil {
+			return nil, err
+		}
+		change.FrozenActivities = frozen
+
+		// The stage clock stops while the project is not active
+		if err := tx.Exec("UPDATE project_stage_histories SET ended_at = ? WHERE project_id = ? AND ended_at IS NULL",
+			now, projectID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	updates := map[string]interface{}{"status": status, "updated_at": now}
+	if status != models.ProjectOnHold {
+		updates["cancelled"] = true
+		updates["cancelled_date"] = now
+	}
+	if err := tx.Model(&models.Project{}).Where("id = ?", projectID).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(&change).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// freezeProjectActivitiesWithTx halts the project's open ballots and public review and its
+// upcoming meetings, recording what is needed to resume them
+func freezeProjectActivitiesWithTx(tx *gorm.DB, projectID string, status models.ProjectStatus, reason string, now time.Time) ([]models.FrozenActivity, error) {
+	frozen := []models.FrozenActivity{}
+
+	var ballots []models.Balloting
+	if err := tx.Where("project_id = ? AND active = ? AND closed_at IS NULL AND end_date > ?", projectID, true, now).
+		Find(&ballots).Error; err != nil {
+		return nil, err
+	}
+	for _, ballot := range ballots {
+		if err := tx.Model(&models.Balloting{}).Where("id = ?", ballot.ID).
+			Updates(map[string]interface{}{"active": false, "updated_at": now}).Error; err != nil {
+			return nil, err
+		}
+		frozen = append(frozen, models.FrozenActivity{
+			Type:             models.FrozenBallot,
+			ID:               ballot.ID.String(),
+			RemainingSeconds: int64(ballot.EndDate.Sub(now).Seconds()),
+		})
+	}
+
+	var reviews []models.DARS
+	if err := tx.Where("project_id = ? AND public_review_start_date <= ? AND public_review_end_date > ?", projectID, now, now).
+		Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	for _, dars := range reviews {
+		if err := tx.Model(&models.DARS{}).Where("id = ?", dars.ID).
+			Updates(map[string]interface{}{"public_review_end_date": now, "updated_at": now}).Error; err != nil {
+			return nil, err
+		}
+		frozen = append(frozen, models.FrozenActivity{
+			Type:             models.FrozenCommentWindow,
+			ID:               dars.ID.String(),
+			RemainingSeconds: int64(dars.PublicReviewEndDate.Sub(now).Seconds()),
+		})
+	}
+
+	var meetings []models.Meeting
+	if err := tx.Where("project_id = ? AND date > ? AND status IN ?", projectID, now,
+		[]models.MeetingStatus{models.MeetingStatusPlanned, models.MeetingStatusConfirmed}).
+		Find(&meetings).Error; err != nil {
+		return nil, err
+	}
+	for _, meeting := range meetings {
+		updates := map[string]interface{}{"status": models.MeetingStatusPostponed, "updated_at": now}
+		if status != models.ProjectOnHold {
+			updates["status"] = models.MeetingStatusCancelled
+			updates["cancellation_reason"] = reason
+		}
+		if err := tx.Model(&models.Meeting{}).Where("id = ?", meeting.ID).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		frozen = append(frozen, models.FrozenActivity{
+			Type:           models.FrozenMeeting,
+			ID:             meeting.ID.String(),
+			PreviousStatus: meeting.Status,
+		})
+	}
+
+	return frozen, nil
+}
+
+// cancelPostponedMeetingsWithTx cancels the meetings postponed by a hold that became a cancellation
+func cancelPostponedMeetingsWithTx(tx *gorm.DB, frozen []models.FrozenActivity, reason string) error {
+	for _, activity := range frozen {
+		if activity.Type != models.FrozenMeeting {
+			continue
+		}
+		if err := tx.Model(&models.Meeting{}).
+			Where("id = ? AND status = ?", activity.ID, models.MeetingStatusPostponed).
+			Updates(map[string]interface{}{
+				"status":              models.MeetingStatusCancelled,
+				"cancellation_reason": reason,
+				"updated_at":          time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// thawProjectActivitiesWithTx resumes the ballots and public review frozen with the project for
+// the time they had left, and restores the meetings that are still ahead
+func thawProjectActivitiesWithTx(tx *gorm.DB, frozen []models.FrozenActivity, now time.Time) error {
+	for _, activity := range frozen {
+		switch activity.Type {
+		case models.FrozenBallot:
+			if err := tx.Model(&models.Balloting{}).
+				Where("id = ? AND closed_at IS NULL", activity.ID).
+				Updates(map[string]interface{}{
+					"active":     true,
+					"end_date":   now.Add(activity.Remaining()),
+					"updated_at": now,
+				}).Error; err != nil {
+				return err
+			}
+		case models.FrozenCommentWindow:
+			if err := tx.Model(&models.DARS{}).
+				Where("id = ?", activity.ID).
+				Updates(map[string]interface{}{
+					"public_review_end_date": now.Add(activity.Remaining()),
+					"updated_at":             now,
+				}).Error; err != nil {
+				return err
+			}
+		case models.FrozenMeeting:
+			if err := tx.Model(&models.Meeting{}).
+				Where("id = ? AND date > ? AND status IN ?", activity.ID, now,
+					[]models.MeetingStatus{models.MeetingStatusPostponed, models.MeetingStatusCancelled}).
+				Updates(map[string]interface{}{
+					"status":              activity.PreviousStatus,
+					"cancellation_reason": "",
+					"updated_at":          now,
+				}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReinstateProject makes a stopped project active again in the stage it was in when it stopped and
+// resumes its frozen activities. Projects cancelled before status changes were recorded are
+// reinstated in their current stage.
+func (r *ProjectRepository) ReinstateProject(projectID, reason string, actorID *string) (*models.ProjectStatusChange, error) {
+	var change models.ProjectStatusChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.Where("id = ?", projectID).First(&project).Error; err != nil {
+			return err
+		}
+		if project.IsActive() {
+			return ErrProjectAlreadyActive
+		}
+
+		now := time.Now()
+		err := tx.Where("project_id = ? AND ended_at IS NULL", projectID).
+			Order("created_at DESC").
+			First(&change).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status := project.Status
+			if status == "" || status == models.ProjectActive {
+				status = models.ProjectCancelled
+			}
+			change = models.ProjectStatusChange{
+				ID:             uuid.New(),
+				ProjectID:      projectID,
+				Status:         status,
+				PreviousStatus: models.ProjectActive,
+				StageID:        project.StageID,
+				CreatedAt:      now,
+			}
+		case err != nil:
+			return err
+		}
+
+		if change.ReinstatableUntil != nil && now.After(*change.ReinstatableUntil) {
+			return ErrReinstatementWindowClosed
+		}
+
+		if err := thawProjectActivitiesWithTx(tx, change.FrozenActivities, now); err != nil {
+			return err
+		}
+
+		fromStageID := project.StageID
+		if err := moveProjectStageWithTx(tx, models.ProjectStageHistory{
+			ProjectID:   projectID,
+			StageID:     change.StageID,
+			FromStageID: &fromStageID,
+			Transition:  "REINSTATE",
+			ActorID:     actorID,
+			Notes:       reason,
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Project{}).Where("id = ?", projectID).
+			Updates(map[string]interface{}{
+				"status":         models.ProjectActive,
+				"cancelled":      false,
+				"cancelled_date": nil,
+				"updated_at":     now,
+			}).Error; err != nil {
+			return err
+		}
+
+		change.EndedAt = &now
+		change.ReinstatedByID = actorID
+		change.ReinstatementReason = reason
+		change.UpdatedAt = now
+		return tx.Save(&change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetProjectStatusChanges lists the periods in which the project was stopped, most recent first
+func (r *ProjectRepository) GetProjectStatusChanges(projectID string) ([]models.ProjectStatusChange, error) {
+	var changes []models.ProjectStatusChange
+	err := r.db.Preload("Stage").
+		Preload("Actor").
+		Preload("ReinstatedBy").
+		Where("project_id = ?", projectID).
+		Order("created_at DESC").
+		Find(&changes).Error
+	return changes, err
+}
@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransferPending            = errors.New("the project already has a pending transfer")
+	ErrTransferNotPending         = errors.New("the transfer is no longer pending")
+	ErrTransferUnchanged          = errors.New("the project is already with this committee and working group")
+	ErrWorkingGroupNotInCommittee = errors.New("the working group does not belong to the technical committee")
+	ErrNotTransferSecretary       = errors.New("only the secretaries of the committees involved may approve or reject the transfer")
+	ErrProjectPublished           = errors.New("published standards cannot be transferred")
+)
+
+// RequestTransfer asks to move a project to another TC or working group. The requester's approval
+// is recorded for each committee whose secretary they are, so a secretary moving a project between
+// working groups of their own TC completes the transfer at once.
+func (r *ProjectRepository) RequestTransfer(transfer *models.ProjectTransfer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, "id = ?", transfer.ProjectID).Error; err != nil {
+			return err
+		}
+		if !project.IsActive() {
+			return ErrProjectNotActive
+		}
+		if project.Published {
+			return ErrProjectPublished
+		}
+
+		var pending int64
+		if err := tx.Model(&models.ProjectTransfer{}).
+			Where("project_id = ? AND status = ?", transfer.ProjectID, models.TransferPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrTransferPending
+		}
+
+		if transfer.ToWorkingGroupID != nil {
+			var group models.WorkingGroup
+			if err := tx.First(&group, "id = ?", *transfer.ToWorkingGroupID).Error; err != nil {
+				return err
+			}
+			if group.ParentTCID != transfer.ToCommitteeID {
+				return ErrWorkingGroupNotInCommittee
+			}
+		}
+		if project.TechnicalCommitteeID == transfer.ToCommitteeID && sameWorkingGroup(project.WorkingGroupID, transfer.ToWorkingGroupID) {
+			return ErrTransferUnchanged
+		}
+
+		now := time.Now()
+		transfer.ID = uuid.New()
+		transfer.FromCommitteeID = project.TechnicalCommitteeID
+		transfer.FromWorkingGroupID = project.WorkingGroupID
+		transfer.PreviousReference = project.Reference
+		transfer.Status = models.TransferPending
+		transfer.CreatedAt = now
+		transfer.UpdatedAt = now
+		if err := approveTransferSidesWithTx(tx, transfer, transfer.RequestedByID, now); err != nil && !errors.Is(err, ErrNotTransferSecretary) {
+			return err
+		}
+
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		if transfer.IsApproved() {
+			return completeTransferWithTx(tx, transfer, now)
+		}
+		return nil
+	})
+}
+
+// ApproveTransfer records the approval of the secretary of either committee and carries out the
+// transfer once both have approved
+func (r *ProjectRepository) ApproveTransfer(transferID uuid.UUID, secretary string) (*models.ProjectTransfer, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		transfer, err := lockPendingTransferWithTx(tx, transferID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := approveTransferSidesWithTx(tx, transfer, secretary, now); err != nil {
+			return err
+		}
+		transfer.UpdatedAt = now
+		if err := tx.Omit(clause.Associations).Save(transfer).Error; err != nil {
+			return err
+		}
+		if transfer.IsApproved() {
+			return completeTransferWithTx(tx, transfer, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTransfer(transferID)
+}
+
+// RejectTransfer ends a pending transfer at the request of the secretary of either committee
+func (r *ProjectRepository) RejectTransfer(transferID uuid.UUID, secretary, reason string) (*models.ProjectTransfer, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		transfer, err := lockPendingTransferWithTx(tx, transferID)
+		if err != nil {
+			return err
+		}
+
+		isSecretary, err := isSecretaryOfWithTx(tx, secretary, transfer.FromCommitteeID, transfer.ToCommitteeID)
+		if err != nil {
+			return err
+		}
+		if !isSecretary {
+			return ErrNotTransferSecretary
+		}
+
+		transfer.Status = models.TransferRejected
+		transfer.RejectedByID = &secretary
+		transfer.RejectionReason = reason
+		transfer.UpdatedAt = time.Now()
+		return tx.Omit(clause.Associations).Save(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTransfer(transferID)
+}
+
+func lockPendingTransferWithTx(tx *gorm.DB, transferID uuid.UUID) (*models.ProjectTransfer, error) {
+	var transfer models.ProjectTransfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, "id = ?", transferID).Error; err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferPending {
+		return nil, ErrTransferNotPending
+	}
+	return &transfer, nil
+}
+
+// approveTransferSidesWithTx records the member's approval for each committee they are the
+// secretary of
+func approveTransferSidesWithTx(tx *gorm.DB, transfer *models.ProjectTransfer, memberID string, now time.Time) error {
+	approved := false
+	if transfer.SourceApprovedByID == nil {
+		if ok, err := isSecretaryOfWithTx(tx, memberID, transfer.FromCommitteeID); err != nil {
+			return err
+		} else if ok {
+			transfer.SourceApprovedByID = &memberID
+			transfer.SourceApprovedAt = &now
+			approved = true
+		}
+	}
+	if transfer.TargetApprovedByID == nil {
+		if ok, err := isSecretaryOfWithTx(tx, memberID, transfer.ToCommitteeID); err != nil {
+			return err
+		} else if ok {
+			transfer.TargetApprovedByID = &memberID
+			transfer.TargetApprovedAt = &now
+			approved = true
+		}
+	}
+	if !approved {
+		return ErrNotTransferSecretary
+	}
+	return nil
+}
+
+// isSecretaryOfWithTx reports whether the member is the secretary of any of the committees
+func isSecretaryOfWithTx(tx *gorm.DB, memberID string, committeeIDs ...string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.TechnicalCommittee{}).
+		Where("id IN ? AND secretary_id = ?", committeeIDs, memberID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// completeTransferWithTx moves the project to its new committee and working group, re-issues its
+// reference for the new TC code and carries its documents, upcoming meetings, open secretariat
+// reviews and project leader along
+func completeTransferWithTx(tx *gorm.DB, transfer *models.ProjectTransfer, now time.Time) error {
+	var project models.Project
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, "id = ?", transfer.ProjectID).Error; err != nil {
+		return err
+	}
+	if !project.IsActive() {
+		return ErrProjectNotActive
+	}
+	if project.Published {
+		return ErrProjectPublished
+	}
+
+	var committee models.TechnicalCommittee
+	if err := tx.First(&committee, "id = ?", transfer.ToCommitteeID).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"technical_committee_id": transfer.ToCommitteeID,
+		"working_group_id":       transfer.ToWorkingGroupID,
+		"updated_at":             now,
+	}
+	// Reviews the old secretariat has not concluded are handed to the new one
+	if project.WorkingDraftStatus != models.ACCEPTED && project.WDTCSecretaryID != nil {
+		updates["wd_tc_secretary_id"] = committee.SecretaryId
+	}
+	if !project.IsConsensusReached && project.CDTCSecretaryID != nil {
+		updates["cd_tc_secretary_id"] = committee.SecretaryId
+	}
+	if err := tx.Model(&models.Project{}).Where("id = ?", transfer.ProjectID).Updates(updates).Error; err != nil {
+		return err
+	}
+	if err := addProjectLeaderWithTx(tx, &project, &committee, transfer.ToWorkingGroupID); err != nil {
+		return err
+	}
+
+	if err := tx.Preload("Stage").First(&project, "id = ?", transfer.ProjectID).Error; err != nil {
+		return err
+	}
+	if project.Stage == nil {
+		return fmt.Errorf("project %s has no current stage", transfer.ProjectID)
+	}
+	reason := fmt.Sprintf("Transferred to TC %s", committee.Code)
+	if err := assignDraftReferenceWithTx(tx, transfer.ProjectID, project.Stage, reason); err != nil {
+		return err
+	}
+	if err := tx.First(&project, "id = ?", transfer.ProjectID).Error; err != nil {
+		return err
+	}
+
+	if project.Reference != transfer.PreviousReference {
+		if err := rereferenceProjectDocumentsWithTx(tx, &project, transfer.PreviousReference); err != nil {
+			return err
+		}
+	}
+	if err := moveUpcomingMeetingsWithTx(tx, transfer, &committee, now); err != nil {
+		return err
+	}
+
+	transfer.Status = models.TransferCompleted
+	transfer.NewReference = project.Reference
+	transfer.CompletedAt = &now
+	transfer.UpdatedAt = now
+	return tx.Omit(clause.Associations).Save(transfer).Error
+}
+
+// rereferenceProjectDocumentsWithTx gives the project's drafts and related documents that carried
+// the old reference the new one
+func rereferenceProjectDocumentsWithTx(tx *gorm.DB, project *models.Project, previousReference string) error {
+	drafts := []string{}
+	for _, id := range []*string{project.WorkingDraftID, project.CommitteeDraftID, project.DARSDocID, project.FDARSDocID} {
+		if id != nil {
+			drafts = append(drafts, *id)
+		}
+	}
+	related := tx.Table("project_related_documents").Select("document_id").Where("project_id = ?", project.ID)
+
+	query := tx.Model(&models.Document{}).Where("reference = ?", previousReference)
+	if len(drafts) > 0 {
+		query = query.Where("id IN ? OR id IN (?)", drafts, related)
+	} else {
+		query = query.Where("id IN (?)", related)
+	}
+	return query.Update("reference", project.Reference).Error
+}
+
+// addProjectLeaderWithTx makes the member leading the project a member of its new committee and,
+// when it moves to a working group, an expert of that group
+func addProjectLeaderWithTx(tx *gorm.DB, project *models.Project, committee *models.TechnicalCommittee, workingGroupID *string) error {
+	var leader models.Member
+	if err := tx.First(&leader, "id = ?", project.MemberID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(committee).Association("CurrentMembers").Append(&leader); err != nil {
+		return err
+	}
+
+	if workingGroupID == nil {
+		return nil
+	}
+	var group models.WorkingGroup
+	if err := tx.First(&group, "id = ?", *workingGroupID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&group).Association("Experts").Append(&leader)
+}
+
+// moveUpcomingMeetingsWithTx hands the project's upcoming meetings to the new committee, or to the
+// new working group for working group meetings. Working group meetings go to the committee when the
+// project leaves its working group. Past meetings stay with the committee that held them.
+func moveUpcomingMeetingsWithTx(tx *gorm.DB, transfer *models.ProjectTransfer, committee *models.TechnicalCommittee, now time.Time) error {
+	upcoming := []models.MeetingStatus{models.MeetingStatusPlanned, models.MeetingStatusConfirmed, models.MeetingStatusPostponed}
+
+	committeeMeetings := tx.Model(&models.Meeting{}).
+		Where("project_id = ? AND date > ? AND status IN ?", transfer.ProjectID, now, upcoming)
+	if transfer.ToWorkingGroupID != nil {
+		var group models.WorkingGroup
+		if err := tx.First(&group, "id = ?", *transfer.ToWorkingGroupID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Meeting{}).
+			Where("project_id = ? AND date > ? AND status IN ? AND meeting_type = ?", transfer.ProjectID, now, upcoming, models.MeetingTypeWG).
+			Updates(map[string]interface{}{
+				"committee_id":   group.ID.String(),
+				"committee_name": group.Name,
+			}).Error; err != nil {
+			return err
+		}
+		committeeMeetings = committeeMeetings.Where("meeting_type <> ?", models.MeetingTypeWG)
+	}
+
+	return committeeMeetings.Updates(map[string]interface{}{
+		"committee_id":   committee.ID.String(),
+		"committee_name": committee.Name,
+	}).Error
+}
+
+func sameWorkingGroup(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (r *ProjectRepository) GetTransfer(id uuid.UUID) (*models.ProjectTransfer, error) {
+	var transfer models.ProjectTransfer
+	err := r.db.Preload("Project").
+		Preload("FromCommittee").
+		Preload("ToCommittee").
+		Preload("FromWorkingGroup").
+		Preload("ToWorkingGroup").
+		Preload("RequestedBy").
+		First(&transfer, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// GetProjectTransfers lists the transfers of a project, most recent first
+func (r *ProjectRepository) GetProjectTransfers(projectID string) ([]models.ProjectTransfer, error) {
+	var transfers []models.ProjectTransfer
+	err := r.db.Preload("FromCommittee").
+		Preload("ToCommittee").
+		Preload("FromWorkingGroup").
+		Preload("ToWorkingGroup").
+		Where("project_id = ?", projectID).
+		Order("created_at DESC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// GetPendingTransfers lists the pending transfers involving the committees the member is the
+// secretary of
+func (r *ProjectRepository) GetPendingTransfers(secretary string) ([]models.ProjectTransfer, error) {
+	committees := r.db.Model(&models.TechnicalCommittee{}).Select("id").Where("secretary_id = ?", secretary)
+
+	var transfers []models.ProjectTransfer
+	err := r.db.Preload("Project").
+		Preload("FromCommittee").
+		Preload("ToCommittee").
+		Where("status = ? AND (from_committee_id IN (?) OR to_committee_id IN (?))", models.TransferPending, committees, committees).
+		Order("created_at ASC").
+		Find(&transfers).Error
+	return transfers, err
+}
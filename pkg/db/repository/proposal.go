@@ -60,21 +60,14 @@ func (r *ProposalRepository) Create(proposal *models.Proposal) error {
 		proposal.CreatedAt = time.Now()
 	}
 
-	// Find the initial stage (number = 1)
-	var stage models.Stage
-	if err := tx.Where("number = ?", 1).First(&stage).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
 	// Create the proposal first
 	if err := tx.Create(proposal).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// Update the project stage
-	if err := UpdateProjectStageWithTx(tx, proposal.ProjectID, stage.ID.String(), "Proposal submitted", "PWI", stage.Abbreviation); err != nil {
+	// Move the project to the proposal stage (number = 1)
+	if err := TransitionProjectStageWithTx(tx, proposal.ProjectID, 1, &proposal.CreatedByID, "Proposal submitted"); err != nil {
 		tx.Rollback()
 		return err
 	}
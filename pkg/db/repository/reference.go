@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// assignDraftReferenceWithTx issues the reference of the project draft at the given stage
+func assignDraftReferenceWithTx(tx *gorm.DB, projectID string, stage *models.Stage, reason string) error {
+	var project models.Project
+	if err := tx.First(&project, "id = ?", projectID).Error; err != nil {
+		return err
+	}
+
+	reference, err := draftReferenceWithTx(tx, &project, stage)
+	if err != nil {
+		return err
+	}
+
+	stageID := stage.ID.String()
+	return setProjectReferenceWithTx(tx, &project, reference, &stageID, reason)
+}
+
+// draftReferenceWithTx builds the reference of the project draft at the stage in the current year
+func draftReferenceWithTx(tx *gorm.DB, project *models.Project, stage *models.Stage) (string, error) {
+	if project.IsSupplement() {
+		if err := loadBaseStandardWithTx(tx, project); err != nil {
+			return "", err
+		}
+		return models.DraftReference(project, stage.Abbreviation, "", time.Now().Year())
+	}
+
+	var committee models.TechnicalCommittee
+	if project.Type != models.REVISION {
+		if err := tx.Select("code").First(&committee, "id = ?", project.TechnicalCommitteeID).Error; err != nil {
+			return "", err
+		}
+	}
+	return models.DraftReference(project, stage.Abbreviation, committee.Code, time.Now().Year())
+}
+
+// assignStandardDesignationWithTx gives the project its ARS designation for the year of publication,
+// allocating an ARS number if the project does not have one yet. Amendments and corrigenda are
+// designated after the standard they apply to.
+func assignStandardDesignationWithTx(tx *gorm.DB, project *models.Project, publishedAt time.Time) error {
+	if project.IsSupplement() {
+		if err := loadBaseStandardWithTx(tx, project); err != nil {
+			return err
+		}
+		reference, err := models.SupplementDesignation(project, publishedAt.Year())
+		if err != nil {
+			return err
+		}
+		return setProjectReferenceWithTx(tx, project, reference, nil, "Standard designation")
+	}
+
+	if project.StandardNumber == 0 {
+		number, err := nextNumberWithTx(tx, models.StandardNumberScope)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Project{}).Where("id = ?", project.ID).
+			Update("standard_number", number).Error; err != nil {
+			return err
+		}
+		project.StandardNumber = number
+	}
+
+	reference := models.StandardDesignation(project, publishedAt.Year())
+	return setProjectReferenceWithTx(tx, project, reference, nil, "Standard designation")
+}
+
+// loadBaseStandardWithTx loads the standard an amendment or corrigendum applies to
+func loadBaseStandardWithTx(tx *gorm.DB, project *models.Project) error {
+	if project.BaseStandard != nil || project.BaseStandardID == nil {
+		return nil
+	}
+
+	var base models.Project
+	if err := tx.First(&base, "id = ?", *project.BaseStandardID).Error; err != nil {
+		return err
+	}
+	project.BaseStandard = &base
+	return nil
+}
+
+// setProjectReferenceWithTx updates the project reference and records it in the reference history.
+// Nothing is recorded when the reference is unchanged.
+func setProjectReferenceWithTx(tx *gorm.DB, project *models.Project, reference string, stageID *string, reason string) error {
+	if project.Reference == reference {
+		return nil
+	}
+
+	if err := tx.Model(&models.Project{}).Where("id = ?", project.ID).
+		Update("reference", reference).Error; err != nil {
+		return err
+	}
+	project.Reference = reference
+
+	return tx.Create(&models.ProjectReference{
+		ID:        uuid.New(),
+		ProjectID: project.ID.String(),
+		Reference: reference,
+		StageID:   stageID,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// GetProjectByReference finds a project by its current reference or by one it carried before.
+// A project currently carrying the reference takes precedence.
+func (r *ProjectRepository) GetProjectByReference(reference string) (*models.Project, error) {
+	var project models.Project
+	pastReferences := r.db.Model(&models.ProjectReference{}).Select("project_id").Where("reference = ?", reference)
+	err := r.db.Preload("Stage").
+		Preload("TechnicalCommittee").
+		Preload("ReferenceHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Where("reference = ? OR id IN (?)", reference, pastReferences).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "CASE WHEN reference = ? THEN 0 ELSE 1 END", Vars: []interface{}{reference}, WithoutParentheses: true}}).
+		First(&project).Error
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetProjectReferences returns the references the project has carried, oldest first
+func (r *ProjectRepository) GetProjectReferences(projectID uuid.UUID) ([]models.ProjectReference, error) {
+	var references []models.ProjectReference
+	err := r.db.Preload("Stage").
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Find(&references).Error
+	return references, err
+}
@@ -222,6 +222,26 @@ func (r *ReportsRepository) GetProjectReportData(filters models.ReportFilters) (
 		projectsByTimeframe[result.Month] = result.Count
 	}
 
+	// Adoptions of international documents by degree of equivalence and source organisation
+	adoptedProjects := r.applyProjectFilters(r.db.Model(&models.Project{}).Select("id"), filters)
+	projectsByAdoption := make(map[string]int64)
+	adoptionsBySource := make(map[string]int64)
+	var adoptionResults []struct {
+		Equivalence  string
+		Organisation string
+		Count        int64
+	}
+	if err := r.db.Model(&models.AdoptedStandard{}).
+		Select("equivalence, organisation, COUNT(*) as count").
+		Where("project_id IN (?)", adoptedProjects).
+		Group("equivalence, organisation").Scan(&adoptionResults).Error; err != nil {
+		return nil, err
+	}
+	for _, result := range adoptionResults {
+		projectsByAdoption[result.Equivalence] += result.Count
+		adoptionsBySource[result.Organisation] += result.Count
+	}
+
 	// Calculate summary metrics
 	var avgTimeframe float64
 	r.db.Model(&models.Project{}).Select("AVG(time_frame)").Scan(&avgTimeframe)
@@ -252,6 +272,8 @@ func (r *ReportsRepository) GetProjectReportData(filters models.ReportFilters) (
 		ProjectsByStage:     projectsByStage,
 		ProjectsByCommittee: projectsByCommittee,
 		ProjectsByTimeframe: projectsByTimeframe,
+		ProjectsByAdoption:  projectsByAdoption,
+		AdoptionsBySource:   adoptionsBySource,
 		Summary:             summary,
 	}, nil
 }
@@ -278,10 +300,11 @@ func (r *ReportsRepository) GetBallotReportData(filters models.ReportFilters) (*
 		return nil, err
 	}
 
-	// Calculate average success rate
+	// Calculate average success rate, abstentions are not counted
 	var avgSuccessRate float64
 	r.db.Table("ballotings b").
 		Joins("JOIN votes v ON b.id = v.balloting_id").
+		Where("COALESCE(v.decision, '') <> ?", models.VoteAbstain).
 		Select("AVG(CASE WHEN v.acceptance = true THEN 100.0 ELSE 0.0 END)").
 		Scan(&avgSuccessRate)
 
@@ -334,6 +357,24 @@ func (r *ReportsRepository) GetBallotReportData(filters models.ReportFilters) (*
 		votingParticipation[result.NSBName] = result.Participation
 	}
 
+	// Votes by decision on the ballots in scope, votes cast before decisions were recorded are
+	// reported by acceptance
+	votesByDecision := make(map[string]int64)
+	var decisionResults []struct {
+		Decision string
+		Count    int64
+	}
+	ballotsInScope := r.applyBallotFilters(r.db.Model(&models.Balloting{}), filters).Select("ballotings.id")
+	if err := r.db.Table("votes v").
+		Where("v.balloting_id IN (?)", ballotsInScope).
+		Select("COALESCE(NULLIF(v.decision, ''), CASE WHEN v.acceptance THEN 'APPROVE' ELSE 'DISAPPROVE' END) as decision, COUNT(*) as count").
+		Group("1").Scan(&decisionResults).Error; err != nil {
+		return nil, err
+	}
+	for _, result := range decisionResults {
+		votesByDecision[result.Decision] = result.Count
+	}
+
 	// Calculate summary metrics
 	var avgVotingTime float64
 	r.db.Table("ballotings").
@@ -366,6 +407,7 @@ func (r *ReportsRepository) GetBallotReportData(filters models.ReportFilters) (*
 		BallotsByCommittee:  ballotsByCommittee,
 		BallotsByTimeframe:  ballotsByTimeframe,
 		VotingParticipation: votingParticipation,
+		VotesByDecision:     votesByDecision,
 		Summary:             summary,
 	}, nil
 }
@@ -641,6 +683,7 @@ func (r *ReportsRepository) GetDashboardMetrics() ([]models.DashboardMetric, err
 	var avgSuccessRate float64
 	r.db.Table("ballotings b").
 		Joins("JOIN votes v ON b.id = v.balloting_id").
+		Where("COALESCE(v.decision, '') <> ?", models.VoteAbstain).
 		Select("AVG(CASE WHEN v.acceptance = true THEN 100.0 ELSE 0.0 END)").
 		Scan(&avgSuccessRate)
 	metrics = append(metrics, models.DashboardMetric{
@@ -716,10 +759,10 @@ func (r *ReportsRepository) applyProjectFilters(query *gorm.DB, filters models.R
 
 func (r *ReportsRepository) applyBallotFilters(query *gorm.DB, filters models.ReportFilters) *gorm.DB {
 	if filters.DateFrom != nil {
-		query = query.Where("created_at >= ?", *filters.DateFrom)
+		query = query.Where("ballotings.created_at >= ?", *filters.DateFrom)
 	}
 	if filters.DateTo != nil {
-		query = query.Where("created_at <= ?", *filters.DateTo)
+		query = query.Where("ballotings.created_at <= ?", *filters.DateTo)
 	}
 	if filters.CommitteeID != nil {
 		query = query.Joins("JOIN projects p ON ballotings.project_id = p.id").
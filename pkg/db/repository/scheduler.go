@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SchedulerRepository struct {
+	db *gorm.DB
+}
+
+func NewSchedulerRepository(db *gorm.DB) *SchedulerRepository {
+	return &SchedulerRepository{db: db}
+}
+
+// RegisterJob creates the job row or refreshes its schedule and description, keeping the paused state
+func (r *SchedulerRepository) RegisterJob(job *models.ScheduledJob) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "schedule", "updated_at"}),
+	}).Create(job).Error
+}
+
+func (r *SchedulerRepository) GetJobs() ([]models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+	err := r.db.Preload("PausedBy").Order("name ASC").Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *SchedulerRepository) GetJobByName(name string) (*models.ScheduledJob, error) {
+	var job models.ScheduledJob
+	if err := r.db.Preload("PausedBy").First(&job, "name = ?", name).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *SchedulerRepository) SetJobPaused(name string, paused bool, memberID *string) error {
+	updates := map[string]any{
+		"paused":       paused,
+		"paused_by_id": nil,
+		"paused_at":    nil,
+		"updated_at":   time.Now(),
+	}
+	if paused {
+		now := time.Now()
+		updates["paused_by_id"] = memberID
+		updates["paused_at"] = &now
+	}
+
+	result := r.db.Model(&models.ScheduledJob{}).Where("name = ?", name).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *SchedulerRepository) CreateJobRun(run *models.JobRun) error {
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	return r.db.Create(run).Error
+}
+
+// FinishJobRun stores the outcome of a run and mirrors it on the job row
+func (r *SchedulerRepository) FinishJobRun(run *models.JobRun) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(run).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.ScheduledJob{}).Where("name = ?", run.JobName).Updates(map[string]any{
+			"last_run_at": run.StartedAt,
+			"last_status": run.Status,
+		}).Error
+	})
+}
+
+func (r *SchedulerRepository) GetJobRuns(jobName string, limit, offset int) ([]models.JobRun, int64, error) {
+	var runs []models.JobRun
+	var total int64
+
+	query := r.db.Model(&models.JobRun{})
+	if jobName != "" {
+		query = query.Where("job_name = ?", jobName)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("TriggeredBy").
+		Order("started_at DESC").Limit(limit).Offset(offset).Find(&runs).Error
+
+	return runs, total, err
+}
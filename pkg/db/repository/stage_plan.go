@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loadStagesWithTimeframesWithTx loads the seeded stages with their durations for every track
+func loadStagesWithTimeframesWithTx(tx *gorm.DB) ([]models.Stage, error) {
+	var stages []models.Stage
+	err := tx.Preload("Timeframe.Standard").
+		Preload("Timeframe.IS").
+		Preload("Timeframe.Emergency").
+		Order("number ASC").
+		Find(&stages).Error
+	return stages, err
+}
+
+// planProjectStagesWithTx replaces the stage plan of the project with one starting at the given date
+func planProjectStagesWithTx(tx *gorm.DB, project *models.Project, start time.Time) ([]models.ProjectStagePlan, error) {
+	stages, err := loadStagesWithTimeframesWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectStagePlan{}).Error; err != nil {
+		return nil, err
+	}
+
+	plans := models.PlanProjectStages(project, stages, start)
+	if len(plans) > 0 {
+		if err := tx.Create(&plans).Error; err != nil {
+			return nil, err
+		}
+	}
+	return plans, nil
+}
+
+// ReplanProjectStages recomputes the stage plan from the project start, for example after the
+// project moved to another track
+func (r *ProjectRepository) ReplanProjectStages(projectID uuid.UUID) ([]models.ProjectStagePlan, error) {
+	var plans []models.ProjectStagePlan
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.Preload("Acceptance").First(&project, "id = ?", projectID).Error; err != nil {
+			return err
+		}
+
+		var err error
+		plans, err = planProjectStagesWithTx(tx, &project, project.CreatedAt)
+		return err
+	})
+	return plans, err
+}
+
+// GetProjectSLA compares the project's stage plan with its stage history. Projects created before
+// stage plans existed are planned on first access.
+func (r *ProjectRepository) GetProjectSLA(projectID uuid.UUID) (*models.ProjectSLA, error) {
+	var project models.Project
+	err := r.db.Preload("StagePlans", func(db *gorm.DB) *gorm.DB {
+		return db.Order("stage_number ASC")
+	}).
+		Preload("StagePlans.Stage").
+		Preload("StageHistory").
+		Preload("Acceptance").
+		First(&project, "id = ?", projectID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(project.StagePlans) == 0 {
+		if project.StagePlans, err = r.ReplanProjectStages(projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	sla := models.EvaluateProjectSLA(&project, project.StagePlans, project.StageHistory, time.Now())
+	return &sla, nil
+}
+
+// GetSLABreaches returns the active projects with at least one stage in breach of its SLA,
+// optionally limited to a technical committee
+func (r *ProjectRepository) GetSLABreaches(committeeID string) ([]models.ProjectSLA, error) {
+	_, slas, err := r.evaluateActiveProjectSLAs(committeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	breaches := []models.ProjectSLA{}
+	for _, sla := range slas {
+		if sla.Breaches > 0 {
+			breaches = append(breaches, sla)
+		}
+	}
+	return breaches, nil
+}
+
+// GetCommitteeSLASummary summarises the SLA breaches of active projects per technical committee
+func (r *ProjectRepository) GetCommitteeSLASummary() ([]models.CommitteeSLA, error) {
+	projects, slas, err := r.evaluateActiveProjectSLAs("")
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []models.CommitteeSLA{}
+	index := make(map[string]int)
+	for i, project := range projects {
+		position, ok := index[project.TechnicalCommitteeID]
+		if !ok {
+			summary := models.CommitteeSLA{TechnicalCommitteeID: project.TechnicalCommitteeID, Projects: []models.ProjectSLA{}}
+			if project.TechnicalCommittee != nil {
+				summary.CommitteeCode = project.TechnicalCommittee.Code
+				summary.CommitteeName = project.TechnicalCommittee.Name
+			}
+			summaries = append(summaries, summary)
+			position = len(summaries) - 1
+			index[project.TechnicalCommitteeID] = position
+		}
+
+		summary := &summaries[position]
+		summary.ActiveProjects++
+		if slas[i].Breaches > 0 {
+			summary.ProjectsInBreach++
+			summary.Breaches += slas[i].Breaches
+			summary.Projects = append(summary.Projects, slas[i])
+		}
+	}
+	return summaries, nil
+}
+
+// evaluateActiveProjectSLAs evaluates every unpublished project that is not stopped. Projects without a
+// stored plan are planned in memory from their creation date.
+func (r *ProjectRepository) evaluateActiveProjectSLAs(committeeID string) ([]models.Project, []models.ProjectSLA, error) {
+	query := r.db.Preload("TechnicalCommittee").
+		Preload("Stage").
+		Preload("StagePlans", func(db *gorm.DB) *gorm.DB {
+			return db.Order("stage_number ASC")
+		}).
+		Preload("StageHistory").
+		Preload("Acceptance").
+		Where("projects.published = ?", false).
+		Where(activeProject)
+	if committeeID != "" {
+		query = query.Where("technical_committee_id = ?", committeeID)
+	}
+
+	var projects []models.Project
+	if err := query.Order("created_at ASC").Find(&projects).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var stages []models.Stage
+	now := time.Now()
+	slas := make([]models.ProjectSLA, len(projects))
+	for i := range projects {
+		plans := projects[i].StagePlans
+		if len(plans) == 0 {
+			if stages == nil {
+				var err error
+				if stages, err = loadStagesWithTimeframesWithTx(r.db); err != nil {
+					return nil, nil, err
+				}
+			}
+			plans = models.PlanProjectStages(&projects[i], stages, projects[i].CreatedAt)
+		}
+		slas[i] = models.EvaluateProjectSLA(&projects[i], plans, projects[i].StageHistory, now)
+	}
+	return projects, slas, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrNotSupplementable = errors.New("amendments and corrigenda can only be issued against a published standard that has not been withdrawn")
+
+// CreateSupplement opens an amendment or corrigendum of a published standard. The project follows
+// the reduced workflow of its type and is numbered after the standard, e.g. ARS 461:2021/Amd 1.
+func (r *ProjectRepository) CreateSupplement(baseProjectID uuid.UUID, supplementType models.ProjectType, memberID, title, description string) (*models.Project, error) {
+	var supplement *models.Project
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var base models.Project
+		if err := tx.First(&base, "id = ?", baseProjectID).Error; err != nil {
+			return err
+		}
+		if !base.Published || base.IsSupplement() || base.LibraryStatus == models.LibraryWithdrawn {
+			return ErrNotSupplementable
+		}
+
+		var err error
+		supplement, err = createSupplementWithTx(tx, &base, supplementType, memberID, title, description)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return supplement, nil
+}
+
+func createSupplementWithTx(tx *gorm.DB, base *models.Project, supplementType models.ProjectType, memberID, title, description string) (*models.Project, error) {
+	if title == "" {
+		title = base.Title
+	}
+
+	baseID := base.ID.String()
+	supplement := models.Project{
+		ID:                   uuid.New(),
+		MemberID:             memberID,
+		ProjectSectorID:      base.ProjectSectorID,
+		Procedure:            models.SupplementProcedure(supplementType),
+		StandardNumber:       base.StandardNumber,
+		PartNo:               base.PartNo,
+		EditionNo:            base.EditionNo,
+		ReferenceSuffix:      base.ReferenceSuffix,
+		Title:                title,
+		Language:             base.Language,
+		Description:          description,
+		TechnicalCommitteeID: base.TechnicalCommitteeID,
+		WorkingGroupID:       base.WorkingGroupID,
+		Timeframe:            base.Timeframe,
+		Type:                 supplementType,
+		VisibleOnLibrary:     false, // Not visible until published
+		PricePerPage:         base.PricePerPage,
+		BaseStandardID:       &baseID,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+	}
+
+	var stage models.Stage
+	if err := tx.Where("number = ?", 0).First(&stage).Error; err != nil {
+		return nil, err
+	}
+	supplement.StageID = stage.ID.String()
+
+	number, err := nextNumberWithTx(tx, models.ProjectNumberScope)
+	if err != nil {
+		return nil, err
+	}
+	supplement.Number = number
+
+	if supplement.SupplementNo, err = nextNumberWithTx(tx, models.SupplementScope(supplementType, baseID)); err != nil {
+		return nil, err
+	}
+
+	supplement.BaseStandard = base
+	if supplement.Reference, err = draftReferenceWithTx(tx, &supplement, &stage); err != nil {
+		return nil, err
+	}
+
+	// The base standard is only needed to build the reference and must not be saved with the project
+	supplement.BaseStandard = nil
+	if err := createProjectWithTx(tx, &supplement, "Project created"); err != nil {
+		return nil, err
+	}
+	if err := linkProjectsWithTx(tx, supplement.ID.String(), baseID, models.RelationAmends); err != nil {
+		return nil, err
+	}
+	return &supplement, nil
+}
+
+// GetSupplements lists the amendments and corrigenda of a standard, published or not, in the order
+// they were issued
+func (r *ProjectRepository) GetSupplements(baseProjectID uuid.UUID) ([]models.Project, error) {
+	var supplements []models.Project
+	err := r.db.Preload("Stage").
+		Where("base_standard_id = ?", baseProjectID).
+		Order("type ASC, supplement_no ASC").
+		Find(&supplements).Error
+	return supplements, err
+}
+
+// publishedSupplements restricts a Supplements preload to the published amendments and corrigenda
+func publishedSupplements(db *gorm.DB) *gorm.DB {
+	return db.Where("published = ?", true).Order("type ASC, supplement_no ASC")
+}
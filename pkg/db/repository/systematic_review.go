@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrReviewClosed      = errors.New("systematic review is closed")
+	ErrReviewAlreadyOpen = errors.New("the standard already has an open systematic review")
+	ErrNotReviewable     = errors.New("only published standards that have not been withdrawn can be reviewed")
+	ErrAmendmentOpen     = errors.New("the standard cannot be reviewed while an amendment to it is in progress")
+)
+
+// reviewableStandard matches the published standards that may be put under systematic review.
+// Amendments and corrigenda are reviewed with the standard they apply to, and a standard is not
+// reviewed again while one of them is in progress.
+const reviewableStandard = "projects.published = true AND projects.base_standard_id IS NULL AND projects.library_status IN ('CURRENT', 'CONFIRMED') AND NOT EXISTS (" + openSupplement + ")"
+
+// openSupplement matches the amendments and corrigenda of the standard that are still in progress
+const openSupplement = "SELECT 1 FROM projects AS supplements WHERE supplements.base_standard_id = projects.id AND supplements.published = false AND supplements.status IN ('ACTIVE', 'ON_HOLD')"
+
+type SystematicReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewSystematicReviewRepository(db *gorm.DB) *SystematicReviewRepository {
+	return &SystematicReviewRepository{db: db}
+}
+
+// OpenReview opens a systematic review of a published standard
+func (r *SystematicReviewRepository) OpenReview(projectID string, closesAt time.Time) (*models.SystematicReview, error) {
+	var review *models.SystematicReview
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.Where("id = ?", projectID).First(&project).Error; err != nil {
+			return err
+		}
+		if !project.Published || project.IsSupplement() || project.LibraryStatus == models.LibraryWithdrawn {
+			return ErrNotReviewable
+		}
+		if project.LibraryStatus == models.LibraryUnderReview {
+			return ErrReviewAlreadyOpen
+		}
+
+		var open int64
+		if err := tx.Model(&models.Project{}).Where("projects.id = ? AND EXISTS ("+openSupplement+")", projectID).Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrAmendmentOpen
+		}
+
+		var err error
+		review, err = openReviewWithTx(tx, &project, closesAt)
+		return err
+	})
+	return review, err
+}
+
+// OpenDueReviews opens a review of every standard published or last confirmed before the cutoff
+func (r *SystematicReviewRepository) OpenDueReviews(cutoff, closesAt time.Time) ([]models.SystematicReview, error) {
+	var projects []models.Project
+	if err := r.db.Where(reviewableStandard).
+		Where("COALESCE(projects.confirmed_date, projects.published_date) <= ?", cutoff).
+		Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	var reviews []models.SystematicReview
+	for i := range projects {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			review, err := openReviewWithTx(tx, &projects[i], closesAt)
+			if err != nil {
+				return err
+			}
+			reviews = append(reviews, *review)
+			return nil
+		})
+		if err != nil {
+			return reviews, err
+		}
+	}
+	return reviews, nil
+}
+
+func openReviewWithTx(tx *gorm.DB, project *models.Project, closesAt time.Time) (*models.SystematicReview, error) {
+	now := time.Now()
+	review := models.SystematicReview{
+		ID:        uuid.New(),
+		ProjectID: project.ID.String(),
+		Reference: project.Reference,
+		Status:    models.SystematicReviewOpen,
+		OpensAt:   now,
+		ClosesAt:  closesAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := tx.Create(&review).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.Project{}).Where("id = ?", project.ID).
+		Updates(map[string]interface{}{"library_status": models.LibraryUnderReview, "updated_at": now}).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *SystematicReviewRepository) GetReview(id uuid.UUID) (*models.SystematicReview, error) {
+	var review models.SystematicReview
+	err := r.db.Preload("Project").
+		Preload("RevisionProject").
+		Preload("Responses", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Responses.NationalStandardBody").
+		First(&review, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetReviews lists reviews by status, most recent first
+func (r *SystematicReviewRepository) GetReviews(status models.SystematicReviewStatus, limit, offset int) ([]models.SystematicReview, int64, error) {
+	query := r.db.Model(&models.SystematicReview{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []models.SystematicReview
+	err := query.Preload("Project").Order("created_at DESC").Limit(limit).Offset(offset).Find(&reviews).Error
+	return reviews, total, err
+}
+
+func (r *SystematicReviewRepository) GetProjectReviews(projectID string) ([]models.SystematicReview, error) {
+	var reviews []models.SystematicReview
+	err := r.db.Where("project_id = ?", projectID).Order("created_at DESC").Find(&reviews).Error
+	return reviews, err
+}
+
+// GetReviewsDueForClosure lists the open reviews whose closing date has passed
+func (r *SystematicReviewRepository) GetReviewsDueForClosure(now time.Time) ([]models.SystematicReview, error) {
+	var reviews []models.SystematicReview
+	err := r.db.Where("status = ? AND closes_at <= ?", models.SystematicReviewOpen, now).
+		Order("closes_at ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// SubmitResponse records the response of the member's NSB, replacing its earlier response. Only
+// the national TC secretary of an NSB from a participating country may respond.
+func (r *SystematicReviewRepository) SubmitResponse(response *models.SystematicReviewResponse) error {
+	if err := response.Decision.Validate(); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var review models.SystematicReview
+		if err := tx.First(&review, "id = ?", response.ReviewID).Error; err != nil {
+			return err
+		}
+		if review.IsClosed() {
+			return ErrReviewClosed
+		}
+
+		nsb, err := participatingNSBWithTx(tx, response.MemberID, review.ProjectID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		response.ID = uuid.New()
+		response.NationalStandardBodyID = nsb.ID.String()
+		response.CreatedAt = now
+		response.UpdatedAt = now
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "review_id"}, {Name: "national_standard_body_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"member_id", "decision", "comment", "updated_at"}),
+		}).Create(response).Error
+	})
+}
+
+// CloseReview tallies the responses and applies the outcome: the standard is confirmed, a revision
+// project is created to revise it, an amendment project is created to amend it, or it is withdrawn
+// from the library
+func (r *SystematicReviewRepository) CloseReview(id uuid.UUID, closedByID *string) (*models.SystematicReview, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var review models.SystematicReview
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Responses").
+			First(&review, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if review.Status != models.SystematicReviewOpen {
+			return ErrReviewClosed
+		}
+
+		now := time.Now()
+		tally := models.TallyReviewResponses(review.Responses)
+		review.Tally = &tally
+		review.Outcome = tally.Outcome()
+		review.Status = models.SystematicReviewClosed
+		review.ClosedAt = &now
+		review.ClosedByID = closedByID
+		review.UpdatedAt = now
+
+		updates := map[string]interface{}{"updated_at": now}
+		switch review.Outcome {
+		case models.ReviewConfirm:
+			updates["library_status"] = models.LibraryConfirmed
+			updates["confirmed_date"] = now
+		case models.ReviewWithdraw:
+			updates["library_status"] = models.LibraryWithdrawn
+			updates["withdrawn_date"] = now
+		case models.ReviewAmend:
+			var project models.Project
+			if err := tx.Where("id = ?", review.ProjectID).First(&project).Error; err != nil {
+				return err
+			}
+			amendment, err := createSupplementWithTx(tx, &project, models.AMENDMENT, project.MemberID, "",
+				fmt.Sprintf("Amendment of %s following its systematic review", project.Reference))
+			if err != nil {
+				return err
+			}
+			amendmentID := amendment.ID.String()
+			review.RevisionProjectID = &amendmentID
+			// The standard remains current while it is amended. It was reviewed now, so the next
+			// review falls due a full interval later.
+			updates["library_status"] = models.LibraryCurrent
+			updates["confirmed_date"] = now
+		default:
+			var project models.Project
+			if err := tx.Where("id = ?", review.ProjectID).First(&project).Error; err != nil {
+				return err
+			}
+			revision, err := createProjectRevisionWithTx(tx, &project)
+			if err != nil {
+				return err
+			}
+			revisionID := revision.ID.String()
+			review.RevisionProjectID = &revisionID
+			updates["library_status"] = models.LibraryUnderRevision
+		}
+
+		if err := tx.Model(&models.Project{}).Where("id = ?", review.ProjectID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if review.Outcome == models.ReviewWithdraw {
+			// Amendments and corrigenda are withdrawn with their standard
+			if err := tx.Model(&models.Project{}).Where("base_standard_id = ? AND published = ?", review.ProjectID, true).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit(clause.Associations).Save(&review).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetReview(id)
+}
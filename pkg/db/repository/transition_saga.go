@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSagaNotClaimable = errors.New("saga is being processed or has already finished")
+
+// abandonedSaga matches sagas whose worker stopped before its lease ran out
+const abandonedSaga = "(status = ? AND locked_until < ?)"
+
+type SagaRepository struct {
+	db *gorm.DB
+}
+
+func NewSagaRepository(db *gorm.DB) *SagaRepository {
+	return &SagaRepository{db: db}
+}
+
+// enqueueTransitionSagaWithTx writes the saga that copies the project's draft for the stage it has
+// just moved to. It must run in the transaction of the transition, after the new reference is set.
+func enqueueTransitionSagaWithTx(tx *gorm.DB, projectID, transition, docType string) (*models.TransitionSaga, error) {
+	var project models.Project
+	if err := tx.Where("id = ?", projectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	saga := models.TransitionSaga{
+		ID:            uuid.New(),
+		ProjectID:     projectID,
+		ProjectNumber: project.Number,
+		Reference:     project.Reference,
+		Transition:    transition,
+		DocType:       docType,
+		FileName:      models.SagaFileName(project.Reference),
+		CreatedByID:   project.MemberID,
+		Status:        models.SagaPending,
+		NextAttemptAt: time.Now(),
+	}
+	if project.SharepointDocID != nil {
+		saga.SourceSharepointDocID = *project.SharepointDocID
+	}
+
+	if err := tx.Create(&saga).Error; err != nil {
+		return nil, err
+	}
+	return &saga, nil
+}
+
+func (r *SagaRepository) GetSaga(id uuid.UUID) (*models.TransitionSaga, error) {
+	var saga models.TransitionSaga
+	err := r.db.Preload("Project").
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("started_at ASC")
+		}).
+		First(&saga, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &saga, nil
+}
+
+func (r *SagaRepository) GetProjectSagas(projectID string) ([]models.TransitionSaga, error) {
+	var sagas []models.TransitionSaga
+	err := r.db.Where("project_id = ?", projectID).Order("created_at DESC").Find(&sagas).Error
+	return sagas, err
+}
+
+// GetSagas lists sagas by status, most recent first
+func (r *SagaRepository) GetSagas(status models.TransitionSagaStatus, limit, offset int) ([]models.TransitionSaga, int64, error) {
+	query := r.db.Model(&models.TransitionSaga{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var sagas []models.TransitionSaga
+	err := query.Preload("Project").Order("created_at DESC").Limit(limit).Offset(offset).Find(&sagas).Error
+	return sagas, total, err
+}
+
+// GetStuckSagas lists the sagas that need attention: failed sagas, sagas abandoned by their worker
+// and pending sagas that have already failed at least once
+func (r *SagaRepository) GetStuckSagas(now time.Time) ([]models.TransitionSaga, error) {
+	var sagas []models.TransitionSaga
+	err := r.db.Preload("Project").
+		Where("status = ?", models.SagaFailed).
+		Or(abandonedSaga, models.SagaRunning, now).
+		Or("status = ? AND attempts > 0", models.SagaPending).
+		Order("created_at ASC").
+		Find(&sagas).Error
+	return sagas, err
+}
+
+// ClaimSaga takes the lease of a saga in one of the given statuses, or abandoned by its worker
+func (r *SagaRepository) ClaimSaga(id uuid.UUID, statuses []models.TransitionSagaStatus, lease time.Duration) (*models.TransitionSaga, error) {
+	now := time.Now()
+	result := r.db.Model(&models.TransitionSaga{}).
+		Where("id = ?", id).
+		Where("(status IN ? OR "+abandonedSaga+")", statuses, models.SagaRunning, now).
+		Updates(map[string]interface{}{"status": models.SagaRunning, "locked_until": now.Add(lease), "updated_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	saga, err := r.GetSaga(id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrSagaNotClaimable
+	}
+	return saga, nil
+}
+
+// ClaimDueSagas takes the lease of pending sagas whose next attempt is due and of sagas abandoned by
+// their worker. Sagas claimed by another replica are skipped.
+func (r *SagaRepository) ClaimDueSagas(limit int, lease time.Duration) ([]models.TransitionSaga, error) {
+	var sagas []models.TransitionSaga
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.SagaPending, now).
+			Or(abandonedSaga, models.SagaRunning, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&sagas).Error; err != nil {
+			return err
+		}
+		if len(sagas) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(sagas))
+		lockedUntil := now.Add(lease)
+		for i := range sagas {
+			ids[i] = sagas[i].ID
+			sagas[i].Status = models.SagaRunning
+			sagas[i].LockedUntil = &lockedUntil
+		}
+		return tx.Model(&models.TransitionSaga{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": models.SagaRunning, "locked_until": lockedUntil, "updated_at": now}).Error
+	})
+	return sagas, err
+}
+
+// SaveSaga stores the progress of a saga
+func (r *SagaRepository) SaveSaga(saga *models.TransitionSaga) error {
+	return saveSagaWithTx(r.db, saga)
+}
+
+func (r *SagaRepository) RecordStep(step *models.TransitionSagaStep) error {
+	return r.db.Create(step).Error
+}
+
+// LinkCopiedFile points the project at the OneDrive copy made by the saga
+func (r *SagaRepository) LinkCopiedFile(saga *models.TransitionSaga) error {
+	return r.applyStep(saga, func(tx *gorm.DB, saga *models.TransitionSaga) error {
+		if err := tx.Model(&models.Project{}).
+			Where("id = ?", saga.ProjectID).
+			Update("sharepoint_doc_id", saga.CopiedSharepointDocID).Error; err != nil {
+			return err
+		}
+
+		saga.CompletedSteps++
+		return nil
+	})
+}
+
+// UnlinkCopiedFile points the project back at the file it had before the saga, unless it has moved
+// on to another file since
+func (r *SagaRepository) UnlinkCopiedFile(saga *models.TransitionSaga) error {
+	return r.applyStep(saga, func(tx *gorm.DB, saga *models.TransitionSaga) error {
+		var previous *string
+		if saga.SourceSharepointDocID != "" {
+			previous = &saga.SourceSharepointDocID
+		}
+		if err := tx.Model(&models.Project{}).
+			Where("id = ? AND sharepoint_doc_id = ?", saga.ProjectID, saga.CopiedSharepointDocID).
+			Update("sharepoint_doc_id", previous).Error; err != nil {
+			return err
+		}
+
+		saga.CompletedSteps--
+		return nil
+	})
+}
+
+// CreateProjectDocument records the copy as the project document of the saga's type
+func (r *SagaRepository) CreateProjectDocument(saga *models.TransitionSaga) error {
+	return r.applyStep(saga, func(tx *gorm.DB, saga *models.TransitionSaga) error {
+		var project models.Project
+		if err := tx.Where("id = ?", saga.ProjectID).First(&project).Error; err != nil {
+			return err
+		}
+		if field := projectDocField(&project, saga.DocType); field != nil {
+			saga.PreviousDocumentID = *field
+		}
+
+		doc, err := updateProjectDocWithTx(tx, saga.ProjectID, saga.DocType, saga.FileName, saga.CreatedByID)
+		if err != nil {
+			return err
+		}
+
+		documentID := doc.ID.String()
+		saga.DocumentID = &documentID
+		saga.CompletedSteps++
+		return nil
+	})
+}
+
+// RemoveProjectDocument deletes the document created by the saga and restores the project document
+// it replaced. Removing a standard also withdraws its publication.
+func (r *SagaRepository) RemoveProjectDocument(saga *models.TransitionSaga) error {
+	return r.applyStep(saga, func(tx *gorm.DB, saga *models.TransitionSaga) error {
+		var project models.Project
+		if err := tx.Where("id = ?", saga.ProjectID).First(&project).Error; err != nil {
+			return err
+		}
+
+		field := projectDocField(&project, saga.DocType)
+		if field != nil && *field != nil && saga.DocumentID != nil && **field == *saga.DocumentID {
+			*field = saga.PreviousDocumentID
+			if saga.DocType == "ARS" {
+				project.Published = false
+				project.PublishedDate = nil
+			}
+			if err := tx.Save(&project).Error; err != nil {
+				return err
+			}
+		}
+
+		if saga.DocumentID != nil {
+			if err := tx.Where("id = ?", *saga.DocumentID).Delete(&models.Document{}).Error; err != nil {
+				return err
+			}
+		}
+
+		saga.DocumentID = nil
+		saga.PreviousDocumentID = nil
+		saga.CompletedSteps--
+		return nil
+	})
+}
+
+// applyStep runs a database step on a copy of the saga and stores the copy in the same
+// transaction. The caller's saga only reflects the step once it has committed.
+func (r *SagaRepository) applyStep(saga *models.TransitionSaga, step func(tx *gorm.DB, saga *models.TransitionSaga) error) error {
+	updated := *saga
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := step(tx, &updated); err != nil {
+			return err
+		}
+		return saveSagaWithTx(tx, &updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	*saga = updated
+	return nil
+}
+
+func saveSagaWithTx(tx *gorm.DB, saga *models.TransitionSaga) error {
+	saga.UpdatedAt = time.Now()
+	return tx.Omit(clause.Associations).Save(saga).Error
+}
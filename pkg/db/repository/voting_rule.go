@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrDefaultVotingRule = errors.New("the default voting rule cannot be deactivated")
+
+type VotingRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewVotingRuleRepository(db *gorm.DB) *VotingRuleRepository {
+	return &VotingRuleRepository{db: db}
+}
+
+// CreateRuleVersion stores the rule as the next version for its procedure and deactivates
+// the version it replaces. Versions of the same procedure are serialised with an advisory lock,
+// which also holds when the procedure has no version yet to lock.
+func (r *VotingRuleRepository) CreateRuleVersion(rule *models.VotingRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "voting_rules:"+string(rule.Procedure)).Error; err != nil {
+			return err
+		}
+
+		var current []models.VotingRule
+		if err := tx.Where("procedure = ?", rule.Procedure).
+			Order("version DESC").Limit(1).Find(&current).Error; err != nil {
+			return err
+		}
+
+		rule.ID = uuid.New()
+		rule.Version = 1
+		rule.Active = true
+		if len(current) > 0 {
+			rule.Version = current[0].Version + 1
+		}
+
+		if err := tx.Model(&models.VotingRule{}).
+			Where("procedure = ? AND active = ?", rule.Procedure, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(rule).Error
+	})
+}
+
+// DeactivateRule removes the procedure specific rule so the default rule applies again
+func (r *VotingRuleRepository) DeactivateRule(procedure models.Procedure) error {
+	if procedure == "" {
+		return ErrDefaultVotingRule
+	}
+
+	result := r.db.Model(&models.VotingRule{}).
+		Where("procedure = ? AND active = ?", procedure, true).
+		Update("active", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *VotingRuleRepository) GetRuleByID(id uuid.UUID) (*models.VotingRule, error) {
+	var rule models.VotingRule
+	if err := r.db.Preload("CreatedBy").First(&rule, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *VotingRuleRepository) GetActiveRules() ([]models.VotingRule, error) {
+	var rules []models.VotingRule
+	err := r.db.Preload("CreatedBy").Where("active = ?", true).Order("procedure ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *VotingRuleRepository) GetRuleVersions(procedure models.Procedure) ([]models.VotingRule, error) {
+	var rules []models.VotingRule
+	err := r.db.Preload("CreatedBy").Where("procedure = ?", procedure).Order("version DESC").Find(&rules).Error
+	return rules, err
+}
+
+// GetApplicableRule returns the rule used to decide ballots for the procedure
+func (r *VotingRuleRepository) GetApplicableRule(procedure models.Procedure) (*models.VotingRule, error) {
+	return findApplicableVotingRule(r.db, procedure)
+}
+
+// findApplicableVotingRule returns the active rule for the procedure, falling back to the active
+// default rule and then to the built-in default
+func findApplicableVotingRule(db *gorm.DB, procedure models.Procedure) (*models.VotingRule, error) {
+	var rules []models.VotingRule
+	if err := db.Where("active = ? AND procedure IN ?", true, []models.Procedure{procedure, ""}).
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load voting rules: %w", err)
+	}
+
+	var fallback *models.VotingRule
+	for i := range rules {
+		if rules[i].Procedure == procedure {
+			return &rules[i], nil
+		}
+		fallback = &rules[i]
+	}
+	if fallback != nil {
+		return fallback, nil
+	}
+
+	rule := models.DefaultVotingRule()
+	return &rule, nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotWorkProgrammeSecretary = errors.New("only the secretary of the technical committee may publish its work programme")
+	ErrWorkProgrammeNotDraft     = errors.New("only draft versions of the work programme can be published")
+	workProgrammeStatuses        = []models.ProjectStatus{models.ProjectActive, models.ProjectOnHold}
+	workProgrammeStageHistory    = func(db *gorm.DB) *gorm.DB { return db.Order("started_at ASC") }
+)
+
+// GetWorkProgramme builds the work programme of a technical committee from the live state of its
+// projects in progress, including those on hold
+func (r *ProjectRepository) GetWorkProgramme(committeeID string) (*models.WorkProgramme, error) {
+	return workProgrammeWithTx(r.db, committeeID, time.Now())
+}
+
+func workProgrammeWithTx(tx *gorm.DB, committeeID string, now time.Time) (*models.WorkProgramme, error) {
+	var committee models.TechnicalCommittee
+	if err := tx.Select("id", "code", "name").First(&committee, "id = ?", committeeID).Error; err != nil {
+		return nil, err
+	}
+
+	var projects []models.Project
+	if err := tx.Preload("Stage").
+		Preload("WorkingGroup").
+		Preload("Acceptance").
+		Preload("StagePlans", func(db *gorm.DB) *gorm.DB {
+			return db.Order("stage_number ASC")
+		}).
+		Preload("StagePlans.Stage").
+		Preload("StageHistory", workProgrammeStageHistory).
+		Where("technical_committee_id = ? AND published = ? AND cancelled = ? AND status IN ?", committeeID, false, false, workProgrammeStatuses).
+		Order("reference ASC").
+		Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	programme := models.WorkProgramme{
+		TechnicalCommitteeID: committee.ID.String(),
+		CommitteeCode:        committee.Code,
+		CommitteeName:        committee.Name,
+		GeneratedAt:          now,
+		Items:                make([]models.WorkProgrammeItem, 0, len(projects)),
+	}
+
+	var stages []models.Stage
+	for i := range projects {
+		plans := projects[i].StagePlans
+		if len(plans) == 0 {
+			// Projects without a stored plan are planned in memory from their creation date
+			if stages == nil {
+				var err error
+				if stages, err = loadStagesWithTimeframesWithTx(tx); err != nil {
+					return nil, err
+				}
+			}
+			plans = models.PlanProjectStages(&projects[i], stages, projects[i].CreatedAt)
+			for j := range plans {
+				for k := range stages {
+					if stages[k].Number == plans[j].StageNumber {
+						plans[j].Stage = &stages[k]
+					}
+				}
+			}
+		}
+
+		sla := models.EvaluateProjectSLA(&projects[i], plans, projects[i].StageHistory, now)
+		item := models.BuildWorkProgrammeItem(&projects[i], sla, now)
+		if item.SlippageDays > 0 {
+			programme.BehindSchedule++
+		}
+		programme.Items = append(programme.Items, item)
+	}
+	return &programme, nil
+}
+
+// CreateWorkProgrammeSnapshot records the current work programme of a technical committee as its
+// next draft version
+func (r *ProjectRepository) CreateWorkProgrammeSnapshot(committeeID, memberID string) (*models.WorkProgrammeSnapshot, error) {
+	var snapshot *models.WorkProgrammeSnapshot
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		programme, err := workProgrammeWithTx(tx, committeeID, now)
+		if err != nil {
+			return err
+		}
+
+		version, err := nextNumberWithTx(tx, models.WorkProgrammeScope(committeeID))
+		if err != nil {
+			return err
+		}
+
+		snapshot = &models.WorkProgrammeSnapshot{
+			ID:                   uuid.New(),
+			TechnicalCommitteeID: committeeID,
+			Version:              version,
+			Status:               models.WorkProgrammeDraft,
+			Programme:            *programme,
+			GeneratedByID:        memberID,
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		}
+		return tx.Create(snapshot).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// PublishWorkProgrammeSnapshot publishes a draft version of a work programme in place of the version
+// published before it. The committee's work programme text is replaced with the published version.
+func (r *ProjectRepository) PublishWorkProgrammeSnapshot(snapshotID uuid.UUID, secretary string) (*models.WorkProgrammeSnapshot, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var snapshot models.WorkProgrammeSnapshot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&snapshot, "id = ?", snapshotID).Error; err != nil {
+			return err
+		}
+		if snapshot.Status != models.WorkProgrammeDraft {
+			return ErrWorkProgrammeNotDraft
+		}
+
+		isSecretary, err := isSecretaryOfWithTx(tx, secretary, snapshot.TechnicalCommitteeID)
+		if err != nil {
+			return err
+		}
+		if !isSecretary {
+			return ErrNotWorkProgrammeSecretary
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.WorkProgrammeSnapshot{}).
+			Where("technical_committee_id = ? AND status = ?", snapshot.TechnicalCommitteeID, models.WorkProgrammePublished).
+			Updates(map[string]interface{}{"status": models.WorkProgrammeSuperseded, "updated_at": now}).Error; err != nil {
+			return err
+		}
+
+		snapshot.Status = models.WorkProgrammePublished
+		snapshot.PublishedByID = &secretary
+		snapshot.PublishedAt = &now
+		snapshot.UpdatedAt = now
+		if err := tx.Omit(clause.Associations).Save(&snapshot).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.TechnicalCommittee{}).Where("id = ?", snapshot.TechnicalCommitteeID).
+			Update("work_program", snapshot.Programme.Text()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetWorkProgrammeSnapshot(snapshotID)
+}
+
+func (r *ProjectRepository) GetWorkProgrammeSnapshot(id uuid.UUID) (*models.WorkProgrammeSnapshot, error) {
+	var snapshot models.WorkProgrammeSnapshot
+	err := r.db.Preload("GeneratedBy").
+		Preload("PublishedBy").
+		First(&snapshot, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetWorkProgrammeSnapshots lists the versions of a committee's work programme, latest first,
+// without their content
+func (r *ProjectRepository) GetWorkProgrammeSnapshots(committeeID string) ([]models.WorkProgrammeSnapshot, error) {
+	var snapshots []models.WorkProgrammeSnapshot
+	err := r.db.Omit("programme").
+		Preload("GeneratedBy").
+		Preload("PublishedBy").
+		Where("technical_committee_id = ?", committeeID).
+		Order("version DESC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// GetPublishedWorkProgramme returns the version of a committee's work programme currently published
+func (r *ProjectRepository) GetPublishedWorkProgramme(committeeID string) (*models.WorkProgrammeSnapshot, error) {
+	var snapshot models.WorkProgrammeSnapshot
+	err := r.db.Preload("PublishedBy").
+		Where("technical_committee_id = ? AND status = ?", committeeID, models.WorkProgrammePublished).
+		First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -66,6 +66,15 @@ type Acceptance struct {
 	ApprovalCriteriaMet bool `json:"approval_criteria_met"`
 	IsApproved          bool `json:"is_approved"`
 
+	// Compilation of the results when the circulation closes
+	// @Description Explains the outcome of the acceptance rule applied to the responses
+	CriteriaComments string     `json:"criteria_comments,omitempty"`
+	AcceptanceRuleID *string    `json:"acceptance_rule_id,omitempty"` // Empty when the built-in default rule was applied
+	CompiledAt       *time.Time `json:"compiled_at,omitempty"`
+
+	// @Description Records when the proposal was put to the SMC for approval after meeting the criteria
+	SMCApprovalOpenedAt *time.Time `json:"smc_approval_opened_at,omitempty"`
+
 	// Draft status
 	// @Description Indicates the current drafting stage of the proposal
 	DraftStatus DraftStatus `json:"draft_status" gorm:"default:NONE" binding:"required"`
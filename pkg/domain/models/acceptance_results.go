@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // AcceptanceResults represents the aggregated results of a Acceptance
 type AcceptanceResults struct {
 	AcceptanceID           string                  `json:"accepatance_id"`
@@ -22,6 +24,7 @@ type IndividualNSBResponse struct {
 	AcceptedAsCD           string `json:"accepted_as_cd"`           // Y/N
 	AcceptedAsDARF         string `json:"accepted_as_dars"`         // Y/N
 	CommentsEnclosed       bool   `json:"comments_enclosed"`
+	Comments               string `json:"comments,omitempty"`
 	Participation          bool   `json:"participation"`
 }
 
@@ -40,3 +43,18 @@ type ResponseTotals struct {
 	CommentsCount               int `json:"comments_count"`
 	ParticipationCount          int `json:"participation_count"`
 }
+
+// AcceptanceMatrix is the compilation of the NSB responses to a proposal in the layout circulated to
+// the SMC, with the rule the responses were evaluated against
+type AcceptanceMatrix struct {
+	ProjectID       string            `json:"project_id"`
+	Reference       string            `json:"reference"`
+	Title           string            `json:"title"`
+	CommitteeCode   string            `json:"committee_code"`
+	CommitteeName   string            `json:"committee_name"`
+	CirculationDate time.Time         `json:"circulation_date"`
+	ClosingDate     time.Time         `json:"closing_date"`
+	Results         AcceptanceResults `json:"results"`
+	Rule            AcceptanceRule    `json:"rule"`
+	GeneratedAt     time.Time         `json:"generated_at"`
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AcceptanceBasis is the kind of proposal an acceptance rule applies to
+type AcceptanceBasis string
+
+const (
+	AcceptanceBasisAdoption    AcceptanceBasis = "ADOPTION"    // The proposal is based on an existing international standard
+	AcceptanceBasisDevelopment AcceptanceBasis = "DEVELOPMENT" // The committee drafts the standard itself
+)
+
+// ProposalAcceptanceBasis returns the basis of the rule that decides the acceptance of the proposal
+func ProposalAcceptanceBasis(proposal *Proposal) AcceptanceBasis {
+	if proposal != nil && proposal.ExistingIntlStandard {
+		return AcceptanceBasisAdoption
+	}
+	return AcceptanceBasisDevelopment
+}
+
+// AcceptanceRule decides whether the NSB responses to a new work item proposal accept it. Rules
+// are versioned like voting rules: saving a rule creates a new version so past compilations can be
+// reproduced.
+type AcceptanceRule struct {
+	ID                      uuid.UUID       `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Basis                   AcceptanceBasis `json:"basis" gorm:"uniqueIndex:idx_acceptance_rule_version" binding:"required,oneof=ADOPTION DEVELOPMENT"`
+	Version                 int             `json:"version" gorm:"uniqueIndex:idx_acceptance_rule_version"`
+	MinimumVotes            int             `json:"minimum_votes" binding:"gte=0"`           // Responses other than abstentions
+	ApprovalThreshold       float64         `json:"approval_threshold" binding:"gte=0,lt=1"` // Share of the votes in favour that must be exceeded, zero for none
+	MinimumParticipants     int             `json:"minimum_participants" binding:"gte=0"`    // NSBs committed to take part in the work
+	ParticipantsMustApprove bool            `json:"participants_must_approve"`               // Only participants voting in favour count
+	Active                  bool            `json:"active" gorm:"default:true"`              // Only the latest version is active
+	Notes                   string          `json:"notes"`
+	CreatedByID             *string         `json:"created_by_id"`
+	CreatedBy               *Member         `json:"created_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	CreatedAt               time.Time       `json:"created_at"`
+}
+
+// DefaultAcceptanceRule is applied when no rule has been configured for the basis. Adoptions need
+// more than half of at least six votes in favour with a favourable participant; developments need
+// six votes and three participants.
+func DefaultAcceptanceRule(basis AcceptanceBasis) AcceptanceRule {
+	if basis == AcceptanceBasisAdoption {
+		return AcceptanceRule{
+			Basis:                   basis,
+			MinimumVotes:            6,
+			ApprovalThreshold:       0.5,
+			MinimumParticipants:     1,
+			ParticipantsMustApprove: true,
+			Active:                  true,
+			Notes:                   "Built-in default",
+		}
+	}
+	return AcceptanceRule{
+		Basis:               AcceptanceBasisDevelopment,
+		MinimumVotes:        6,
+		MinimumParticipants: 3,
+		Active:              true,
+		Notes:               "Built-in default",
+	}
+}
+
+// Summary states the criteria of the rule in words
+func (rule *AcceptanceRule) Summary() string {
+	summary := fmt.Sprintf("At least %d votes", rule.MinimumVotes)
+	if rule.ApprovalThreshold > 0 {
+		summary += fmt.Sprintf(", more than %.1f%% in favour", rule.ApprovalThreshold*100)
+	}
+	if rule.MinimumParticipants > 0 {
+		summary += fmt.Sprintf(", at least %d NSBs committed to participate", rule.MinimumParticipants)
+		if rule.ParticipantsMustApprove {
+			summary += " while voting in favour"
+		}
+	}
+	return summary
+}
+
+// Evaluate applies the rule to the responses and explains the outcome
+func (rule *AcceptanceRule) Evaluate(responses []IndividualNSBResponse) (bool, string) {
+	var votes, favourable, participants int
+	for _, response := range responses {
+		if response.Abstention {
+			continue
+		}
+		votes++
+		if response.FeasibleYes {
+			favourable++
+		}
+		if response.Participation && (response.FeasibleYes || !rule.ParticipantsMustApprove) {
+			participants++
+		}
+	}
+
+	if votes < rule.MinimumVotes {
+		return false, fmt.Sprintf("Criteria not met: Only %d P-members voted (minimum %d required)", votes, rule.MinimumVotes)
+	}
+
+	var share float64
+	if votes > 0 {
+		share = float64(favourable) / float64(votes)
+	}
+	if rule.ApprovalThreshold > 0 && share <= rule.ApprovalThreshold {
+		return false, fmt.Sprintf("Criteria not met: Only %.1f%% of P-members voted in favor (more than %.1f%% required)", share*100, rule.ApprovalThreshold*100)
+	}
+
+	if participants < rule.MinimumParticipants {
+		return false, fmt.Sprintf("Criteria not met: Only %d members willing to participate actively (minimum %d required)", participants, rule.MinimumParticipants)
+	}
+
+	return true, fmt.Sprintf("Criteria met: %d P-members voted, %.1f%% in favor, with %d members willing to participate actively", votes, share*100, participants)
+}
+
+// CirculationOverride lets an NSB respond to a proposal outside its circulation window, e.g. when
+// its response was held up. Only the TC secretariat can grant an override.
+type CirculationOverride struct {
+	ID                     uuid.UUID             `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	AcceptanceID           string                `json:"acceptance_id" gorm:"type:uuid;index"`
+	NationalStandardBodyID string                `json:"national_standard_body_id" gorm:"type:uuid;index"`
+	NationalStandardBody   *NationalStandardBody `json:"national_standard_body,omitempty"`
+	Reason                 string                `json:"reason"`
+	ExpiresAt              time.Time             `json:"expires_at"`
+	GrantedByID            string                `json:"granted_by_id"`
+	GrantedBy              *Member               `json:"granted_by,omitempty"`
+	CreatedAt              time.Time             `json:"created_at"`
+}
+
+// HasCirculationWindow reports whether responses to the proposal are limited to a circulation
+// window. Acceptances opened before windows were introduced have none.
+func (a *Acceptance) HasCirculationWindow() bool {
+	return !a.ClosingDate.IsZero()
+}
+
+// InCirculationWindow reports whether the proposal is circulating at the given time
+func (a *Acceptance) InCirculationWindow(now time.Time) bool {
+	return !a.HasCirculationWindow() || (!now.Before(a.CirculationDate) && now.Before(a.ClosingDate))
+}
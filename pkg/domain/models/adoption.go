@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EquivalenceDegree is how closely an adopted standard corresponds to the international document
+type EquivalenceDegree string
+
+const (
+	EquivalenceIdentical     EquivalenceDegree = "IDT" // Identical, at most editorial changes
+	EquivalenceModified      EquivalenceDegree = "MOD" // Technical deviations are identified and explained
+	EquivalenceNotEquivalent EquivalenceDegree = "NEQ" // Not equivalent, the correspondence is not clearly identified
+)
+
+var ErrInvalidEquivalence = errors.New("degree of equivalence must be IDT, MOD or NEQ")
+
+// Validate rejects degrees other than IDT, MOD and NEQ
+func (d EquivalenceDegree) Validate() error {
+	switch d {
+	case EquivalenceIdentical, EquivalenceModified, EquivalenceNotEquivalent:
+		return nil
+	}
+	return ErrInvalidEquivalence
+}
+
+// SourceOrganisation is the body that published an adopted international document
+type SourceOrganisation string
+
+const (
+	SourceISO    SourceOrganisation = "ISO"
+	SourceIEC    SourceOrganisation = "IEC"
+	SourceISOIEC SourceOrganisation = "ISO/IEC"
+	SourceCodex  SourceOrganisation = "CODEX"
+)
+
+var ErrInvalidSourceOrganisation = errors.New("source organisation must be ISO, IEC, ISO/IEC or CODEX")
+
+// Validate rejects organisations whose documents are not adopted
+func (o SourceOrganisation) Validate() error {
+	switch o {
+	case SourceISO, SourceIEC, SourceISOIEC, SourceCodex:
+		return nil
+	}
+	return ErrInvalidSourceOrganisation
+}
+
+// AdoptedStandard identifies the international document a project adopts and its degree of
+// equivalence. It is kept with the project for the library and for reports.
+type AdoptedStandard struct {
+	ID              uuid.UUID          `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID       string             `json:"project_id" gorm:"type:uuid;uniqueIndex"`
+	Project         *Project           `json:"project,omitempty"`
+	Organisation    SourceOrganisation `json:"organisation" gorm:"index"`
+	SourceReference string             `json:"source_reference" gorm:"index"` // e.g. ISO 22000:2018
+	SourceTitle     string             `json:"source_title"`
+	PublicationYear int                `json:"publication_year"`
+	Equivalence     EquivalenceDegree  `json:"equivalence" gorm:"index"`
+	Deviations      string             `json:"deviations"`  // Technical deviations of a MOD adoption or differences of a NEQ one
+	DocumentID      *string            `json:"document_id"` // Copy of the international document circulated for enquiry
+	Document        *Document          `json:"document,omitempty"`
+	RegisteredByID  string             `json:"registered_by_id"`
+	RegisteredBy    *Member            `json:"registered_by,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
@@ -26,6 +26,9 @@ const (
 	ActionProjectApprove     ActionType = "PROJECT_APPROVE"
 	ActionProjectReject      ActionType = "PROJECT_REJECT"
 	ActionProjectCancel      ActionType = "PROJECT_CANCEL"
+	ActionProjectWithdraw    ActionType = "PROJECT_WITHDRAW"
+	ActionProjectSuspend     ActionType = "PROJECT_SUSPEND"
+	ActionProjectReinstate   ActionType = "PROJECT_REINSTATE"
 	ActionProjectPublish     ActionType = "PROJECT_PUBLISH"
 
 	// Ballot actions
@@ -18,16 +18,26 @@ const (
 	TR                     FDARSAction = "Publish Technical Report (TR)"
 	Guide                  FDARSAction = "Publish Guide"
 	CANCELLED              FDARSAction = "CANCELLED"
+	PROCEED_TO_APPROVAL    FDARSAction = "PROCEED_TO_APPROVAL" // Proposed when the ballot met the acceptance criteria
 )
 
 // AcceptanceCriteriaResult represents the result of checking project acceptance criteria
 type AcceptanceCriteriaResult struct {
-	CriteriaMet    bool    `json:"criteria_met"`
-	AcceptanceRate float64 `json:"acceptance_rate"`
-	RequiredRate   float64 `json:"required_rate"`
-	TotalVotes     int64   `json:"total_votes"`
-	AcceptedVotes  int64   `json:"accepted_votes"`
-	Message        string  `json:"message"`
+	CriteriaMet      bool       `json:"criteria_met"`
+	QuorumMet        bool       `json:"quorum_met"`
+	AcceptanceRate   float64    `json:"acceptance_rate"`
+	RequiredRate     float64    `json:"required_rate"`
+	NegativeShare    float64    `json:"negative_share"`
+	MaxNegativeShare float64    `json:"max_negative_share"`
+	TotalVotes       int64      `json:"total_votes"`
+	PMemberVotes     int64      `json:"p_member_votes"`
+	AcceptedVotes    int64      `json:"accepted_votes"`
+	DisapprovedVotes int64      `json:"disapproved_votes"`
+	Abstentions      int64      `json:"abstentions"`
+	RuleID           *uuid.UUID `json:"rule_id"` // Nil when the built-in default rule was applied
+	RuleProcedure    Procedure  `json:"rule_procedure"`
+	RuleVersion      int        `json:"rule_version"`
+	Message          string     `json:"message"`
 }
 
 type Balloting struct {
@@ -49,6 +59,33 @@ type Balloting struct {
 	ApprovedBy         Member      `json:"approved_by" gorm:"constraint:OnDelete:SET NULL"`
 	ApprovedAt         *time.Time  `json:"approved_at"`
 	NextCourseOfAction FDARSAction `json:"next_course_of_action"`
-	CreatedAt          time.Time   `json:"created_at"`
-	UpdatedAt          time.Time   `json:"updated_at"`
+	ClosedAt           *time.Time  `json:"closed_at"` // Set when the ballot closes at its end date
+	// Outcome of the acceptance criteria check taken when the ballot closed
+	Result    *AcceptanceCriteriaResult `json:"result,omitempty" gorm:"serializer:json"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+// IsClosed reports whether the ballot no longer accepts votes
+func (b *Balloting) IsClosed() bool {
+	return !b.Active || b.ClosedAt != nil || time.Now().After(b.EndDate)
+}
+
+// ProposeNextCourseOfAction suggests what should happen to the FDARS given the ballot result.
+// The secretariat confirms or overrides the proposal when approving the FDARS.
+func ProposeNextCourseOfAction(result *AcceptanceCriteriaResult) FDARSAction {
+	switch {
+	case result.CriteriaMet:
+		return PROCEED_TO_APPROVAL
+	case !result.QuorumMet:
+		// Too few votes to judge the draft, ballot it again
+		return RESUBMIT_FDARS
+	case result.AcceptanceRate >= 0.5:
+		// Majority support but short of the threshold, the comments can be resolved in a revised FDARS
+		return RESUBMIT_FDARS
+	case result.AcceptedVotes > 0:
+		return RESUBMIT_ENQUIRY_DRAFT
+	default:
+		return RESUBMIT_CD
+	}
 }
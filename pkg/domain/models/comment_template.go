@@ -0,0 +1,255 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CommentTemplateColumns are the columns of the ISO template for comments and secretariat
+// observations, in the order of the form
+var CommentTemplateColumns = []string{
+	"MB/NC",
+	"Line number",
+	"Clause/Subclause",
+	"Paragraph/Figure/Table/Note",
+	"Type of comment",
+	"Comments",
+	"Proposed change",
+	"Observations of the secretariat",
+}
+
+// CommentTemplateRow is one line of a comment template. On import Row is the line of the sheet or
+// table it was read from.
+type CommentTemplateRow struct {
+	Row                int         `json:"row"`
+	MemberBody         string      `json:"member_body"`
+	LineNumber         string      `json:"line_number"`
+	ClauseNo           string      `json:"clause_no"`
+	ParagraphRef       string      `json:"paragraph_ref"`
+	CommentType        CommentType `json:"comment_type"`
+	Comment            string      `json:"comment"`
+	ProposedChange     string      `json:"proposed_change"`
+	SecretariatRemarks string      `json:"secretariat_remarks"`
+}
+
+// CommentTemplateError reports a problem with a line of an imported comment template
+type CommentTemplateError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// CommentTemplateReport is the result of reading a comment template. Nothing is imported while it
+// has errors.
+type CommentTemplateReport struct {
+	DryRun    bool                   `json:"dry_run"`
+	FileName  string                 `json:"file_name"`
+	TotalRows int                    `json:"total_rows"`
+	ValidRows int                    `json:"valid_rows"`
+	Imported  int                    `json:"imported"`
+	Errors    []CommentTemplateError `json:"errors"`
+	Rows      []CommentTemplateRow   `json:"rows"`
+}
+
+// CommentTemplate is the compilation of the comments on a project's draft in the layout of the
+// comment template
+type CommentTemplate struct {
+	ProjectID     string               `json:"project_id"`
+	Reference     string               `json:"reference"`
+	Title         string               `json:"title"`
+	CommitteeCode string               `json:"committee_code"`
+	Rows          []CommentTemplateRow `json:"rows"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+}
+
+// ParseCommentType reads the type of a comment as written in the template, either as its code or
+// in full
+func ParseCommentType(value string) (CommentType, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "ge", "general":
+		return General, true
+	case "te", "technical":
+		return Technical, true
+	case "ed", "editorial":
+		return Editorial, true
+	}
+	return "", false
+}
+
+// Validate checks a line against the rules of the comment form
+func (row *CommentTemplateRow) Validate() []CommentTemplateError {
+	var errs []CommentTemplateError
+	required := []struct{ Column, Value string }{
+		{CommentTemplateColumns[2], row.ClauseNo},
+		{CommentTemplateColumns[3], row.ParagraphRef},
+		{CommentTemplateColumns[5], row.Comment},
+	}
+	for _, field := range required {
+		if field.Value == "" {
+			errs = append(errs, CommentTemplateError{Row: row.Row, Column: field.Column, Message: "is required"})
+		}
+	}
+	if _, ok := ParseCommentType(string(row.CommentType)); !ok {
+		errs = append(errs, CommentTemplateError{Row: row.Row, Column: CommentTemplateColumns[4], Message: "must be ge, te or ed"})
+	}
+	return errs
+}
+
+// ValidateUpload checks a line of a template uploaded by an NSB. The MB/NC, when given, must be the
+// uploader's and is filled in when left blank. The observations of the secretariat are left to the
+// secretariat.
+func (row *CommentTemplateRow) ValidateUpload(uploader *Member) []CommentTemplateError {
+	var errs []CommentTemplateError
+	memberBody := commentMemberBody(uploader)
+	switch {
+	case memberBody == "":
+		errs = append(errs, CommentTemplateError{Row: row.Row, Column: CommentTemplateColumns[0], Message: "you must belong to an NSB to submit comments"})
+	case row.MemberBody == "":
+		row.MemberBody = memberBody
+	case !isMemberBody(uploader, row.MemberBody):
+		errs = append(errs, CommentTemplateError{Row: row.Row, Column: CommentTemplateColumns[0], Message: "must be your own member body, " + memberBody})
+	default:
+		row.MemberBody = memberBody
+	}
+	if row.SecretariatRemarks != "" {
+		errs = append(errs, CommentTemplateError{Row: row.Row, Column: CommentTemplateColumns[7], Message: "is filled in by the secretariat and must be left blank"})
+	}
+	return errs
+}
+
+// SortByClause orders the rows as they appear in the draft: by clause, then paragraph, figure or
+// table, then member body
+func (t *CommentTemplate) SortByClause() {
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		a, b := t.Rows[i], t.Rows[j]
+		if c := CompareClauses(a.ClauseNo, b.ClauseNo); c != 0 {
+			return c < 0
+		}
+		if c := CompareClauses(a.ParagraphRef, b.ParagraphRef); c != 0 {
+			return c < 0
+		}
+		return a.MemberBody < b.MemberBody
+	})
+}
+
+// CompareClauses compares two clause references so that numbered parts sort by value, e.g. 4.2
+// before 4.10 and Table 2 before Table 10
+func CompareClauses(a, b string) int {
+	x, y := clauseParts(a), clauseParts(b)
+	for i := 0; i < len(x) && i < len(y); i++ {
+		p, q := x[i], y[i]
+		pn, perr := strconv.Atoi(p)
+		qn, qerr := strconv.Atoi(q)
+		switch {
+		case perr == nil && qerr == nil:
+			if pn != qn {
+				if pn < qn {
+					return -1
+				}
+				return 1
+			}
+		case perr == nil:
+			return -1
+		case qerr == nil:
+			return 1
+		default:
+			if c := strings.Compare(p, q); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(x) - len(y)
+}
+
+// clauseParts splits a clause reference into its runs of digits and of letters
+func clauseParts(clause string) []string {
+	var parts []string
+	var current []rune
+	digits := false
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, string(current))
+			current = current[:0]
+		}
+	}
+	for _, r := range strings.ToLower(clause) {
+		switch {
+		case unicode.IsDigit(r):
+			if !digits {
+				flush()
+			}
+			digits = true
+			current = append(current, r)
+		case unicode.IsLetter(r):
+			if digits {
+				flush()
+			}
+			digits = false
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return parts
+}
+
+// TemplateRow writes the comment as a line of the comment template
+func (c *CommentObservation) TemplateRow() CommentTemplateRow {
+	return CommentTemplateRow{
+		MemberBody:         commentMemberBody(c.NationalSecretary),
+		LineNumber:         c.LineNumber,
+		ClauseNo:           c.ClauseNo,
+		ParagraphRef:       c.ParagraphRef,
+		CommentType:        c.CommentType,
+		Comment:            c.Comment,
+		ProposedChange:     c.ProposedChange,
+		SecretariatRemarks: c.SecretariatRemarks,
+	}
+}
+
+// TemplateRow writes the consultation comment as a line of the comment template
+func (c *NationalConsultation) TemplateRow() CommentTemplateRow {
+	return CommentTemplateRow{
+		MemberBody:         commentMemberBody(c.NationalSecretary),
+		LineNumber:         c.LineNumber,
+		ClauseNo:           c.ClauseNo,
+		ParagraphRef:       c.ParagraphRef,
+		CommentType:        c.CommentType,
+		Comment:            c.Comment,
+		ProposedChange:     c.ProposedChange,
+		SecretariatRemarks: c.SecretariatRemarks,
+	}
+}
+
+// commentMemberBody is the MB/NC code of the member who made a comment: the code of their member
+// state, or the name of their NSB when it has none
+func commentMemberBody(member *Member) string {
+	if member == nil || member.NationalStandardBody == nil {
+		return ""
+	}
+	if state := member.NationalStandardBody.MemberState; state != nil && state.Code != "" {
+		return state.Code
+	}
+	return member.NationalStandardBody.Name
+}
+
+// isMemberBody reports whether an MB/NC entry names the member's NSB, by the code or name of its
+// member state or by its own name
+func isMemberBody(member *Member, value string) bool {
+	value = strings.TrimSpace(value)
+	nsb := member.NationalStandardBody
+	names := []string{nsb.Name}
+	if nsb.MemberState != nil {
+		names = append(names, nsb.MemberState.Code, nsb.MemberState.Name)
+	}
+	for _, name := range names {
+		if name != "" && strings.EqualFold(name, value) {
+			return true
+		}
+	}
+	return false
+}
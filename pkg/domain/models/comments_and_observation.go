@@ -15,18 +15,24 @@ const (
 	Editorial CommentType = "ed" // Editorial comment
 )
 
+// CommentReference locates a comment in the draft and classifies it
+type CommentReference struct {
+	ClauseNo     string      `json:"clause_no" binding:"required"`
+	ParagraphRef string      `json:"paragraph_ref" binding:"required"`
+	CommentType  CommentType `json:"comment_type" binding:"required"`
+}
+
 // CommentObservation represents a single comment and observation entry
 type CommentObservation struct {
-	ID                  uuid.UUID   `json:"id"`
-	ProjectID           string      `json:"project_id" binding:"required"`
-	Project             *Project    `json:"project"`
-	NationalSecretaryID string      `json:"national_secretary_id"`
-	NationalSecretary   *Member     `json:"national_secretary"`
-	ClauseNo            string      `json:"clause_no" binding:"required"`
-	ParagraphRef        string      `json:"paragraph_ref" binding:"required"`
-	CommentType         CommentType `json:"comment_type" binding:"required"`
-	Comment             string      `json:"comment" binding:"required"`
-	ProposedChange      string      `json:"proposed_change"`
-	SecretariatRemarks  string      `json:"secretariat_remarks"`
-	CreatedAt           time.Time   `json:"created_at"`
+	ID                  uuid.UUID `json:"id"`
+	ProjectID           string    `json:"project_id" binding:"required"`
+	Project             *Project  `json:"project"`
+	NationalSecretaryID string    `json:"national_secretary_id"`
+	NationalSecretary   *Member   `json:"national_secretary"`
+	CommentReference
+	LineNumber         string    `json:"line_number"` // Line of the draft, as given in the comment template
+	Comment            string    `json:"comment" binding:"required"`
+	ProposedChange     string    `json:"proposed_change"`
+	SecretariatRemarks string    `json:"secretariat_remarks"`
+	CreatedAt          time.Time `json:"created_at"`
 }
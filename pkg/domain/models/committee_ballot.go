@@ -0,0 +1,212 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommitteeBallotSubject is what a committee internal ballot decides
+type CommitteeBallotSubject string
+
+const (
+	CommitteeBallotNewWorkItem    CommitteeBallotSubject = "NEW_WORK_ITEM"
+	CommitteeBallotConvenor       CommitteeBallotSubject = "CONVENOR"
+	CommitteeBallotCDRegistration CommitteeBallotSubject = "CD_REGISTRATION"
+	CommitteeBallotOther          CommitteeBallotSubject = "OTHER"
+)
+
+type CommitteeBallotStatus string
+
+const (
+	CommitteeBallotOpen      CommitteeBallotStatus = "OPEN"
+	CommitteeBallotClosed    CommitteeBallotStatus = "CLOSED"
+	CommitteeBallotCancelled CommitteeBallotStatus = "CANCELLED"
+)
+
+type CommitteeBallotEventType string
+
+const (
+	CommitteeBallotOpened      CommitteeBallotEventType = "OPENED"
+	CommitteeBallotVoted       CommitteeBallotEventType = "VOTED"
+	CommitteeBallotVoteChanged CommitteeBallotEventType = "VOTE_CHANGED"
+	CommitteeBallotClosedEvent CommitteeBallotEventType = "CLOSED"
+	CommitteeBallotCancelEvent CommitteeBallotEventType = "CANCELLED"
+)
+
+// CommitteeBallot is a vote by correspondence of a technical committee's P-members on a decision
+// outside the FDARS ballot, such as approving a new work item or confirming a convenor. The voters
+// are the P-members when the ballot opens. A ballot may be linked to a project or to a meeting;
+// a ballot linked to a meeting that is carried is issued the meeting's next resolution number.
+type CommitteeBallot struct {
+	ID                   uuid.UUID               `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	TechnicalCommitteeID string                  `json:"technical_committee_id" gorm:"type:uuid;index"`
+	TechnicalCommittee   *TechnicalCommittee     `json:"technical_committee,omitempty"`
+	Number               int64                   `json:"number"` // Counts the committee's internal ballots, from 1
+	Subject              CommitteeBallotSubject  `json:"subject"`
+	Title                string                  `json:"title"`
+	Question             string                  `json:"question"`
+	ProjectID            *string                 `json:"project_id" gorm:"type:uuid;index"`
+	Project              *Project                `json:"project,omitempty"`
+	MeetingID            *string                 `json:"meeting_id" gorm:"type:uuid;index"`
+	Meeting              *Meeting                `json:"meeting,omitempty"`
+	OpensAt              time.Time               `json:"opens_at"`
+	ClosesAt             time.Time               `json:"closes_at" gorm:"index"`
+	ApprovalThreshold    float64                 `json:"approval_threshold"`  // Share of counted votes the leading option needs
+	QuorumShare          float64                 `json:"quorum_share"`        // Share of the voters who must vote, abstentions included
+	ExcludeAbstentions   bool                    `json:"exclude_abstentions"` // Abstentions are left out of the counted votes
+	Status               CommitteeBallotStatus   `json:"status" gorm:"index"`
+	Options              []CommitteeBallotOption `json:"options,omitempty" gorm:"foreignKey:BallotID"`
+	Voters               []CommitteeBallotVoter  `json:"voters,omitempty" gorm:"foreignKey:BallotID"`
+	Votes                []CommitteeBallotVote   `json:"votes,omitempty" gorm:"foreignKey:BallotID"`
+	Events               []CommitteeBallotEvent  `json:"events,omitempty" gorm:"foreignKey:BallotID"`
+	Result               *CommitteeBallotResult  `json:"result" gorm:"serializer:json"`
+	ResolutionNumber     *int64                  `json:"resolution_number"`
+	CreatedByID          string                  `json:"created_by_id"`
+	CreatedBy            *Member                 `json:"created_by,omitempty"`
+	ClosedByID           *string                 `json:"closed_by_id"` // Empty when the ballot closed at its closing date
+	ClosedBy             *Member                 `json:"closed_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	ClosedAt             *time.Time              `json:"closed_at"`
+	CancellationReason   string                  `json:"cancellation_reason,omitempty"`
+	CreatedAt            time.Time               `json:"created_at"`
+	UpdatedAt            time.Time               `json:"updated_at"`
+}
+
+// CommitteeBallotOption is one of the answers a committee ballot offers
+type CommitteeBallotOption struct {
+	ID       uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	BallotID string    `json:"ballot_id" gorm:"type:uuid;index"`
+	Label    string    `json:"label"`
+	Position int       `json:"position"`
+}
+
+// CommitteeBallotVoter is an NSB of a P-member country when the ballot opened
+type CommitteeBallotVoter struct {
+	ID                     uuid.UUID             `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	BallotID               string                `json:"ballot_id" gorm:"type:uuid;uniqueIndex:idx_committee_ballot_voter"`
+	NationalStandardBodyID string                `json:"national_standard_body_id" gorm:"type:uuid;uniqueIndex:idx_committee_ballot_voter"`
+	NationalStandardBody   *NationalStandardBody `json:"national_standard_body,omitempty"`
+}
+
+// CommitteeBallotVote is the vote of an NSB, cast by its national TC secretary. It can be changed
+// while the ballot is open.
+type CommitteeBallotVote struct {
+	ID                     uuid.UUID              `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	BallotID               string                 `json:"ballot_id" gorm:"type:uuid;uniqueIndex:idx_committee_ballot_vote"`
+	NationalStandardBodyID string                 `json:"national_standard_body_id" gorm:"type:uuid;uniqueIndex:idx_committee_ballot_vote"`
+	NationalStandardBody   *NationalStandardBody  `json:"national_standard_body,omitempty"`
+	OptionID               *string                `json:"option_id" gorm:"type:uuid"` // Empty for an abstention
+	Option                 *CommitteeBallotOption `json:"option,omitempty" gorm:"foreignKey:OptionID"`
+	Abstain                bool                   `json:"abstain"`
+	Comment                string                 `json:"comment"`
+	MemberID               string                 `json:"member_id"` // Secretary who cast the vote
+	Member                 *Member                `json:"member,omitempty"`
+	CreatedAt              time.Time              `json:"created_at"`
+	UpdatedAt              time.Time              `json:"updated_at"`
+}
+
+// CommitteeBallotEvent records what happened to a committee ballot, so the ballot keeps its own
+// trail of opened, cast, changed, closed and cancelled votes
+type CommitteeBallotEvent struct {
+	ID                     uuid.UUID                `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	BallotID               string                   `json:"ballot_id" gorm:"type:uuid;index"`
+	Type                   CommitteeBallotEventType `json:"type"`
+	ActorID                *string                  `json:"actor_id"` // Empty for events of the scheduler
+	Actor                  *Member                  `json:"actor,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	NationalStandardBodyID *string                  `json:"national_standard_body_id"`
+	OptionID               *string                  `json:"option_id"`
+	Abstain                bool                     `json:"abstain"`
+	Notes                  string                   `json:"notes"`
+	CreatedAt              time.Time                `json:"created_at"`
+}
+
+// CommitteeBallotOptionResult holds the votes for one option of a closed ballot
+type CommitteeBallotOptionResult struct {
+	OptionID uuid.UUID `json:"option_id"`
+	Label    string    `json:"label"`
+	Votes    int64     `json:"votes"`
+	Share    float64   `json:"share"` // Share of the counted votes
+}
+
+// CommitteeBallotResult is the outcome of a committee ballot
+type CommitteeBallotResult struct {
+	EligibleVoters  int64                         `json:"eligible_voters"`
+	VotesCast       int64                         `json:"votes_cast"`
+	Abstentions     int64                         `json:"abstentions"`
+	CountedVotes    int64                         `json:"counted_votes"`
+	QuorumRequired  int64                         `json:"quorum_required"`
+	QuorumMet       bool                          `json:"quorum_met"`
+	Options         []CommitteeBallotOptionResult `json:"options"`
+	WinningOptionID *uuid.UUID                    `json:"winning_option_id"`
+	Carried         bool                          `json:"carried"`
+	Message         string                        `json:"message"`
+}
+
+// Tally determines the result of the ballot from its voters and votes. The leading option carries
+// the ballot when the quorum is met, no other option has as many votes and its share of the
+// counted votes reaches the approval threshold.
+func (b *CommitteeBallot) Tally() *CommitteeBallotResult {
+	result := &CommitteeBallotResult{
+		EligibleVoters: int64(len(b.Voters)),
+		VotesCast:      int64(len(b.Votes)),
+		QuorumRequired: int64(math.Ceil(b.QuorumShare * float64(len(b.Voters)))),
+		Options:        make([]CommitteeBallotOptionResult, 0, len(b.Options)),
+	}
+
+	counts := map[string]int64{}
+	for _, vote := range b.Votes {
+		if vote.Abstain || vote.OptionID == nil {
+			result.Abstentions++
+			continue
+		}
+		counts[*vote.OptionID]++
+	}
+
+	result.CountedVotes = result.VotesCast
+	if b.ExcludeAbstentions {
+		result.CountedVotes -= result.Abstentions
+	}
+
+	leading, tied := -1, false
+	for _, option := range b.Options {
+		optionResult := CommitteeBallotOptionResult{OptionID: option.ID, Label: option.Label, Votes: counts[option.ID.String()]}
+		if result.CountedVotes > 0 {
+			optionResult.Share = float64(optionResult.Votes) / float64(result.CountedVotes)
+		}
+		result.Options = append(result.Options, optionResult)
+
+		switch {
+		case leading < 0 || optionResult.Votes > result.Options[leading].Votes:
+			leading, tied = len(result.Options)-1, false
+		case optionResult.Votes == result.Options[leading].Votes:
+			tied = true
+		}
+	}
+
+	result.QuorumMet = result.VotesCast >= result.QuorumRequired
+	switch {
+	case !result.QuorumMet:
+		result.Message = fmt.Sprintf("Not carried: %d of the %d required votes were cast.", result.VotesCast, result.QuorumRequired)
+	case result.CountedVotes <= 0 || leading < 0 || result.Options[leading].Votes == 0:
+		result.Message = "Not carried: no votes were counted."
+	case tied:
+		result.Message = fmt.Sprintf("Not carried: options are tied with %d votes.", result.Options[leading].Votes)
+	case result.Options[leading].Share < b.ApprovalThreshold:
+		result.Message = fmt.Sprintf("Not carried: %q received %.1f%% of the votes (required: %.1f%%).",
+			result.Options[leading].Label, result.Options[leading].Share*100, b.ApprovalThreshold*100)
+	default:
+		optionID := result.Options[leading].OptionID
+		result.WinningOptionID = &optionID
+		result.Carried = true
+		result.Message = fmt.Sprintf("Carried: %q received %.1f%% of the votes (required: %.1f%%).",
+			result.Options[leading].Label, result.Options[leading].Share*100, b.ApprovalThreshold*100)
+	}
+	return result
+}
+
+// AcceptsVotes reports whether votes can be cast on the ballot at the given time
+func (b *CommitteeBallot) AcceptsVotes(now time.Time) bool {
+	return b.Status == CommitteeBallotOpen && !now.Before(b.OpensAt) && now.Before(b.ClosesAt)
+}
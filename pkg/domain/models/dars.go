@@ -42,3 +42,15 @@ type DARS struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// NewDARS opens the public review of a project's DARS at the given time. The review runs for two
+// months, with a week's grace for comments to arrive.
+func NewDARS(projectID string, start time.Time) DARS {
+	return DARS{
+		ID:                    uuid.New(),
+		ProjectID:             projectID,
+		CreatedAt:             start,
+		PublicReviewStartDate: start,
+		PublicReviewEndDate:   start.AddDate(0, 2, 7),
+	}
+}
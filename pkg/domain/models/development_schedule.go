@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NonWorkingPeriod is a span of days, such as an ARSO holiday, that does not count towards the
+// development time of a standard. Recurring periods fall on the same dates every year.
+type NonWorkingPeriod struct {
+	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Name        string    `json:"name" binding:"required"`
+	StartDate   time.Time `json:"start_date" binding:"required"`
+	EndDate     time.Time `json:"end_date" binding:"required"` // Inclusive
+	Recurring   bool      `json:"recurring"`
+	CreatedByID *string   `json:"created_by_id"`
+	CreatedBy   *Member   `json:"created_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Includes reports whether the day falls within the period
+func (p *NonWorkingPeriod) Includes(day time.Time) bool {
+	day = dateOf(day)
+	start, end := dateOf(p.StartDate), dateOf(p.EndDate)
+	if !p.Recurring {
+		return !day.Before(start) && !day.After(end)
+	}
+
+	// Try the occurrence starting this year and the one starting last year, which covers periods
+	// running over the new year
+	length := int(end.Sub(start).Hours() / 24)
+	for _, year := range []int{day.Year(), day.Year() - 1} {
+		from := time.Date(year, start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		if !day.Before(from) && !day.After(from.AddDate(0, 0, length)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkingCalendar is the set of non-working periods development time is counted against
+type WorkingCalendar []NonWorkingPeriod
+
+// IsWorkingDay reports whether the day falls outside every non-working period
+func (c WorkingCalendar) IsWorkingDay(day time.Time) bool {
+	for i := range c {
+		if c[i].Includes(day) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxCalendarDays bounds the search for working days so a calendar without any cannot loop forever
+const maxCalendarDays = 20 * 366
+
+// AddMonths returns the date the given number of months after start, counting only working days.
+// The calendar days in the months are taken as working days, so every non-working day on the way
+// pushes the date back by a day.
+func (c WorkingCalendar) AddMonths(start time.Time, months int) time.Time {
+	days := int(dateOf(start.AddDate(0, months, 0)).Sub(dateOf(start)).Hours() / 24)
+
+	day := start
+	for i := 0; days > 0 && i < maxCalendarDays; i++ {
+		day = day.AddDate(0, 0, 1)
+		if c.IsWorkingDay(day) {
+			days--
+		}
+	}
+	return day
+}
+
+// dateOf drops the time of day so periods compare by calendar date
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// trackMilestones are the months after SMC approval by which a project on the track must reach CD,
+// DARS and FDARS. Fast-tracked projects go straight to DARS, so they have no CD target.
+var trackMilestones = map[DevelopmentTrack][3]int{
+	TrackDefault:       {9, 15, 21},
+	TrackInternational: {2, 4, 7},
+	TrackFastTrack:     {0, 1, 3},
+}
+
+// Months is the time allowed to develop a standard on the track
+func (t DevelopmentTrack) Months() int {
+	milestones, ok := trackMilestones[t]
+	if !ok {
+		milestones = trackMilestones[TrackDefault]
+	}
+	return milestones[2]
+}
+
+// ScheduleTargets computes the CD, DARS and FDARS target dates from the development track and the
+// SMC approval date, skipping the non-working days of the calendar. Proposals not yet approved by
+// the SMC are left unscheduled and false is returned.
+func (a *Acceptance) ScheduleTargets(calendar WorkingCalendar) bool {
+	if a.SMCApprovalDate == nil {
+		return false
+	}
+
+	milestones, ok := trackMilestones[a.DevelopmentTrack]
+	if !ok {
+		milestones = trackMilestones[TrackDefault]
+	}
+
+	targets := make([]*time.Time, len(milestones))
+	for i, months := range milestones {
+		if months == 0 {
+			continue
+		}
+		target := calendar.AddMonths(*a.SMCApprovalDate, months)
+		targets[i] = &target
+	}
+	a.TargetDateCD, a.TargetDateDARS, a.TargetDateFDARS = targets[0], targets[1], targets[2]
+	return true
+}
@@ -15,6 +15,7 @@ type NationalConsultation struct {
 	DARS                *DARS       `json:"-"`
 	NationalSecretaryID string      `json:"national_secretary_id"`
 	NationalSecretary   *Member     `json:"national_secretary"`
+	LineNumber          string      `json:"line_number"` // Line of the draft, as given in the comment template
 	ClauseNo            string      `json:"clause_no" binding:"required"`
 	ParagraphRef        string      `json:"paragraph_ref" binding:"required"`
 	CommentType         CommentType `json:"comment_type" binding:"required"`
@@ -15,6 +15,9 @@ const (
 	NotificationProjectCreated   NotificationType = "PROJECT_CREATED"
 	NotificationProjectAssigned  NotificationType = "PROJECT_ASSIGNED"
 	NotificationProjectUpdated   NotificationType = "PROJECT_UPDATED"
+	NotificationProjectOnHold    NotificationType = "PROJECT_ON_HOLD"
+	NotificationProjectCancelled NotificationType = "PROJECT_CANCELLED"
+	NotificationProjectResumed   NotificationType = "PROJECT_REINSTATED"
 
 	// Ballot related notifications
 	NotificationBallotOpened     NotificationType = "BALLOT_OPENED"
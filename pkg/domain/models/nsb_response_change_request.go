@@ -14,17 +14,22 @@ const (
 	REJECTED Status = "REJECTED"
 )
 
+// NSBResponseStatusChange is a request of an NSB to change its answer to a new work item proposal
+// after submitting it. The TC secretariat approves or rejects the request; an approved request
+// replaces the answer of the initial response.
 type NSBResponseStatusChange struct {
 	ID                       uuid.UUID `json:"id"`
 	ResponderID              string    `json:"responder_id"`
 	Responder                *Member   `json:"responder"`
 	InitialResponseID        string    `json:"nsb_response_id"`
 	InitialResponse          *NSBResponse
-	Response                 Response  `json:"response" binding:"required"`
-	IsCommittedToParticipate bool      `json:"is_committed_to_participate"`
-	Status                   Status    `json:"status" gorm:"default:PENDING;"`
-	TCSecretariatID          *string   `json:"tc_secretariat_id"`
-	TCSecretariat            *Member   `json:"tc_secretariat"`
-	TCSecretariatComment     string    `json:"tc_secretariat_comment"`
-	CreatedAt                time.Time `json:"created_at"`
+	Response                 Response   `json:"response" binding:"required"`
+	IsCommittedToParticipate bool       `json:"is_committed_to_participate"`
+	Reason                   string     `json:"reason"` // Why the NSB wants to change its answer
+	Status                   Status     `json:"status" gorm:"default:PENDING;"`
+	TCSecretariatID          *string    `json:"tc_secretariat_id"`
+	TCSecretariat            *Member    `json:"tc_secretariat"`
+	TCSecretariatComment     string     `json:"tc_secretariat_comment"`
+	DecidedAt                *time.Time `json:"decided_at"`
+	CreatedAt                time.Time  `json:"created_at"`
 }
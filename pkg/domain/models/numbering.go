@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Numbering scopes. Every scope has its own sequence in the registry.
+const (
+	ProjectNumberScope  = "project"
+	StandardNumberScope = "ars"
+
+	tcDocumentScopePrefix        = "tc-document:"
+	meetingResolutionScopePrefix = "meeting-resolution:"
+	amendmentScopePrefix         = "amendment:"
+	corrigendumScopePrefix       = "corrigendum:"
+	workProgrammeScopePrefix     = "work-programme:"
+	committeeBallotScopePrefix   = "committee-ballot:"
+)
+
+var ErrInvalidNumberingScope = errors.New("invalid numbering scope")
+
+// TCDocumentScope is the scope of the N-document numbers of a technical committee
+func TCDocumentScope(committeeID string) string {
+	return tcDocumentScopePrefix + committeeID
+}
+
+// MeetingResolutionScope is the scope of the resolution numbers of a committee's meetings
+func MeetingResolutionScope(committeeID string) string {
+	return meetingResolutionScopePrefix + committeeID
+}
+
+// SupplementScope is the scope of the amendment or corrigendum numbers of a standard
+func SupplementScope(supplementType ProjectType, standardID string) string {
+	if supplementType == CORRIGENDUM {
+		return corrigendumScopePrefix + standardID
+	}
+	return amendmentScopePrefix + standardID
+}
+
+// WorkProgrammeScope is the scope of the version numbers of a technical committee's work programme
+func WorkProgrammeScope(committeeID string) string {
+	return workProgrammeScopePrefix + committeeID
+}
+
+// CommitteeBallotScope is the scope of the numbers of a technical committee's internal ballots
+func CommitteeBallotScope(committeeID string) string {
+	return committeeBallotScopePrefix + committeeID
+}
+
+// ValidateNumberingScope checks that the scope is one of the registry scopes
+func ValidateNumberingScope(scope string) error {
+	switch {
+	case scope == ProjectNumberScope, scope == StandardNumberScope:
+		return nil
+	case strings.HasPrefix(scope, tcDocumentScopePrefix) && len(scope) > len(tcDocumentScopePrefix):
+		return nil
+	case strings.HasPrefix(scope, meetingResolutionScopePrefix) && len(scope) > len(meetingResolutionScopePrefix):
+		return nil
+	case strings.HasPrefix(scope, amendmentScopePrefix) && len(scope) > len(amendmentScopePrefix):
+		return nil
+	case strings.HasPrefix(scope, corrigendumScopePrefix) && len(scope) > len(corrigendumScopePrefix):
+		return nil
+	case strings.HasPrefix(scope, workProgrammeScopePrefix) && len(scope) > len(workProgrammeScopePrefix):
+		return nil
+	case strings.HasPrefix(scope, committeeBallotScopePrefix) && len(scope) > len(committeeBallotScopePrefix):
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidNumberingScope, scope)
+}
+
+// NumberSequence holds the last number issued in a scope
+type NumberSequence struct {
+	Scope      string    `json:"scope" gorm:"primaryKey"`
+	LastNumber int64     `json:"last_number"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type NumberReservationStatus string
+
+const (
+	NumberReserved NumberReservationStatus = "RESERVED"
+	NumberUsed     NumberReservationStatus = "USED"
+	NumberReleased NumberReservationStatus = "RELEASED" // Returned to the scope and issued again before new numbers
+)
+
+// NumberReservation holds a number back for later use. Released numbers are issued again before
+// the sequence moves on, so numbers that were never used do not leave gaps.
+type NumberReservation struct {
+	ID           uuid.UUID               `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Scope        string                  `json:"scope" gorm:"uniqueIndex:idx_number_reservation"`
+	Number       int64                   `json:"number" gorm:"uniqueIndex:idx_number_reservation"`
+	Status       NumberReservationStatus `json:"status" gorm:"index"`
+	Purpose      string                  `json:"purpose"`
+	ReservedByID *string                 `json:"reserved_by_id"`
+	ReservedBy   *Member                 `json:"reserved_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	ExpiresAt    *time.Time              `json:"expires_at"` // Reservations still unused at this time are released
+	UsedAt       *time.Time              `json:"used_at"`
+	ReleasedAt   *time.Time              `json:"released_at"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+}
@@ -17,6 +17,9 @@ const (
 	PublicAvailableSpecification Procedure = "Public_Available_Specification"
 	GuidesAndGuidelines          Procedure = "Guides_And_Guidelines"
 	WorkshopAgreement            Procedure = "Workshop_Agreement"
+	Amendment                    Procedure = "Amendment"   // Reduced workflow of amendments
+	Corrigendum                  Procedure = "Corrigendum" // Reduced workflow of technical corrigenda
+	Adoption                     Procedure = "Adoption"    // Adoption of an international document, straight to enquiry
 )
 
 type ProjectType string
@@ -25,6 +28,8 @@ const (
 	NEW           ProjectType = "NEW"
 	REVISION      ProjectType = "REVISION"
 	INTERNATIONAL ProjectType = "INTERNATIONAL"
+	AMENDMENT     ProjectType = "AMENDMENT"   // Amends a published standard
+	CORRIGENDUM   ProjectType = "CORRIGENDUM" // Corrects errors in a published standard
 )
 
 type WorkingDraftStatus string
@@ -45,15 +50,20 @@ const (
 
 // ProjectStageHistory tracks the history of stages a project has gone through
 type ProjectStageHistory struct {
-	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	ProjectID string     `json:"project_id" gorm:"type:uuid"`
-	StageID   string     `json:"stage_id" gorm:"type:uuid"`
-	Stage     *Stage     `json:"stage"`
-	StartedAt time.Time  `json:"started_at"`
-	EndedAt   *time.Time `json:"ended_at"` // Null until the project moves to a new stage
-	Notes     string     `json:"notes"`    // Optional notes about this stage transition
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID   string     `json:"project_id" gorm:"type:uuid"`
+	StageID     string     `json:"stage_id" gorm:"type:uuid"`
+	Stage       *Stage     `json:"stage"`
+	FromStageID *string    `json:"from_stage_id" gorm:"type:uuid"` // Stage the project left, null for the initial entry
+	FromStage   *Stage     `json:"from_stage,omitempty"`
+	Transition  string     `json:"transition"` // Name of the workflow transition that produced this entry
+	ActorID     *string    `json:"actor_id"`   // Member who triggered the transition
+	Actor       *Member    `json:"actor,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at"` // Null until the project moves to a new stage
+	Notes       string     `json:"notes"`    // Reason given for this stage transition
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 type Project struct {
@@ -64,10 +74,12 @@ type Project struct {
 	ProjectSector           *Sector               `json:"project_sector"`
 	Procedure               Procedure             `json:"procedure"`
 	Number                  int64                 `json:"number"`
+	StandardNumber          int64                 `json:"standard_number"` // ARS number, allocated at approval for publication and kept by revisions
 	PartNo                  int64                 `json:"part_number"`
 	EditionNo               int64                 `json:"edition_number"`
 	Reference               string                `json:"reference"`
 	ReferenceSuffix         string                `json:"reference_suffix"`
+	ReferenceHistory        []ProjectReference    `json:"reference_history,omitempty"` // References of earlier drafts
 	Title                   string                `json:"title" binding:"required"`
 	Language                string                `json:"language" gorm:"default:English"`
 	Description             string                `json:"description" binding:"required"`
@@ -78,6 +90,8 @@ type Project struct {
 	StageID                 string                `json:"stage_id"`                // Current stage ID
 	Stage                   *Stage                `json:"stage"`                   // Current stage
 	StageHistory            []ProjectStageHistory `json:"stage_history,omitempty"` // History of all stages
+	StagePlans              []ProjectStagePlan    `json:"stage_plans,omitempty"`   // Planned dates of every stage
+	Transfers               []ProjectTransfer     `json:"transfers,omitempty"`     // Transfers between committees and working groups
 	Timeframe               int                   `json:"time_frame"`              // Timeframe In Months
 	Type                    ProjectType           `json:"type" binding:"required" gorm:"default:NEW"`
 	VisibleOnLibrary        bool                  `json:"visible_on_library" gorm:"default:true"`
@@ -110,37 +124,48 @@ type Project struct {
 	SubmissionDate          *time.Time            `json:"submission_date,omitempty"`
 	DARS                    *DARS                 `json:"dars"`
 	Balloting               *Balloting            `json:"ballot"`
+	Adoption                *AdoptedStandard      `json:"adoption,omitempty"` // International document adopted by the project
 	RelatedDocuments        *[]Document           `json:"project_related_documents" gorm:"many2many:project_related_documents;"`
+	ImportBatchID           *string               `json:"import_batch_id,omitempty" gorm:"type:uuid;index"` // Import the project was created by
 	// Keep track of cancellation at ballot level
-	Cancelled                     bool       `json:"cancelled" gorm:"default:false"`
-	CancelledDate                 *time.Time `json:"cancelled_date"`
-	ApprovedForPublication        bool       `json:"approved_for_publication" gorm:"default:false"`
-	ApprovedForPublicationDate    *time.Time `json:"approved_for_publication_date"`
-	ApprovedForPublicationByID    *string    `json:"approved_for_publication_by_id"`
-	ApprovedForPublicationBy      *Member    `json:"approved_for_publication_by"`
-	ApprovedForPublicationComment string     `json:"approved_for_publication_comment"`
-	DARSDocID                     *string    `json:"dars_doc_id"`
-	DARSDoc                       *Document  `json:"dars_doc"`
-	FDARSDocID                    *string    `json:"fdars_doc_id"`
-	FDARSDoc                      *Document  `json:"fdars_doc"`
-	StandardID                    *string    `json:"standard_id"`
-	Standard                      *Document  `json:"standard"`
-	Published                     bool       `json:"published" gorm:"default:false"`
-	SharepointDocID               *string    `json:"sharepoint_doc_id"`
-	PublishedDate                 *time.Time `json:"published_date"`
-	CreatedAt                     time.Time  `json:"created_at"`
-	UpdatedAt                     time.Time  `json:"updated_at"`
+	Cancelled                     bool          `json:"cancelled" gorm:"default:false"`
+	Status                        ProjectStatus `json:"status" gorm:"default:ACTIVE;index"` // Active, on hold, cancelled or withdrawn
+	CancelledDate                 *time.Time    `json:"cancelled_date"`
+	ApprovedForPublication        bool          `json:"approved_for_publication" gorm:"default:false"`
+	ApprovedForPublicationDate    *time.Time    `json:"approved_for_publication_date"`
+	ApprovedForPublicationByID    *string       `json:"approved_for_publication_by_id"`
+	ApprovedForPublicationBy      *Member       `json:"approved_for_publication_by"`
+	ApprovedForPublicationComment string        `json:"approved_for_publication_comment"`
+	DARSDocID                     *string       `json:"dars_doc_id"`
+	DARSDoc                       *Document     `json:"dars_doc"`
+	FDARSDocID                    *string       `json:"fdars_doc_id"`
+	FDARSDoc                      *Document     `json:"fdars_doc"`
+	StandardID                    *string       `json:"standard_id"`
+	Standard                      *Document     `json:"standard"`
+	Published                     bool          `json:"published" gorm:"default:false"`
+	SharepointDocID               *string       `json:"sharepoint_doc_id"`
+	PublishedDate                 *time.Time    `json:"published_date"`
+	LibraryStatus                 LibraryStatus `json:"library_status" gorm:"default:CURRENT"`
+	ConfirmedDate                 *time.Time    `json:"confirmed_date"` // Last confirmation by a systematic review
+	WithdrawnDate                 *time.Time    `json:"withdrawn_date"`
+	BaseStandardID                *string       `json:"base_standard_id" gorm:"type:uuid;index"` // Standard an amendment or corrigendum applies to
+	BaseStandard                  *Project      `json:"base_standard,omitempty"`
+	SupplementNo                  int64         `json:"supplement_number"`                                      // Amendment or corrigendum number, counted per standard
+	Supplements                   []Project     `json:"supplements,omitempty" gorm:"foreignKey:BaseStandardID"` // Amendments and corrigenda of the standard
+	CreatedAt                     time.Time     `json:"created_at"`
+	UpdatedAt                     time.Time     `json:"updated_at"`
 }
 
 // ProjectDTO represents a subset of Project fields for repository queries
 type ProjectDTO struct {
-	ID              uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	Title           string    `json:"title"`
-	Reference       string    `json:"reference"`
-	ProjectSectorID *string   `json:"-"`
-	ProjectSector   *Sector   `json:"project_sector"`
-	Description     string    `json:"description"`
-	Published       bool      `json:"published"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Title           string        `json:"title"`
+	Reference       string        `json:"reference"`
+	ProjectSectorID *string       `json:"-"`
+	ProjectSector   *Sector       `json:"project_sector"`
+	Description     string        `json:"description"`
+	Published       bool          `json:"published"`
+	LibraryStatus   LibraryStatus `json:"library_status"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
 }
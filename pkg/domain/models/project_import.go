@@ -0,0 +1,169 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImportBatchStatus string
+
+const (
+	ImportBatchImported ImportBatchStatus = "IMPORTED"
+	ImportBatchUndone   ImportBatchStatus = "UNDONE" // Every project and document of the batch was removed
+)
+
+// ProjectImportBatch records an import of legacy projects and published standards from a manifest,
+// so that the whole batch can be undone
+type ProjectImportBatch struct {
+	ID               uuid.UUID         `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ManifestName     string            `json:"manifest_name"`
+	ArchiveName      string            `json:"archive_name"` // ZIP of PDFs, empty when no files were imported
+	Status           ImportBatchStatus `json:"status" gorm:"index"`
+	TotalRows        int               `json:"total_rows"`
+	ProjectsCreated  int               `json:"projects_created"`
+	StandardsCreated int               `json:"standards_created"` // Rows imported as published standards
+	DocumentsCreated int               `json:"documents_created"`
+	ImportedByID     string            `json:"imported_by_id"`
+	ImportedBy       *Member           `json:"imported_by,omitempty"`
+	UndoneByID       *string           `json:"undone_by_id"`
+	UndoneBy         *Member           `json:"undone_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	UndoneAt         *time.Time        `json:"undone_at"`
+	Projects         []Project         `json:"projects,omitempty" gorm:"foreignKey:ImportBatchID"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// ProjectImportRow is a row of an import manifest. The committee, working group, sector and stage
+// are given by code, name or number and resolved when the row is validated.
+type ProjectImportRow struct {
+	Row            int         `json:"row"` // Line of the manifest, counting the header as line 1
+	Title          string      `json:"title"`
+	Description    string      `json:"description"`
+	Language       string      `json:"language"`
+	CommitteeCode  string      `json:"tc_code"`
+	WorkingGroup   string      `json:"working_group"`
+	Sector         string      `json:"sector"`
+	StageNumber    *int        `json:"stage"`
+	Type           ProjectType `json:"type"`
+	Procedure      Procedure   `json:"procedure"`
+	StandardNumber int64       `json:"standard_number"`
+	PartNo         int64       `json:"part_number"`
+	EditionNo      int64       `json:"edition_number"`
+	Timeframe      int         `json:"time_frame"`
+	Reference      string      `json:"reference"` // Legacy reference, built from the row when empty
+	StageStartedAt *time.Time  `json:"stage_started_at"`
+	PublishedDate  *time.Time  `json:"published_date"` // Rows with a publication date are imported as published standards
+	File           string      `json:"file"`           // Name of the PDF in the ZIP archive
+
+	TechnicalCommitteeID string  `json:"technical_committee_id,omitempty"`
+	WorkingGroupID       *string `json:"working_group_id,omitempty"`
+	ProjectSectorID      *string `json:"project_sector_id,omitempty"`
+	Stage                *Stage  `json:"-"`
+	FileURL              string  `json:"-"`
+}
+
+// IsPublished reports whether the row is a published standard rather than a project in progress
+func (r *ProjectImportRow) IsPublished() bool {
+	return r.PublishedDate != nil
+}
+
+// importableProcedures are the procedures legacy projects may follow. Amendments, corrigenda and
+// adoptions need records the manifest does not carry.
+var importableProcedures = map[Procedure]bool{
+	Normal:                       true,
+	DraftSubmittedWithProposal:   true,
+	FastTrack:                    true,
+	TechnicalSpecification:       true,
+	TechnicalReport:              true,
+	PublicAvailableSpecification: true,
+	GuidesAndGuidelines:          true,
+	WorkshopAgreement:            true,
+}
+
+// Validate checks the row on its own, without looking up its committee, sector or stage. Rows
+// without a type or procedure are given NEW and Normal.
+func (r *ProjectImportRow) Validate() []ProjectImportError {
+	var errs []ProjectImportError
+	fail := func(column, format string, args ...interface{}) {
+		errs = append(errs, ProjectImportError{Row: r.Row, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if r.Title == "" {
+		fail("title", "title is required")
+	}
+	if r.CommitteeCode == "" {
+		fail("tc_code", "technical committee code is required")
+	}
+
+	if r.Type == "" {
+		r.Type = NEW
+	}
+	switch r.Type {
+	case NEW, INTERNATIONAL:
+	case REVISION:
+		if r.StandardNumber <= 0 {
+			fail("standard_number", "revisions need the ARS number of the standard they revise")
+		}
+	default:
+		fail("type", "type must be NEW, REVISION or INTERNATIONAL")
+	}
+
+	if r.Procedure == "" {
+		r.Procedure = Normal
+	}
+	if !importableProcedures[r.Procedure] {
+		fail("procedure", "procedure %q cannot be imported", r.Procedure)
+	}
+
+	if r.IsPublished() {
+		if r.StageNumber != nil && *r.StageNumber != 6 {
+			fail("stage", "published standards are imported at the approval stage (6)")
+		}
+		approval := 6
+		r.StageNumber = &approval
+		if r.StandardNumber <= 0 {
+			fail("standard_number", "published standards need their ARS number")
+		}
+		if r.File == "" {
+			fail("file", "published standards need the PDF of the standard")
+		}
+		return errs
+	}
+
+	if r.StageNumber == nil {
+		fail("stage", "stage is required for projects in progress")
+		return errs
+	}
+	onPath := false
+	for _, number := range StagePath(r.Procedure) {
+		onPath = onPath || number == *r.StageNumber
+	}
+	if !onPath {
+		fail("stage", "stage %d is not part of the %s procedure", *r.StageNumber, r.Procedure)
+	}
+	if r.File != "" && *r.StageNumber < 2 {
+		fail("file", "drafts can only be attached to projects from the preparatory stage (2) on")
+	}
+	return errs
+}
+
+// ProjectImportError is a problem with a row, or with the manifest as a whole when Row is 0
+type ProjectImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProjectImportReport is the result of validating a manifest. Nothing is imported while it has errors.
+type ProjectImportReport struct {
+	DryRun       bool                 `json:"dry_run"`
+	ManifestName string               `json:"manifest_name"`
+	ArchiveName  string               `json:"archive_name"`
+	TotalRows    int                  `json:"total_rows"`
+	ValidRows    int                  `json:"valid_rows"`
+	Errors       []ProjectImportError `json:"errors"`
+	Rows         []ProjectImportRow   `json:"rows"`
+	Batch        *ProjectImportBatch  `json:"batch,omitempty"` // Set once the rows have been imported
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StandardDesignationPrefix prefixes the designation of published African Standards
+const StandardDesignationPrefix = "ARS"
+
+var (
+	ErrSupplementWithoutBase     = errors.New("amendments and corrigenda must have the standard they apply to")
+	ErrSupplementWithoutNumber   = errors.New("amendments and corrigenda must have a supplement number")
+	ErrSupplementOutsideWorkflow = errors.New("amendments and corrigenda are created from the published standard they apply to")
+)
+
+// ProjectReference records a reference a project has carried. A new entry is added whenever the
+// reference changes so that projects can still be found by the reference of an earlier draft.
+type ProjectReference struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID string    `json:"project_id" gorm:"type:uuid;index"`
+	Reference string    `json:"reference" gorm:"index"`
+	StageID   *string   `json:"stage_id" gorm:"type:uuid"` // Stage the reference was issued for, null for the standard designation
+	Stage     *Stage    `json:"stage,omitempty"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DraftReference builds the reference of a project draft circulated at the stage in the given year.
+//
+// New work is numbered <STAGE>/TC NN/XXX/YYYY, where NN is the TC code and XXX the serial number of
+// the project. Revisions are numbered <STAGE>/XXX:YYYY, where XXX is the ARS number of the standard
+// being revised, e.g. WD/461:2024 when revising ARS 461:2021. Amendments and corrigenda are
+// numbered after the standard they apply to, e.g. DARS/461:2021/Amd 1. The base standard of
+// amendments and corrigenda must be loaded.
+func DraftReference(project *Project, stageAbbreviation, committeeCode string, year int) (string, error) {
+	if project.IsSupplement() {
+		if err := checkSupplement(project); err != nil {
+			return "", err
+		}
+		base := strings.TrimPrefix(project.BaseStandard.Reference, StandardDesignationPrefix+" ")
+		return fmt.Sprintf("%s/%s/%s", stageAbbreviation, base, supplementNumber(project)), nil
+	}
+	if project.Type == REVISION {
+		return fmt.Sprintf("%s/%s:%d", stageAbbreviation, standardNumber(project), year), nil
+	}
+	return fmt.Sprintf("%s/TC %s/%03d/%d", stageAbbreviation, committeeCode, project.Number, year), nil
+}
+
+// StandardDesignation builds the ARS NNN:YYYY designation of a standard published in the given year
+func StandardDesignation(project *Project, year int) string {
+	return fmt.Sprintf("%s %s:%d", StandardDesignationPrefix, standardNumber(project), year)
+}
+
+// SupplementDesignation builds the designation of an amendment or corrigendum published in the
+// given year, e.g. ARS 461:2021/Amd 1:2025. The base standard must be loaded.
+func SupplementDesignation(project *Project, year int) (string, error) {
+	if err := checkSupplement(project); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s:%d", project.BaseStandard.Reference, supplementNumber(project), year), nil
+}
+
+// IsSupplement reports whether the project is an amendment or corrigendum of a published standard
+func (p *Project) IsSupplement() bool {
+	return p.Type == AMENDMENT || p.Type == CORRIGENDUM
+}
+
+// SupplementProcedure is the reduced workflow followed by amendments or corrigenda
+func SupplementProcedure(supplementType ProjectType) Procedure {
+	if supplementType == CORRIGENDUM {
+		return Corrigendum
+	}
+	return Amendment
+}
+
+// checkSupplement ensures an amendment or corrigendum can be designated after its base standard
+func checkSupplement(project *Project) error {
+	if project.BaseStandard == nil {
+		return ErrSupplementWithoutBase
+	}
+	if project.SupplementNo <= 0 {
+		return ErrSupplementWithoutNumber
+	}
+	return nil
+}
+
+// supplementNumber is the Amd N or Cor N number of an amendment or corrigendum
+func supplementNumber(project *Project) string {
+	if project.Type == CORRIGENDUM {
+		return fmt.Sprintf("Cor %d", project.SupplementNo)
+	}
+	return fmt.Sprintf("Amd %d", project.SupplementNo)
+}
+
+// standardNumber is the ARS number, followed by the part number for multipart standards. Projects
+// designated before ARS numbers were allocated separately use their project number.
+func standardNumber(project *Project) string {
+	number := project.StandardNumber
+	if number == 0 {
+		number = project.Number
+	}
+	if project.PartNo > 0 {
+		return fmt.Sprintf("%03d-%d", number, project.PartNo)
+	}
+	return fmt.Sprintf("%03d", number)
+}
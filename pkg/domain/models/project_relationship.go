@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RelationshipType is the meaning of a link from a project to another project or standard
+type RelationshipType string
+
+const (
+	RelationSupersedes            RelationshipType = "SUPERSEDES"
+	RelationAmends                RelationshipType = "AMENDS"
+	RelationRevises               RelationshipType = "REVISES" // Is a revision of
+	RelationNormativelyReferences RelationshipType = "NORMATIVELY_REFERENCES"
+	RelationPartOf                RelationshipType = "PART_OF" // Is a part of the multi-part series of
+)
+
+var ErrInvalidRelationshipType = errors.New("relationship type must be SUPERSEDES, AMENDS, REVISES, NORMATIVELY_REFERENCES or PART_OF")
+
+// Validate rejects relationship types that are not defined
+func (t RelationshipType) Validate() error {
+	switch t {
+	case RelationSupersedes, RelationAmends, RelationRevises, RelationNormativelyReferences, RelationPartOf:
+		return nil
+	}
+	return ErrInvalidRelationshipType
+}
+
+// Inverse names the relationship as seen from its target, e.g. SUPERSEDED_BY for SUPERSEDES
+func (t RelationshipType) Inverse() string {
+	switch t {
+	case RelationSupersedes:
+		return "SUPERSEDED_BY"
+	case RelationAmends:
+		return "AMENDED_BY"
+	case RelationRevises:
+		return "REVISED_BY"
+	case RelationNormativelyReferences:
+		return "NORMATIVELY_REFERENCED_BY"
+	case RelationPartOf:
+		return "HAS_PART"
+	}
+	return string(t)
+}
+
+// RequiresPublishedTarget reports whether the relationship may only point at a published standard.
+// Parts of a series may be linked while they are still in development.
+func (t RelationshipType) RequiresPublishedTarget() bool {
+	return t != RelationPartOf
+}
+
+// ProjectRelationship is a typed link from a project to another project or published standard
+type ProjectRelationship struct {
+	ID          uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	SourceID    string           `json:"source_id" gorm:"type:uuid;uniqueIndex:idx_project_relationship"`
+	Source      *Project         `json:"source,omitempty"`
+	TargetID    string           `json:"target_id" gorm:"type:uuid;uniqueIndex:idx_project_relationship;index"`
+	Target      *Project         `json:"target,omitempty"`
+	Type        RelationshipType `json:"type" gorm:"uniqueIndex:idx_project_relationship"`
+	Note        string           `json:"note"`
+	CreatedByID *string          `json:"created_by_id"` // Null for links recorded by the system
+	CreatedBy   *Member          `json:"created_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// RelationshipNode is a project or standard in a relationship graph
+type RelationshipNode struct {
+	ID            string        `json:"id"`
+	Title         string        `json:"title"`
+	Reference     string        `json:"reference"`
+	Type          ProjectType   `json:"type"`
+	Published     bool          `json:"published"`
+	LibraryStatus LibraryStatus `json:"library_status"`
+	Depth         int           `json:"depth"` // Number of links from the project the graph was built for
+}
+
+// RelationshipEdge is a link in a relationship graph, named from both ends
+type RelationshipEdge struct {
+	ID       string           `json:"id"`
+	SourceID string           `json:"source_id"`
+	TargetID string           `json:"target_id"`
+	Type     RelationshipType `json:"type"`
+	Inverse  string           `json:"inverse"`
+	Note     string           `json:"note"`
+}
+
+// RelationshipWarning flags a normative reference to a standard that is no longer current
+type RelationshipWarning struct {
+	RelationshipID string `json:"relationship_id"`
+	StandardID     string `json:"standard_id"`
+	Reference      string `json:"reference"`
+	Reason         string `json:"reason"`
+}
+
+// RelationshipGraph holds the projects and standards linked to a project, in both directions
+type RelationshipGraph struct {
+	ProjectID string                `json:"project_id"`
+	Nodes     []RelationshipNode    `json:"nodes"`
+	Edges     []RelationshipEdge    `json:"edges"`
+	Warnings  []RelationshipWarning `json:"warnings"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RollbackTransition names rollbacks in the stage history and reference history
+const RollbackTransition = "ROLLBACK"
+
+// MinRollbackStage is the earliest stage a project can be returned to. The stages before it are
+// decided by the NSBs rather than by the committee.
+const MinRollbackStage = 2
+
+// rollbackDocTypes are the stage drafts of a project by the stage that produces them
+var rollbackDocTypes = []struct {
+	Stage   int
+	DocType string
+}{
+	{2, "WD"},
+	{3, "CD"},
+	{4, "DARS"},
+	{5, "FDARS"},
+}
+
+// ProjectRollback returns a project to an earlier stage, for instance to resubmit a committee draft
+// or an enquiry draft after an unsuccessful ballot. The ballot and enquiry of the abandoned stages
+// are closed and detached from the project, and their drafts are kept as superseded versions.
+type ProjectRollback struct {
+	ID                  uuid.UUID            `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID           string               `json:"project_id" gorm:"type:uuid;index"`
+	Project             *Project             `json:"project,omitempty"`
+	FromStageID         string               `json:"from_stage_id" gorm:"type:uuid"`
+	FromStage           *Stage               `json:"from_stage,omitempty"`
+	ToStageID           string               `json:"to_stage_id" gorm:"type:uuid"`
+	ToStage             *Stage               `json:"to_stage,omitempty"`
+	Reason              string               `json:"reason"`
+	PreviousReference   string               `json:"previous_reference"`
+	NewReference        string               `json:"new_reference"`
+	SupersededBallotID  *string              `json:"superseded_ballot_id" gorm:"type:uuid"` // Ballot closed by the rollback
+	SupersededBallot    *Balloting           `json:"superseded_ballot,omitempty" gorm:"foreignKey:SupersededBallotID"`
+	SupersededDARSID    *string              `json:"superseded_dars_id" gorm:"type:uuid"` // Enquiry closed by the rollback
+	SupersededDARS      *DARS                `json:"superseded_dars,omitempty" gorm:"foreignKey:SupersededDARSID"`
+	SupersededDocuments []SupersededDocument `json:"superseded_documents,omitempty" gorm:"foreignKey:RollbackID"`
+	RolledBackByID      string               `json:"rolled_back_by_id"`
+	RolledBackBy        *Member              `json:"rolled_back_by,omitempty"`
+	CreatedAt           time.Time            `json:"created_at"`
+}
+
+// SupersededDocument is a stage draft a project gave up when it was rolled back
+type SupersededDocument struct {
+	ID         uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	RollbackID string    `json:"rollback_id" gorm:"type:uuid;index"`
+	ProjectID  string    `json:"project_id" gorm:"type:uuid;index"`
+	DocumentID string    `json:"document_id" gorm:"type:uuid"`
+	Document   *Document `json:"document,omitempty"`
+	DocType    string    `json:"doc_type"` // WD, CD, DARS or FDARS
+	Version    int       `json:"version"`  // Counts the superseded drafts of the type for the project, from 1
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AbandonedDrafts returns the drafts, by document type, of the stages a project gives up when it
+// returns to the given stage. The draft of the stage returned to is kept for revision.
+func (p *Project) AbandonedDrafts(toStage int) map[string]*string {
+	fields := map[string]*string{
+		"WD":    p.WorkingDraftID,
+		"CD":    p.CommitteeDraftID,
+		"DARS":  p.DARSDocID,
+		"FDARS": p.FDARSDocID,
+	}
+
+	drafts := map[string]*string{}
+	for _, draft := range rollbackDocTypes {
+		if draft.Stage > toStage && fields[draft.DocType] != nil {
+			drafts[draft.DocType] = fields[draft.DocType]
+		}
+	}
+	return drafts
+}
+
+// RollbackResets returns the project columns cleared when a project returns to the given stage, so
+// that the reviews of that stage and of the stages after it have to be carried out again
+func RollbackResets(toStage int) map[string]interface{} {
+	resets := map[string]interface{}{
+		"approved_for_publication":         false,
+		"approved_for_publication_date":    nil,
+		"approved_for_publication_by_id":   nil,
+		"approved_for_publication_comment": "",
+	}
+	if toStage <= 3 {
+		resets["is_consensus_reached"] = false
+		resets["proposal_action"] = ""
+		resets["meeting_required"] = false
+		resets["cd_tc_secretary_id"] = nil
+		resets["submission_date"] = nil
+	}
+	if toStage <= 2 {
+		resets["working_draft_status"] = UNDER_REVIEW
+		resets["wd_tc_secretary_id"] = nil
+	}
+
+	columns := map[string]string{"WD": "working_draft_id", "CD": "committee_draft_id", "DARS": "dars_doc_id", "FDARS": "fdars_doc_id"}
+	for _, draft := range rollbackDocTypes {
+		if draft.Stage > toStage {
+			resets[columns[draft.DocType]] = nil
+		}
+	}
+	return resets
+}
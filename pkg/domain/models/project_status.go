@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectStatus is where a project stands in its lifecycle, independently of its stage
+type ProjectStatus string
+
+const (
+	ProjectActive    ProjectStatus = "ACTIVE"
+	ProjectOnHold    ProjectStatus = "ON_HOLD"
+	ProjectCancelled ProjectStatus = "CANCELLED"
+	ProjectWithdrawn ProjectStatus = "WITHDRAWN" // Cancelled at the request of the proposer
+)
+
+// IsActive reports whether work on the project may proceed. Projects cancelled before the status
+// was recorded only have the Cancelled flag set.
+func (p *Project) IsActive() bool {
+	return !p.Cancelled && (p.Status == "" || p.Status == ProjectActive)
+}
+
+type FrozenActivityType string
+
+const (
+	FrozenBallot        FrozenActivityType = "BALLOT"
+	FrozenCommentWindow FrozenActivityType = "COMMENT_WINDOW" // DARS public review
+	FrozenMeeting       FrozenActivityType = "MEETING"
+)
+
+// FrozenActivity is an open ballot, comment window or upcoming meeting halted when the project
+// stopped. Reinstatement gives ballots and comment windows back the time they had left.
+type FrozenActivity struct {
+	Type             FrozenActivityType `json:"type"`
+	ID               string             `json:"id"`
+	RemainingSeconds int64              `json:"remaining_seconds,omitempty"` // Time left before the ballot or window closed
+	PreviousStatus   MeetingStatus      `json:"previous_status,omitempty"`   // Status of the meeting before it was postponed or cancelled
+}
+
+// Remaining is the time the activity had left when it was frozen
+func (a FrozenActivity) Remaining() time.Duration {
+	return time.Duration(a.RemainingSeconds) * time.Second
+}
+
+// ProjectStatusChange records a period in which a project was on hold, cancelled or withdrawn. The
+// period ends when the project is reinstated to the stage it was in.
+type ProjectStatusChange struct {
+	ID                  uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID           string           `json:"project_id" gorm:"type:uuid;index"`
+	Status              ProjectStatus    `json:"status"`
+	PreviousStatus      ProjectStatus    `json:"previous_status"`
+	StageID             string           `json:"stage_id" gorm:"type:uuid"` // Stage the project returns to when reinstated
+	Stage               *Stage           `json:"stage,omitempty"`
+	Reason              string           `json:"reason"`
+	ActorID             *string          `json:"actor_id"`
+	Actor               *Member          `json:"actor,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	FrozenActivities    []FrozenActivity `json:"frozen_activities" gorm:"serializer:json"`
+	ReinstatableUntil   *time.Time       `json:"reinstatable_until"` // Null when the project can be reinstated at any time
+	EndedAt             *time.Time       `json:"ended_at"`           // Null while the project is still stopped
+	ReinstatedByID      *string          `json:"reinstated_by_id"`
+	ReinstatedBy        *Member          `json:"reinstated_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	ReinstatementReason string           `json:"reinstatement_reason,omitempty"`
+	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProjectTransferStatus string
+
+const (
+	TransferPending   ProjectTransferStatus = "PENDING"   // Awaiting the approval of one or both secretariats
+	TransferCompleted ProjectTransferStatus = "COMPLETED" // Approved by both secretariats and carried out
+	TransferRejected  ProjectTransferStatus = "REJECTED"
+)
+
+// ProjectTransfer moves a project to another technical committee or working group. It is carried
+// out once the secretariats of both committees have approved it. When the project moves between
+// working groups of the same TC, that TC's secretariat approves for both sides.
+type ProjectTransfer struct {
+	ID                 uuid.UUID             `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID          string                `json:"project_id" gorm:"type:uuid;index"`
+	Project            *Project              `json:"project,omitempty"`
+	FromCommitteeID    string                `json:"from_committee_id" gorm:"type:uuid"`
+	FromCommittee      *TechnicalCommittee   `json:"from_committee,omitempty"`
+	FromWorkingGroupID *string               `json:"from_working_group_id" gorm:"type:uuid"`
+	FromWorkingGroup   *WorkingGroup         `json:"from_working_group,omitempty"`
+	ToCommitteeID      string                `json:"to_committee_id" gorm:"type:uuid"`
+	ToCommittee        *TechnicalCommittee   `json:"to_committee,omitempty"`
+	ToWorkingGroupID   *string               `json:"to_working_group_id" gorm:"type:uuid"`
+	ToWorkingGroup     *WorkingGroup         `json:"to_working_group,omitempty"`
+	Reason             string                `json:"reason"`
+	Status             ProjectTransferStatus `json:"status" gorm:"index"`
+	RequestedByID      string                `json:"requested_by_id"`
+	RequestedBy        *Member               `json:"requested_by,omitempty"`
+	SourceApprovedByID *string               `json:"source_approved_by_id"` // Secretary of the committee giving up the project
+	SourceApprovedAt   *time.Time            `json:"source_approved_at"`
+	TargetApprovedByID *string               `json:"target_approved_by_id"` // Secretary of the committee taking over the project
+	TargetApprovedAt   *time.Time            `json:"target_approved_at"`
+	RejectedByID       *string               `json:"rejected_by_id"`
+	RejectionReason    string                `json:"rejection_reason,omitempty"`
+	PreviousReference  string                `json:"previous_reference"`
+	NewReference       string                `json:"new_reference"` // Set when the transfer is carried out
+	CompletedAt        *time.Time            `json:"completed_at"`
+	CreatedAt          time.Time             `json:"created_at"`
+	UpdatedAt          time.Time             `json:"updated_at"`
+}
+
+// IsApproved reports whether both secretariats have approved the transfer
+func (t *ProjectTransfer) IsApproved() bool {
+	return t.SourceApprovedByID != nil && t.TargetApprovedByID != nil
+}
@@ -170,6 +170,8 @@ type ProjectReportData struct {
 	ProjectsByStage     map[string]int64         `json:"projects_by_stage"`
 	ProjectsByCommittee map[string]int64         `json:"projects_by_committee"`
 	ProjectsByTimeframe map[string]int64         `json:"projects_by_timeframe"`
+	ProjectsByAdoption  map[string]int64         `json:"projects_by_adoption"` // Adoptions of international documents by degree of equivalence
+	AdoptionsBySource   map[string]int64         `json:"adoptions_by_source"`  // Adoptions by source organisation
 	Projects            []Project                `json:"projects,omitempty"`
 	Summary             ProjectReportSummary     `json:"summary"`
 }
@@ -182,6 +184,7 @@ type BallotReportData struct {
 	BallotsByCommittee  map[string]int64     `json:"ballots_by_committee"`
 	BallotsByTimeframe  map[string]int64     `json:"ballots_by_timeframe"`
 	VotingParticipation map[string]float64   `json:"voting_participation"`
+	VotesByDecision     map[string]int64     `json:"votes_by_decision"`
 	Ballots             []Balloting          `json:"ballots,omitempty"`
 	Summary             BallotReportSummary  `json:"summary"`
 }
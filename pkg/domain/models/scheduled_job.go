@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobRunStatus defines the outcome of a single scheduled job run
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "RUNNING"
+	JobRunSucceeded JobRunStatus = "SUCCEEDED"
+	JobRunFailed    JobRunStatus = "FAILED"
+)
+
+// JobTrigger defines what started a job run
+type JobTrigger string
+
+const (
+	JobTriggerSchedule JobTrigger = "SCHEDULE"
+	JobTriggerManual   JobTrigger = "MANUAL"
+)
+
+// ScheduledJob is a background job registered with the scheduler. The row is shared by all
+// replicas so that pausing a job takes effect everywhere.
+type ScheduledJob struct {
+	Name        string       `json:"name" gorm:"primaryKey"`
+	Description string       `json:"description"`
+	Schedule    string       `json:"schedule"` // Cron expression (minute hour day month weekday)
+	Paused      bool         `json:"paused" gorm:"default:false"`
+	PausedByID  *string      `json:"paused_by_id"`
+	PausedBy    *Member      `json:"paused_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	PausedAt    *time.Time   `json:"paused_at"`
+	LastRunAt   *time.Time   `json:"last_run_at"`
+	LastStatus  JobRunStatus `json:"last_status"`
+	NextRunAt   *time.Time   `json:"next_run_at" gorm:"-"` // Computed by the local scheduler
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// JobRun records a single execution of a scheduled job
+type JobRun struct {
+	ID            uuid.UUID    `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	JobName       string       `json:"job_name" gorm:"index"`
+	Trigger       JobTrigger   `json:"trigger"`
+	TriggeredByID *string      `json:"triggered_by_id"`
+	TriggeredBy   *Member      `json:"triggered_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	Status        JobRunStatus `json:"status"`
+	Instance      string       `json:"instance"` // Replica that executed the run
+	StartedAt     time.Time    `json:"started_at"`
+	FinishedAt    *time.Time   `json:"finished_at"`
+	DurationMs    int64        `json:"duration_ms"`
+	Error         string       `json:"error,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimeframeTrack selects which of a stage's timeframes apply to a project
+type TimeframeTrack string
+
+const (
+	TimeframeStandard  TimeframeTrack = "STANDARD"
+	TimeframeIS        TimeframeTrack = "IS"
+	TimeframeEmergency TimeframeTrack = "EMERGENCY"
+)
+
+type StageProgress string
+
+const (
+	StageNotStarted StageProgress = "NOT_STARTED"
+	StageInProgress StageProgress = "IN_PROGRESS"
+	StageCompleted  StageProgress = "COMPLETED"
+)
+
+// TimeframeTrack returns the timeframes the project is planned with. Emergency projects take the
+// emergency timeframes, international projects and projects accepted on the international
+// development track take the IS timeframes.
+func (p *Project) TimeframeTrack() TimeframeTrack {
+	switch {
+	case p.IsEmergency:
+		return TimeframeEmergency
+	case p.Type == INTERNATIONAL:
+		return TimeframeIS
+	case p.Acceptance != nil && p.Acceptance.DevelopmentTrack == TrackInternational:
+		return TimeframeIS
+	default:
+		return TimeframeStandard
+	}
+}
+
+// DurationFor returns the stage duration for the track, falling back to the standard duration
+// when the stage defines none for the track
+func (tf *Timeframe) DurationFor(track TimeframeTrack) *ProjectDuration {
+	if tf == nil {
+		return nil
+	}
+	switch {
+	case track == TimeframeEmergency && tf.Emergency != nil:
+		return tf.Emergency
+	case track == TimeframeIS && tf.IS != nil:
+		return tf.IS
+	default:
+		return tf.Standard
+	}
+}
+
+// TargetDays is the number of days allowed for the stage: the maximum when one is set, otherwise
+// the minimum
+func (d *ProjectDuration) TargetDays() int64 {
+	if d == nil {
+		return 0
+	}
+	if d.Max > 0 {
+		return d.Max
+	}
+	return d.Min
+}
+
+// ProjectStagePlan is the planned start and end of a stage on the project's path. Stages without a
+// timeframe for the track have a zero duration and no SLA.
+type ProjectStagePlan struct {
+	ID           uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID    string         `json:"project_id" gorm:"type:uuid;uniqueIndex:idx_project_stage_plan"`
+	StageID      string         `json:"stage_id" gorm:"type:uuid;uniqueIndex:idx_project_stage_plan"`
+	Stage        *Stage         `json:"stage,omitempty"`
+	StageNumber  int            `json:"stage_number"`
+	Track        TimeframeTrack `json:"track"`
+	DurationDays int64          `json:"duration_days"`
+	PlannedStart time.Time      `json:"planned_start"`
+	PlannedEnd   time.Time      `json:"planned_end"`
+	TargetDate   *time.Time     `json:"target_date,omitempty"` // Date agreed on acceptance for reaching the next milestone, when the stage leads to one
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// StageSLA compares the plan of a stage with the dates recorded in the stage history
+type StageSLA struct {
+	Plan        ProjectStagePlan `json:"plan"`
+	Progress    StageProgress    `json:"progress"`
+	ActualStart *time.Time       `json:"actual_start"`
+	ActualEnd   *time.Time       `json:"actual_end"`  // Null while the project is still in the stage
+	ActualDays  int64            `json:"actual_days"` // Days spent in the stage so far, across every visit
+	OverrunDays int64            `json:"overrun_days"`
+	Breached    bool             `json:"breached"`
+}
+
+// ProjectSLA is the schedule of a project against its plan
+type ProjectSLA struct {
+	ProjectID            string         `json:"project_id"`
+	Reference            string         `json:"reference"`
+	Title                string         `json:"title"`
+	TechnicalCommitteeID string         `json:"technical_committee_id"`
+	Track                TimeframeTrack `json:"track"`
+	Stages               []StageSLA     `json:"stages"`
+	Breaches             int            `json:"breaches"`
+}
+
+// CommitteeSLA summarises the SLA breaches of a technical committee's active projects
+type CommitteeSLA struct {
+	TechnicalCommitteeID string       `json:"technical_committee_id"`
+	CommitteeCode        string       `json:"committee_code"`
+	CommitteeName        string       `json:"committee_name"`
+	ActiveProjects       int          `json:"active_projects"`
+	ProjectsInBreach     int          `json:"projects_in_breach"`
+	Breaches             int          `json:"breaches"`
+	Projects             []ProjectSLA `json:"projects"` // Only the projects in breach
+}
+
+// StageDeadline is when an active project must complete the stage it is in
+type StageDeadline struct {
+	Project  Project   `json:"project"`
+	Stage    string    `json:"stage"`
+	DueDate  time.Time `json:"due_date"`
+	DaysLeft int64     `json:"days_left"` // Negative once the stage is overdue
+}
+
+// DueDate returns when the stage must be completed: the target date agreed on acceptance for the
+// milestone the stage leads to, or else once the time spent in the stage reaches its planned
+// duration. Stages with neither have no due date.
+func (sla *StageSLA) DueDate(now time.Time) *time.Time {
+	if sla.Plan.TargetDate != nil {
+		return sla.Plan.TargetDate
+	}
+	if sla.Plan.DurationDays == 0 {
+		return nil
+	}
+	due := now.AddDate(0, 0, int(sla.Plan.DurationDays-sla.ActualDays))
+	return &due
+}
+
+// PlanProjectStages lays the stages of the project's workflow path end to end from the start date,
+// using the timeframes of the project's track. When the project was accepted with milestone target
+// dates, the stage leading to a milestone is stretched or shortened to end on its target date.
+// Stages must include their timeframe durations.
+func PlanProjectStages(project *Project, stages []Stage, start time.Time) []ProjectStagePlan {
+	byNumber := make(map[int]*Stage, len(stages))
+	for i := range stages {
+		byNumber[stages[i].Number] = &stages[i]
+	}
+
+	track := project.TimeframeTrack()
+	plans := []ProjectStagePlan{}
+	plannedStart := start
+	for _, number := range StagePath(project.Procedure) {
+		stage, ok := byNumber[number]
+		if !ok {
+			continue
+		}
+
+		if target, ok := milestoneTargets[number]; ok && project.Acceptance != nil && len(plans) > 0 {
+			if date := target(project.Acceptance); date != nil && date.After(plans[len(plans)-1].PlannedStart) {
+				previous := &plans[len(plans)-1]
+				previous.PlannedEnd = *date
+				previous.TargetDate = date
+				previous.DurationDays = int64(date.Sub(previous.PlannedStart).Hours() / 24)
+				plannedStart = *date
+			}
+		}
+
+		days := stage.Timeframe.DurationFor(track).TargetDays()
+		plannedEnd := plannedStart.AddDate(0, 0, int(days))
+		plans = append(plans, ProjectStagePlan{
+			ID:           uuid.New(),
+			ProjectID:    project.ID.String(),
+			StageID:      stage.ID.String(),
+			StageNumber:  stage.Number,
+			Track:        track,
+			DurationDays: days,
+			PlannedStart: plannedStart,
+			PlannedEnd:   plannedEnd,
+		})
+		plannedStart = plannedEnd
+	}
+	return plans
+}
+
+// EvaluateProjectSLA compares each planned stage with the project's stage history. A stage is in
+// breach when the time spent in it exceeds its planned duration.
+func EvaluateProjectSLA(project *Project, plans []ProjectStagePlan, history []ProjectStageHistory, now time.Time) ProjectSLA {
+	result := ProjectSLA{
+		ProjectID:            project.ID.String(),
+		Reference:            project.Reference,
+		Title:                project.Title,
+		TechnicalCommitteeID: project.TechnicalCommitteeID,
+		Track:                project.TimeframeTrack(),
+		Stages:               []StageSLA{},
+	}
+
+	for _, plan := range plans {
+		sla := StageSLA{Plan: plan, Progress: StageNotStarted}
+
+		var spent time.Duration
+		for i := range history {
+			entry := history[i]
+			if entry.StageID != plan.StageID {
+				continue
+			}
+			if sla.ActualStart == nil || entry.StartedAt.Before(*sla.ActualStart) {
+				sla.ActualStart = &entry.StartedAt
+			}
+
+			end := now
+			if entry.EndedAt != nil {
+				end = *entry.EndedAt
+			}
+			spent += end.Sub(entry.StartedAt)
+
+			if entry.EndedAt == nil {
+				sla.Progress = StageInProgress
+			} else if sla.Progress != StageInProgress {
+				sla.Progress = StageCompleted
+				if sla.ActualEnd == nil || entry.EndedAt.After(*sla.ActualEnd) {
+					sla.ActualEnd = entry.EndedAt
+				}
+			}
+		}
+		if sla.Progress == StageInProgress {
+			sla.ActualEnd = nil
+		}
+
+		sla.ActualDays = int64(spent.Hours() / 24)
+		if plan.DurationDays > 0 && sla.ActualDays > plan.DurationDays {
+			sla.OverrunDays = sla.ActualDays - plan.DurationDays
+			sla.Breached = true
+			result.Breaches++
+		}
+		result.Stages = append(result.Stages, sla)
+	}
+
+	return result
+}
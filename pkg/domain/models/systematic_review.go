@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LibraryStatus is the standing of a published standard in the library
+type LibraryStatus string
+
+const (
+	LibraryCurrent       LibraryStatus = "CURRENT"
+	LibraryUnderReview   LibraryStatus = "UNDER_REVIEW"   // A systematic review is open
+	LibraryConfirmed     LibraryStatus = "CONFIRMED"      // Confirmed by its last systematic review
+	LibraryUnderRevision LibraryStatus = "UNDER_REVISION" // Remains current while a revision project replaces it
+	LibraryWithdrawn     LibraryStatus = "WITHDRAWN"
+)
+
+// ReviewDecision is the position an NSB takes on a standard under systematic review
+type ReviewDecision string
+
+const (
+	ReviewConfirm  ReviewDecision = "CONFIRM"
+	ReviewRevise   ReviewDecision = "REVISE"
+	ReviewAmend    ReviewDecision = "AMEND"
+	ReviewWithdraw ReviewDecision = "WITHDRAW"
+)
+
+var ErrInvalidReviewDecision = errors.New("decision must be CONFIRM, REVISE, AMEND or WITHDRAW")
+
+// Validate rejects decisions other than the four review outcomes
+func (d ReviewDecision) Validate() error {
+	switch d {
+	case ReviewConfirm, ReviewRevise, ReviewAmend, ReviewWithdraw:
+		return nil
+	}
+	return ErrInvalidReviewDecision
+}
+
+type SystematicReviewStatus string
+
+const (
+	SystematicReviewOpen   SystematicReviewStatus = "OPEN"
+	SystematicReviewClosed SystematicReviewStatus = "CLOSED"
+)
+
+// SystematicReview is a campaign in which NSBs decide whether a published standard is confirmed,
+// revised, amended or withdrawn
+type SystematicReview struct {
+	ID                uuid.UUID                  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID         string                     `json:"project_id" gorm:"type:uuid;index"`
+	Project           *Project                   `json:"project,omitempty"`
+	Reference         string                     `json:"reference"` // Reference of the standard when the review opened
+	Status            SystematicReviewStatus     `json:"status" gorm:"index"`
+	OpensAt           time.Time                  `json:"opens_at"`
+	ClosesAt          time.Time                  `json:"closes_at" gorm:"index"`
+	Responses         []SystematicReviewResponse `json:"responses,omitempty" gorm:"foreignKey:ReviewID"`
+	Tally             *ReviewTally               `json:"tally,omitempty" gorm:"serializer:json"` // Set when the review closes
+	Outcome           ReviewDecision             `json:"outcome,omitempty"`
+	RevisionProjectID *string                    `json:"revision_project_id"` // Project created to revise or amend the standard
+	RevisionProject   *Project                   `json:"revision_project,omitempty"`
+	ClosedAt          *time.Time                 `json:"closed_at"`
+	ClosedByID        *string                    `json:"closed_by_id"` // Null when closed by the scheduler
+	ClosedBy          *Member                    `json:"closed_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+}
+
+// IsClosed reports whether the review no longer accepts responses
+func (r *SystematicReview) IsClosed() bool {
+	return r.Status != SystematicReviewOpen || time.Now().After(r.ClosesAt)
+}
+
+// SystematicReviewResponse is the current response of an NSB to a review. NSBs may change their
+// response while the review is open.
+type SystematicReviewResponse struct {
+	ID                     uuid.UUID             `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ReviewID               uuid.UUID             `json:"review_id" gorm:"type:uuid;uniqueIndex:idx_review_nsb"`
+	NationalStandardBodyID string                `json:"nsb_id" gorm:"type:uuid;uniqueIndex:idx_review_nsb"`
+	NationalStandardBody   *NationalStandardBody `json:"nsb,omitempty"`
+	MemberID               string                `json:"member_id"`
+	Member                 *Member               `json:"national_secretary,omitempty"`
+	Decision               ReviewDecision        `json:"decision"`
+	Comment                string                `json:"comment"`
+	CreatedAt              time.Time             `json:"created_at"`
+	UpdatedAt              time.Time             `json:"updated_at"`
+}
+
+// ReviewTally counts the responses to a review by decision
+type ReviewTally struct {
+	Confirm  int `json:"confirm"`
+	Revise   int `json:"revise"`
+	Amend    int `json:"amend"`
+	Withdraw int `json:"withdraw"`
+	Total    int `json:"total"`
+}
+
+// TallyReviewResponses counts the responses by decision
+func TallyReviewResponses(responses []SystematicReviewResponse) ReviewTally {
+	var tally ReviewTally
+	for _, response := range responses {
+		switch response.Decision {
+		case ReviewConfirm:
+			tally.Confirm++
+		case ReviewRevise:
+			tally.Revise++
+		case ReviewAmend:
+			tally.Amend++
+		case ReviewWithdraw:
+			tally.Withdraw++
+		default:
+			continue
+		}
+		tally.Total++
+	}
+	return tally
+}
+
+// Outcome is the decision the responses support. A standard is withdrawn only when a majority of
+// the responding NSBs ask for it. Otherwise it is revised or amended when more NSBs ask for a
+// change than for confirmation, and confirmed in every other case, including when nobody responded.
+func (t ReviewTally) Outcome() ReviewDecision {
+	if t.Withdraw*2 > t.Total {
+		return ReviewWithdraw
+	}
+	if t.Revise+t.Amend > t.Confirm {
+		if t.Amend > t.Revise {
+			return ReviewAmend
+		}
+		return ReviewRevise
+	}
+	return ReviewConfirm
+}
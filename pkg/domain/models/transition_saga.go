@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransitionSagaStatus string
+
+const (
+	SagaPending     TransitionSagaStatus = "PENDING"
+	SagaRunning     TransitionSagaStatus = "RUNNING"
+	SagaCompleted   TransitionSagaStatus = "COMPLETED"
+	SagaFailed      TransitionSagaStatus = "FAILED" // Retries are exhausted, waits for an administrator
+	SagaCompensated TransitionSagaStatus = "COMPENSATED"
+)
+
+// SagaStep is one step of the document work that follows a stage transition
+type SagaStep string
+
+const (
+	SagaStepCopyFile       SagaStep = "COPY_ONEDRIVE_FILE"      // Copy the draft in OneDrive under the new reference
+	SagaStepLinkFile       SagaStep = "LINK_SHAREPOINT_DOC"     // Point the project at the copy
+	SagaStepCreateDocument SagaStep = "CREATE_PROJECT_DOCUMENT" // Record the copy as the project document of the new stage
+)
+
+// TransitionSagaSteps are the steps of a transition saga in execution order. Compensation runs the
+// completed steps in reverse.
+var TransitionSagaSteps = []SagaStep{SagaStepCopyFile, SagaStepLinkFile, SagaStepCreateDocument}
+
+const (
+	MaxSagaAttempts   = 8
+	sagaRetryDelay    = time.Minute
+	sagaMaxRetryDelay = 2 * time.Hour
+)
+
+// TransitionSaga is the outbox entry of a stage transition whose document has to be copied in
+// OneDrive. It is written in the transaction of the transition, so the transition is never
+// committed without it, and is then driven to completion step by step.
+type TransitionSaga struct {
+	ID                    uuid.UUID            `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	ProjectID             string               `json:"project_id" gorm:"type:uuid;index"`
+	Project               *Project             `json:"project,omitempty"`
+	ProjectNumber         int64                `json:"project_number"` // Selects the project's OneDrive folder
+	Reference             string               `json:"reference"`      // Reference the project moved to
+	Transition            string               `json:"transition"`     // Reason recorded in the stage history
+	DocType               string               `json:"doc_type"`       // Project document created by the saga: WD, CD, DARS, FDARS or ARS
+	FileName              string               `json:"file_name"`
+	CreatedByID           string               `json:"created_by_id"` // Member recorded as the author of the document
+	SourceSharepointDocID string               `json:"source_sharepoint_doc_id"`
+	CopiedSharepointDocID *string              `json:"copied_sharepoint_doc_id"`
+	DocumentID            *string              `json:"document_id"`
+	PreviousDocumentID    *string              `json:"previous_document_id"` // Project document of the type before the saga replaced it
+	Status                TransitionSagaStatus `json:"status" gorm:"index"`
+	CompletedSteps        int                  `json:"completed_steps"` // Number of steps of TransitionSagaSteps completed so far
+	Attempts              int                  `json:"attempts"`
+	LastError             string               `json:"last_error,omitempty"`
+	NextAttemptAt         time.Time            `json:"next_attempt_at" gorm:"index"`
+	LockedUntil           *time.Time           `json:"locked_until"` // Lease of the worker processing the saga
+	CompletedAt           *time.Time           `json:"completed_at"`
+	Steps                 []TransitionSagaStep `json:"steps,omitempty" gorm:"foreignKey:SagaID"`
+	CreatedAt             time.Time            `json:"created_at"`
+	UpdatedAt             time.Time            `json:"updated_at"`
+}
+
+// TransitionSagaStep records a single execution of a step or of its compensation
+type TransitionSagaStep struct {
+	ID           uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	SagaID       uuid.UUID `json:"saga_id" gorm:"type:uuid;index"`
+	Step         SagaStep  `json:"step"`
+	Compensation bool      `json:"compensation"`
+	Succeeded    bool      `json:"succeeded"`
+	Error        string    `json:"error,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+}
+
+// SagaFileName is the name of the OneDrive copy of a draft circulated under the reference
+func SagaFileName(reference string) string {
+	return fmt.Sprintf("%s.docx", strings.ReplaceAll(reference, "/", "-"))
+}
+
+// NextStep returns the step to run next, or false when every step has completed
+func (saga *TransitionSaga) NextStep() (SagaStep, bool) {
+	if saga.CompletedSteps >= len(TransitionSagaSteps) {
+		return "", false
+	}
+	return TransitionSagaSteps[saga.CompletedSteps], true
+}
+
+// LastCompletedStep returns the step to compensate next, or false when nothing is left to undo
+func (saga *TransitionSaga) LastCompletedStep() (SagaStep, bool) {
+	if saga.CompletedSteps == 0 {
+		return "", false
+	}
+	return TransitionSagaSteps[saga.CompletedSteps-1], true
+}
+
+// RetryDelay doubles with every failed attempt, up to a couple of hours
+func (saga *TransitionSaga) RetryDelay() time.Duration {
+	delay := sagaRetryDelay
+	for i := 1; i < saga.Attempts && delay < sagaMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > sagaMaxRetryDelay {
+		return sagaMaxRetryDelay
+	}
+	return delay
+}
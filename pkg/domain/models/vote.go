@@ -1,20 +1,131 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// VoteDecision is the position an NSB takes on a ballot
+type VoteDecision string
+
+const (
+	VoteApprove             VoteDecision = "APPROVE"
+	VoteApproveWithComments VoteDecision = "APPROVE_WITH_COMMENTS"
+	VoteDisapprove          VoteDecision = "DISAPPROVE"
+	VoteAbstain             VoteDecision = "ABSTAIN"
+)
+
+var ErrInvalidVote = errors.New("invalid vote")
+
+// IsApproval reports whether the decision counts as an approving vote
+func (d VoteDecision) IsApproval() bool {
+	return d == VoteApprove || d == VoteApproveWithComments
+}
+
+// Vote is the current vote of an NSB on a ballot. Amendments replace the vote in place and are
+// kept as VoteRevision rows, so only the latest revision counts towards the result.
 type Vote struct {
-	ID          uuid.UUID  `json:"id"`
-	ProjectID   string     `json:"project_id" binding:"required"`
-	Project     *Project   `json:"project"`
-	MemberID    string     `json:"member_id"`
-	Member      *Member    `json:"national_secretary"`
-	BallotingID uuid.UUID  `json:"-"`
-	Balloting   *Balloting `json:"-"`
-	Acceptance  bool       `json:"acceptance" gorm:"default:false"`
-	Comment     string     `json:"comment"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ID                     uuid.UUID             `json:"id"`
+	ProjectID              string                `json:"project_id" binding:"required"`
+	Project                *Project              `json:"project"`
+	MemberID               string                `json:"member_id"`
+	Member                 *Member               `json:"national_secretary"`
+	NationalStandardBodyID *string               `json:"nsb_id" gorm:"uniqueIndex:idx_vote_ballot_nsb"`
+	NationalStandardBody   *NationalStandardBody `json:"nsb,omitempty"`
+	BallotingID            uuid.UUID             `json:"-" gorm:"uniqueIndex:idx_vote_ballot_nsb"`
+	Balloting              *Balloting            `json:"-"`
+	Decision               VoteDecision          `json:"decision" gorm:"index"`
+	Acceptance             bool                  `json:"acceptance" gorm:"default:false"` // Derived from Decision, kept for existing clients
+	Comment                string                `json:"comment"`
+	TechnicalComments      []VoteComment         `json:"technical_comments" binding:"dive" gorm:"constraint:OnDelete:CASCADE"`
+	Revision               int                   `json:"revision" gorm:"default:1"`
+	Revisions              []VoteRevision        `json:"revisions,omitempty"`
+	CreatedAt              time.Time             `json:"created_at"`
+	UpdatedAt              time.Time             `json:"updated_at"`
+}
+
+// VoteComment is a structured comment submitted with a vote
+type VoteComment struct {
+	ID     uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	VoteID uuid.UUID `json:"vote_id" gorm:"index"`
+	CommentReference
+	Comment        string    `json:"comment" binding:"required"`
+	ProposedChange string    `json:"proposed_change"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// VoteRevision records every version of a vote, including the original one
+type VoteRevision struct {
+	ID                uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	VoteID            uuid.UUID     `json:"vote_id" gorm:"uniqueIndex:idx_vote_revision"`
+	Revision          int           `json:"revision" gorm:"uniqueIndex:idx_vote_revision"`
+	MemberID          string        `json:"member_id"` // Secretary who cast this revision
+	Member            *Member       `json:"member,omitempty"`
+	Decision          VoteDecision  `json:"decision"`
+	Acceptance        bool          `json:"acceptance"`
+	Comment           string        `json:"comment"`
+	TechnicalComments []VoteComment `json:"technical_comments" gorm:"serializer:json"` // Snapshot of the comments at this revision
+	CreatedAt         time.Time     `json:"created_at"`
+}
+
+// PrepareDecision fills in the decision for clients that only send Acceptance, keeps Acceptance
+// in step with the decision and checks that the comments required by the decision are present.
+// A disapproval must be justified by at least one technical comment.
+func (vote *Vote) PrepareDecision() error {
+	if vote.Decision == "" {
+		vote.Decision = VoteDisapprove
+		if vote.Acceptance {
+			vote.Decision = VoteApprove
+		}
+	}
+
+	switch vote.Decision {
+	case VoteApprove, VoteAbstain:
+	case VoteApproveWithComments:
+		if len(vote.TechnicalComments) == 0 {
+			return fmt.Errorf("%w: an approval with comments must include at least one comment", ErrInvalidVote)
+		}
+	case VoteDisapprove:
+		hasTechnical := false
+		for _, comment := range vote.TechnicalComments {
+			if comment.CommentType == Technical {
+				hasTechnical = true
+				break
+			}
+		}
+		if !hasTechnical {
+			return fmt.Errorf("%w: a disapproval must include at least one technical comment giving the reasons", ErrInvalidVote)
+		}
+	default:
+		return fmt.Errorf("%w: unknown decision %q", ErrInvalidVote, vote.Decision)
+	}
+
+	for _, comment := range vote.TechnicalComments {
+		switch comment.CommentType {
+		case General, Technical, Editorial:
+		default:
+			return fmt.Errorf("%w: unknown comment type %q", ErrInvalidVote, comment.CommentType)
+		}
+	}
+
+	vote.Acceptance = vote.Decision.IsApproval()
+	return nil
+}
+
+// NewVoteRevision snapshots the vote as a revision
+func NewVoteRevision(vote *Vote) VoteRevision {
+	return VoteRevision{
+		ID:                uuid.New(),
+		VoteID:            vote.ID,
+		Revision:          vote.Revision,
+		MemberID:          vote.MemberID,
+		Decision:          vote.Decision,
+		Acceptance:        vote.Acceptance,
+		Comment:           vote.Comment,
+		TechnicalComments: vote.TechnicalComments,
+		CreatedAt:         time.Now(),
+	}
 }
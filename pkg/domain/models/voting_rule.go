@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VotingRule defines how a ballot result is determined. Rules are versioned: changing a rule
+// creates a new version and leaves the old row untouched so past results can be reproduced.
+type VotingRule struct {
+	ID                  uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Procedure           Procedure `json:"procedure" gorm:"uniqueIndex:idx_voting_rule_version"` // Empty for the default rule
+	Version             int       `json:"version" gorm:"uniqueIndex:idx_voting_rule_version"`
+	ApprovalThreshold   float64   `json:"approval_threshold" binding:"required,gt=0,lte=1"` // Share of counted votes that must approve
+	MinimumPMemberVotes int64     `json:"minimum_p_member_votes" binding:"gte=0"`           // Votes required from participating members
+	ExcludeAbstentions  bool      `json:"exclude_abstentions"`                              // Abstentions are left out of the counted votes
+	MaxNegativeShare    float64   `json:"max_negative_share" binding:"gte=0,lte=1"`         // Zero means no limit on negative votes
+	Active              bool      `json:"active" gorm:"default:true"`                       // Only the latest version is active
+	Notes               string    `json:"notes"`
+	CreatedByID         *string   `json:"created_by_id"`
+	CreatedBy           *Member   `json:"created_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// VoteTally holds the vote counts a voting rule is evaluated against
+type VoteTally struct {
+	TotalVotes       int64
+	PMemberVotes     int64
+	ApprovalVotes    int64
+	DisapprovalVotes int64
+	Abstentions      int64
+	NoPMembers       bool // The committee has no participating members, so no P-member minimum applies
+}
+
+// DefaultVotingRule is applied when no rule has been configured. It matches the original
+// 75% approval with at least three votes.
+func DefaultVotingRule() VotingRule {
+	return VotingRule{
+		ApprovalThreshold:   0.75,
+		MinimumPMemberVotes: 3,
+		ExcludeAbstentions:  true,
+		Active:              true,
+		Notes:               "Built-in default",
+	}
+}
+
+// Evaluate applies the rule to the tally
+func (rule *VotingRule) Evaluate(tally VoteTally) *AcceptanceCriteriaResult {
+	result := &AcceptanceCriteriaResult{
+		RequiredRate:     rule.ApprovalThreshold,
+		MaxNegativeShare: rule.MaxNegativeShare,
+		TotalVotes:       tally.TotalVotes,
+		PMemberVotes:     tally.PMemberVotes,
+		AcceptedVotes:    tally.ApprovalVotes,
+		DisapprovedVotes: tally.DisapprovalVotes,
+		Abstentions:      tally.Abstentions,
+		RuleProcedure:    rule.Procedure,
+		RuleVersion:      rule.Version,
+	}
+	if rule.ID != uuid.Nil {
+		ruleID := rule.ID
+		result.RuleID = &ruleID
+	}
+
+	if !tally.NoPMembers && tally.PMemberVotes < rule.MinimumPMemberVotes {
+		result.Message = fmt.Sprintf("At least %d votes from participating members are required.", rule.MinimumPMemberVotes)
+		return result
+	}
+	result.QuorumMet = true
+
+	counted := tally.TotalVotes
+	if rule.ExcludeAbstentions {
+		counted -= tally.Abstentions
+	}
+	if counted <= 0 {
+		result.Message = "No votes were counted."
+		return result
+	}
+
+	result.AcceptanceRate = float64(tally.ApprovalVotes) / float64(counted)
+	result.NegativeShare = float64(tally.DisapprovalVotes) / float64(counted)
+
+	approved := result.AcceptanceRate >= rule.ApprovalThreshold
+	negativeWithinLimit := rule.MaxNegativeShare == 0 || result.NegativeShare <= rule.MaxNegativeShare
+	result.CriteriaMet = approved && negativeWithinLimit
+
+	switch {
+	case result.CriteriaMet:
+		result.Message = fmt.Sprintf("Project accepted with %.1f%% approval (required: %.1f%%)",
+			result.AcceptanceRate*100, rule.ApprovalThreshold*100)
+	case !approved:
+		result.Message = fmt.Sprintf("Project not accepted. Current approval: %.1f%% (required: %.1f%%)",
+			result.AcceptanceRate*100, rule.ApprovalThreshold*100)
+	default:
+		result.Message = fmt.Sprintf("Project not accepted. Negative votes: %.1f%% (maximum: %.1f%%)",
+			result.NegativeShare*100, rule.MaxNegativeShare*100)
+	}
+
+	return result
+}
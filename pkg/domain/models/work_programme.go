@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// milestoneTargets are the stages whose target dates are agreed when a project is accepted
+var milestoneTargets = map[int]func(*Acceptance) *time.Time{
+	3: func(a *Acceptance) *time.Time { return a.TargetDateCD },
+	4: func(a *Acceptance) *time.Time { return a.TargetDateDARS },
+	5: func(a *Acceptance) *time.Time { return a.TargetDateFDARS },
+}
+
+// WorkProgrammeItem is a project on a TC work programme
+type WorkProgrammeItem struct {
+	ProjectID             string        `json:"project_id"`
+	Reference             string        `json:"reference"`
+	Title                 string        `json:"title"`
+	Type                  ProjectType   `json:"type"`
+	Status                ProjectStatus `json:"status"`
+	WorkingGroupID        *string       `json:"working_group_id"`
+	WorkingGroup          string        `json:"working_group"` // Responsible working group, empty when the TC works on the project itself
+	StageNumber           int           `json:"stage_number"`
+	StageName             string        `json:"stage_name"`
+	StageAbbreviation     string        `json:"stage_abbreviation"`
+	StageStartedAt        *time.Time    `json:"stage_started_at"`
+	NextStage             string        `json:"next_stage"`  // Abbreviation of the stage the project moves to next
+	TargetDate            *time.Time    `json:"target_date"` // Date the project is due to reach its next stage
+	TargetPublicationDate *time.Time    `json:"target_publication_date"`
+	SlippageDays          int64         `json:"slippage_days"` // Days the project is behind its target date
+}
+
+// WorkProgramme lists the projects in progress in a technical committee
+type WorkProgramme struct {
+	TechnicalCommitteeID string              `json:"technical_committee_id"`
+	CommitteeCode        string              `json:"committee_code"`
+	CommitteeName        string              `json:"committee_name"`
+	GeneratedAt          time.Time           `json:"generated_at"`
+	Items                []WorkProgrammeItem `json:"items"`
+	BehindSchedule       int                 `json:"behind_schedule"` // Items with slippage
+}
+
+type WorkProgrammeStatus string
+
+const (
+	WorkProgrammeDraft      WorkProgrammeStatus = "DRAFT"
+	WorkProgrammePublished  WorkProgrammeStatus = "PUBLISHED"
+	WorkProgrammeSuperseded WorkProgrammeStatus = "SUPERSEDED" // Replaced by a later published version
+)
+
+// WorkProgrammeSnapshot is a numbered version of a TC work programme. A TC has at most one
+// published version at a time.
+type WorkProgrammeSnapshot struct {
+	ID                   uuid.UUID           `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	TechnicalCommitteeID string              `json:"technical_committee_id" gorm:"type:uuid;uniqueIndex:idx_work_programme_version"`
+	TechnicalCommittee   *TechnicalCommittee `json:"technical_committee,omitempty"`
+	Version              int64               `json:"version" gorm:"uniqueIndex:idx_work_programme_version"`
+	Status               WorkProgrammeStatus `json:"status" gorm:"index"`
+	Programme            WorkProgramme       `json:"programme" gorm:"serializer:json"`
+	GeneratedByID        string              `json:"generated_by_id"`
+	GeneratedBy          *Member             `json:"generated_by,omitempty"`
+	PublishedByID        *string             `json:"published_by_id"`
+	PublishedBy          *Member             `json:"published_by,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	PublishedAt          *time.Time          `json:"published_at"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+// BuildWorkProgrammeItem places a project on the work programme. The target date is the one agreed
+// at acceptance for the project's next milestone, or the planned end of its current stage. The
+// project's stage, working group and acceptance must be loaded.
+func BuildWorkProgrammeItem(project *Project, sla ProjectSLA, now time.Time) WorkProgrammeItem {
+	item := WorkProgrammeItem{
+		ProjectID:      project.ID.String(),
+		Reference:      project.Reference,
+		Title:          project.Title,
+		Type:           project.Type,
+		Status:         project.Status,
+		WorkingGroupID: project.WorkingGroupID,
+	}
+	if project.WorkingGroup != nil {
+		item.WorkingGroup = project.WorkingGroup.Name
+	}
+	if project.Stage != nil {
+		item.StageNumber = project.Stage.Number
+		item.StageName = project.Stage.Name
+		item.StageAbbreviation = project.Stage.Abbreviation
+	}
+
+	for i, stage := range sla.Stages {
+		if i == len(sla.Stages)-1 {
+			end := stage.Plan.PlannedEnd
+			item.TargetPublicationDate = &end
+		}
+		if stage.Plan.StageNumber != item.StageNumber {
+			continue
+		}
+
+		item.StageStartedAt = stage.ActualStart
+		if stage.Plan.DurationDays > 0 {
+			end := stage.Plan.PlannedEnd
+			item.TargetDate = &end
+		}
+		if i+1 < len(sla.Stages) {
+			next := sla.Stages[i+1].Plan
+			if next.Stage != nil {
+				item.NextStage = next.Stage.Abbreviation
+			}
+			if target, ok := milestoneTargets[next.StageNumber]; ok && project.Acceptance != nil && target(project.Acceptance) != nil {
+				item.TargetDate = target(project.Acceptance)
+			}
+		}
+	}
+
+	if item.TargetDate != nil && now.After(*item.TargetDate) {
+		item.SlippageDays = int64(now.Sub(*item.TargetDate).Hours() / 24)
+	}
+	return item
+}
+
+// Text lists the work programme one project per line, as kept on the technical committee
+func (p *WorkProgramme) Text() string {
+	var b strings.Builder
+	for _, item := range p.Items {
+		fmt.Fprintf(&b, "%s %s (%s", item.Reference, item.Title, item.StageAbbreviation)
+		if item.TargetDate != nil {
+			fmt.Fprintf(&b, ", target %s", item.TargetDate.Format("2006-01-02"))
+		}
+		if item.WorkingGroup != "" {
+			fmt.Fprintf(&b, ", %s", item.WorkingGroup)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,219 @@
+package models
+
+import "slices"
+
+// TransitionGuard names a condition that must hold on a project before it may move between stages
+type TransitionGuard string
+
+const (
+	GuardProposalSubmitted    TransitionGuard = "PROPOSAL_SUBMITTED"
+	GuardAcceptanceApproved   TransitionGuard = "ACCEPTANCE_APPROVED"
+	GuardWorkingDraftAccepted TransitionGuard = "WORKING_DRAFT_ACCEPTED"
+	GuardConsensusReached     TransitionGuard = "CONSENSUS_REACHED"
+	GuardDARSApproved         TransitionGuard = "DARS_APPROVED"
+	GuardBallotApproved       TransitionGuard = "BALLOT_APPROVED"
+	GuardNotCancelled         TransitionGuard = "NOT_CANCELLED"
+	GuardAdoptionRegistered   TransitionGuard = "ADOPTION_REGISTERED"
+)
+
+// StageTransition declares a permitted move between two seeded stages (by stage number)
+type StageTransition struct {
+	Name             string            `json:"name"`
+	FromStage        int               `json:"from_stage"`
+	ToStage          int               `json:"to_stage"`
+	Procedures       []Procedure       `json:"procedures,omitempty"`        // Only these procedures may use the transition
+	ExceptProcedures []Procedure       `json:"except_procedures,omitempty"` // These procedures may not use the transition
+	Guards           []TransitionGuard `json:"guards"`
+}
+
+// AvailableTransition describes a transition out of a project's current stage and whether its guards hold
+type AvailableTransition struct {
+	Name        string            `json:"name"`
+	FromStage   *Stage            `json:"from_stage"`
+	ToStage     *Stage            `json:"to_stage"`
+	Guards      []TransitionGuard `json:"guards"`
+	UnmetGuards []TransitionGuard `json:"unmet_guards"`
+	Allowed     bool              `json:"allowed"`
+}
+
+// ProjectWorkflow is the declarative definition of the project lifecycle across the seeded stages:
+// PWI (0), NWIP (1), WD (2), CD (3), DARS (4), FDARS (5) and Approval (6)
+var ProjectWorkflow = []StageTransition{
+	{
+		Name:             "SUBMIT_PROPOSAL",
+		FromStage:        0,
+		ToStage:          1,
+		ExceptProcedures: []Procedure{Adoption},
+		Guards:           []TransitionGuard{GuardNotCancelled, GuardProposalSubmitted},
+	},
+	{
+		// Adoptions of international documents skip the preparatory and committee stages
+		Name:       "REGISTER_ADOPTION",
+		FromStage:  0,
+		ToStage:    4,
+		Procedures: []Procedure{Adoption},
+		Guards:     []TransitionGuard{GuardNotCancelled, GuardAdoptionRegistered},
+	},
+	{
+		Name:             "ACCEPT_PROPOSAL",
+		FromStage:        1,
+		ToStage:          2,
+		ExceptProcedures: []Procedure{FastTrack, Amendment, Corrigendum},
+		Guards:           []TransitionGuard{GuardNotCancelled, GuardAcceptanceApproved},
+	},
+	{
+		Name:       "ACCEPT_PROPOSAL_FAST_TRACK",
+		FromStage:  1,
+		ToStage:    4,
+		Procedures: []Procedure{FastTrack},
+		Guards:     []TransitionGuard{GuardNotCancelled, GuardAcceptanceApproved},
+	},
+	{
+		// Amendments go straight to enquiry once the NSBs accept the proposal
+		Name:       "ACCEPT_AMENDMENT_PROPOSAL",
+		FromStage:  1,
+		ToStage:    4,
+		Procedures: []Procedure{Amendment},
+		Guards:     []TransitionGuard{GuardNotCancelled, GuardAcceptanceApproved},
+	},
+	{
+		// Corrigenda change no technical content and are approved once the NSBs accept them
+		Name:       "ACCEPT_CORRIGENDUM",
+		FromStage:  1,
+		ToStage:    6,
+		Procedures: []Procedure{Corrigendum},
+		Guards:     []TransitionGuard{GuardNotCancelled, GuardAcceptanceApproved},
+	},
+	{
+		Name:             "ACCEPT_WORKING_DRAFT",
+		FromStage:        2,
+		ToStage:          3,
+		ExceptProcedures: []Procedure{WorkshopAgreement},
+		Guards:           []TransitionGuard{GuardNotCancelled, GuardWorkingDraftAccepted},
+	},
+	{
+		Name:       "ACCEPT_WORKING_DRAFT_SKIP_COMMITTEE",
+		FromStage:  2,
+		ToStage:    4,
+		Procedures: []Procedure{WorkshopAgreement},
+		Guards:     []TransitionGuard{GuardNotCancelled, GuardWorkingDraftAccepted},
+	},
+	{
+		Name:             "REGISTER_FOR_ENQUIRY",
+		FromStage:        3,
+		ToStage:          4,
+		ExceptProcedures: []Procedure{TechnicalSpecification, TechnicalReport, PublicAvailableSpecification},
+		Guards:           []TransitionGuard{GuardNotCancelled, GuardConsensusReached},
+	},
+	{
+		Name:       "REGISTER_FOR_APPROVAL",
+		FromStage:  3,
+		ToStage:    6,
+		Procedures: []Procedure{TechnicalSpecification, TechnicalReport, PublicAvailableSpecification},
+		Guards:     []TransitionGuard{GuardNotCancelled, GuardConsensusReached},
+	},
+	{
+		Name:             "ADVANCE_TO_BALLOT",
+		FromStage:        4,
+		ToStage:          5,
+		ExceptProcedures: []Procedure{GuidesAndGuidelines, WorkshopAgreement},
+		Guards:           []TransitionGuard{GuardNotCancelled, GuardDARSApproved},
+	},
+	{
+		Name:       "ADVANCE_TO_APPROVAL_SKIP_BALLOT",
+		FromStage:  4,
+		ToStage:    6,
+		Procedures: []Procedure{GuidesAndGuidelines, WorkshopAgreement},
+		Guards:     []TransitionGuard{GuardNotCancelled, GuardDARSApproved},
+	},
+	{
+		Name:      "APPROVE_FDARS",
+		FromStage: 5,
+		ToStage:   6,
+		Guards:    []TransitionGuard{GuardNotCancelled, GuardBallotApproved},
+	},
+}
+
+// AppliesTo reports whether the transition may be used by a project following the given procedure
+func (t StageTransition) AppliesTo(procedure Procedure) bool {
+	if len(t.Procedures) > 0 && !slices.Contains(t.Procedures, procedure) {
+		return false
+	}
+	return !slices.Contains(t.ExceptProcedures, procedure)
+}
+
+// UnmetGuards returns the guards of the transition that the project does not satisfy
+func (t StageTransition) UnmetGuards(project *Project) []TransitionGuard {
+	unmet := []TransitionGuard{}
+	for _, guard := range t.Guards {
+		if !guard.IsSatisfied(project) {
+			unmet = append(unmet, guard)
+		}
+	}
+	return unmet
+}
+
+// IsSatisfied evaluates the guard against the project. The project must be loaded with
+// its Proposal, Acceptance, DARS, Balloting and Adoption associations.
+func (g TransitionGuard) IsSatisfied(project *Project) bool {
+	switch g {
+	case GuardProposalSubmitted:
+		return project.Proposal != nil
+	case GuardAcceptanceApproved:
+		return project.Acceptance != nil && project.Acceptance.IsApproved
+	case GuardWorkingDraftAccepted:
+		return project.WorkingDraftStatus == ACCEPTED
+	case GuardConsensusReached:
+		return project.IsConsensusReached
+	case GuardDARSApproved:
+		return project.DARS != nil && project.DARS.Status == DARSApproved
+	case GuardBallotApproved:
+		return project.Balloting != nil && project.Balloting.Approved
+	case GuardNotCancelled:
+		return project.IsActive()
+	case GuardAdoptionRegistered:
+		return project.Adoption != nil
+	default:
+		return false
+	}
+}
+
+// FindStageTransition returns the workflow transition between two stages for the given procedure
+func FindStageTransition(procedure Procedure, fromStage, toStage int) (*StageTransition, bool) {
+	for _, transition := range ProjectWorkflow {
+		if transition.FromStage == fromStage && transition.ToStage == toStage && transition.AppliesTo(procedure) {
+			return &transition, true
+		}
+	}
+	return nil, false
+}
+
+// StageTransitionsFrom returns every workflow transition leaving the given stage for the given procedure
+func StageTransitionsFrom(procedure Procedure, fromStage int) []StageTransition {
+	var transitions []StageTransition
+	for _, transition := range ProjectWorkflow {
+		if transition.FromStage == fromStage && transition.AppliesTo(procedure) {
+			transitions = append(transitions, transition)
+		}
+	}
+	return transitions
+}
+
+// StagePath returns the stage numbers a project following the procedure passes through, from the
+// preliminary stage to approval
+func StagePath(procedure Procedure) []int {
+	path := []int{0}
+	for current := 0; ; {
+		next := -1
+		for _, transition := range StageTransitionsFrom(procedure, current) {
+			if transition.ToStage > current && (next == -1 || transition.ToStage < next) {
+				next = transition.ToStage
+			}
+		}
+		if next == -1 {
+			return path
+		}
+		path = append(path, next)
+		current = next
+	}
+}
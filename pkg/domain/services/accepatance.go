@@ -1,9 +1,6 @@
 package services
 
 import (
-	"context"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/ekbaya/asham/pkg/db/repository"
@@ -12,19 +9,34 @@ import (
 )
 
 type AcceptanceService struct {
-	repo           *repository.AcceptanceRepository
-	docService     *DocumentService
-	projectService *ProjectService
-}
-
-func NewAcceptanceService(repo *repository.AcceptanceRepository, docService *DocumentService, projectService *ProjectService) *AcceptanceService {
-	return &AcceptanceService{repo: repo, docService: docService, projectService: projectService}
+	repo                *repository.AcceptanceRepository
+	changeRepo          *repository.NSBResponseStatusChangeRepository
+	sagaService         *SagaService
+	auditLogService     *AuditLogService
+	notificationService *NotificationService
+}
+
+func NewAcceptanceService(repo *repository.AcceptanceRepository, changeRepo *repository.NSBResponseStatusChangeRepository, sagaService *SagaService, auditLogService *AuditLogService, notificationService *NotificationService) *AcceptanceService {
+	return &AcceptanceService{
+		repo:                repo,
+		changeRepo:          changeRepo,
+		sagaService:         sagaService,
+		auditLogService:     auditLogService,
+		notificationService: notificationService,
+	}
 }
 
+// CreateNSBResponse records an NSB's response to a proposal while it circulates and refreshes the
+// response totals
 func (service *AcceptanceService) CreateNSBResponse(response *models.NSBResponse) error {
 	response.ID = uuid.New()
 	response.ResponseDate = time.Now()
-	return service.repo.CreateNSBResponse(response)
+	if err := service.repo.CreateNSBResponse(response); err != nil {
+		return err
+	}
+
+	service.refreshAcceptance(response.ProjectID)
+	return nil
 }
 
 func (service *AcceptanceService) GetNSBResponse(id string) (*models.NSBResponse, error) {
@@ -36,11 +48,20 @@ func (service *AcceptanceService) GetNSBResponsesByProjectID(projectID string) (
 }
 
 func (service *AcceptanceService) UpdateNSBResponse(response *models.NSBResponse) error {
-	return service.repo.UpdateNSBResponse(response)
+	if err := service.repo.UpdateNSBResponse(response); err != nil {
+		return err
+	}
+	service.refreshAcceptance(response.ProjectID)
+	return nil
 }
 
 func (service *AcceptanceService) DeleteNSBResponse(id string) error {
-	return service.repo.DeleteNSBResponse(id)
+	response, err := service.repo.DeleteNSBResponse(id)
+	if err != nil {
+		return err
+	}
+	service.refreshAcceptance(response.ProjectID)
+	return nil
 }
 
 func (service *AcceptanceService) GetAcceptance(id string) (*models.Acceptance, error) {
@@ -72,34 +93,14 @@ func (service *AcceptanceService) CalculateNSBResponseStats(projectID string) er
 }
 
 func (service *AcceptanceService) SetAcceptanceApproval(acceptance models.Acceptance) error {
-	err := service.repo.SetAcceptanceApproval(acceptance)
-	if err == nil {
-		projectUUID, err := uuid.Parse(acceptance.ProjectID)
-		if err != nil {
-			return err
-		}
-		project, err := service.projectService.GetProjectByID(projectUUID)
-		if err != nil {
-			return err
-		}
-		fileName := fmt.Sprintf("%s.docx", strings.ReplaceAll(project.Reference, "/", "-"))
-		doc, errr := service.docService.CopyOneDriveFile(context.Background(), *project.SharepointDocID, fileName, project.Number)
-		if errr != nil {
-			return fmt.Errorf("failed to copy OneDrive file: %w", errr)
-		}
-		project.SharepointDocID = &doc.ID
-		err = service.projectService.UpdateProject(project, nil, "", "", "", "")
-		if err != nil {
-			return fmt.Errorf("failed to update project after WD review: %w", err)
-		}
-
-		err = service.docService.UpdateProjectDoc(acceptance.ProjectID, "WD", fileName, project.MemberID)
-		if err != nil {
-			return fmt.Errorf("failed to create WD: %w", err)
-		}
-
+	saga, err := service.repo.SetAcceptanceApproval(acceptance)
+	if err != nil {
+		return err
 	}
-	return err
+
+	// The WD copy of the draft is made by the transition saga
+	service.sagaService.Start(saga, nil)
+	return nil
 }
 
 func (service *AcceptanceService) GetAcceptanceResults(id string) (*models.AcceptanceResults, error) {
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+)
+
+// OpenCirculation sets the window in which the NSBs can respond to a project's proposal
+func (service *AcceptanceService) OpenCirculation(projectID, secretary string, circulationDate, closingDate time.Time, ipAddress, userAgent, sessionID, requestID string) (*models.Acceptance, error) {
+	startTime := time.Now()
+
+	acceptance, err := service.repo.OpenCirculation(projectID, secretary, circulationDate, closingDate)
+	service.logAcceptance(models.ActionProjectUpdate, projectID, fmt.Sprintf("Opened the circulation of the proposal until %s", closingDate.Format("2006-01-02")), map[string]interface{}{
+		"circulation_date": circulationDate,
+		"closing_date":     closingDate,
+	}, err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	return acceptance, err
+}
+
+// GrantCirculationOverride lets an NSB respond to a project's proposal outside its circulation
+// window until the override expires
+func (service *AcceptanceService) GrantCirculationOverride(projectID string, override *models.CirculationOverride, secretary string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+	override.GrantedByID = secretary
+
+	err := service.repo.GrantCirculationOverride(projectID, override)
+	service.logAcceptance(models.ActionProjectUpdate, projectID, fmt.Sprintf("Allowed NSB %s to respond to the proposal until %s", override.NationalStandardBodyID, override.ExpiresAt.Format("2006-01-02")), map[string]interface{}{
+		"override_id":               override.ID,
+		"national_standard_body_id": override.NationalStandardBodyID,
+		"expires_at":                override.ExpiresAt,
+		"reason":                    override.Reason,
+	}, err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	return err
+}
+
+func (service *AcceptanceService) GetCirculationOverrides(projectID string) ([]models.CirculationOverride, error) {
+	return service.repo.GetCirculationOverrides(projectID)
+}
+
+// CompileDueAcceptances compiles the results of the proposals whose circulation has closed. It is
+// run by the scheduler, failures on one proposal do not stop the others from being compiled.
+func (service *AcceptanceService) CompileDueAcceptances() error {
+	acceptances, err := service.repo.FindAcceptancesDueForCompilation()
+	if err != nil {
+		return fmt.Errorf("failed to get proposals due for compilation: %w", err)
+	}
+
+	var failed int
+	for _, acceptance := range acceptances {
+		if _, err := service.compileAcceptance(acceptance.ProjectID); err != nil {
+			fmt.Printf("Failed to compile the responses to project %s: %v\n", acceptance.ProjectID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to compile %d of %d proposals", failed, len(acceptances))
+	}
+	return nil
+}
+
+// refreshAcceptance brings the response totals of a proposal up to date after a response. Proposals
+// already compiled, e.g. when an NSB responds late under an override, are compiled again. The
+// response stands if the totals cannot be refreshed.
+func (service *AcceptanceService) refreshAcceptance(projectID string) {
+	acceptance, err := service.repo.GetAcceptanceByProjectID(projectID)
+	if err == nil && acceptance.CompiledAt != nil {
+		_, err = service.compileAcceptance(projectID)
+	} else if err == nil {
+		err = service.repo.CalculateNSBResponseStats(projectID)
+	}
+	if err != nil {
+		fmt.Printf("Failed to refresh the response totals of project %s: %v\n", projectID, err)
+	}
+}
+
+// compileAcceptance applies the acceptance rule to the responses to a proposal and puts the
+// proposal to the SMC when the criteria are met for the first time
+func (service *AcceptanceService) compileAcceptance(projectID string) (*models.Acceptance, error) {
+	startTime := time.Now()
+
+	acceptance, opened, err := service.repo.CompileAcceptance(projectID)
+	metadata := map[string]interface{}{}
+	if acceptance != nil {
+		metadata["approval_criteria_met"] = acceptance.ApprovalCriteriaMet
+		metadata["criteria_comments"] = acceptance.CriteriaComments
+		metadata["acceptance_rule_id"] = acceptance.AcceptanceRuleID
+		metadata["smc_approval_opened"] = opened
+	}
+	service.logAcceptance(models.ActionWorkflowTransition, projectID, "Compiled the responses to the proposal", metadata,
+		err, startTime, nil, "", "", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if opened && service.notificationService != nil && acceptance.Project != nil {
+		if err := service.notificationService.NotifySMCApprovalOpened(acceptance.Project, acceptance); err != nil {
+			fmt.Printf("Failed to send SMC approval notification for project %s: %v\n", projectID, err)
+		}
+	}
+	return acceptance, nil
+}
+
+// SaveAcceptanceRule stores a new version of the acceptance rule for the rule's basis
+func (service *AcceptanceService) SaveAcceptanceRule(rule *models.AcceptanceRule, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+	rule.CreatedByID = userID
+
+	err := service.repo.SaveAcceptanceRule(rule)
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	if service.auditLogService != nil {
+		ruleID := rule.ID.String()
+		service.auditLogService.LogAction(LogActionParams{
+			UserID:        userID,
+			Action:        models.ActionConfigUpdate,
+			Module:        models.ModuleProjects,
+			ResourceType:  "AcceptanceRule",
+			ResourceID:    &ruleID,
+			ResourceTitle: fmt.Sprintf("Acceptance rule %s v%d", rule.Basis, rule.Version),
+			Description:   fmt.Sprintf("Saved acceptance rule for %s proposals", rule.Basis),
+			Metadata: map[string]interface{}{
+				"basis":                     rule.Basis,
+				"version":                   rule.Version,
+				"minimum_votes":             rule.MinimumVotes,
+				"approval_threshold":        rule.ApprovalThreshold,
+				"minimum_participants":      rule.MinimumParticipants,
+				"participants_must_approve": rule.ParticipantsMustApprove,
+			},
+			IPAddress:    ipAddress,
+			UserAgent:    userAgent,
+			SessionID:    sessionID,
+			RequestID:    requestID,
+			Success:      err == nil,
+			ErrorMessage: errorMsg,
+			Duration:     time.Since(startTime).Milliseconds(),
+		})
+	}
+
+	return err
+}
+
+func (service *AcceptanceService) GetActiveAcceptanceRules() ([]models.AcceptanceRule, error) {
+	return service.repo.GetActiveAcceptanceRules()
+}
+
+func (service *AcceptanceService) GetAcceptanceRuleVersions(basis models.AcceptanceBasis) ([]models.AcceptanceRule, error) {
+	return service.repo.GetAcceptanceRuleVersions(basis)
+}
+
+// GetApplicableAcceptanceRule returns the rule that would decide a proposal of the basis today
+func (service *AcceptanceService) GetApplicableAcceptanceRule(basis models.AcceptanceBasis) (*models.AcceptanceRule, error) {
+	return service.repo.GetApplicableAcceptanceRule(basis)
+}
+
+func (service *AcceptanceService) logAcceptance(action models.ActionType, projectID, description string, metadata map[string]interface{}, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	service.auditLogService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        action,
+		Module:        models.ModuleProjects,
+		ResourceType:  "Acceptance",
+		ResourceID:    &projectID,
+		ResourceTitle: fmt.Sprintf("Acceptance of project %s", projectID),
+		Description:   description,
+		Metadata:      metadata,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+		SessionID:     sessionID,
+		RequestID:     requestID,
+		Success:       err == nil,
+		ErrorMessage:  errorMsg,
+		Duration:      time.Since(startTime).Milliseconds(),
+	})
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+var ErrUnsupportedAcceptanceMatrixFormat = errors.New("the NSB response matrix can only be rendered as xlsx, docx or pdf")
+
+// AcceptanceMatrixFile is a rendered NSB response matrix ready for download
+type AcceptanceMatrixFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+func (service *AcceptanceService) GetAcceptanceMatrix(projectID string) (*models.AcceptanceMatrix, error) {
+	return service.repo.GetAcceptanceMatrix(projectID)
+}
+
+// RenderAcceptanceMatrix renders the NSB response matrix as an XLSX, DOCX or PDF document in the
+// layout of the compilation template
+func (service *AcceptanceService) RenderAcceptanceMatrix(matrix *models.AcceptanceMatrix, format string) (*AcceptanceMatrixFile, error) {
+	name := strings.NewReplacer(" ", "_", "/", "-").Replace(strings.TrimSpace(fmt.Sprintf("%s NSB responses", matrix.Reference)))
+
+	switch strings.ToLower(format) {
+	case "xlsx":
+		data, err := renderAcceptanceMatrixXLSX(matrix)
+		if err != nil {
+			return nil, err
+		}
+		return &AcceptanceMatrixFile{
+			Name:        name + ".xlsx",
+			ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			Data:        data,
+		}, nil
+	case "docx":
+		data, err := renderAcceptanceMatrixDOCX(matrix)
+		if err != nil {
+			return nil, err
+		}
+		return &AcceptanceMatrixFile{
+			Name:        name + ".docx",
+			ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			Data:        data,
+		}, nil
+	case "pdf":
+		data, err := renderAcceptanceMatrixPDF(matrix)
+		if err != nil {
+			return nil, err
+		}
+		return &AcceptanceMatrixFile{Name: name + ".pdf", ContentType: "application/pdf", Data: data}, nil
+	default:
+		return nil, ErrUnsupportedAcceptanceMatrixFormat
+	}
+}
+
+// ExportAcceptanceMatrix renders the current NSB response matrix of a project and stores it as a
+// related document of the project
+func (service *AcceptanceService) ExportAcceptanceMatrix(projectID, format, memberID string, ipAddress, userAgent, sessionID, requestID string) (*models.Document, error) {
+	startTime := time.Now()
+
+	matrix, err := service.repo.GetAcceptanceMatrix(projectID)
+	if err != nil {
+		return nil, err
+	}
+	file, err := service.RenderAcceptanceMatrix(matrix, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(importAssetsDir, 0755); err != nil {
+		return nil, err
+	}
+	filename := uuid.New().String() + filepath.Ext(file.Name)
+	target := filepath.Join(importAssetsDir, filename)
+	if err := os.WriteFile(target, file.Data, 0644); err != nil {
+		return nil, err
+	}
+
+	doc := models.Document{
+		CreatedByID: memberID,
+		Title:       acceptanceMatrixTitle(matrix),
+		Description: fmt.Sprintf("Compilation of the NSB responses to the proposal (%s), generated on %s", strings.ToUpper(strings.TrimPrefix(filepath.Ext(file.Name), ".")), matrix.GeneratedAt.Format("2 January 2006")),
+		Reference:   matrix.Reference,
+		FileURL:     "/assets/documents/" + filename,
+	}
+	err = service.repo.SaveAcceptanceMatrixDocument(projectID, &doc)
+	if err != nil {
+		os.Remove(target)
+	}
+
+	service.logAcceptance(models.ActionProjectUpdate, projectID, fmt.Sprintf("Exported the NSB response matrix as %s", file.Name), map[string]interface{}{
+		"document_id":  doc.ID,
+		"format":       strings.ToLower(format),
+		"criteria_met": matrix.Results.CriterialMet,
+		"responses":    matrix.Results.Totals.TotalResponses,
+	}, err, startTime, &memberID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,440 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	acceptanceMatrixRowsPerPage  = 18
+	acceptanceMatrixLinesPerPage = 32
+	acceptanceMatrixLineHeight   = 14
+	acceptanceMatrixFontSize     = 8
+	acceptanceMatrixTextSize     = 10
+)
+
+// acceptanceMatrixColumns are the columns of the compilation template circulated to the SMC, with
+// their share of the page width in percent
+var acceptanceMatrixColumns = []struct {
+	Title string
+	Width int
+}{
+	{"NSB", 19},
+	{"Feasible", 7},
+	{"Not feasible", 8},
+	{"Abstention", 8},
+	{"As NWIP", 7},
+	{"Progressing", 9},
+	{"As WD", 7},
+	{"As CD", 7},
+	{"As DARS", 8},
+	{"Comments", 9},
+	{"Participates", 11},
+}
+
+// acceptanceMatrixLine is a line of the evaluation and annex pages
+type acceptanceMatrixLine struct {
+	Text string
+	Bold bool
+}
+
+func acceptanceMatrixTitle(matrix *models.AcceptanceMatrix) string {
+	return fmt.Sprintf("Compilation of NSB responses - %s", matrix.Reference)
+}
+
+func acceptanceMatrixSubtitle(matrix *models.AcceptanceMatrix) string {
+	subtitle := matrix.Title
+	if matrix.CommitteeCode != "" {
+		subtitle = fmt.Sprintf("%s. %s %s", subtitle, matrix.CommitteeCode, matrix.CommitteeName)
+	}
+	if !matrix.ClosingDate.IsZero() {
+		subtitle = fmt.Sprintf("%s, circulated from %s to %s", subtitle,
+			matrix.CirculationDate.Format("2 January 2006"), matrix.ClosingDate.Format("2 January 2006"))
+	}
+	return fmt.Sprintf("%s. Generated on %s.", subtitle, matrix.GeneratedAt.Format("2 January 2006"))
+}
+
+func acceptanceMatrixHeaders() ([]string, []int) {
+	headers := make([]string, len(acceptanceMatrixColumns))
+	widths := make([]int, len(acceptanceMatrixColumns))
+	for i, column := range acceptanceMatrixColumns {
+		headers[i] = column.Title
+		widths[i] = column.Width
+	}
+	return headers, widths
+}
+
+// acceptanceMatrixRows lists one row per NSB followed by the totals row
+func acceptanceMatrixRows(matrix *models.AcceptanceMatrix) [][]string {
+	yesNo := func(value bool) string {
+		if value {
+			return "Y"
+		}
+		return "N"
+	}
+
+	rows := make([][]string, 0, len(matrix.Results.IndividualNSBResponses)+1)
+	for _, response := range matrix.Results.IndividualNSBResponses {
+		rows = append(rows, []string{
+			response.NSB,
+			yesNo(response.FeasibleYes),
+			yesNo(response.FeasibleNo),
+			yesNo(response.Abstention),
+			response.AcceptedAsNWIP,
+			response.AcceptedForProgressing,
+			response.AcceptedAsWD,
+			response.AcceptedAsCD,
+			response.AcceptedAsDARF,
+			yesNo(response.CommentsEnclosed),
+			yesNo(response.Participation),
+		})
+	}
+
+	totals := matrix.Results.Totals
+	rows = append(rows, []string{"Total"})
+	for _, count := range []int{
+		totals.FeasibleYesCount,
+		totals.FeasibleNoCount,
+		totals.AbstentionCount,
+		totals.AcceptedAsNWIPCount,
+		totals.AcceptedForProgressingCount,
+		totals.AcceptedAsWDCount,
+		totals.AcceptedAsCDCount,
+		totals.AcceptedAsDARFCount,
+		totals.CommentsCount,
+		totals.ParticipationCount,
+	} {
+		rows[len(rows)-1] = append(rows[len(rows)-1], strconv.Itoa(count))
+	}
+	return rows
+}
+
+// acceptanceMatrixEvaluation explains how the acceptance criteria were evaluated
+func acceptanceMatrixEvaluation(matrix *models.AcceptanceMatrix) []string {
+	rule := fmt.Sprintf("Rule: %s proposals, version %d", matrix.Rule.Basis, matrix.Rule.Version)
+	if matrix.Rule.Version == 0 {
+		rule = fmt.Sprintf("Rule: %s proposals, built-in default", matrix.Rule.Basis)
+	}
+
+	outcome := "Outcome: criteria not met"
+	if matrix.Results.CriterialMet {
+		outcome = "Outcome: criteria met"
+	}
+	return []string{
+		rule,
+		"Criteria: " + matrix.Rule.Summary(),
+		fmt.Sprintf("Responses: %d received, %d votes and %d abstentions",
+			matrix.Results.Totals.TotalResponses, matrix.Results.Totals.ValidResponses, matrix.Results.Totals.AbstentionCount),
+		outcome,
+		matrix.Results.CriterialComments,
+	}
+}
+
+// acceptanceMatrixComments returns the NSBs that enclosed comments with their comments
+func acceptanceMatrixComments(matrix *models.AcceptanceMatrix) []models.IndividualNSBResponse {
+	var comments []models.IndividualNSBResponse
+	for _, response := range matrix.Results.IndividualNSBResponses {
+		if strings.TrimSpace(response.Comments) != "" {
+			comments = append(comments, response)
+		}
+	}
+	return comments
+}
+
+// wrapPDFText breaks Helvetica text into lines no wider than the available width in points
+func wrapPDFText(text string, size int, available float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && font.TextWidth(candidate, "Helvetica", size) > available {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderAcceptanceMatrixPDF lays the matrix out as landscape A4 tables of a fixed number of rows per
+// page, followed by the evaluation of the criteria and the comments annex
+func renderAcceptanceMatrixPDF(matrix *models.AcceptanceMatrix) ([]byte, error) {
+	headers, widths := acceptanceMatrixHeaders()
+	rows := acceptanceMatrixRows(matrix)
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = fitPDFText(cell, acceptanceMatrixFontSize, float64(widths[i]*workProgrammeTableWidth)/100-2*workProgrammeCellPadding-2)
+		}
+	}
+
+	pages := map[string]interface{}{}
+	page := 0
+	for ; page == 0 || page*acceptanceMatrixRowsPerPage < len(rows); page++ {
+		end := (page + 1) * acceptanceMatrixRowsPerPage
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		content := map[string]interface{}{}
+		tableDY := 20
+		if page == 0 {
+			tableDY = 75
+			content["text"] = []map[string]interface{}{
+				{
+					"value":  acceptanceMatrixTitle(matrix),
+					"anchor": "TopLeft",
+					"dx":     0,
+					"dy":     10,
+					"font":   map[string]interface{}{"name": "Helvetica-Bold", "size": 14},
+				},
+				{
+					"value":  fitPDFText(acceptanceMatrixSubtitle(matrix), acceptanceMatrixTextSize, workProgrammeTableWidth),
+					"anchor": "TopLeft",
+					"dx":     0,
+					"dy":     35,
+					"font":   map[string]interface{}{"name": "Helvetica", "size": acceptanceMatrixTextSize},
+				},
+			}
+		}
+		content["table"] = []map[string]interface{}{{
+			"anchor":    "TopLeft",
+			"dx":        0,
+			"dy":        tableDY,
+			"rows":      end - page*acceptanceMatrixRowsPerPage,
+			"cols":      len(headers),
+			"width":     workProgrammeTableWidth,
+			"lheight":   18,
+			"colWidths": widths,
+			"grid":      true,
+			"font":      map[string]interface{}{"name": "Helvetica", "size": acceptanceMatrixFontSize},
+			"padding":   map[string]interface{}{"width": workProgrammeCellPadding},
+			"header": map[string]interface{}{
+				"values": headers,
+				"bgCol":  "#D9E2F3",
+				"font":   map[string]interface{}{"name": "Helvetica-Bold", "size": acceptanceMatrixFontSize},
+			},
+			"values": rows[page*acceptanceMatrixRowsPerPage : end],
+		}}
+		pages[strconv.Itoa(page+1)] = map[string]interface{}{"content": content}
+	}
+
+	// The evaluation and the annex follow on pages of their own
+	lines := []acceptanceMatrixLine{{Text: "Evaluation of the acceptance criteria", Bold: true}}
+	for _, text := range acceptanceMatrixEvaluation(matrix) {
+		for _, line := range wrapPDFText(text, acceptanceMatrixTextSize, workProgrammeTableWidth) {
+			lines = append(lines, acceptanceMatrixLine{Text: line})
+		}
+	}
+	lines = append(lines, acceptanceMatrixLine{}, acceptanceMatrixLine{Text: "Annex: Comments enclosed by the NSBs", Bold: true})
+	comments := acceptanceMatrixComments(matrix)
+	if len(comments) == 0 {
+		lines = append(lines, acceptanceMatrixLine{Text: "No comments were enclosed."})
+	}
+	for _, response := range comments {
+		lines = append(lines, acceptanceMatrixLine{Text: response.NSB, Bold: true})
+		for _, line := range wrapPDFText(response.Comments, acceptanceMatrixTextSize, workProgrammeTableWidth) {
+			lines = append(lines, acceptanceMatrixLine{Text: line})
+		}
+	}
+
+	for start := 0; start < len(lines); start += acceptanceMatrixLinesPerPage {
+		end := start + acceptanceMatrixLinesPerPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		var text []map[string]interface{}
+		for i, line := range lines[start:end] {
+			if line.Text == "" {
+				continue
+			}
+			name := "Helvetica"
+			if line.Bold {
+				name = "Helvetica-Bold"
+			}
+			text = append(text, map[string]interface{}{
+				"value":  line.Text,
+				"anchor": "TopLeft",
+				"dx":     0,
+				"dy":     10 + i*acceptanceMatrixLineHeight,
+				"font":   map[string]interface{}{"name": name, "size": acceptanceMatrixTextSize},
+			})
+		}
+		page++
+		pages[strconv.Itoa(page)] = map[string]interface{}{"content": map[string]interface{}{"text": text}}
+	}
+
+	layout := map[string]interface{}{
+		"paper":  "A4L",
+		"origin": "UpperLeft",
+		"margin": map[string]interface{}{"width": 30},
+		"header": map[string]interface{}{
+			"left":   workProgrammeOrganisation,
+			"right":  matrix.Reference,
+			"font":   map[string]interface{}{"name": "Helvetica", "size": 9},
+			"height": 30,
+			"dx":     30,
+			"dy":     10,
+		},
+		"footer": map[string]interface{}{
+			"left":   matrix.GeneratedAt.Format("2006-01-02"),
+			"right":  "Page %p of %P",
+			"font":   map[string]interface{}{"name": "Helvetica", "size": 9},
+			"height": 30,
+			"dx":     30,
+			"dy":     10,
+		},
+		"pages": pages,
+	}
+	return createPDF(layout)
+}
+
+// renderAcceptanceMatrixDOCX writes the matrix as a landscape Word document, with the evaluation of
+// the criteria and the comments annex after the table
+func renderAcceptanceMatrixDOCX(matrix *models.AcceptanceMatrix) ([]byte, error) {
+	headers, widths := acceptanceMatrixHeaders()
+
+	var body bytes.Buffer
+	body.WriteString(docxParagraph(workProgrammeOrganisation, false, 18))
+	body.WriteString(docxParagraph(acceptanceMatrixTitle(matrix), true, 28))
+	body.WriteString(docxParagraph(acceptanceMatrixSubtitle(matrix), false, 20))
+	body.WriteString(docxTableStart())
+	body.WriteString(docxTableRow(headers, widths, true))
+	for _, row := range acceptanceMatrixRows(matrix) {
+		body.WriteString(docxTableRow(row, widths, false))
+	}
+	body.WriteString(`</w:tbl>`)
+
+	body.WriteString(docxParagraph("Evaluation of the acceptance criteria", true, 24))
+	for _, line := range acceptanceMatrixEvaluation(matrix) {
+		body.WriteString(docxParagraph(line, false, 20))
+	}
+
+	body.WriteString(docxParagraph("Annex: Comments enclosed by the NSBs", true, 24))
+	comments := acceptanceMatrixComments(matrix)
+	if len(comments) == 0 {
+		body.WriteString(docxParagraph("No comments were enclosed.", false, 20))
+	}
+	for _, response := range comments {
+		body.WriteString(docxParagraph(response.NSB, true, 20))
+		for _, paragraph := range strings.Split(response.Comments, "\n") {
+			body.WriteString(docxParagraph(paragraph, false, 20))
+		}
+	}
+	return packageDOCX(body.String())
+}
+
+// renderAcceptanceMatrixXLSX writes the matrix and the evaluation of the criteria on one sheet and
+// the comments annex on another
+func renderAcceptanceMatrixXLSX(matrix *models.AcceptanceMatrix) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet, annex = "Responses", "Comments"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(annex); err != nil {
+		return nil, err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	title, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	if err != nil {
+		return nil, err
+	}
+	header, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"D9E2F3"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", WrapText: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	wrap, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Vertical: "top", WrapText: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	setRow := func(sheet string, row int, values []string) error {
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		cells := make([]interface{}, len(values))
+		for i, value := range values {
+			cells[i] = value
+		}
+		return f.SetSheetRow(sheet, cell, &cells)
+	}
+
+	headers, _ := acceptanceMatrixHeaders()
+	lastColumn, err := excelize.ColumnNumberToName(len(headers))
+	if err != nil {
+		return nil, err
+	}
+
+	_ = f.SetCellValue(sheet, "A1", acceptanceMatrixTitle(matrix))
+	_ = f.SetCellStyle(sheet, "A1", "A1", title)
+	_ = f.SetCellValue(sheet, "A2", acceptanceMatrixSubtitle(matrix))
+	if err := setRow(sheet, 4, headers); err != nil {
+		return nil, err
+	}
+	_ = f.SetCellStyle(sheet, "A4", lastColumn+"4", header)
+
+	row := 5
+	for _, values := range acceptanceMatrixRows(matrix) {
+		if err := setRow(sheet, row, values); err != nil {
+			return nil, err
+		}
+		row++
+	}
+	totalsRow := strconv.Itoa(row - 1)
+	_ = f.SetCellStyle(sheet, "A"+totalsRow, lastColumn+totalsRow, bold)
+
+	row++
+	_ = f.SetCellValue(sheet, "A"+strconv.Itoa(row), "Evaluation of the acceptance criteria")
+	_ = f.SetCellStyle(sheet, "A"+strconv.Itoa(row), "A"+strconv.Itoa(row), bold)
+	for _, line := range acceptanceMatrixEvaluation(matrix) {
+		row++
+		_ = f.SetCellValue(sheet, "A"+strconv.Itoa(row), line)
+	}
+
+	_ = f.SetColWidth(sheet, "A", "A", 30)
+	_ = f.SetColWidth(sheet, "B", lastColumn, 12)
+	_ = f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 4, TopLeftCell: "A5", ActivePane: "bottomLeft"})
+
+	_ = f.SetColWidth(annex, "A", "A", 30)
+	_ = f.SetColWidth(annex, "B", "B", 100)
+	_ = f.SetColStyle(annex, "A:B", wrap)
+	if err := setRow(annex, 1, []string{"NSB", "Comments"}); err != nil {
+		return nil, err
+	}
+	_ = f.SetCellStyle(annex, "A1", "B1", header)
+	for i, response := range acceptanceMatrixComments(matrix) {
+		if err := setRow(annex, i+2, []string{response.NSB, response.Comments}); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	if err := f.Write(&out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
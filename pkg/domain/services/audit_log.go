@@ -382,6 +382,12 @@ func (s *AuditLogService) generateProjectActionDescription(action models.ActionT
 		return fmt.Sprintf("Rejected project: %s", projectTitle)
 	case models.ActionProjectCancel:
 		return fmt.Sprintf("Cancelled project: %s", projectTitle)
+	case models.ActionProjectWithdraw:
+		return fmt.Sprintf("Withdrew project: %s", projectTitle)
+	case models.ActionProjectSuspend:
+		return fmt.Sprintf("Put project on hold: %s", projectTitle)
+	case models.ActionProjectReinstate:
+		return fmt.Sprintf("Reinstated project: %s", projectTitle)
 	case models.ActionProjectPublish:
 		return fmt.Sprintf("Published project: %s", projectTitle)
 	default:
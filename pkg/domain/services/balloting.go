@@ -10,12 +10,14 @@ import (
 )
 
 type BallotingService struct {
-	repo         *repository.BallotingRepository
-	auditService *AuditLogService
+	repo                *repository.BallotingRepository
+	votingRuleRepo      *repository.VotingRuleRepository
+	auditService        *AuditLogService
+	notificationService *NotificationService
 }
 
-func NewBallotingService(repo *repository.BallotingRepository, auditService *AuditLogService) *BallotingService {
-	return &BallotingService{repo: repo, auditService: auditService}
+func NewBallotingService(repo *repository.BallotingRepository, votingRuleRepo *repository.VotingRuleRepository, auditService *AuditLogService, notificationService *NotificationService) *BallotingService {
+	return &BallotingService{repo: repo, votingRuleRepo: votingRuleRepo, auditService: auditService, notificationService: notificationService}
 }
 
 func (service *BallotingService) CreateVote(vote *models.Vote, userID, ipAddress, userAgent, sessionID, requestID string) error {
@@ -26,6 +28,7 @@ func (service *BallotingService) CreateVote(vote *models.Vote, userID, ipAddress
 	
 	// Log the action
 	metadata := map[string]interface{}{
+		"decision":          vote.Decision,
 		"acceptance":        vote.Acceptance,
 		"project_id":        vote.ProjectID,
 		"balloting_id":      vote.BallotingID.String(),
@@ -70,10 +73,12 @@ func (service *BallotingService) UpdateVote(vote *models.Vote, userID, ipAddress
 	
 	// Log the action
 	metadata := map[string]interface{}{
+		"decision":          vote.Decision,
 		"acceptance":        vote.Acceptance,
 		"project_id":        vote.ProjectID,
 		"balloting_id":      vote.BallotingID.String(),
 		"comment":           vote.Comment,
+		"revision":          vote.Revision,
 		"execution_time_ms": time.Since(startTime).Milliseconds(),
 	}
 	
@@ -91,6 +96,10 @@ func (service *BallotingService) UpdateVote(vote *models.Vote, userID, ipAddress
 	return err
 }
 
+func (service *BallotingService) FindVoteRevisions(voteID uuid.UUID) ([]models.VoteRevision, error) {
+	return service.repo.FindVoteRevisions(voteID)
+}
+
 func (service *BallotingService) DeleteVote(id uuid.UUID) error {
 	return service.repo.DeleteVote(id)
 }
@@ -192,3 +201,160 @@ func (service *BallotingService) RecommendFDARS(memberId, projectId string, reco
 func (service *BallotingService) VerifyFDARSRecommendation(memberId, projectId string) error {
 	return service.repo.VerifyFDARSRecommendation(memberId, projectId)
 }
+
+// CloseExpiredBallotings closes every active ballot whose end date has passed. It is run by the
+// scheduler, failures on one ballot do not stop the others from closing.
+func (service *BallotingService) CloseExpiredBallotings() error {
+	ballotings, err := service.repo.FindBallotingsDueForClosure()
+	if err != nil {
+		return fmt.Errorf("failed to get ballots due for closure: %w", err)
+	}
+
+	var failed int
+	for _, balloting := range ballotings {
+		if _, err := service.CloseBalloting(balloting.ID, nil, "", "", "", ""); err != nil {
+			fmt.Printf("Failed to close balloting %s: %v\n", balloting.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to close %d of %d ballots", failed, len(ballotings))
+	}
+	return nil
+}
+
+// CloseBalloting closes a ballot, determines its result and notifies the voters. A nil userID
+// means the ballot was closed by the system when its end date passed.
+func (service *BallotingService) CloseBalloting(id uuid.UUID, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.Balloting, error) {
+	startTime := time.Now()
+
+	balloting, err := service.repo.CloseBalloting(id)
+
+	metadata := map[string]interface{}{
+		"execution_time_ms": time.Since(startTime).Milliseconds(),
+	}
+	title := fmt.Sprintf("Balloting %s", id)
+	if balloting != nil {
+		metadata["project_id"] = balloting.ProjectID
+		metadata["end_date"] = balloting.EndDate
+		metadata["result"] = balloting.Result
+		metadata["next_course_of_action"] = balloting.NextCourseOfAction
+		title = fmt.Sprintf("Closed balloting for project %s", balloting.ProjectID)
+	}
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	service.auditService.LogBallotAction(
+		userID, models.ActionBallotClose, id.String(), title,
+		metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+		ipAddress, userAgent, sessionID, requestID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if balloting.Project != nil {
+		if err := service.notificationService.NotifyBallotClosed(balloting.Project, balloting); err != nil {
+			fmt.Printf("Failed to send ballot closed notification for %s: %v\n", balloting.ID, err)
+		}
+	}
+
+	return balloting, nil
+}
+
+// SaveVotingRule stores a new version of the voting rule for the rule's procedure. An empty
+// procedure sets the default rule.
+func (service *BallotingService) SaveVotingRule(rule *models.VotingRule, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+	rule.CreatedByID = userID
+
+	err := service.votingRuleRepo.CreateRuleVersion(rule)
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	ruleID := rule.ID.String()
+	service.auditService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        models.ActionConfigUpdate,
+		Module:        models.ModuleBalloting,
+		ResourceType:  "VotingRule",
+		ResourceID:    &ruleID,
+		ResourceTitle: fmt.Sprintf("Voting rule %q v%d", rule.Procedure, rule.Version),
+		Description:   fmt.Sprintf("Saved voting rule for procedure %q", rule.Procedure),
+		Metadata: map[string]interface{}{
+			"procedure":              rule.Procedure,
+			"version":                rule.Version,
+			"approval_threshold":     rule.ApprovalThreshold,
+			"minimum_p_member_votes": rule.MinimumPMemberVotes,
+			"exclude_abstentions":    rule.ExcludeAbstentions,
+			"max_negative_share":     rule.MaxNegativeShare,
+		},
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		SessionID:    sessionID,
+		RequestID:    requestID,
+		Success:      err == nil,
+		ErrorMessage: errorMsg,
+		Duration:     time.Since(startTime).Milliseconds(),
+	})
+
+	return err
+}
+
+// DeactivateVotingRule removes the rule of a procedure so that the default rule decides its ballots
+func (service *BallotingService) DeactivateVotingRule(procedure models.Procedure, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+
+	err := service.votingRuleRepo.DeactivateRule(procedure)
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	service.auditService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        models.ActionConfigUpdate,
+		Module:        models.ModuleBalloting,
+		ResourceType:  "VotingRule",
+		ResourceTitle: fmt.Sprintf("Voting rule %q", procedure),
+		Description:   fmt.Sprintf("Deactivated voting rule for procedure %q", procedure),
+		Metadata: map[string]interface{}{
+			"procedure": procedure,
+		},
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		SessionID:    sessionID,
+		RequestID:    requestID,
+		Success:      err == nil,
+		ErrorMessage: errorMsg,
+		Duration:     time.Since(startTime).Milliseconds(),
+	})
+
+	return err
+}
+
+func (service *BallotingService) GetVotingRuleByID(id uuid.UUID) (*models.VotingRule, error) {
+	return service.votingRuleRepo.GetRuleByID(id)
+}
+
+func (service *BallotingService) GetActiveVotingRules() ([]models.VotingRule, error) {
+	return service.votingRuleRepo.GetActiveRules()
+}
+
+func (service *BallotingService) GetVotingRuleVersions(procedure models.Procedure) ([]models.VotingRule, error) {
+	return service.votingRuleRepo.GetRuleVersions(procedure)
+}
+
+// GetApplicableVotingRule returns the rule that would decide a ballot for the procedure today
+func (service *BallotingService) GetApplicableVotingRule(procedure models.Procedure) (*models.VotingRule, error) {
+	return service.votingRuleRepo.GetApplicableRule(procedure)
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	ErrUnsupportedCommentTemplate = errors.New("comment templates can only be read and written as .xlsx or .docx")
+	ErrInvalidCommentTemplate     = errors.New("invalid comment template")
+)
+
+// CommentTemplateFile is a rendered comment template ready for download
+type CommentTemplateFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// commentTemplateFields are the fields of a template row in the order of models.CommentTemplateColumns
+var commentTemplateFields = []string{
+	"member_body",
+	"line_number",
+	"clause_no",
+	"paragraph_ref",
+	"comment_type",
+	"comment",
+	"proposed_change",
+	"secretariat_remarks",
+}
+
+// ImportCommentTemplate reads the comments prepared by an NSB in the Word or Excel comment template
+// and adds them to a project. Every line is validated first, and must be the NSB's own; nothing is
+// imported while a line has errors or when only a dry run is asked for.
+func (service *CommentService) ImportCommentTemplate(projectID, fileName string, data []byte, dryRun bool, memberID string) (*models.CommentTemplateReport, error) {
+	uploader, err := service.repo.GetCommentMember(memberID)
+	if err != nil {
+		return nil, err
+	}
+	report, err := readCommentTemplateReport(fileName, data, dryRun, uploader)
+	if err != nil || dryRun || len(report.Errors) > 0 {
+		return report, err
+	}
+
+	comments := make([]models.CommentObservation, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		comments = append(comments, models.CommentObservation{
+			NationalSecretaryID: memberID,
+			CommentReference: models.CommentReference{
+				ClauseNo:     row.ClauseNo,
+				ParagraphRef: row.ParagraphRef,
+				CommentType:  row.CommentType,
+			},
+			LineNumber:     row.LineNumber,
+			Comment:        row.Comment,
+			ProposedChange: row.ProposedChange,
+		})
+	}
+	if err := service.repo.ImportComments(projectID, comments); err != nil {
+		return nil, err
+	}
+	report.Imported = len(comments)
+	return report, nil
+}
+
+// ExportCommentTemplate compiles the comments on a project's draft, sorted by clause, into the
+// comment template. A project without comments gives a blank template.
+func (service *CommentService) ExportCommentTemplate(projectID, format string) (*CommentTemplateFile, error) {
+	template, err := service.repo.GetCommentTemplate(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return renderCommentTemplate(template, format, "comments")
+}
+
+// ImportCommentTemplate reads the public consultation comments of an NSB from the Word or Excel
+// comment template and adds them to the project's DARS. Every line is validated first, and must be
+// the NSB's own; nothing is imported while a line has errors or when only a dry run is asked for.
+func (service *NationalConsultationService) ImportCommentTemplate(projectID, fileName string, data []byte, dryRun bool, memberID string) (*models.CommentTemplateReport, error) {
+	uploader, err := service.repo.GetCommentMember(memberID)
+	if err != nil {
+		return nil, err
+	}
+	report, err := readCommentTemplateReport(fileName, data, dryRun, uploader)
+	if err != nil || dryRun || len(report.Errors) > 0 {
+		return report, err
+	}
+
+	consultations := make([]models.NationalConsultation, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		consultations = append(consultations, models.NationalConsultation{
+			NationalSecretaryID: memberID,
+			LineNumber:          row.LineNumber,
+			ClauseNo:            row.ClauseNo,
+			ParagraphRef:        row.ParagraphRef,
+			CommentType:         row.CommentType,
+			Comment:             row.Comment,
+			ProposedChange:      row.ProposedChange,
+		})
+	}
+	if err := service.repo.ImportConsultations(projectID, consultations); err != nil {
+		return nil, err
+	}
+	report.Imported = len(consultations)
+	return report, nil
+}
+
+// ExportCommentTemplate compiles the public consultation comments on a project's draft, sorted by
+// clause, into the comment template
+func (service *NationalConsultationService) ExportCommentTemplate(projectID, format string) (*CommentTemplateFile, error) {
+	template, err := service.repo.GetCommentTemplate(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return renderCommentTemplate(template, format, "consultation comments")
+}
+
+func readCommentTemplateReport(fileName string, data []byte, dryRun bool, uploader *models.Member) (*models.CommentTemplateReport, error) {
+	records, err := readCommentTemplate(fileName, data)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := commentTemplateRows(records)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CommentTemplateReport{
+		DryRun:    dryRun,
+		FileName:  fileName,
+		TotalRows: len(rows),
+		Errors:    []models.CommentTemplateError{},
+		Rows:      rows,
+	}
+	for i := range rows {
+		errs := append(rows[i].Validate(), rows[i].ValidateUpload(uploader)...)
+		if len(errs) == 0 {
+			report.ValidRows++
+		}
+		report.Errors = append(report.Errors, errs...)
+	}
+	if len(rows) == 0 {
+		report.Errors = append(report.Errors, models.CommentTemplateError{Message: "the template has no comments"})
+	}
+	return report, nil
+}
+
+// readCommentTemplate returns the lines of the first sheet of an Excel template, or of the tables
+// of a Word template
+func readCommentTemplate(name string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCommentTemplate, err)
+		}
+		defer workbook.Close()
+
+		records, err := workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCommentTemplate, err)
+		}
+		return records, nil
+	case ".docx":
+		records, err := readDOCXTableRows(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCommentTemplate, err)
+		}
+		return records, nil
+	}
+	return nil, ErrUnsupportedCommentTemplate
+}
+
+// readDOCXTableRows returns the text of the cells of every row of the top-level tables of a Word
+// document. The paragraphs of a cell are separated by new lines.
+func readDOCXTableRows(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var document *zip.File
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			document = file
+			break
+		}
+	}
+	if document == nil {
+		return nil, errors.New("the document has no body")
+	}
+	reader, err := document.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var (
+		records   [][]string
+		row       []string
+		cell      strings.Builder
+		depth     int
+		inCell    bool
+		inText    bool
+		paragraph int
+	)
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "tbl":
+				depth++
+			case "tr":
+				if depth == 1 {
+					row = []string{}
+				}
+			case "tc":
+				if depth == 1 {
+					inCell, paragraph = true, 0
+					cell.Reset()
+				}
+			case "p":
+				if inCell {
+					if paragraph > 0 {
+						cell.WriteString("\n")
+					}
+					paragraph++
+				}
+			case "t":
+				inText = inCell
+			case "tab":
+				if inCell {
+					cell.WriteString("\t")
+				}
+			case "br":
+				if inCell {
+					cell.WriteString("\n")
+				}
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "tbl":
+				depth--
+			case "tr":
+				if depth == 1 {
+					records = append(records, row)
+				}
+			case "tc":
+				if depth == 1 {
+					row = append(row, strings.TrimSpace(cell.String()))
+					inCell = false
+				}
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				cell.Write(element)
+			}
+		}
+	}
+	return records, nil
+}
+
+// commentTemplateColumn recognises a column of the template by its title, as printed on the ISO
+// form or as the name of the field
+func commentTemplateColumn(title string) string {
+	title = strings.ToLower(strings.TrimSpace(title))
+	switch {
+	case title == "":
+		return ""
+	case strings.Contains(title, "observation"), strings.Contains(title, "secretariat"):
+		return "secretariat_remarks"
+	case strings.Contains(title, "proposed"):
+		return "proposed_change"
+	case strings.Contains(title, "type"):
+		return "comment_type"
+	case strings.Contains(title, "clause"):
+		return "clause_no"
+	case strings.Contains(title, "paragraph"), strings.Contains(title, "figure"), strings.Contains(title, "table"):
+		return "paragraph_ref"
+	case strings.Contains(title, "line"):
+		return "line_number"
+	case strings.Contains(title, "comment"):
+		return "comment"
+	case strings.HasPrefix(title, "mb"), strings.HasPrefix(title, "nc"), strings.Contains(title, "member"):
+		return "member_body"
+	}
+	return ""
+}
+
+// commentTemplateRows reads the lines below the header row of the template. Lines repeating the
+// header, and lines with nothing but the MB/NC or line number filled in, are skipped.
+func commentTemplateRows(records [][]string) ([]models.CommentTemplateRow, error) {
+	header := -1
+	columns := map[string]int{}
+	for i, record := range records {
+		found := map[string]int{}
+		for j, title := range record {
+			if field := commentTemplateColumn(title); field != "" {
+				if _, ok := found[field]; !ok {
+					found[field] = j
+				}
+			}
+		}
+		_, clause := found["clause_no"]
+		_, comment := found["comment"]
+		if clause && comment {
+			header, columns = i, found
+			break
+		}
+	}
+	if header < 0 {
+		return nil, fmt.Errorf("%w: no header row with the Clause/Subclause and Comments columns was found", ErrInvalidCommentTemplate)
+	}
+	for i, field := range commentTemplateFields {
+		if _, ok := columns[field]; !ok && field != "member_body" && field != "line_number" {
+			return nil, fmt.Errorf("%w: the %s column is missing", ErrInvalidCommentTemplate, models.CommentTemplateColumns[i])
+		}
+	}
+
+	rows := []models.CommentTemplateRow{}
+	for i, record := range records[header+1:] {
+		cell := func(name string) string {
+			if index, ok := columns[name]; ok && index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		if commentTemplateColumn(cell("clause_no")) == "clause_no" && commentTemplateColumn(cell("comment")) == "comment" {
+			continue
+		}
+
+		row := models.CommentTemplateRow{
+			Row:                header + i + 2,
+			MemberBody:         cell("member_body"),
+			LineNumber:         cell("line_number"),
+			ClauseNo:           cell("clause_no"),
+			ParagraphRef:       cell("paragraph_ref"),
+			CommentType:        models.CommentType(cell("comment_type")),
+			Comment:            cell("comment"),
+			ProposedChange:     cell("proposed_change"),
+			SecretariatRemarks: cell("secretariat_remarks"),
+		}
+		if row.ClauseNo+row.ParagraphRef+string(row.CommentType)+row.Comment+row.ProposedChange+row.SecretariatRemarks == "" {
+			continue
+		}
+		if commentType, ok := models.ParseCommentType(string(row.CommentType)); ok {
+			row.CommentType = commentType
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/xuri/excelize/v2"
+)
+
+const commentTemplateTitle = "Template for comments and secretariat observations"
+
+// commentTemplateWidths are the shares of the page width in percent of the columns of the template
+var commentTemplateWidths = []int{7, 6, 9, 10, 6, 24, 22, 16}
+
+func renderCommentTemplate(template *models.CommentTemplate, format, subject string) (*CommentTemplateFile, error) {
+	name := strings.NewReplacer(" ", "_", "/", "-").Replace(strings.TrimSpace(fmt.Sprintf("%s %s", template.Reference, subject)))
+
+	switch strings.ToLower(format) {
+	case "xlsx":
+		data, err := renderCommentTemplateXLSX(template)
+		if err != nil {
+			return nil, err
+		}
+		return &CommentTemplateFile{
+			Name:        name + ".xlsx",
+			ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			Data:        data,
+		}, nil
+	case "docx":
+		data, err := renderCommentTemplateDOCX(template)
+		if err != nil {
+			return nil, err
+		}
+		return &CommentTemplateFile{
+			Name:        name + ".docx",
+			ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			Data:        data,
+		}, nil
+	}
+	return nil, ErrUnsupportedCommentTemplate
+}
+
+func commentTemplateSubtitle(template *models.CommentTemplate) string {
+	document := template.Reference
+	if template.Title != "" {
+		document = strings.TrimSpace(document + " " + template.Title)
+	}
+	subtitle := fmt.Sprintf("Date: %s    Document: %s", template.GeneratedAt.Format("2006-01-02"), document)
+	if template.CommitteeCode != "" {
+		subtitle += "    Committee: " + template.CommitteeCode
+	}
+	return subtitle
+}
+
+func commentTemplateValues(row models.CommentTemplateRow) []string {
+	return []string{
+		row.MemberBody,
+		row.LineNumber,
+		row.ClauseNo,
+		row.ParagraphRef,
+		string(row.CommentType),
+		row.Comment,
+		row.ProposedChange,
+		row.SecretariatRemarks,
+	}
+}
+
+// renderCommentTemplateDOCX writes the comments as the table of the Word comment template
+func renderCommentTemplateDOCX(template *models.CommentTemplate) ([]byte, error) {
+	var body bytes.Buffer
+	body.WriteString(docxParagraph(commentTemplateTitle, true, 28))
+	body.WriteString(docxParagraph(commentTemplateSubtitle(template), false, 20))
+	body.WriteString(docxTableStart())
+	body.WriteString(docxTableRow(models.CommentTemplateColumns, commentTemplateWidths, true))
+	for _, row := range template.Rows {
+		body.WriteString(docxTableRow(commentTemplateValues(row), commentTemplateWidths, false))
+	}
+	body.WriteString(`</w:tbl>`)
+	body.WriteString(docxParagraph("MB = Member body / NC = National Committee. Type of comment: ge = general, te = technical, ed = editorial.", false, 16))
+	return packageDOCX(body.String())
+}
+
+// renderCommentTemplateXLSX writes the comments on the first sheet of the Excel comment template
+func renderCommentTemplateXLSX(template *models.CommentTemplate) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Comments"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+
+	title, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	if err != nil {
+		return nil, err
+	}
+	header, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"D9E2F3"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", WrapText: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	wrap, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Vertical: "top", WrapText: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	lastColumn, err := excelize.ColumnNumberToName(len(models.CommentTemplateColumns))
+	if err != nil {
+		return nil, err
+	}
+	for i, width := range commentTemplateWidths {
+		column, _ := excelize.ColumnNumberToName(i + 1)
+		_ = f.SetColWidth(sheet, column, column, float64(width)*1.6)
+	}
+
+	setRow := func(row int, values []string) error {
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		cells := make([]interface{}, len(values))
+		for i, value := range values {
+			cells[i] = value
+		}
+		return f.SetSheetRow(sheet, cell, &cells)
+	}
+
+	_ = f.SetCellValue(sheet, "A1", commentTemplateTitle)
+	_ = f.SetCellStyle(sheet, "A1", "A1", title)
+	_ = f.SetCellValue(sheet, "A2", commentTemplateSubtitle(template))
+	if err := setRow(4, models.CommentTemplateColumns); err != nil {
+		return nil, err
+	}
+	_ = f.SetCellStyle(sheet, "A4", lastColumn+"4", header)
+	_ = f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 4, TopLeftCell: "A5", ActivePane: "bottomLeft"})
+
+	for i, row := range template.Rows {
+		if err := setRow(i+5, commentTemplateValues(row)); err != nil {
+			return nil, err
+		}
+	}
+	if len(template.Rows) > 0 {
+		_ = f.SetCellStyle(sheet, "A5", fmt.Sprintf("%s%d", lastColumn, len(template.Rows)+4), wrap)
+	}
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
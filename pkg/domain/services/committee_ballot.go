@@ -0,0 +1,160 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// CreateCommitteeBallot opens an internal ballot of a technical committee on behalf of its secretary
+func (service *BallotingService) CreateCommitteeBallot(ballot *models.CommitteeBallot, secretary string, ipAddress, userAgent, sessionID, requestID string) (*models.CommitteeBallot, error) {
+	startTime := time.Now()
+	ballot.CreatedByID = secretary
+
+	err := service.repo.CreateCommitteeBallot(ballot)
+	service.logCommitteeBallot(models.ActionBallotCreate, ballot, fmt.Sprintf("Opened internal ballot %q", ballot.Title), map[string]interface{}{
+		"technical_committee_id": ballot.TechnicalCommitteeID,
+		"number":                 ballot.Number,
+		"subject":                ballot.Subject,
+		"project_id":             ballot.ProjectID,
+		"meeting_id":             ballot.MeetingID,
+		"opens_at":               ballot.OpensAt,
+		"closes_at":              ballot.ClosesAt,
+		"voters":                 len(ballot.Voters),
+	}, err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.repo.GetCommitteeBallot(ballot.ID)
+}
+
+// CastCommitteeBallotVote records or changes the vote of the member's NSB on an internal ballot
+func (service *BallotingService) CastCommitteeBallotVote(vote *models.CommitteeBallotVote, memberID string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+	vote.MemberID = memberID
+
+	err := service.repo.CastCommitteeBallotVote(vote)
+
+	action := models.ActionVoteSubmit
+	if err == nil && vote.CreatedAt.Before(vote.UpdatedAt) {
+		action = models.ActionVoteUpdate
+	}
+	ballot := &models.CommitteeBallot{}
+	ballot.ID, _ = uuid.Parse(vote.BallotID)
+	service.logCommitteeBallot(action, ballot, fmt.Sprintf("Voted on internal ballot %s", vote.BallotID), map[string]interface{}{
+		"vote_id":                   vote.ID,
+		"national_standard_body_id": vote.NationalStandardBodyID,
+		"option_id":                 vote.OptionID,
+		"abstain":                   vote.Abstain,
+	}, err, startTime, &memberID, ipAddress, userAgent, sessionID, requestID)
+	return err
+}
+
+// CloseCommitteeBallot closes an internal ballot and records its result. A nil userID means the
+// ballot was closed by the system at its closing date.
+func (service *BallotingService) CloseCommitteeBallot(id uuid.UUID, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.CommitteeBallot, error) {
+	startTime := time.Now()
+
+	ballot, err := service.repo.CloseCommitteeBallot(id, userID)
+
+	logged := ballot
+	metadata := map[string]interface{}{}
+	if ballot == nil {
+		logged = &models.CommitteeBallot{ID: id}
+	} else {
+		metadata["result"] = ballot.Result
+		metadata["resolution_number"] = ballot.ResolutionNumber
+	}
+	service.logCommitteeBallot(models.ActionBallotClose, logged, fmt.Sprintf("Closed internal ballot %s", id), metadata,
+		err, startTime, userID, ipAddress, userAgent, sessionID, requestID)
+	return ballot, err
+}
+
+// CancelCommitteeBallot withdraws an open internal ballot for the given reason
+func (service *BallotingService) CancelCommitteeBallot(id uuid.UUID, secretary, reason string, ipAddress, userAgent, sessionID, requestID string) (*models.CommitteeBallot, error) {
+	startTime := time.Now()
+
+	ballot, err := service.repo.CancelCommitteeBallot(id, secretary, reason)
+
+	logged := ballot
+	if ballot == nil {
+		logged = &models.CommitteeBallot{ID: id}
+	}
+	service.logCommitteeBallot(models.ActionBallotUpdate, logged, fmt.Sprintf("Cancelled internal ballot %s", id), map[string]interface{}{
+		"status": models.CommitteeBallotCancelled,
+		"reason": reason,
+	}, err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	return ballot, err
+}
+
+func (service *BallotingService) GetCommitteeBallot(id uuid.UUID) (*models.CommitteeBallot, error) {
+	return service.repo.GetCommitteeBallot(id)
+}
+
+func (service *BallotingService) GetCommitteeBallots(committeeID string, status models.CommitteeBallotStatus, projectID string) ([]models.CommitteeBallot, error) {
+	return service.repo.GetCommitteeBallots(committeeID, status, projectID)
+}
+
+func (service *BallotingService) GetProjectCommitteeBallots(projectID string) ([]models.CommitteeBallot, error) {
+	return service.repo.GetProjectCommitteeBallots(projectID)
+}
+
+// CloseDueCommitteeBallots closes every open internal ballot whose closing date has passed. It is
+// run by the scheduler, failures on one ballot do not stop the others from closing.
+func (service *BallotingService) CloseDueCommitteeBallots() error {
+	ballots, err := service.repo.FindCommitteeBallotsDueForClosure()
+	if err != nil {
+		return fmt.Errorf("failed to get internal ballots due for closure: %w", err)
+	}
+
+	var failed int
+	for _, ballot := range ballots {
+		if _, err := service.CloseCommitteeBallot(ballot.ID, nil, "", "", "", ""); err != nil {
+			fmt.Printf("Failed to close internal ballot %s: %v\n", ballot.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to close %d of %d internal ballots", failed, len(ballots))
+	}
+	return nil
+}
+
+func (service *BallotingService) logCommitteeBallot(action models.ActionType, ballot *models.CommitteeBallot, description string, metadata map[string]interface{}, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditService == nil {
+		return
+	}
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+	metadata["execution_time_ms"] = time.Since(startTime).Milliseconds()
+
+	ballotID := ballot.ID.String()
+	title := ballot.Title
+	if title == "" {
+		title = ballotID
+	}
+	service.auditService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        action,
+		Module:        models.ModuleBalloting,
+		ResourceType:  "CommitteeBallot",
+		ResourceID:    &ballotID,
+		ResourceTitle: title,
+		Description:   description,
+		Metadata:      metadata,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+		SessionID:     sessionID,
+		RequestID:     requestID,
+		Success:       err == nil,
+		ErrorMessage:  errorMsg,
+		Duration:      time.Since(startTime).Milliseconds(),
+	})
+}
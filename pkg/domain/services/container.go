@@ -20,6 +20,10 @@ type ServiceContainer struct {
 	NotificationService         *NotificationService
 	ReportsService              *ReportsService
 	AuditLogService             *AuditLogService
+	SchedulerService            *SchedulerService
+	NumberingService            *NumberingService
+	SagaService                 *SagaService
+	SystematicReviewService     *SystematicReviewService
 }
 
 func NewServiceContainer(
@@ -42,6 +46,10 @@ func NewServiceContainer(
 	notificationService *NotificationService,
 	reportsService *ReportsService,
 	auditLogService *AuditLogService,
+	schedulerService *SchedulerService,
+	numberingService *NumberingService,
+	sagaService *SagaService,
+	systematicReviewService *SystematicReviewService,
 ) *ServiceContainer {
 	return &ServiceContainer{
 		OrganizationService:         organizationService,
@@ -63,5 +71,9 @@ func NewServiceContainer(
 		NotificationService:         notificationService,
 		ReportsService:              reportsService,
 		AuditLogService:             auditLogService,
+		SchedulerService:            schedulerService,
+		NumberingService:            numberingService,
+		SagaService:                 sagaService,
+		SystematicReviewService:     systematicReviewService,
 	}
 }
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// ChangeDevelopmentTrack moves an accepted project to another development track and recomputes its
+// target dates and stage plan
+func (service *AcceptanceService) ChangeDevelopmentTrack(projectID, secretary string, track models.DevelopmentTrack, ipAddress, userAgent, sessionID, requestID string) (*models.Acceptance, error) {
+	startTime := time.Now()
+
+	acceptance, err := service.repo.ChangeDevelopmentTrack(projectID, secretary, track)
+	metadata := map[string]interface{}{"development_track": track}
+	if acceptance != nil {
+		metadata["target_date_cd"] = acceptance.TargetDateCD
+		metadata["target_date_dars"] = acceptance.TargetDateDARS
+		metadata["target_date_fdars"] = acceptance.TargetDateFDARS
+	}
+	service.logAcceptance(models.ActionProjectUpdate, projectID, fmt.Sprintf("Moved the project to the %s development track", track), metadata,
+		err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	return acceptance, err
+}
+
+// CreateNonWorkingPeriod adds a period to the development calendar. Target dates computed before
+// are not recomputed.
+func (service *AcceptanceService) CreateNonWorkingPeriod(period *models.NonWorkingPeriod, userID string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+
+	period.CreatedByID = &userID
+	err := service.repo.CreateNonWorkingPeriod(period)
+	service.logNonWorkingPeriod(models.ActionConfigUpdate, period, fmt.Sprintf("Added the non-working period %s", period.Name),
+		err, startTime, &userID, ipAddress, userAgent, sessionID, requestID)
+	return err
+}
+
+func (service *AcceptanceService) GetNonWorkingPeriods() ([]models.NonWorkingPeriod, error) {
+	return service.repo.GetNonWorkingPeriods()
+}
+
+func (service *AcceptanceService) DeleteNonWorkingPeriod(id uuid.UUID, userID string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+
+	period, err := service.repo.DeleteNonWorkingPeriod(id)
+	if period == nil {
+		period = &models.NonWorkingPeriod{ID: id}
+	}
+	service.logNonWorkingPeriod(models.ActionConfigUpdate, period, fmt.Sprintf("Removed the non-working period %s", period.Name),
+		err, startTime, &userID, ipAddress, userAgent, sessionID, requestID)
+	return err
+}
+
+func (service *AcceptanceService) logNonWorkingPeriod(action models.ActionType, period *models.NonWorkingPeriod, description string, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	periodID := period.ID.String()
+	service.auditLogService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        action,
+		Module:        models.ModuleProjects,
+		ResourceType:  "NonWorkingPeriod",
+		ResourceID:    &periodID,
+		ResourceTitle: period.Name,
+		Description:   description,
+		Metadata: map[string]interface{}{
+			"start_date": period.StartDate,
+			"end_date":   period.EndDate,
+			"recurring":  period.Recurring,
+		},
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		SessionID:    sessionID,
+		RequestID:    requestID,
+		Success:      err == nil,
+		ErrorMessage: errorMsg,
+		Duration:     time.Since(startTime).Milliseconds(),
+	})
+}
@@ -626,6 +626,36 @@ func (service *DocumentService) CopyOneDriveFile(
 	return nil, fmt.Errorf("copy operation completed, but new file not found after polling")
 }
 
+// DeleteOneDriveFile removes a file from OneDrive. Files that are already gone are not an error.
+func (service *DocumentService) DeleteOneDriveFile(ctx context.Context, itemID string) error {
+	token, err := service.tokenManager.RetrieveToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %v", err)
+	}
+
+	globalConfig := config.GetConfig()
+	userEmail := globalConfig.AZURE_USER_EMAIL
+
+	deleteURL := fmt.Sprintf("https://graph.microsoft.com/v1.0/users/%s/drive/items/%s", userEmail, itemID)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", deleteURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create delete request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("delete failed: %s, %s", resp.Status, string(bodyBytes))
+	}
+	return nil
+}
+
 func (service *DocumentService) InviteExternalUsersToDocument(
 	ctx context.Context,
 	itemID string,
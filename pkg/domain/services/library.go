@@ -69,6 +69,27 @@ func (s *LibraryService) FindStandards(params map[string]any, limit, offset int)
 				code = project.TechnicalCommittee.Code
 			}
 
+			amendments := []map[string]any{}
+			for _, supplement := range project.Supplements {
+				amendments = append(amendments, map[string]any{
+					"id":             supplement.ID,
+					"type":           supplement.Type,
+					"title":          supplement.Title,
+					"reference":      supplement.Reference,
+					"published_date": supplement.PublishedDate,
+				})
+			}
+
+			var adoption map[string]any
+			if project.Adoption != nil {
+				adoption = map[string]any{
+					"organisation":     project.Adoption.Organisation,
+					"source_reference": project.Adoption.SourceReference,
+					"source_title":     project.Adoption.SourceTitle,
+					"equivalence":      project.Adoption.Equivalence,
+				}
+			}
+
 			standard := map[string]any{
 				"id":             project.ID,
 				"title":          project.Title,
@@ -79,6 +100,9 @@ func (s *LibraryService) FindStandards(params map[string]any, limit, offset int)
 				"language":       project.Language,
 				"published":      project.Published,
 				"published_date": project.PublishedDate,
+				"library_status": project.LibraryStatus,
+				"amendments":     amendments,
+				"adoption":       adoption,
 				"pages":          pageCount,
 				"created_at":     project.CreatedAt,
 				"updated_at":     project.UpdatedAt,
@@ -19,6 +19,7 @@ type NotificationService struct {
 	memberRepo       *repository.MemberRepository
 	rbacRepo         *repository.RbacRepository
 	ballotingRepo    *repository.BallotingRepository
+	projectRepo      *repository.ProjectRepository
 	emailService     *EmailService
 	db               *gorm.DB
 }
@@ -29,6 +30,7 @@ func NewNotificationService(
 	memberRepo *repository.MemberRepository,
 	rbacRepo *repository.RbacRepository,
 	ballotingRepo *repository.BallotingRepository,
+	projectRepo *repository.ProjectRepository,
 	emailService *EmailService,
 	db *gorm.DB,
 ) *NotificationService {
@@ -37,6 +39,7 @@ func NewNotificationService(
 		memberRepo:       memberRepo,
 		rbacRepo:         rbacRepo,
 		ballotingRepo:    ballotingRepo,
+		projectRepo:      projectRepo,
 		emailService:     emailService,
 		db:               db,
 	}
@@ -148,28 +151,38 @@ func (s *NotificationService) NotifyProjectAssigned(project *models.Project, ass
 
 // NotifyBallotClosed sends notification when a ballot is closed
 func (s *NotificationService) NotifyBallotClosed(project *models.Project, balloting *models.Balloting) error {
-	// Get eligible voters
-	members, _, err := s.memberRepo.GetAllMembers(1, 1000)
+	// Eligibility ends with the ballot, so notify the members who voted on it
+	votes, err := s.ballotingRepo.FindVotesByBallotingID(balloting.ID)
 	if err != nil {
 		return err
 	}
 
 	var recipients []string
-	for _, member := range *members {
-		if eligible, _ := s.ballotingRepo.IsEligibleToVote(member.ID.String(), balloting.ID.String()); eligible {
-			recipients = append(recipients, member.ID.String())
-		}
+	for _, vote := range votes {
+		recipients = append(recipients, vote.MemberID)
+	}
+	recipients = s.removeDuplicates(recipients)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"project_title":         project.Title,
+		"project_id":            project.ID.String(),
+		"end_date":              balloting.EndDate,
+		"next_course_of_action": balloting.NextCourseOfAction,
+	}
+	if balloting.Result != nil {
+		data["criteria_met"] = balloting.Result.CriteriaMet
+		data["acceptance_rate"] = balloting.Result.AcceptanceRate
+		data["result"] = balloting.Result.Message
 	}
 
 	notificationReq := &models.NotificationRequest{
-		Type:     models.NotificationBallotClosed,
-		Priority: models.NotificationPriorityHigh,
-		Channel:  models.NotificationChannelBoth,
-		Data: map[string]interface{}{
-			"project_title": project.Title,
-			"project_id":    project.ID.String(),
-			"end_date":      balloting.EndDate,
-		},
+		Type:        models.NotificationBallotClosed,
+		Priority:    models.NotificationPriorityHigh,
+		Channel:     models.NotificationChannelBoth,
+		Data:        data,
 		ProjectID:   func() *string { s := project.ID.String(); return &s }(),
 		BallotingID: func() *string { s := balloting.ID.String(); return &s }(),
 	}
@@ -349,17 +362,12 @@ func (s *NotificationService) NotifyCommentReceived(project *models.Project, com
 
 // NotifyDeadlineReminder sends deadline reminder notifications
 func (s *NotificationService) NotifyDeadlineReminder(project *models.Project, deadlineType string, daysLeft int) error {
-	// Get relevant members based on deadline type
-	members, _, err := s.memberRepo.GetAllMembers(1, 1000)
+	// The committee working on the project is responsible for its deadlines
+	recipients, err := s.getCommitteeMembers(project.TechnicalCommitteeID)
 	if err != nil {
 		return err
 	}
 
-	var recipients []string
-	for _, member := range *members {
-		recipients = append(recipients, member.ID.String())
-	}
-
 	notificationReq := &models.NotificationRequest{
 		Type:     models.NotificationDeadlineReminder,
 		Priority: models.NotificationPriorityHigh,
@@ -514,6 +522,163 @@ func (s *NotificationService) NotifyProjectCreated(project *models.Project, crea
 	return s.CreateNotification(req, recipients)
 }
 
+// NotifyProjectStatusChanged tells the project's stakeholders that it was put on hold, cancelled or
+// withdrawn, or that it was reinstated when the change has ended
+func (s *NotificationService) NotifyProjectStatusChanged(project *models.Project, change *models.ProjectStatusChange) error {
+	recipients, err := s.getProjectRelatedMembers(project)
+	if err != nil {
+		return fmt.Errorf("failed to get project-related members: %w", err)
+	}
+
+	req := &models.NotificationRequest{
+		Priority: models.NotificationPriorityHigh,
+		Channel:  models.NotificationChannelBoth,
+		Data: map[string]interface{}{
+			"project_id":        project.ID,
+			"project_title":     project.Title,
+			"project_reference": project.Reference,
+			"status":            change.Status,
+			"reason":            change.Reason,
+		},
+		ProjectID: func() *string { s := project.ID.String(); return &s }(),
+	}
+
+	switch {
+	case change.EndedAt != nil:
+		req.Type = models.NotificationProjectResumed
+		req.Title = "Project Reinstated"
+		req.Message = fmt.Sprintf("Project '%s' has been reinstated and work on it resumes", project.Title)
+		req.Data["status"] = models.ProjectActive
+		req.Data["reason"] = change.ReinstatementReason
+	case change.Status == models.ProjectOnHold:
+		req.Type = models.NotificationProjectOnHold
+		req.Title = "Project On Hold"
+		req.Message = fmt.Sprintf("Project '%s' has been put on hold: %s", project.Title, change.Reason)
+	case change.Status == models.ProjectWithdrawn:
+		req.Type = models.NotificationProjectCancelled
+		req.Title = "Project Withdrawn"
+		req.Message = fmt.Sprintf("Project '%s' has been withdrawn: %s", project.Title, change.Reason)
+	default:
+		req.Type = models.NotificationProjectCancelled
+		req.Title = "Project Cancelled"
+		req.Message = fmt.Sprintf("Project '%s' has been cancelled: %s", project.Title, change.Reason)
+	}
+
+	return s.CreateNotification(req, recipients)
+}
+
+// NotifyProjectTransfer tells the officers and members of both committees that a transfer of the
+// project was requested, carried out or rejected
+func (s *NotificationService) NotifyProjectTransfer(project *models.Project, transfer *models.ProjectTransfer) error {
+	recipients, err := s.getCommitteeMembers(transfer.FromCommitteeID, transfer.ToCommitteeID)
+	if err != nil {
+		return fmt.Errorf("failed to get committee members: %w", err)
+	}
+
+	req := &models.NotificationRequest{
+		Type:     models.NotificationProjectAssigned,
+		Priority: models.NotificationPriorityMedium,
+		Channel:  models.NotificationChannelBoth,
+		Data: map[string]interface{}{
+			"project_id":         project.ID,
+			"project_title":      project.Title,
+			"transfer_id":        transfer.ID,
+			"from_committee_id":  transfer.FromCommitteeID,
+			"to_committee_id":    transfer.ToCommitteeID,
+			"previous_reference": transfer.PreviousReference,
+			"new_reference":      transfer.NewReference,
+			"status":             transfer.Status,
+		},
+		ProjectID: func() *string { s := project.ID.String(); return &s }(),
+	}
+
+	switch transfer.Status {
+	case models.TransferCompleted:
+		req.Title = "Project Transferred"
+		req.Message = fmt.Sprintf("Project '%s' has been transferred and is now referenced %s", project.Title, transfer.NewReference)
+	case models.TransferRejected:
+		req.Title = "Project Transfer Rejected"
+		req.Message = fmt.Sprintf("The transfer of project '%s' has been rejected: %s", project.Title, transfer.RejectionReason)
+	default:
+		req.Title = "Project Transfer Requested"
+		req.Message = fmt.Sprintf("A transfer of project '%s' has been requested and awaits the approval of both secretariats: %s", project.Title, transfer.Reason)
+	}
+
+	return s.CreateNotification(req, recipients)
+}
+
+// NotifyNSBResponseChange tells the NSB that asked to change its response and the TC secretariat
+// that the request was submitted, approved or rejected
+func (s *NotificationService) NotifyNSBResponseChange(project *models.Project, change *models.NSBResponseStatusChange) error {
+	recipients := []string{change.ResponderID}
+	if project.TechnicalCommittee != nil && project.TechnicalCommittee.SecretaryId != nil {
+		recipients = append(recipients, *project.TechnicalCommittee.SecretaryId)
+	}
+
+	req := &models.NotificationRequest{
+		Type:     models.NotificationProjectUpdated,
+		Priority: models.NotificationPriorityMedium,
+		Channel:  models.NotificationChannelBoth,
+		Data: map[string]interface{}{
+			"project_id":        project.ID,
+			"project_title":     project.Title,
+			"project_reference": project.Reference,
+			"change_request_id": change.ID,
+			"nsb_response_id":   change.InitialResponseID,
+			"response":          change.Response,
+			"status":            change.Status,
+			"comment":           change.TCSecretariatComment,
+		},
+		ProjectID: func() *string { s := project.ID.String(); return &s }(),
+	}
+
+	switch change.Status {
+	case models.APPROVED:
+		req.Title = "NSB Response Change Approved"
+		req.Message = fmt.Sprintf("The change of an NSB response on project '%s' to %s has been approved: %s", project.Title, change.Response, change.TCSecretariatComment)
+	case models.REJECTED:
+		req.Title = "NSB Response Change Rejected"
+		req.Message = fmt.Sprintf("The change of an NSB response on project '%s' to %s has been rejected: %s", project.Title, change.Response, change.TCSecretariatComment)
+	default:
+		req.Title = "NSB Response Change Requested"
+		req.Message = fmt.Sprintf("An NSB has asked to change its response on project '%s' to %s: %s", project.Title, change.Response, change.Reason)
+	}
+
+	return s.CreateNotification(req, s.removeDuplicates(recipients))
+}
+
+// NotifySMCApprovalOpened asks the SMC and the TC secretariat to approve a proposal whose
+// compiled results met the acceptance criteria
+func (s *NotificationService) NotifySMCApprovalOpened(project *models.Project, acceptance *models.Acceptance) error {
+	recipients, err := s.getMembersByRoles([]string{"SMC_MEMBER"})
+	if err != nil {
+		return fmt.Errorf("failed to get SMC members: %w", err)
+	}
+	if acceptance.TCSecretaryID != nil {
+		recipients = append(recipients, *acceptance.TCSecretaryID)
+	}
+
+	req := &models.NotificationRequest{
+		Type:     models.NotificationProjectUpdated,
+		Priority: models.NotificationPriorityHigh,
+		Channel:  models.NotificationChannelBoth,
+		Title:    "Proposal Ready for SMC Approval",
+		Message:  fmt.Sprintf("The circulation of the proposal for project '%s' has closed and its results met the acceptance criteria: %s", project.Title, acceptance.CriteriaComments),
+		Data: map[string]interface{}{
+			"project_id":        project.ID,
+			"project_title":     project.Title,
+			"project_reference": project.Reference,
+			"acceptance_id":     acceptance.ID,
+			"total_responses":   acceptance.TotalResponses,
+			"agreement_count":   acceptance.AgreementCount,
+			"criteria_comments": acceptance.CriteriaComments,
+		},
+		ProjectID: func() *string { s := project.ID.String(); return &s }(),
+	}
+
+	return s.CreateNotification(req, s.removeDuplicates(recipients))
+}
+
 // NotifyBallotOpened sends notifications when a ballot is opened
 func (s *NotificationService) NotifyBallotOpened(balloting *models.Balloting, project *models.Project) error {
 	// Get eligible voters for this ballot
@@ -785,7 +950,8 @@ func (s *NotificationService) ProcessPendingEmailNotifications() error {
 // shouldSendNotification checks if a notification should be sent based on user preferences
 func (s *NotificationService) shouldSendNotification(notificationType models.NotificationType, preferences *models.NotificationPreference) bool {
 	switch notificationType {
-	case models.NotificationProjectCreated, models.NotificationProjectAssigned, models.NotificationProjectUpdated:
+	case models.NotificationProjectCreated, models.NotificationProjectAssigned, models.NotificationProjectUpdated,
+		models.NotificationProjectOnHold, models.NotificationProjectCancelled, models.NotificationProjectResumed:
 		return preferences.ProjectNotifications
 	case models.NotificationBallotOpened, models.NotificationBallotReminder, models.NotificationBallotClosing, models.NotificationBallotClosed:
 		return preferences.BallotNotifications
@@ -867,6 +1033,28 @@ func (s *NotificationService) getProjectRelatedMembers(project *models.Project)
 	return s.removeDuplicates(recipients), nil
 }
 
+// getCommitteeMembers gets the secretaries, chairpersons and current members of the committees
+func (s *NotificationService) getCommitteeMembers(committeeIDs ...string) ([]string, error) {
+	var committees []models.TechnicalCommittee
+	if err := s.db.Preload("CurrentMembers").Where("id IN ?", committeeIDs).Find(&committees).Error; err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	for _, committee := range committees {
+		if committee.SecretaryId != nil {
+			recipients = append(recipients, *committee.SecretaryId)
+		}
+		if committee.ChairpersonId != nil {
+			recipients = append(recipients, *committee.ChairpersonId)
+		}
+		for _, member := range committee.CurrentMembers {
+			recipients = append(recipients, member.ID.String())
+		}
+	}
+	return s.removeDuplicates(recipients), nil
+}
+
 // getBallotEligibleMembers gets members eligible to vote on a ballot
 func (s *NotificationService) getBallotEligibleMembers(balloting *models.Balloting, project *models.Project) ([]string, error) {
 	// This would need to be implemented based on your balloting eligibility logic
@@ -1020,9 +1208,32 @@ func (s *NotificationService) EscalateOverdueTasks() error {
 	return nil
 }
 
-// SendDeadlineReminders sends deadline reminder notifications
+// deadlineReminderDays are the days before a stage is due on which its committee is reminded
+var deadlineReminderDays = map[int64]bool{30: true, 7: true, 1: true}
+
+// SendDeadlineReminders reminds the committees of the stages their projects must complete in 30, 7
+// or 1 days, as planned from the stage timeframes and the milestone target dates agreed on
+// acceptance
 func (s *NotificationService) SendDeadlineReminders() error {
-	// This would need to be implemented based on your specific deadline logic
-	// For now, this is a placeholder
+	deadlines, err := s.projectRepo.GetStageDeadlines()
+	if err != nil {
+		return fmt.Errorf("failed to get stage deadlines: %w", err)
+	}
+
+	var failed int
+	for i := range deadlines {
+		deadline := &deadlines[i]
+		if !deadlineReminderDays[deadline.DaysLeft] {
+			continue
+		}
+		if err := s.NotifyDeadlineReminder(&deadline.Project, deadline.Stage, int(deadline.DaysLeft)); err != nil {
+			fmt.Printf("Failed to send deadline reminder for project %s: %v\n", deadline.Project.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d deadline reminders", failed)
+	}
 	return nil
 }
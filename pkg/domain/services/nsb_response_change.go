@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// SubmitNSBResponseChange records an NSB's request to change its response to a proposal and tells
+// the TC secretariat about it
+func (service *AcceptanceService) SubmitNSBResponseChange(change *models.NSBResponseStatusChange, memberID string, ipAddress, userAgent, sessionID, requestID string) (*models.NSBResponseStatusChange, error) {
+	startTime := time.Now()
+	change.ResponderID = memberID
+
+	err := service.changeRepo.SubmitChange(change)
+	service.logNSBResponseChange(models.ActionProjectUpdate, change, fmt.Sprintf("Requested a change of NSB response %s to %s", change.InitialResponseID, change.Response),
+		err, startTime, &memberID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.notifyNSBResponseChange(change.ID)
+}
+
+// ApproveNSBResponseChange applies a requested change to the NSB's response, recalculates the
+// acceptance statistics of the project and tells both parties
+func (service *AcceptanceService) ApproveNSBResponseChange(id uuid.UUID, secretary, comment string, ipAddress, userAgent, sessionID, requestID string) (*models.NSBResponseStatusChange, error) {
+	startTime := time.Now()
+
+	err := service.changeRepo.ApproveChange(id, secretary, comment)
+	service.logNSBResponseChange(models.ActionProjectApprove, &models.NSBResponseStatusChange{ID: id, TCSecretariatComment: comment}, fmt.Sprintf("Approved NSB response change request %s", id),
+		err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := service.changeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if change.InitialResponse != nil {
+		service.refreshAcceptance(change.InitialResponse.ProjectID)
+	}
+
+	return service.notifyNSBResponseChange(id)
+}
+
+// RejectNSBResponseChange rejects a requested change, leaving the NSB's response as it was
+func (service *AcceptanceService) RejectNSBResponseChange(id uuid.UUID, secretary, comment string, ipAddress, userAgent, sessionID, requestID string) (*models.NSBResponseStatusChange, error) {
+	startTime := time.Now()
+
+	err := service.changeRepo.RejectChange(id, secretary, comment)
+	service.logNSBResponseChange(models.ActionProjectReject, &models.NSBResponseStatusChange{ID: id, TCSecretariatComment: comment}, fmt.Sprintf("Rejected NSB response change request %s", id),
+		err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.notifyNSBResponseChange(id)
+}
+
+func (service *AcceptanceService) GetNSBResponseChange(id uuid.UUID) (*models.NSBResponseStatusChange, error) {
+	return service.changeRepo.GetByID(id)
+}
+
+func (service *AcceptanceService) GetNSBResponseChanges(responseID string) ([]models.NSBResponseStatusChange, error) {
+	return service.changeRepo.GetByInitialResponseID(responseID)
+}
+
+func (service *AcceptanceService) GetProjectNSBResponseChanges(projectID string) ([]models.NSBResponseStatusChange, error) {
+	return service.changeRepo.GetByProjectID(projectID)
+}
+
+func (service *AcceptanceService) GetPendingNSBResponseChanges(secretary string) ([]models.NSBResponseStatusChange, error) {
+	return service.changeRepo.GetPendingChanges(secretary)
+}
+
+// notifyNSBResponseChange reloads the change request and sends its notification. The request stands
+// if the notification cannot be sent.
+func (service *AcceptanceService) notifyNSBResponseChange(id uuid.UUID) (*models.NSBResponseStatusChange, error) {
+	change, err := service.changeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.notificationService != nil && change.InitialResponse != nil && change.InitialResponse.Project != nil {
+		if err := service.notificationService.NotifyNSBResponseChange(change.InitialResponse.Project, change); err != nil {
+			fmt.Printf("Failed to send notification for NSB response change %s: %v\n", change.ID, err)
+		}
+	}
+	return change, nil
+}
+
+func (service *AcceptanceService) logNSBResponseChange(action models.ActionType, change *models.NSBResponseStatusChange, description string, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	changeID := change.ID.String()
+	service.auditLogService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        action,
+		Module:        models.ModuleProjects,
+		ResourceType:  "NSBResponseStatusChange",
+		ResourceID:    &changeID,
+		ResourceTitle: fmt.Sprintf("NSB response change request %s", changeID),
+		Description:   description,
+		Metadata: map[string]interface{}{
+			"nsb_response_id":             change.InitialResponseID,
+			"response":                    change.Response,
+			"is_committed_to_participate": change.IsCommittedToParticipate,
+			"reason":                      change.Reason,
+			"comment":                     change.TCSecretariatComment,
+		},
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		SessionID:    sessionID,
+		RequestID:    requestID,
+		Success:      err == nil,
+		ErrorMessage: errorMsg,
+		Duration:     time.Since(startTime).Milliseconds(),
+	})
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"time"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// NumberingService is the registry that issues project, ARS, TC N-document and meeting resolution
+// numbers. Numbers are allocated by the database so concurrent requests never share a number.
+type NumberingService struct {
+	repo *repository.NumberingRepository
+}
+
+func NewNumberingService(repo *repository.NumberingRepository) *NumberingService {
+	return &NumberingService{repo: repo}
+}
+
+func (service *NumberingService) NextNumber(scope string) (int64, error) {
+	if err := models.ValidateNumberingScope(scope); err != nil {
+		return 0, err
+	}
+	return service.repo.NextNumber(scope)
+}
+
+func (service *NumberingService) PeekNextNumber(scope string) (int64, error) {
+	if err := models.ValidateNumberingScope(scope); err != nil {
+		return 0, err
+	}
+	return service.repo.PeekNextNumber(scope)
+}
+
+func (service *NumberingService) Reserve(scope string, memberID *string, purpose string, expiresAt *time.Time) (*models.NumberReservation, error) {
+	if err := models.ValidateNumberingScope(scope); err != nil {
+		return nil, err
+	}
+	return service.repo.Reserve(scope, memberID, purpose, expiresAt)
+}
+
+func (service *NumberingService) UseReservation(id uuid.UUID) (*models.NumberReservation, error) {
+	return service.repo.UseReservation(id)
+}
+
+func (service *NumberingService) ReleaseReservation(id uuid.UUID) (*models.NumberReservation, error) {
+	return service.repo.ReleaseReservation(id)
+}
+
+// ReleaseExpiredReservations returns reserved numbers that were not used in time to their scopes
+func (service *NumberingService) ReleaseExpiredReservations() (int64, error) {
+	return service.repo.ReleaseExpiredReservations(time.Now())
+}
+
+func (service *NumberingService) GetReservations(scope string, status models.NumberReservationStatus) ([]models.NumberReservation, error) {
+	return service.repo.GetReservations(scope, status)
+}
+
+func (service *NumberingService) GetSequences() ([]models.NumberSequence, error) {
+	return service.repo.GetSequences()
+}
@@ -12,20 +12,26 @@ import (
 )
 
 type ProjectService struct {
-	repo            *repository.ProjectRepository
-	docService      *DocumentService
-	auditLogService *AuditLogService
+	repo                *repository.ProjectRepository
+	docService          *DocumentService
+	auditLogService     *AuditLogService
+	sagaService         *SagaService
+	notificationService *NotificationService
 }
 
-func NewProjectService(repo *repository.ProjectRepository, docService *DocumentService, auditLogService *AuditLogService) *ProjectService {
+func NewProjectService(repo *repository.ProjectRepository, docService *DocumentService, auditLogService *AuditLogService, sagaService *SagaService, notificationService *NotificationService) *ProjectService {
 	return &ProjectService{
-		repo:            repo,
-		docService:      docService,
-		auditLogService: auditLogService,
+		repo:                repo,
+		docService:          docService,
+		auditLogService:     auditLogService,
+		sagaService:         sagaService,
+		notificationService: notificationService,
 	}
 }
 
-func (service *ProjectService) CreateProject(project *models.Project, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+// CreateProject registers a new project at stage 0. A number reserved in the registry beforehand
+// may be given to number it with, otherwise the next project number is issued.
+func (service *ProjectService) CreateProject(project *models.Project, reservationID *uuid.UUID, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
 	startTime := time.Now()
 	
 	// Generate project ID
@@ -33,20 +39,6 @@ func (service *ProjectService) CreateProject(project *models.Project, userID *st
 	project.CreatedAt = time.Now()
 	project.ProposalApproved = false
 
-	number, err := service.repo.GetNextAvailableNumber()
-	if err != nil {
-		// Log failed action
-		if service.auditLogService != nil {
-			service.auditLogService.LogProjectAction(
-				userID, models.ActionProjectCreate, project.ID.String(), project.Title,
-				map[string]interface{}{"error": "failed to get next available number"},
-				false, err.Error(), time.Since(startTime).Milliseconds(),
-				ipAddress, userAgent, sessionID, requestID,
-			)
-		}
-		return err
-	}
-
 	tc, err := service.repo.GetTCByID(project.TechnicalCommitteeID)
 	if err != nil {
 		// Log failed action
@@ -61,11 +53,6 @@ func (service *ProjectService) CreateProject(project *models.Project, userID *st
 		return err
 	}
 
-	project.Number = number + 1
-
-	// Generate reference number
-	project.Reference = generateProjectReference(project, tc.Code)
-
 	stage, err := service.repo.GetStageByNumber(0)
 	if err != nil {
 		// Log failed action
@@ -83,8 +70,8 @@ func (service *ProjectService) CreateProject(project *models.Project, userID *st
 	// project is at stage 0
 	project.StageID = stage.ID.String()
 
-	// Save project in the repository
-	err = service.repo.CreateProject(project)
+	// Save project in the repository, which allocates its number and reference
+	err = service.repo.CreateProject(project, reservationID)
 	
 	// Log the action
 	if service.auditLogService != nil {
@@ -94,6 +81,9 @@ func (service *ProjectService) CreateProject(project *models.Project, userID *st
 			"technical_committee": tc.Code,
 			"initial_stage":      stage.Name,
 		}
+		if reservationID != nil {
+			metadata["reservation_id"] = reservationID.String()
+		}
 		
 		service.auditLogService.LogProjectAction(
 			userID, models.ActionProjectCreate, project.ID.String(), project.Title,
@@ -111,27 +101,36 @@ func (service *ProjectService) Exists(projectID string) (bool, error) {
 	return service.repo.Exists(projectID)
 }
 
-/*
-	For new WDs, shall indicate WD/TC NN/XXX/YYYY, where NN is the TC code, XXX is the serial
-	number allocated to the Working Draft by the TC Secretariat and YYYY is the year of circulation.
-	For WDs on revision of the standard, shall indicate WD/XXX: YYYY where XXX is the ARS
-	number of the current standard and YYYY is the year of circulation. For example, when revising
-	ARS 461:2021 in 2024, the corresponding drafts shall be numbered as WD/461:2024. This kind
-	of numbering WDs applies also for various stages (CD, DARS and FDARS).
-*/
-
-// generateProjectReference generates the reference number for a project
-func generateProjectReference(project *models.Project, code string) string {
-	currentYear := time.Now().Year()
-	return fmt.Sprintf("PWI/TC %s/%03d/%d", code, project.Number, currentYear)
-}
-
 func (service *ProjectService) GetNextAvailableNumber() (int64, error) {
 	return service.repo.GetNextAvailableNumber()
 }
 
-func (service *ProjectService) UpdateProjectStage(projectID uuid.UUID, newStageID uuid.UUID, notes string) error {
-	return service.repo.UpdateProjectStage(projectID, newStageID, notes)
+func (service *ProjectService) UpdateProjectStage(projectID uuid.UUID, newStageID uuid.UUID, notes string, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+
+	err := service.repo.UpdateProjectStage(projectID, newStageID, userID, notes)
+
+	// Log the action
+	if service.auditLogService != nil {
+		metadata := map[string]interface{}{
+			"new_stage_id": newStageID.String(),
+			"notes":        notes,
+		}
+
+		service.auditLogService.LogProjectAction(
+			userID, models.ActionProjectStageChange, projectID.String(), projectID.String(),
+			metadata, err == nil,
+			func() string { if err != nil { return err.Error() } else { return "" } }(),
+			time.Since(startTime).Milliseconds(),
+			ipAddress, userAgent, sessionID, requestID,
+		)
+	}
+
+	return err
+}
+
+func (service *ProjectService) GetAvailableTransitions(projectID uuid.UUID) ([]models.AvailableTransition, error) {
+	return service.repo.GetAvailableTransitions(projectID)
 }
 
 func (service *ProjectService) ApproveProject(projectID string, approved bool, comment, approvedBy string) error {
@@ -266,6 +265,14 @@ func (service *ProjectService) GetProjectsByReferenceBase(referenceBase string)
 	return service.repo.GetProjectsByReferenceBase(referenceBase)
 }
 
+func (service *ProjectService) GetProjectByReference(reference string) (*models.Project, error) {
+	return service.repo.GetProjectByReference(reference)
+}
+
+func (service *ProjectService) GetProjectReferences(projectID uuid.UUID) ([]models.ProjectReference, error) {
+	return service.repo.GetProjectReferences(projectID)
+}
+
 func (service *ProjectService) CreateProjectRevision(baseProjectID uuid.UUID) (*models.Project, error) {
 	return service.repo.CreateProjectRevision(baseProjectID)
 }
@@ -274,8 +281,20 @@ func (service *ProjectService) GetProjectsApproachingDeadline(daysThreshold int)
 	return service.repo.GetProjectsApproachingDeadline(daysThreshold)
 }
 
-func (service *ProjectService) GetProjectsInStageForTooLong(stageID uuid.UUID, dayThreshold int) ([]models.Project, error) {
-	return service.repo.GetProjectsInStageForTooLong(stageID, dayThreshold)
+func (service *ProjectService) GetProjectSLA(projectID uuid.UUID) (*models.ProjectSLA, error) {
+	return service.repo.GetProjectSLA(projectID)
+}
+
+func (service *ProjectService) ReplanProjectStages(projectID uuid.UUID) ([]models.ProjectStagePlan, error) {
+	return service.repo.ReplanProjectStages(projectID)
+}
+
+func (service *ProjectService) GetSLABreaches(committeeID string) ([]models.ProjectSLA, error) {
+	return service.repo.GetSLABreaches(committeeID)
+}
+
+func (service *ProjectService) GetCommitteeSLASummary() ([]models.CommitteeSLA, error) {
+	return service.repo.GetCommitteeSLASummary()
 }
 
 func (service *ProjectService) GetRelatedProjects(projectID uuid.UUID) ([]models.Project, error) {
@@ -287,65 +306,25 @@ func (service *ProjectService) FetchStages() (*[]models.Stage, error) {
 }
 
 func (service *ProjectService) ReviewWD(secretary, projectID, comment string, status models.WorkingDraftStatus, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
-	err := service.repo.ReviewWD(secretary, projectID, comment, status)
-	if err == nil && status == models.ACCEPTED {
-		projectUUID, err := uuid.Parse(projectID)
-		if err != nil {
-			return err
-		}
-		project, err := service.GetProjectByID(projectUUID)
-		if err != nil {
-			return err
-		}
-		fileName := fmt.Sprintf("%s.docx", strings.ReplaceAll(project.Reference, "/", "-"))
-		doc, errr := service.docService.CopyOneDriveFile(context.Background(), *project.SharepointDocID, fileName, project.Number)
-		if errr != nil {
-			return fmt.Errorf("failed to copy OneDrive file: %w", errr)
-		}
-
-		project.SharepointDocID = &doc.ID
-		err = service.UpdateProject(project, userID, ipAddress, userAgent, sessionID, requestID)
-		if err != nil {
-			return fmt.Errorf("failed to update project after WD review: %w", err)
-		}
-
-		err = service.docService.UpdateProjectDoc(projectID, "CD", fileName, project.MemberID)
-		if err != nil {
-			return fmt.Errorf("failed to create CD: %w", err)
-		}
+	saga, err := service.repo.ReviewWD(secretary, projectID, comment, status)
+	if err != nil {
+		return err
 	}
-	return err
+
+	// The CD copy of the draft is made by the transition saga
+	service.startTransitionSaga(saga, userID, ipAddress, userAgent, sessionID, requestID)
+	return nil
 }
 
 func (service *ProjectService) ReviewCD(secretary, projectId string, isConsensusReached bool, action models.ProposalAction, meetingRequired bool, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
-	err := service.repo.ReviewCD(secretary, projectId, isConsensusReached, action, meetingRequired)
-	if err == nil && isConsensusReached {
-		projectUUID, err := uuid.Parse(projectId)
-		if err != nil {
-			return err
-		}
-		project, err := service.GetProjectByID(projectUUID)
-		if err != nil {
-			return err
-		}
-		fileName := fmt.Sprintf("%s.docx", strings.ReplaceAll(project.Reference, "/", "-"))
-		doc, errr := service.docService.CopyOneDriveFile(context.Background(), *project.SharepointDocID, fileName, project.Number)
-		if errr != nil {
-			return fmt.Errorf("failed to copy OneDrive file: %w", errr)
-		}
-
-		project.SharepointDocID = &doc.ID
-		err = service.UpdateProject(project, userID, ipAddress, userAgent, sessionID, requestID)
-		if err != nil {
-			return fmt.Errorf("failed to update project after CD review: %w", err)
-		}
-
-		err = service.docService.UpdateProjectDoc(projectId, "DARS", fileName, project.MemberID)
-		if err != nil {
-			return fmt.Errorf("failed to create DARS: %w", err)
-		}
+	saga, err := service.repo.ReviewCD(secretary, projectId, isConsensusReached, action, meetingRequired)
+	if err != nil {
+		return err
 	}
-	return err
+
+	// The DARS copy of the draft is made by the transition saga
+	service.startTransitionSaga(saga, userID, ipAddress, userAgent, sessionID, requestID)
+	return nil
 }
 
 func (service *ProjectService) ReviewDARS(secretary,
@@ -359,74 +338,75 @@ func (service *ProjectService) ReviewDARS(secretary,
 		return fmt.Errorf("alternative deliverables cannot be empty when status is rejected")
 
 	}
-	err := service.repo.ReviewDARS(secretary, projectId, wto_notification_notified, unresolvedIssues, alternativeDeliverable, status)
-	if err == nil && status != "" && status == string(models.DARSApproved) {
-		projectUUID, err := uuid.Parse(projectId)
-		if err != nil {
-			return err
-		}
-		project, err := service.GetProjectByID(projectUUID)
-		if err != nil {
-			return err
-		}
-		fileName := fmt.Sprintf("%s.docx", strings.ReplaceAll(project.Reference, "/", "-"))
-		doc, errr := service.docService.CopyOneDriveFile(context.Background(), *project.SharepointDocID, fileName, project.Number)
-		if errr != nil {
-			return fmt.Errorf("failed to copy OneDrive file: %w", errr)
-		}
+	saga, err := service.repo.ReviewDARS(secretary, projectId, wto_notification_notified, unresolvedIssues, alternativeDeliverable, status)
+	if err != nil {
+		return err
+	}
 
-		project.SharepointDocID = &doc.ID
-		err = service.UpdateProject(project, userID, ipAddress, userAgent, sessionID, requestID)
-		if err != nil {
-			return fmt.Errorf("failed to update project after DARS review: %w", err)
-		}
+	// The FDARS copy of the draft is made by the transition saga
+	service.startTransitionSaga(saga, userID, ipAddress, userAgent, sessionID, requestID)
+	return nil
+}
 
-		err = service.docService.UpdateProjectDoc(projectId, "FDARS", fileName, project.MemberID)
-		if err != nil {
-			return fmt.Errorf("failed to create FDARS: %w", err)
+// ApproveFDARS records the secretary's decision on the FDARS ballot. Choosing to cancel the project
+// stops it, and the cancellation is audited like any other.
+func (service *ProjectService) ApproveFDARS(secretary, projectId string, approve bool, action string, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+	if action == "" && !approve {
+		return fmt.Errorf("action cannot be empty when not approving")
+	}
+
+	startTime := time.Now()
+	change, err := service.repo.ApproveFDARS(secretary, projectId, approve, action, reinstatableUntil())
+	if models.FDARSAction(action) == models.CANCELLED {
+		if project, perr := service.getProject(projectId); perr == nil {
+			service.logStatusChange(project, models.ActionProjectCancel, "Cancelled following the FDARS ballot", change, err, startTime, userID, ipAddress, userAgent, sessionID, requestID)
+			if err == nil {
+				service.notifyStatusChange(project, change)
+			}
 		}
 	}
 	return err
 }
 
-func (service *ProjectService) ApproveFDARS(secretary, projectId string, approve bool, action string) error {
-	if action == "" && !approve {
-		return fmt.Errorf("action cannot be empty when not approving")
+func (service *ProjectService) ApproveFDRSForPublication(secretary, projectId string, approve bool, comment string, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+	saga, err := service.repo.ApproveFDRSForPublication(secretary, projectId, approve, comment)
+	if err != nil {
+		return err
 	}
-	return service.repo.ApproveFDARS(secretary, projectId, approve, action)
+
+	// The ARS copy of the draft is made by the transition saga
+	service.startTransitionSaga(saga, userID, ipAddress, userAgent, sessionID, requestID)
+	return nil
 }
 
-func (service *ProjectService) ApproveFDRSForPublication(secretary, projectId string, approve bool, comment string, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
-	err := service.repo.ApproveFDRSForPublication(secretary, projectId, approve, comment)
-	if approve {
-		if err == nil && approve {
-			projectUUID, err := uuid.Parse(projectId)
-			if err != nil {
-				return err
-			}
-			project, err := service.GetProjectByID(projectUUID)
-			if err != nil {
-				return err
-			}
-			fileName := fmt.Sprintf("%s.docx", strings.ReplaceAll(project.Reference, "/", "-"))
-			doc, errr := service.docService.CopyOneDriveFile(context.Background(), *project.SharepointDocID, fileName, project.Number)
-			if errr != nil {
-				return fmt.Errorf("failed to copy OneDrive file: %w", errr)
-			}
+// startTransitionSaga starts the saga that copies the project's draft for its new stage and records
+// the outcome of its first attempt in the audit log
+func (service *ProjectService) startTransitionSaga(saga *models.TransitionSaga, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if saga == nil {
+		return
+	}
 
-			project.SharepointDocID = &doc.ID
-			err = service.UpdateProject(project, userID, ipAddress, userAgent, sessionID, requestID)
-			if err != nil {
-				return fmt.Errorf("failed to update project after FDARS review: %w", err)
-			}
+	startTime := time.Now()
+	service.sagaService.Start(saga, func(err error) {
+		if service.auditLogService == nil {
+			return
+		}
 
-			err = service.docService.UpdateProjectDoc(projectId, "ARS", fileName, project.MemberID)
-			if err != nil {
-				return fmt.Errorf("failed to create ARS: %w", err)
-			}
+		errorMsg := ""
+		if err != nil {
+			errorMsg = err.Error()
 		}
-	}
-	return err
+		metadata := map[string]interface{}{
+			"project_reference": saga.Reference,
+			"transition":        saga.Transition,
+			"saga_id":           saga.ID.String(),
+		}
+		service.auditLogService.LogProjectAction(
+			userID, models.ActionProjectUpdate, saga.ProjectID, saga.Reference,
+			metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+			ipAddress, userAgent, sessionID, requestID,
+		)
+	})
 }
 
 func (service *ProjectService) GetDashboardStats() (map[string]any, error) {
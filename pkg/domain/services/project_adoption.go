@@ -0,0 +1,41 @@
+package services
+
+import (
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+)
+
+// RegisterAdoption registers the adoption of an international document as a project that goes
+// straight to enquiry
+func (service *ProjectService) RegisterAdoption(project *models.Project, adoption *models.AdoptedStandard, secretary string, ipAddress, userAgent, sessionID, requestID string) error {
+	startTime := time.Now()
+	err := service.repo.RegisterAdoption(project, adoption, secretary)
+
+	if service.auditLogService != nil {
+		metadata := map[string]interface{}{
+			"procedure":        models.Adoption,
+			"organisation":     adoption.Organisation,
+			"source_reference": adoption.SourceReference,
+			"equivalence":      adoption.Equivalence,
+		}
+		projectID, errorMsg := "", ""
+		if err == nil {
+			projectID = project.ID.String()
+		} else {
+			errorMsg = err.Error()
+		}
+
+		service.auditLogService.LogProjectAction(
+			&secretary, models.ActionProjectCreate, projectID, project.Title,
+			metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+			ipAddress, userAgent, sessionID, requestID,
+		)
+	}
+
+	return err
+}
+
+func (service *ProjectService) GetAdoptions(equivalence models.EquivalenceDegree, organisation models.SourceOrganisation, limit, offset int) ([]models.AdoptedStandard, int64, error) {
+	return service.repo.GetAdoptions(equivalence, organisation, limit, offset)
+}
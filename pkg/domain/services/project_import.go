@@ -0,0 +1,417 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// importAssetsDir is where the PDFs of imported projects are stored, alongside uploaded documents
+const importAssetsDir = "../assets/documents"
+
+// maxImportFileSize is the largest PDF accepted from an import archive, as for document uploads
+const maxImportFileSize = 100 << 20
+
+var (
+	ErrUnsupportedManifest = errors.New("the manifest must be a CSV or XLSX file")
+	ErrInvalidManifest     = errors.New("the manifest cannot be read")
+	ErrInvalidArchive      = errors.New("the archive must be a ZIP file")
+	ErrImportFileTooLarge  = errors.New("the file is larger than 100 MB")
+)
+
+// importColumns are the manifest columns, with the required ones set to true
+var importColumns = map[string]bool{
+	"title":            true,
+	"tc_code":          true,
+	"description":      false,
+	"language":         false,
+	"working_group":    false,
+	"sector":           false,
+	"stage":            false,
+	"type":             false,
+	"procedure":        false,
+	"standard_number":  false,
+	"part_number":      false,
+	"edition_number":   false,
+	"time_frame":       false,
+	"reference":        false,
+	"stage_started_at": false,
+	"published_date":   false,
+	"file":             false,
+}
+
+// ImportProjects validates a CSV or XLSX manifest of legacy projects and published standards, with
+// a ZIP of the PDFs it names, and imports it unless a dry run is asked for or a row is invalid.
+// The report lists the problems of every row; it carries the import batch once the rows are imported.
+func (service *ProjectService) ImportProjects(manifestName string, manifest []byte, archiveName string, archive []byte, dryRun bool, memberID string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectImportReport, error) {
+	records, err := readImportManifest(manifestName, manifest)
+	if err != nil {
+		return nil, err
+	}
+	rows, errs, err := importRowsFromRecords(records)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string]*zip.File
+	if len(archive) > 0 {
+		if files, err = readImportArchive(archive); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range rows {
+		if row.File == "" {
+			continue
+		}
+		if !strings.EqualFold(filepath.Ext(row.File), ".pdf") {
+			errs = append(errs, models.ProjectImportError{Row: row.Row, Column: "file", Message: "files must be PDFs"})
+		} else if file, ok := files[row.File]; !ok {
+			errs = append(errs, models.ProjectImportError{Row: row.Row, Column: "file", Message: fmt.Sprintf("%s is not in the archive", row.File)})
+		} else if file.UncompressedSize64 > maxImportFileSize {
+			errs = append(errs, models.ProjectImportError{Row: row.Row, Column: "file", Message: ErrImportFileTooLarge.Error()})
+		}
+	}
+
+	rowErrs, err := service.repo.ValidateImportRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	errs = append(errs, rowErrs...)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+
+	invalid := map[int]bool{}
+	for _, e := range errs {
+		invalid[e.Row] = true
+	}
+	report := &models.ProjectImportReport{
+		DryRun:       dryRun,
+		ManifestName: manifestName,
+		ArchiveName:  archiveName,
+		TotalRows:    len(rows),
+		Errors:       errs,
+		Rows:         rows,
+	}
+	for _, row := range rows {
+		if !invalid[row.Row] {
+			report.ValidRows++
+		}
+	}
+	if report.Errors == nil {
+		report.Errors = []models.ProjectImportError{}
+	}
+	if dryRun || len(errs) > 0 {
+		return report, nil
+	}
+
+	startTime := time.Now()
+	written, err := extractImportFiles(rows, files)
+	if err == nil {
+		batch := models.ProjectImportBatch{
+			ManifestName: manifestName,
+			ArchiveName:  archiveName,
+			ImportedByID: memberID,
+		}
+		if err = service.repo.ImportProjects(&batch, rows); err == nil {
+			report.Batch = &batch
+		}
+	}
+	if err != nil {
+		removeImportFiles(written)
+	}
+
+	service.logImport(models.ActionProjectCreate, report.Batch, manifestName, err, startTime, &memberID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// UndoImportBatch removes every project, standard and document created by an import batch,
+// together with their files
+func (service *ProjectService) UndoImportBatch(batchID uuid.UUID, memberID string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectImportBatch, error) {
+	startTime := time.Now()
+	batch, fileURLs, err := service.repo.UndoImportBatch(batchID, memberID)
+	service.logImport(models.ActionProjectDelete, batch, "", err, startTime, &memberID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, fileURL := range fileURLs {
+		if strings.HasPrefix(fileURL, "/assets/documents/") {
+			paths = append(paths, filepath.Join(importAssetsDir, path.Base(fileURL)))
+		}
+	}
+	removeImportFiles(paths)
+	return batch, nil
+}
+
+func (service *ProjectService) GetImportBatch(id uuid.UUID) (*models.ProjectImportBatch, error) {
+	return service.repo.GetImportBatch(id)
+}
+
+func (service *ProjectService) GetImportBatches(limit, offset int) ([]models.ProjectImportBatch, int64, error) {
+	return service.repo.GetImportBatches(limit, offset)
+}
+
+func (service *ProjectService) logImport(action models.ActionType, batch *models.ProjectImportBatch, manifestName string, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	var resourceID *string
+	metadata := map[string]interface{}{}
+	if batch != nil {
+		id := batch.ID.String()
+		resourceID = &id
+		manifestName = batch.ManifestName
+		metadata["status"] = batch.Status
+		metadata["total_rows"] = batch.TotalRows
+		metadata["projects_created"] = batch.ProjectsCreated
+		metadata["standards_created"] = batch.StandardsCreated
+		metadata["documents_created"] = batch.DocumentsCreated
+	}
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	description := fmt.Sprintf("Imported projects from %s", manifestName)
+	if action == models.ActionProjectDelete {
+		description = fmt.Sprintf("Undid the import of %s", manifestName)
+	}
+	service.auditLogService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        action,
+		Module:        models.ModuleProjects,
+		ResourceType:  "ProjectImportBatch",
+		ResourceID:    resourceID,
+		ResourceTitle: manifestName,
+		Description:   description,
+		Metadata:      metadata,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+		SessionID:     sessionID,
+		RequestID:     requestID,
+		Success:       err == nil,
+		ErrorMessage:  errorMsg,
+		Duration:      time.Since(startTime).Milliseconds(),
+	})
+}
+
+// readImportManifest reads the cells of a CSV file or of the first sheet of an XLSX workbook
+func readImportManifest(name string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+		}
+		return records, nil
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+		}
+		defer workbook.Close()
+
+		records, err := workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+		}
+		return records, nil
+	}
+	return nil, ErrUnsupportedManifest
+}
+
+// importRowsFromRecords reads the rows of the manifest by the column names of its header. Cells that
+// cannot be read are reported against their row; blank lines are skipped.
+func importRowsFromRecords(records [][]string) ([]models.ProjectImportRow, []models.ProjectImportError, error) {
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%w: the manifest is empty", ErrInvalidManifest)
+	}
+
+	var errs []models.ProjectImportError
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := importColumns[name]; !ok {
+			errs = append(errs, models.ProjectImportError{Row: 1, Column: name, Message: "unknown column"})
+			continue
+		}
+		columns[name] = i
+	}
+	for name, required := range importColumns {
+		if _, ok := columns[name]; required && !ok {
+			return nil, nil, fmt.Errorf("%w: the %s column is missing", ErrInvalidManifest, name)
+		}
+	}
+
+	rows := []models.ProjectImportRow{}
+	for i, record := range records[1:] {
+		line := i + 2
+		cell := func(name string) string {
+			if index, ok := columns[name]; ok && index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		integer := func(name string) int64 {
+			value := cell(name)
+			if value == "" {
+				return 0
+			}
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				errs = append(errs, models.ProjectImportError{Row: line, Column: name, Message: fmt.Sprintf("%q is not a whole number", value)})
+			}
+			return number
+		}
+		date := func(name string) *time.Time {
+			value := cell(name)
+			if value == "" {
+				return nil
+			}
+			for _, layout := range []string{"2006-01-02", "2006"} {
+				if parsed, err := time.Parse(layout, value); err == nil {
+					return &parsed
+				}
+			}
+			errs = append(errs, models.ProjectImportError{Row: line, Column: name, Message: fmt.Sprintf("%q is not a date in the form YYYY-MM-DD", value)})
+			return nil
+		}
+
+		row := models.ProjectImportRow{
+			Row:            line,
+			Title:          cell("title"),
+			Description:    cell("description"),
+			Language:       cell("language"),
+			CommitteeCode:  cell("tc_code"),
+			WorkingGroup:   cell("working_group"),
+			Sector:         cell("sector"),
+			Type:           models.ProjectType(strings.ToUpper(cell("type"))),
+			Procedure:      models.Procedure(cell("procedure")),
+			StandardNumber: integer("standard_number"),
+			PartNo:         integer("part_number"),
+			EditionNo:      integer("edition_number"),
+			Timeframe:      int(integer("time_frame")),
+			Reference:      cell("reference"),
+			StageStartedAt: date("stage_started_at"),
+			PublishedDate:  date("published_date"),
+			File:           path.Base(filepath.ToSlash(cell("file"))),
+		}
+		if row.File == "." {
+			row.File = ""
+		}
+		if cell("stage") != "" {
+			stage := int(integer("stage"))
+			row.StageNumber = &stage
+		}
+		rows = append(rows, row)
+	}
+	return rows, errs, nil
+}
+
+// readImportArchive indexes the files of a ZIP archive by name, ignoring the folders they are in
+func readImportArchive(data []byte) (map[string]*zip.File, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		if _, ok := files[path.Base(file.Name)]; !ok {
+			files[path.Base(file.Name)] = file
+		}
+	}
+	return files, nil
+}
+
+// extractImportFiles stores the PDFs named by the rows with the uploaded documents and sets the
+// file URL of each row. It returns the paths written, so they can be removed if the import fails.
+func extractImportFiles(rows []models.ProjectImportRow, files map[string]*zip.File) ([]string, error) {
+	var written []string
+	if err := os.MkdirAll(importAssetsDir, 0755); err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if rows[i].File == "" {
+			continue
+		}
+
+		filename := uuid.New().String() + ".pdf"
+		target := filepath.Join(importAssetsDir, filename)
+		written = append(written, target)
+		if err := extractImportFile(files[rows[i].File], target); err != nil {
+			return written, fmt.Errorf("row %d: %w", rows[i].Row, err)
+		}
+		rows[i].FileURL = "/assets/documents/" + filename
+	}
+	return written, nil
+}
+
+// extractImportFile writes a PDF of the archive to the target. The size recorded in the archive is
+// not trusted; extraction stops once the file grows past maxImportFileSize.
+func extractImportFile(file *zip.File, target string) error {
+	if file.UncompressedSize64 > maxImportFileSize {
+		return ErrImportFileTooLarge
+	}
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	written, err := io.Copy(dst, io.LimitReader(src, maxImportFileSize+1))
+	if err != nil {
+		return err
+	}
+	if written > maxImportFileSize {
+		return ErrImportFileTooLarge
+	}
+	return nil
+}
+
+func removeImportFiles(paths []string) {
+	for _, target := range paths {
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Failed to remove imported file %s: %v\n", target, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// maxRelationshipDepth bounds how many links a relationship graph follows
+const maxRelationshipDepth = 3
+
+// CreateRelationship links a project to another project or published standard and returns warnings
+// about normative references to standards that are no longer current
+func (service *ProjectService) CreateRelationship(relationship *models.ProjectRelationship, ipAddress, userAgent, sessionID, requestID string) ([]models.RelationshipWarning, error) {
+	project, err := service.getProject(relationship.SourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	warnings, err := service.repo.CreateRelationship(relationship)
+
+	if service.auditLogService != nil {
+		metadata := map[string]interface{}{
+			"relationship": relationship.Type,
+			"target_id":    relationship.TargetID,
+			"warnings":     len(warnings),
+		}
+		errorMsg := ""
+		if err != nil {
+			errorMsg = err.Error()
+		}
+
+		service.auditLogService.LogProjectAction(
+			relationship.CreatedByID, models.ActionProjectUpdate, relationship.SourceID, project.Title,
+			metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+			ipAddress, userAgent, sessionID, requestID,
+		)
+	}
+
+	return warnings, err
+}
+
+// DeleteRelationship removes a link a member made from the project
+func (service *ProjectService) DeleteRelationship(projectID string, relationshipID uuid.UUID, userID *string, ipAddress, userAgent, sessionID, requestID string) error {
+	project, err := service.getProject(projectID)
+	if err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+	relationship, err := service.repo.DeleteRelationship(projectID, relationshipID)
+
+	if service.auditLogService != nil {
+		metadata := map[string]interface{}{
+			"removed_relationship_id": relationshipID.String(),
+		}
+		if relationship != nil {
+			metadata["relationship"] = relationship.Type
+			metadata["target_id"] = relationship.TargetID
+		}
+		errorMsg := ""
+		if err != nil {
+			errorMsg = err.Error()
+		}
+
+		service.auditLogService.LogProjectAction(
+			userID, models.ActionProjectUpdate, projectID, project.Title,
+			metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+			ipAddress, userAgent, sessionID, requestID,
+		)
+	}
+
+	return err
+}
+
+// GetRelationshipGraph returns the projects and standards linked to the project within the given
+// number of links, capped at maxRelationshipDepth
+func (service *ProjectService) GetRelationshipGraph(projectID string, depth int) (*models.RelationshipGraph, error) {
+	if depth < 1 {
+		depth = 1
+	}
+	if depth > maxRelationshipDepth {
+		depth = maxRelationshipDepth
+	}
+	return service.repo.GetRelationshipGraph(projectID, depth)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// RollbackProject returns a project to an earlier stage for the given reason. Returning to the
+// enquiry or ballot stage opens a new enquiry or ballot, which the NSBs are told about.
+func (service *ProjectService) RollbackProject(projectID string, toStage int, reason, secretary string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectRollback, error) {
+	project, err := service.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := models.ProjectRollback{
+		ProjectID:      projectID,
+		Reason:         reason,
+		RolledBackByID: secretary,
+	}
+
+	startTime := time.Now()
+	err = service.repo.RollbackProject(&rollback, toStage)
+	service.logRollback(project, &rollback, toStage, err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	service.notifyRollback(projectID, toStage)
+	return service.repo.GetRollback(rollback.ID)
+}
+
+func (service *ProjectService) GetRollback(id uuid.UUID) (*models.ProjectRollback, error) {
+	return service.repo.GetRollback(id)
+}
+
+func (service *ProjectService) GetProjectRollbacks(projectID string) ([]models.ProjectRollback, error) {
+	return service.repo.GetProjectRollbacks(projectID)
+}
+
+func (service *ProjectService) logRollback(project *models.Project, rollback *models.ProjectRollback, toStage int, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{
+		"rollback_id":          rollback.ID,
+		"to_stage":             toStage,
+		"reason":               rollback.Reason,
+		"previous_reference":   rollback.PreviousReference,
+		"new_reference":        rollback.NewReference,
+		"superseded_ballot_id": rollback.SupersededBallotID,
+		"superseded_dars_id":   rollback.SupersededDARSID,
+	}
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	service.auditLogService.LogProjectAction(
+		userID, models.ActionProjectStageChange, project.ID.String(), project.Title,
+		metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+		ipAddress, userAgent, sessionID, requestID,
+	)
+}
+
+// notifyRollback announces the enquiry or ballot reopened by the rollback. The rollback stands if
+// the notifications cannot be sent.
+func (service *ProjectService) notifyRollback(projectID string, toStage int) {
+	if service.notificationService == nil || (toStage != 4 && toStage != 5) {
+		return
+	}
+
+	project, err := service.getProject(projectID)
+	if err != nil {
+		fmt.Printf("Failed to load project %s for rollback notification: %v\n", projectID, err)
+		return
+	}
+
+	switch {
+	case toStage == 4 && project.DARS != nil:
+		err = service.notificationService.NotifyCommentWindowOpened(project, project.DARS.PublicReviewEndDate.Format("2006-01-02"))
+	case toStage == 5 && project.Balloting != nil:
+		err = service.notificationService.NotifyBallotOpened(project.Balloting, project)
+	}
+	if err != nil {
+		fmt.Printf("Failed to send rollback notification for project %s: %v\n", projectID, err)
+	}
+}
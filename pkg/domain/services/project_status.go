@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/config"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// SuspendProject puts a project on hold until it is reinstated
+func (service *ProjectService) SuspendProject(projectID, reason string, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectStatusChange, error) {
+	return service.stopProject(projectID, models.ProjectOnHold, models.ActionProjectSuspend, reason, userID, ipAddress, userAgent, sessionID, requestID)
+}
+
+// CancelProject cancels a project. It can be reinstated within the configured window.
+func (service *ProjectService) CancelProject(projectID, reason string, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectStatusChange, error) {
+	return service.stopProject(projectID, models.ProjectCancelled, models.ActionProjectCancel, reason, userID, ipAddress, userAgent, sessionID, requestID)
+}
+
+// WithdrawProject cancels a project at the request of its proposer
+func (service *ProjectService) WithdrawProject(projectID, reason string, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectStatusChange, error) {
+	return service.stopProject(projectID, models.ProjectWithdrawn, models.ActionProjectWithdraw, reason, userID, ipAddress, userAgent, sessionID, requestID)
+}
+
+// ReinstateProject returns a stopped project to the stage it was in and resumes its frozen
+// ballots, comment windows and meetings
+func (service *ProjectService) ReinstateProject(projectID, reason string, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectStatusChange, error) {
+	project, err := service.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	change, err := service.repo.ReinstateProject(projectID, reason, userID)
+	service.logStatusChange(project, models.ActionProjectReinstate, reason, change, err, startTime, userID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	service.notifyStatusChange(project, change)
+	return change, nil
+}
+
+func (service *ProjectService) GetProjectStatusChanges(projectID string) ([]models.ProjectStatusChange, error) {
+	return service.repo.GetProjectStatusChanges(projectID)
+}
+
+func (service *ProjectService) stopProject(projectID string, status models.ProjectStatus, action models.ActionType, reason string, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectStatusChange, error) {
+	project, err := service.getProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	change, err := service.repo.StopProject(projectID, status, reason, userID, reinstatableUntil())
+	service.logStatusChange(project, action, reason, change, err, startTime, userID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	service.notifyStatusChange(project, change)
+	return change, nil
+}
+
+func (service *ProjectService) getProject(projectID string) (*models.Project, error) {
+	id, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+	return service.repo.GetProjectByID(id)
+}
+
+func (service *ProjectService) logStatusChange(project *models.Project, action models.ActionType, reason string, change *models.ProjectStatusChange, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{"reason": reason}
+	if change != nil {
+		metadata["status"] = change.Status
+		metadata["stage_id"] = change.StageID
+		metadata["frozen_activities"] = len(change.FrozenActivities)
+	}
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	service.auditLogService.LogProjectAction(
+		userID, action, project.ID.String(), project.Title,
+		metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+		ipAddress, userAgent, sessionID, requestID,
+	)
+}
+
+// notifyStatusChange tells the project's stakeholders about the change. The change stands if the
+// notifications cannot be sent.
+func (service *ProjectService) notifyStatusChange(project *models.Project, change *models.ProjectStatusChange) {
+	if service.notificationService == nil {
+		return
+	}
+
+	if err := service.notificationService.NotifyProjectStatusChanged(project, change); err != nil {
+		fmt.Printf("Failed to send status notification for project %s: %v\n", project.ID, err)
+	}
+}
+
+// reinstatableUntil is the end of the reinstatement window of a project cancelled now, or nil when
+// the window is unlimited
+func reinstatableUntil() *time.Time {
+	days := config.GetConfig().PROJECT_REINSTATEMENT_WINDOW_DAYS
+	if days <= 0 {
+		return nil
+	}
+	until := time.Now().AddDate(0, 0, days)
+	return &until
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// CreateAmendment opens an amendment of a published standard
+func (service *ProjectService) CreateAmendment(baseProjectID uuid.UUID, memberID, title, description string, ipAddress, userAgent, sessionID, requestID string) (*models.Project, error) {
+	return service.createSupplement(baseProjectID, models.AMENDMENT, memberID, title, description, ipAddress, userAgent, sessionID, requestID)
+}
+
+// CreateCorrigendum opens a technical corrigendum of a published standard
+func (service *ProjectService) CreateCorrigendum(baseProjectID uuid.UUID, memberID, title, description string, ipAddress, userAgent, sessionID, requestID string) (*models.Project, error) {
+	return service.createSupplement(baseProjectID, models.CORRIGENDUM, memberID, title, description, ipAddress, userAgent, sessionID, requestID)
+}
+
+// GetSupplements lists the amendments and corrigenda of a standard
+func (service *ProjectService) GetSupplements(baseProjectID uuid.UUID) ([]models.Project, error) {
+	return service.repo.GetSupplements(baseProjectID)
+}
+
+func (service *ProjectService) createSupplement(baseProjectID uuid.UUID, supplementType models.ProjectType, memberID, title, description string, ipAddress, userAgent, sessionID, requestID string) (*models.Project, error) {
+	startTime := time.Now()
+	supplement, err := service.repo.CreateSupplement(baseProjectID, supplementType, memberID, title, description)
+
+	if service.auditLogService != nil {
+		metadata := map[string]interface{}{
+			"type":             supplementType,
+			"base_standard_id": baseProjectID.String(),
+		}
+		projectID, reference, errorMsg := "", "", ""
+		if supplement != nil {
+			projectID = supplement.ID.String()
+			reference = supplement.Reference
+			metadata["supplement_number"] = supplement.SupplementNo
+		}
+		if err != nil {
+			errorMsg = err.Error()
+		}
+
+		service.auditLogService.LogProjectAction(
+			&memberID, models.ActionProjectCreate, projectID, reference,
+			metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+			ipAddress, userAgent, sessionID, requestID,
+		)
+	}
+
+	return supplement, err
+}
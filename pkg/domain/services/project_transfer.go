@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// RequestTransfer asks to move a project to another technical committee or working group. The
+// transfer is carried out at once when the requester is the secretary of both committees.
+func (service *ProjectService) RequestTransfer(transfer *models.ProjectTransfer, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectTransfer, error) {
+	project, err := service.getProject(transfer.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	err = service.repo.RequestTransfer(transfer)
+	service.logTransfer(project, transfer, err, startTime, &transfer.RequestedByID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	service.notifyTransfer(project, transfer)
+	return service.repo.GetTransfer(transfer.ID)
+}
+
+// ApproveTransfer records the approval of one of the secretariats and carries out the transfer
+// once both have approved
+func (service *ProjectService) ApproveTransfer(transferID uuid.UUID, secretary string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectTransfer, error) {
+	startTime := time.Now()
+	transfer, err := service.repo.ApproveTransfer(transferID, secretary)
+	if err != nil {
+		service.logFailedTransfer(transferID, err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+		return nil, err
+	}
+
+	service.logTransfer(transfer.Project, transfer, nil, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	if transfer.Status == models.TransferCompleted {
+		service.notifyTransfer(transfer.Project, transfer)
+	}
+	return transfer, nil
+}
+
+// RejectTransfer ends a pending transfer; the project stays where it is
+func (service *ProjectService) RejectTransfer(transferID uuid.UUID, secretary, reason string, ipAddress, userAgent, sessionID, requestID string) (*models.ProjectTransfer, error) {
+	startTime := time.Now()
+	transfer, err := service.repo.RejectTransfer(transferID, secretary, reason)
+	if err != nil {
+		service.logFailedTransfer(transferID, err, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+		return nil, err
+	}
+
+	service.logTransfer(transfer.Project, transfer, nil, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	service.notifyTransfer(transfer.Project, transfer)
+	return transfer, nil
+}
+
+func (service *ProjectService) GetProjectTransfers(projectID string) ([]models.ProjectTransfer, error) {
+	return service.repo.GetProjectTransfers(projectID)
+}
+
+func (service *ProjectService) GetPendingTransfers(secretary string) ([]models.ProjectTransfer, error) {
+	return service.repo.GetPendingTransfers(secretary)
+}
+
+func (service *ProjectService) logTransfer(project *models.Project, transfer *models.ProjectTransfer, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil || project == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{
+		"transfer_id":        transfer.ID,
+		"status":             transfer.Status,
+		"from_committee_id":  transfer.FromCommitteeID,
+		"to_committee_id":    transfer.ToCommitteeID,
+		"previous_reference": transfer.PreviousReference,
+		"new_reference":      transfer.NewReference,
+		"reason":             transfer.Reason,
+	}
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	service.auditLogService.LogProjectAction(
+		userID, models.ActionProjectUpdate, project.ID.String(), project.Title,
+		metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+		ipAddress, userAgent, sessionID, requestID,
+	)
+}
+
+// logFailedTransfer records a decision on a transfer that could not be made against the project
+// being transferred. Nothing is recorded when the transfer does not exist.
+func (service *ProjectService) logFailedTransfer(transferID uuid.UUID, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	transfer, gerr := service.repo.GetTransfer(transferID)
+	if gerr != nil {
+		return
+	}
+	service.logTransfer(transfer.Project, transfer, err, startTime, userID, ipAddress, userAgent, sessionID, requestID)
+}
+
+// notifyTransfer tells both committees about the transfer. The transfer stands if the
+// notifications cannot be sent.
+func (service *ProjectService) notifyTransfer(project *models.Project, transfer *models.ProjectTransfer) {
+	if service.notificationService == nil || project == nil {
+		return
+	}
+
+	if err := service.notificationService.NotifyProjectTransfer(project, transfer); err != nil {
+		fmt.Printf("Failed to send transfer notification for project %s: %v\n", project.ID, err)
+	}
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	redisClient "github.com/ekbaya/asham/pkg/db/redis"
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+)
+
+const schedulerLockPrefix = "scheduler:lock:"
+
+var (
+	ErrJobNotFound       = errors.New("scheduled job not found")
+	ErrJobAlreadyRunning = errors.New("scheduled job is already running")
+)
+
+// releaseLockScript deletes the lock only if it is still held by the caller
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// renewLockScript extends the lock only if it is still held by the caller
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// JobFunc is the work performed by a scheduled job
+type JobFunc func(ctx context.Context) error
+
+type registeredJob struct {
+	name        string
+	spec        string
+	description string
+	timeout     time.Duration
+	run         JobFunc
+	entryID     cron.EntryID
+}
+
+// SchedulerService runs registered jobs on cron schedules. Every replica runs the scheduler; a Redis
+// lock per job and scheduled time makes sure only one of them executes a given run, and a lock per
+// job, held for as long as the job runs, keeps runs of the same job from overlapping.
+type SchedulerService struct {
+	repo        *repository.SchedulerRepository
+	redisClient *redis.Client
+	cron        *cron.Cron
+	instance    string
+	mu          sync.RWMutex
+	jobs        map[string]*registeredJob
+}
+
+func NewSchedulerService(repo *repository.SchedulerRepository) *SchedulerService {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = uuid.NewString()
+	}
+
+	return &SchedulerService{
+		repo:        repo,
+		redisClient: redisClient.GetRedis(),
+		cron:        cron.New(),
+		instance:    instance,
+		jobs:        make(map[string]*registeredJob),
+	}
+}
+
+// RegisterJob adds a job with a standard five field cron expression. The timeout bounds the run
+// and is how long the lock on a scheduled time is kept after the run.
+func (s *SchedulerService) RegisterJob(name, spec, description string, timeout time.Duration, run JobFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("job %s is already registered", name)
+	}
+
+	job := &registeredJob{
+		name:        name,
+		spec:        spec,
+		description: description,
+		timeout:     timeout,
+		run:         run,
+	}
+
+	entryID, err := s.cron.AddFunc(spec, func() { s.runScheduled(job) })
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for job %s: %w", spec, name, err)
+	}
+	job.entryID = entryID
+
+	if err := s.repo.RegisterJob(&models.ScheduledJob{
+		Name:        name,
+		Description: description,
+		Schedule:    spec,
+	}); err != nil {
+		s.cron.Remove(entryID)
+		return fmt.Errorf("failed to register job %s: %w", name, err)
+	}
+
+	s.jobs[name] = job
+	return nil
+}
+
+// Start begins running jobs on their schedules
+func (s *SchedulerService) Start() {
+	fmt.Printf("[Scheduler] Starting on %s with %d jobs\n", s.instance, len(s.jobs))
+	s.cron.Start()
+}
+
+// Stop stops scheduling new runs and waits for running jobs until the context is done
+func (s *SchedulerService) Stop(ctx context.Context) {
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+	}
+}
+
+// ListJobs returns all registered jobs together with their next run time on this replica
+func (s *SchedulerService) ListJobs() ([]models.ScheduledJob, error) {
+	jobs, err := s.repo.GetJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	registered := []models.ScheduledJob{}
+	for _, job := range jobs {
+		local, ok := s.jobs[job.Name]
+		if !ok {
+			// Job row left behind by an older release
+			continue
+		}
+		if next := s.cron.Entry(local.entryID).Next; !next.IsZero() && !job.Paused {
+			job.NextRunAt = &next
+		}
+		registered = append(registered, job)
+	}
+	return registered, nil
+}
+
+func (s *SchedulerService) PauseJob(name string, memberID *string) error {
+	if _, err := s.getJob(name); err != nil {
+		return err
+	}
+	return s.repo.SetJobPaused(name, true, memberID)
+}
+
+func (s *SchedulerService) ResumeJob(name string) error {
+	if _, err := s.getJob(name); err != nil {
+		return err
+	}
+	return s.repo.SetJobPaused(name, false, nil)
+}
+
+// TriggerJob runs a job immediately, regardless of whether it is paused. The run record is
+// returned as soon as the job has started.
+func (s *SchedulerService) TriggerJob(name string, memberID *string) (*models.JobRun, error) {
+	job, err := s.getJob(name)
+	if err != nil {
+		return nil, err
+	}
+
+	token, acquired, err := s.acquireLock(job)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrJobAlreadyRunning
+	}
+
+	run, err := s.startRun(job, models.JobTriggerManual, memberID)
+	if err != nil {
+		s.releaseLock(job, token)
+		return nil, err
+	}
+
+	started := *run
+	go s.execute(job, run, token)
+
+	return &started, nil
+}
+
+func (s *SchedulerService) GetJobRuns(name string, limit, offset int) ([]models.JobRun, int64, error) {
+	if _, err := s.getJob(name); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetJobRuns(name, limit, offset)
+}
+
+func (s *SchedulerService) getJob(name string) (*registeredJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// runScheduled is invoked by cron on every replica. Only the first replica to claim the scheduled
+// time runs it; the claim is not released, so a replica that fires late skips the same run. Replicas
+// that do not get the locks skip the run without recording anything.
+func (s *SchedulerService) runScheduled(job *registeredJob) {
+	state, err := s.repo.GetJobByName(job.name)
+	if err != nil {
+		fmt.Printf("[Scheduler] Failed to load job %s: %v\n", job.name, err)
+		return
+	}
+	if state.Paused {
+		return
+	}
+
+	scheduledAt := s.cron.Entry(job.entryID).Prev
+	if scheduledAt.IsZero() {
+		scheduledAt = time.Now().Truncate(time.Minute)
+	}
+	slotKey := fmt.Sprintf("%s%s:%s", schedulerLockPrefix, job.name, scheduledAt.UTC().Format(time.RFC3339))
+	claimed, err := s.redisClient.SetNX(context.Background(), slotKey, s.instance, job.timeout).Result()
+	if err != nil {
+		fmt.Printf("[Scheduler] Failed to claim scheduled run of job %s: %v\n", job.name, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	token, acquired, err := s.acquireLock(job)
+	if err != nil {
+		fmt.Printf("[Scheduler] Failed to acquire lock for job %s: %v\n", job.name, err)
+		return
+	}
+	if !acquired {
+		fmt.Printf("[Scheduler] Skipping run of job %s scheduled at %s, the previous run is still in progress\n", job.name, scheduledAt.Format(time.RFC3339))
+		return
+	}
+
+	run, err := s.startRun(job, models.JobTriggerSchedule, nil)
+	if err != nil {
+		fmt.Printf("[Scheduler] Failed to record run for job %s: %v\n", job.name, err)
+		s.releaseLock(job, token)
+		return
+	}
+
+	s.execute(job, run, token)
+}
+
+func (s *SchedulerService) startRun(job *registeredJob, trigger models.JobTrigger, memberID *string) (*models.JobRun, error) {
+	run := &models.JobRun{
+		JobName:       job.name,
+		Trigger:       trigger,
+		TriggeredByID: memberID,
+		Status:        models.JobRunRunning,
+		Instance:      s.instance,
+		StartedAt:     time.Now(),
+	}
+	if err := s.repo.CreateJobRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute runs the job, records its outcome and releases the lock. The lock is renewed while the
+// job runs, so it cannot expire under a run that outlasts its timeout.
+func (s *SchedulerService) execute(job *registeredJob, run *models.JobRun, token string) {
+	defer s.releaseLock(job, token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), job.timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.renewLock(job, token, done)
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+			}
+		}()
+		return job.run(ctx)
+	}()
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = finishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Status = models.JobRunSucceeded
+	if err != nil {
+		run.Status = models.JobRunFailed
+		run.Error = err.Error()
+		fmt.Printf("[Scheduler] Job %s failed after %dms: %v\n", job.name, run.DurationMs, err)
+	}
+
+	if err := s.repo.FinishJobRun(run); err != nil {
+		fmt.Printf("[Scheduler] Failed to record outcome of job %s: %v\n", job.name, err)
+	}
+}
+
+func (s *SchedulerService) acquireLock(job *registeredJob) (string, bool, error) {
+	token := fmt.Sprintf("%s:%s", s.instance, uuid.NewString())
+	acquired, err := s.redisClient.SetNX(context.Background(), schedulerLockPrefix+job.name, token, job.timeout).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	return token, acquired, nil
+}
+
+// renewLock extends the job lock at a third of its lifetime until done is closed
+func (s *SchedulerService) renewLock(job *registeredJob, token string, done <-chan struct{}) {
+	ticker := time.NewTicker(job.timeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := renewLockScript.Run(context.Background(), s.redisClient, []string{schedulerLockPrefix + job.name}, token, job.timeout.Milliseconds()).Err(); err != nil {
+				fmt.Printf("[Scheduler] Failed to renew lock for job %s: %v\n", job.name, err)
+			}
+		}
+	}
+}
+
+func (s *SchedulerService) releaseLock(job *registeredJob, token string) {
+	if err := releaseLockScript.Run(context.Background(), s.redisClient, []string{schedulerLockPrefix + job.name}, token).Err(); err != nil {
+		fmt.Printf("[Scheduler] Failed to release lock for job %s: %v\n", job.name, err)
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/config"
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+// SystematicReviewService puts published standards under systematic review once they reach the
+// review age and applies the outcome of each review when it closes
+type SystematicReviewService struct {
+	repo            *repository.SystematicReviewRepository
+	auditLogService *AuditLogService
+}
+
+func NewSystematicReviewService(repo *repository.SystematicReviewRepository, auditLogService *AuditLogService) *SystematicReviewService {
+	return &SystematicReviewService{repo: repo, auditLogService: auditLogService}
+}
+
+// OpenDueReviews opens a review of every standard that reached the review age since it was
+// published or last confirmed
+func (service *SystematicReviewService) OpenDueReviews() error {
+	cfg := config.GetConfig()
+	if cfg.SYSTEMATIC_REVIEW_INTERVAL_YEARS == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(-cfg.SYSTEMATIC_REVIEW_INTERVAL_YEARS, 0, 0)
+	reviews, err := service.repo.OpenDueReviews(cutoff, reviewClosingDate(now))
+	for i := range reviews {
+		service.logReview(models.ActionWorkflowStart, reviews[i].ProjectID, &reviews[i], nil, now, nil, "", "", "", "")
+	}
+	return err
+}
+
+// CloseDueReviews closes the reviews whose closing date has passed
+func (service *SystematicReviewService) CloseDueReviews() error {
+	reviews, err := service.repo.GetReviewsDueForClosure(time.Now())
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, review := range reviews {
+		if _, err := service.CloseReview(review.ID, nil, "", "", "", ""); err != nil {
+			fmt.Printf("[SystematicReview] Failed to close review %s: %v\n", review.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d systematic reviews could not be closed", failed, len(reviews))
+	}
+	return nil
+}
+
+// OpenReview opens a review of a published standard ahead of its review age
+func (service *SystematicReviewService) OpenReview(projectID string, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.SystematicReview, error) {
+	startTime := time.Now()
+	review, err := service.repo.OpenReview(projectID, reviewClosingDate(startTime))
+	service.logReview(models.ActionWorkflowStart, projectID, review, err, startTime, userID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// SubmitResponse records the response of the member's NSB to an open review
+func (service *SystematicReviewService) SubmitResponse(response *models.SystematicReviewResponse) error {
+	return service.repo.SubmitResponse(response)
+}
+
+// CloseReview closes a review and applies its outcome to the standard
+func (service *SystematicReviewService) CloseReview(id uuid.UUID, userID *string, ipAddress, userAgent, sessionID, requestID string) (*models.SystematicReview, error) {
+	startTime := time.Now()
+	review, err := service.repo.CloseReview(id, userID)
+	projectID := ""
+	if review == nil {
+		if current, gerr := service.repo.GetReview(id); gerr == nil {
+			projectID = current.ProjectID
+		}
+	} else {
+		projectID = review.ProjectID
+	}
+	service.logReview(models.ActionWorkflowComplete, projectID, review, err, startTime, userID, ipAddress, userAgent, sessionID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (service *SystematicReviewService) GetReview(id uuid.UUID) (*models.SystematicReview, error) {
+	return service.repo.GetReview(id)
+}
+
+func (service *SystematicReviewService) GetReviews(status models.SystematicReviewStatus, limit, offset int) ([]models.SystematicReview, int64, error) {
+	return service.repo.GetReviews(status, limit, offset)
+}
+
+func (service *SystematicReviewService) GetProjectReviews(projectID string) ([]models.SystematicReview, error) {
+	return service.repo.GetProjectReviews(projectID)
+}
+
+// logReview records the opening or the outcome of a review against the standard. The review is nil
+// when the operation failed.
+func (service *SystematicReviewService) logReview(action models.ActionType, projectID string, review *models.SystematicReview, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{}
+	reference := ""
+	if review != nil {
+		reference = review.Reference
+		metadata["systematic_review_id"] = review.ID.String()
+		metadata["status"] = review.Status
+		metadata["closes_at"] = review.ClosesAt
+		if review.Status == models.SystematicReviewClosed {
+			metadata["outcome"] = review.Outcome
+			metadata["tally"] = review.Tally
+			if review.RevisionProjectID != nil {
+				metadata["revision_project_id"] = *review.RevisionProjectID
+			}
+		}
+	}
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	service.auditLogService.LogProjectAction(
+		userID, action, projectID, reference,
+		metadata, err == nil, errorMsg, time.Since(startTime).Milliseconds(),
+		ipAddress, userAgent, sessionID, requestID,
+	)
+}
+
+// reviewClosingDate is the closing date of a review opened at the given time
+func reviewClosingDate(opensAt time.Time) time.Time {
+	return opensAt.AddDate(0, 0, config.GetConfig().SYSTEMATIC_REVIEW_PERIOD_DAYS)
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/db/repository"
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// sagaLease bounds how long a worker holds a saga. Copying a file polls OneDrive for up to half
+	// a minute per attempt.
+	sagaLease     = 10 * time.Minute
+	sagaBatchSize = 20
+)
+
+var ErrNoSharepointDocument = errors.New("project has no SharePoint document to copy")
+
+// SagaService drives the transition sagas written by stage transitions: it copies the project's
+// draft in OneDrive, points the project at the copy and records it as the document of the new
+// stage. Failed steps are retried with backoff until the attempts run out, after which an
+// administrator can resume the saga or compensate its completed steps.
+type SagaService struct {
+	repo       *repository.SagaRepository
+	docService *DocumentService
+}
+
+func NewSagaService(repo *repository.SagaRepository, docService *DocumentService) *SagaService {
+	return &SagaService{repo: repo, docService: docService}
+}
+
+// Start runs the saga of a transition that has just committed in the background, so that the
+// request making the transition does not wait for OneDrive. The transition stands whatever happens
+// here, failed steps are left to the retry job. done, when given, is called with the outcome of
+// the first attempt.
+func (service *SagaService) Start(saga *models.TransitionSaga, done func(err error)) {
+	if saga == nil {
+		return
+	}
+
+	go func() {
+		err := service.start(saga)
+		if done != nil {
+			done(err)
+		}
+	}()
+}
+
+func (service *SagaService) start(saga *models.TransitionSaga) error {
+	claimed, err := service.repo.ClaimSaga(saga.ID, []models.TransitionSagaStatus{models.SagaPending}, sagaLease)
+	if err != nil {
+		fmt.Printf("[Saga] Could not start saga %s: %v\n", saga.ID, err)
+		return err
+	}
+	if err := service.run(context.Background(), claimed); err != nil {
+		fmt.Printf("[Saga] %s of project %s will be retried: %v\n", claimed.Transition, claimed.ProjectID, err)
+		return err
+	}
+	return nil
+}
+
+// ProcessDueSagas runs the sagas whose next attempt is due and those abandoned by their worker
+func (service *SagaService) ProcessDueSagas(ctx context.Context) error {
+	sagas, err := service.repo.ClaimDueSagas(sagaBatchSize, sagaLease)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range sagas {
+		if ctx.Err() != nil {
+			// Unprocessed sagas are picked up again once their lease expires
+			return ctx.Err()
+		}
+		if err := service.run(ctx, &sagas[i]); err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transition sagas failed", failed, len(sagas))
+	}
+	return nil
+}
+
+// Resume runs a pending or failed saga straight away with a fresh set of attempts
+func (service *SagaService) Resume(ctx context.Context, id uuid.UUID) (*models.TransitionSaga, error) {
+	saga, err := service.repo.ClaimSaga(id, []models.TransitionSagaStatus{models.SagaPending, models.SagaFailed}, sagaLease)
+	if err != nil {
+		return nil, err
+	}
+
+	saga.Attempts = 0
+	if err := service.run(ctx, saga); err != nil {
+		fmt.Printf("[Saga] Resumed saga %s failed again: %v\n", id, err)
+	}
+	return service.repo.GetSaga(id)
+}
+
+// Compensate undoes the completed steps of a pending or failed saga in reverse order, leaving the
+// project on its previous file and document. The stage transition itself is kept.
+func (service *SagaService) Compensate(ctx context.Context, id uuid.UUID) (*models.TransitionSaga, error) {
+	saga, err := service.repo.ClaimSaga(id, []models.TransitionSagaStatus{models.SagaPending, models.SagaFailed}, sagaLease)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		step, ok := saga.LastCompletedStep()
+		if !ok {
+			break
+		}
+		if err := service.runStep(ctx, saga, step, true); err != nil {
+			saga.Status = models.SagaFailed
+			saga.LastError = fmt.Sprintf("compensating %s: %v", step, err)
+			saga.LockedUntil = nil
+			if err := service.repo.SaveSaga(saga); err != nil {
+				return nil, err
+			}
+			return service.repo.GetSaga(id)
+		}
+	}
+
+	saga.Status = models.SagaCompensated
+	saga.LastError = ""
+	saga.LockedUntil = nil
+	if err := service.repo.SaveSaga(saga); err != nil {
+		return nil, err
+	}
+	return service.repo.GetSaga(id)
+}
+
+func (service *SagaService) GetSaga(id uuid.UUID) (*models.TransitionSaga, error) {
+	return service.repo.GetSaga(id)
+}
+
+func (service *SagaService) GetSagas(status models.TransitionSagaStatus, limit, offset int) ([]models.TransitionSaga, int64, error) {
+	return service.repo.GetSagas(status, limit, offset)
+}
+
+func (service *SagaService) GetStuckSagas() ([]models.TransitionSaga, error) {
+	return service.repo.GetStuckSagas(time.Now())
+}
+
+func (service *SagaService) GetProjectSagas(projectID string) ([]models.TransitionSaga, error) {
+	return service.repo.GetProjectSagas(projectID)
+}
+
+// run executes the remaining steps of a claimed saga
+func (service *SagaService) run(ctx context.Context, saga *models.TransitionSaga) error {
+	for {
+		step, ok := saga.NextStep()
+		if !ok {
+			break
+		}
+		if err := service.runStep(ctx, saga, step, false); err != nil {
+			return service.fail(saga, step, err)
+		}
+	}
+
+	now := time.Now()
+	saga.Status = models.SagaCompleted
+	saga.CompletedAt = &now
+	saga.LastError = ""
+	saga.LockedUntil = nil
+	return service.repo.SaveSaga(saga)
+}
+
+// runStep executes a step or its compensation and records the outcome
+func (service *SagaService) runStep(ctx context.Context, saga *models.TransitionSaga, step models.SagaStep, compensation bool) error {
+	record := models.TransitionSagaStep{
+		ID:           uuid.New(),
+		SagaID:       saga.ID,
+		Step:         step,
+		Compensation: compensation,
+		StartedAt:    time.Now(),
+	}
+
+	var err error
+	if compensation {
+		err = service.compensateStep(ctx, saga, step)
+	} else {
+		err = service.executeStep(ctx, saga, step)
+	}
+
+	record.FinishedAt = time.Now()
+	record.Succeeded = err == nil
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if recordErr := service.repo.RecordStep(&record); recordErr != nil {
+		fmt.Printf("[Saga] Failed to record step %s of saga %s: %v\n", step, saga.ID, recordErr)
+	}
+	return err
+}
+
+func (service *SagaService) executeStep(ctx context.Context, saga *models.TransitionSaga, step models.SagaStep) error {
+	switch step {
+	case models.SagaStepCopyFile:
+		if saga.SourceSharepointDocID == "" {
+			return ErrNoSharepointDocument
+		}
+
+		doc := service.findEarlierCopy(ctx, saga)
+		if doc == nil {
+			var err error
+			doc, err = service.docService.CopyOneDriveFile(ctx, saga.SourceSharepointDocID, saga.FileName, saga.ProjectNumber)
+			if err != nil {
+				return fmt.Errorf("failed to copy OneDrive file: %w", err)
+			}
+		}
+
+		saga.CopiedSharepointDocID = &doc.ID
+		saga.CompletedSteps++
+		return service.repo.SaveSaga(saga)
+	case models.SagaStepLinkFile:
+		return service.repo.LinkCopiedFile(saga)
+	case models.SagaStepCreateDocument:
+		return service.repo.CreateProjectDocument(saga)
+	}
+	return fmt.Errorf("unknown saga step %s", step)
+}
+
+func (service *SagaService) compensateStep(ctx context.Context, saga *models.TransitionSaga, step models.SagaStep) error {
+	switch step {
+	case models.SagaStepCopyFile:
+		if saga.CopiedSharepointDocID != nil {
+			if err := service.docService.DeleteOneDriveFile(ctx, *saga.CopiedSharepointDocID); err != nil {
+				return fmt.Errorf("failed to delete OneDrive copy: %w", err)
+			}
+		}
+
+		saga.CopiedSharepointDocID = nil
+		saga.CompletedSteps--
+		return service.repo.SaveSaga(saga)
+	case models.SagaStepLinkFile:
+		return service.repo.UnlinkCopiedFile(saga)
+	case models.SagaStepCreateDocument:
+		return service.repo.RemoveProjectDocument(saga)
+	}
+	return fmt.Errorf("unknown saga step %s", step)
+}
+
+// findEarlierCopy looks for the copy made by an earlier attempt that failed after OneDrive accepted
+// the copy, so that retries do not copy the file twice
+func (service *SagaService) findEarlierCopy(ctx context.Context, saga *models.TransitionSaga) *models.SharepointDocument {
+	if saga.LastError == "" {
+		return nil
+	}
+
+	documents, err := service.docService.ListDocuments(ctx, saga.ProjectNumber)
+	if err != nil {
+		return nil
+	}
+	for i := range documents {
+		if documents[i].Name == saga.FileName {
+			return &documents[i]
+		}
+	}
+	return nil
+}
+
+// fail records a failed attempt and schedules the next one, or marks the saga failed once the
+// attempts run out or the error cannot be cured by retrying
+func (service *SagaService) fail(saga *models.TransitionSaga, step models.SagaStep, err error) error {
+	saga.Attempts++
+	saga.LastError = fmt.Sprintf("%s: %v", step, err)
+	saga.LockedUntil = nil
+	if saga.Attempts >= models.MaxSagaAttempts || errors.Is(err, ErrNoSharepointDocument) {
+		saga.Status = models.SagaFailed
+	} else {
+		saga.Status = models.SagaPending
+		saga.NextAttemptAt = time.Now().Add(saga.RetryDelay())
+	}
+
+	if saveErr := service.repo.SaveSaga(saga); saveErr != nil {
+		return fmt.Errorf("%w (saving saga: %v)", err, saveErr)
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/google/uuid"
+)
+
+var ErrUnsupportedWorkProgrammeFormat = errors.New("work programmes can only be rendered as docx or pdf")
+
+// WorkProgrammeFile is a rendered work programme ready for download
+type WorkProgrammeFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+func (service *ProjectService) GetWorkProgramme(committeeID string) (*models.WorkProgramme, error) {
+	return service.repo.GetWorkProgramme(committeeID)
+}
+
+// CreateWorkProgrammeSnapshot saves the current work programme of a committee as a new draft version
+func (service *ProjectService) CreateWorkProgrammeSnapshot(committeeID, memberID string, ipAddress, userAgent, sessionID, requestID string) (*models.WorkProgrammeSnapshot, error) {
+	startTime := time.Now()
+	snapshot, err := service.repo.CreateWorkProgrammeSnapshot(committeeID, memberID)
+	service.logWorkProgramme(models.ActionProjectCreate, snapshot, committeeID, err, startTime, &memberID, ipAddress, userAgent, sessionID, requestID)
+	return snapshot, err
+}
+
+// PublishWorkProgrammeSnapshot publishes a draft version, superseding the version published before it
+func (service *ProjectService) PublishWorkProgrammeSnapshot(snapshotID uuid.UUID, secretary string, ipAddress, userAgent, sessionID, requestID string) (*models.WorkProgrammeSnapshot, error) {
+	startTime := time.Now()
+	snapshot, err := service.repo.PublishWorkProgrammeSnapshot(snapshotID, secretary)
+	if err != nil {
+		return nil, err
+	}
+
+	service.logWorkProgramme(models.ActionProjectPublish, snapshot, snapshot.TechnicalCommitteeID, nil, startTime, &secretary, ipAddress, userAgent, sessionID, requestID)
+	return snapshot, nil
+}
+
+func (service *ProjectService) GetWorkProgrammeSnapshot(id uuid.UUID) (*models.WorkProgrammeSnapshot, error) {
+	return service.repo.GetWorkProgrammeSnapshot(id)
+}
+
+func (service *ProjectService) GetWorkProgrammeSnapshots(committeeID string) ([]models.WorkProgrammeSnapshot, error) {
+	return service.repo.GetWorkProgrammeSnapshots(committeeID)
+}
+
+func (service *ProjectService) GetPublishedWorkProgramme(committeeID string) (*models.WorkProgrammeSnapshot, error) {
+	return service.repo.GetPublishedWorkProgramme(committeeID)
+}
+
+// RenderWorkProgramme renders a work programme as a DOCX or PDF document in the ARSO layout. The
+// version is printed on the document when it is greater than zero.
+func (service *ProjectService) RenderWorkProgramme(programme *models.WorkProgramme, version int64, format string) (*WorkProgrammeFile, error) {
+	name := fmt.Sprintf("%s work programme", programme.CommitteeCode)
+	if version > 0 {
+		name = fmt.Sprintf("%s v%d", name, version)
+	}
+	name = strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+
+	switch strings.ToLower(format) {
+	case "docx":
+		data, err := renderWorkProgrammeDOCX(programme, version)
+		if err != nil {
+			return nil, err
+		}
+		return &WorkProgrammeFile{
+			Name:        name + ".docx",
+			ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			Data:        data,
+		}, nil
+	case "pdf":
+		data, err := renderWorkProgrammePDF(programme, version)
+		if err != nil {
+			return nil, err
+		}
+		return &WorkProgrammeFile{Name: name + ".pdf", ContentType: "application/pdf", Data: data}, nil
+	default:
+		return nil, ErrUnsupportedWorkProgrammeFormat
+	}
+}
+
+func (service *ProjectService) logWorkProgramme(action models.ActionType, snapshot *models.WorkProgrammeSnapshot, committeeID string, err error, startTime time.Time, userID *string, ipAddress, userAgent, sessionID, requestID string) {
+	if service.auditLogService == nil {
+		return
+	}
+
+	var resourceID *string
+	metadata := map[string]interface{}{"technical_committee_id": committeeID}
+	title := "Work programme"
+	if snapshot != nil {
+		id := snapshot.ID.String()
+		resourceID = &id
+		title = fmt.Sprintf("%s work programme v%d", snapshot.Programme.CommitteeCode, snapshot.Version)
+		metadata["version"] = snapshot.Version
+		metadata["status"] = snapshot.Status
+		metadata["items"] = len(snapshot.Programme.Items)
+		metadata["behind_schedule"] = snapshot.Programme.BehindSchedule
+	}
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	description := fmt.Sprintf("Generated %s", title)
+	if action == models.ActionProjectPublish {
+		description = fmt.Sprintf("Published %s", title)
+	}
+	service.auditLogService.LogAction(LogActionParams{
+		UserID:        userID,
+		Action:        action,
+		Module:        models.ModuleProjects,
+		ResourceType:  "WorkProgrammeSnapshot",
+		ResourceID:    resourceID,
+		ResourceTitle: title,
+		Description:   description,
+		Metadata:      metadata,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+		SessionID:     sessionID,
+		RequestID:     requestID,
+		Success:       err == nil,
+		ErrorMessage:  errorMsg,
+		Duration:      time.Since(startTime).Milliseconds(),
+	})
+}
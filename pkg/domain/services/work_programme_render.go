@@ -0,0 +1,317 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ekbaya/asham/pkg/domain/models"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+const (
+	workProgrammeOrganisation = "African Organisation for Standardisation (ARSO)"
+	workProgrammeRowsPerPage  = 20
+	workProgrammeTableWidth   = 780 // Points across landscape A4 inside the margins
+	workProgrammeFontSize     = 8
+	workProgrammeCellPadding  = 2
+)
+
+// workProgrammeColumns are the columns of the ARSO work programme table, with their share of the
+// page width in percent
+var workProgrammeColumns = []struct {
+	Title string
+	Width int
+}{
+	{"Reference", 13},
+	{"Title", 35},
+	{"Stage", 7},
+	{"Next", 8},
+	{"Target date", 10},
+	{"Publication", 10},
+	{"Days late", 7},
+	{"Working group", 10},
+}
+
+func workProgrammeTitle(programme *models.WorkProgramme) string {
+	return fmt.Sprintf("%s %s - Work programme", programme.CommitteeCode, programme.CommitteeName)
+}
+
+func workProgrammeSubtitle(programme *models.WorkProgramme, version int64) string {
+	subtitle := fmt.Sprintf("Generated on %s", programme.GeneratedAt.Format("2 January 2006"))
+	if version > 0 {
+		subtitle = fmt.Sprintf("Version %d, generated on %s", version, programme.GeneratedAt.Format("2 January 2006"))
+	}
+	return fmt.Sprintf("%s. %d projects, %d behind schedule.", subtitle, len(programme.Items), programme.BehindSchedule)
+}
+
+func workProgrammeRow(item models.WorkProgrammeItem) []string {
+	date := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("2006-01-02")
+	}
+	nextStage := item.NextStage
+	if nextStage == "" {
+		nextStage = "-"
+	}
+	workingGroup := item.WorkingGroup
+	if workingGroup == "" {
+		workingGroup = "TC"
+	}
+	slippage := "-"
+	if item.SlippageDays > 0 {
+		slippage = strconv.FormatInt(item.SlippageDays, 10)
+	}
+	return []string{
+		item.Reference,
+		item.Title,
+		item.StageAbbreviation,
+		nextStage,
+		date(item.TargetDate),
+		date(item.TargetPublicationDate),
+		slippage,
+		workingGroup,
+	}
+}
+
+// fitWorkProgrammeCell cuts text down to the width of a PDF table column given in percent
+func fitWorkProgrammeCell(text string, width int) string {
+	return fitPDFText(text, workProgrammeFontSize, float64(width*workProgrammeTableWidth)/100-2*workProgrammeCellPadding-2)
+}
+
+// fitPDFText cuts Helvetica text down to the available width in points
+func fitPDFText(text string, size int, available float64) string {
+	if font.TextWidth(text, "Helvetica", size) <= available {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && font.TextWidth(string(runes)+"...", "Helvetica", size) > available {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// renderWorkProgrammePDF lays the work programme out as landscape A4 tables of a fixed number of rows
+// per page, since pdfcpu does not break tables across pages
+func renderWorkProgrammePDF(programme *models.WorkProgramme, version int64) ([]byte, error) {
+	headers := make([]string, len(workProgrammeColumns))
+	widths := make([]int, len(workProgrammeColumns))
+	for i, column := range workProgrammeColumns {
+		headers[i] = column.Title
+		widths[i] = column.Width
+	}
+
+	rows := make([][]string, 0, len(programme.Items))
+	for _, item := range programme.Items {
+		// Table cells do not wrap, so long text is cut to the width of its column
+		row := workProgrammeRow(item)
+		for i, cell := range row {
+			row[i] = fitWorkProgrammeCell(cell, workProgrammeColumns[i].Width)
+		}
+		rows = append(rows, row)
+	}
+
+	pages := map[string]interface{}{}
+	for page := 0; page == 0 || page*workProgrammeRowsPerPage < len(rows); page++ {
+		end := (page + 1) * workProgrammeRowsPerPage
+		if end > len(rows) {
+			end = len(rows)
+		}
+		values := rows[page*workProgrammeRowsPerPage : end]
+
+		content := map[string]interface{}{}
+		tableDY := 20
+		if page == 0 {
+			tableDY = 75
+			content["text"] = []map[string]interface{}{
+				{
+					"value":  workProgrammeTitle(programme),
+					"anchor": "TopLeft",
+					"dx":     0,
+					"dy":     10,
+					"font":   map[string]interface{}{"name": "Helvetica-Bold", "size": 14},
+				},
+				{
+					"value":  workProgrammeSubtitle(programme, version),
+					"anchor": "TopLeft",
+					"dx":     0,
+					"dy":     35,
+					"font":   map[string]interface{}{"name": "Helvetica", "size": 10},
+				},
+			}
+		}
+		if len(values) > 0 {
+			content["table"] = []map[string]interface{}{{
+				"anchor":    "TopLeft",
+				"dx":        0,
+				"dy":        tableDY,
+				"rows":      len(values),
+				"cols":      len(headers),
+				"width":     workProgrammeTableWidth,
+				"lheight":   18,
+				"colWidths": widths,
+				"grid":      true,
+				"font":      map[string]interface{}{"name": "Helvetica", "size": workProgrammeFontSize},
+				"padding":   map[string]interface{}{"width": workProgrammeCellPadding},
+				"header": map[string]interface{}{
+					"values": headers,
+					"bgCol":  "#D9E2F3",
+					"font":   map[string]interface{}{"name": "Helvetica-Bold", "size": workProgrammeFontSize},
+				},
+				"values": values,
+			}}
+		}
+		pages[strconv.Itoa(page+1)] = map[string]interface{}{"content": content}
+	}
+
+	footer := "Page %p of %P"
+	if version > 0 {
+		footer = fmt.Sprintf("Version %d - Page %%p of %%P", version)
+	}
+	layout := map[string]interface{}{
+		"paper":  "A4L",
+		"origin": "UpperLeft",
+		"margin": map[string]interface{}{"width": 30},
+		"header": map[string]interface{}{
+			"left":   workProgrammeOrganisation,
+			"right":  programme.CommitteeCode,
+			"font":   map[string]interface{}{"name": "Helvetica", "size": 9},
+			"height": 30,
+			"dx":     30,
+			"dy":     10,
+		},
+		"footer": map[string]interface{}{
+			"left":   programme.GeneratedAt.Format("2006-01-02"),
+			"right":  footer,
+			"font":   map[string]interface{}{"name": "Helvetica", "size": 9},
+			"height": 30,
+			"dx":     30,
+			"dy":     10,
+		},
+		"pages": pages,
+	}
+	return createPDF(layout)
+}
+
+// createPDF renders a pdfcpu JSON layout
+func createPDF(layout map[string]interface{}) ([]byte, error) {
+	spec, err := json.Marshal(layout)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := api.Create(nil, bytes.NewReader(spec), &out, nil); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// renderWorkProgrammeDOCX writes the work programme as a landscape Word document whose table header
+// repeats on every page
+func renderWorkProgrammeDOCX(programme *models.WorkProgramme, version int64) ([]byte, error) {
+	headers := make([]string, len(workProgrammeColumns))
+	widths := make([]int, len(workProgrammeColumns))
+	for i, column := range workProgrammeColumns {
+		headers[i] = column.Title
+		widths[i] = column.Width
+	}
+
+	var body bytes.Buffer
+	body.WriteString(docxParagraph(workProgrammeOrganisation, false, 18))
+	body.WriteString(docxParagraph(workProgrammeTitle(programme), true, 28))
+	body.WriteString(docxParagraph(workProgrammeSubtitle(programme, version), false, 20))
+	body.WriteString(docxTableStart())
+	body.WriteString(docxTableRow(headers, widths, true))
+	for _, item := range programme.Items {
+		body.WriteString(docxTableRow(workProgrammeRow(item), widths, false))
+	}
+	body.WriteString(`</w:tbl>`)
+	return packageDOCX(body.String())
+}
+
+// docxTableWidth is the width of a table across landscape A4 inside the margins, in twentieths of
+// a point
+const docxTableWidth = 14400
+
+func docxParagraph(text string, bold bool, size int) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(text))
+	props := fmt.Sprintf(`<w:sz w:val="%d"/>`, size)
+	if bold {
+		props = `<w:b/>` + props
+	}
+	return fmt.Sprintf(`<w:p><w:r><w:rPr>%s</w:rPr><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, props, escaped.String())
+}
+
+// docxTableStart opens a bordered table across the page
+func docxTableStart() string {
+	return fmt.Sprintf(`<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblBorders>`+
+		`<w:top w:val="single" w:sz="4"/><w:left w:val="single" w:sz="4"/><w:bottom w:val="single" w:sz="4"/>`+
+		`<w:right w:val="single" w:sz="4"/><w:insideH w:val="single" w:sz="4"/><w:insideV w:val="single" w:sz="4"/>`+
+		`</w:tblBorders></w:tblPr>`, docxTableWidth)
+}
+
+// docxTableRow writes a table row whose cell widths are given in percent. Header rows are shaded,
+// bold and repeated on every page.
+func docxTableRow(cells []string, widths []int, header bool) string {
+	var b bytes.Buffer
+	b.WriteString(`<w:tr>`)
+	if header {
+		b.WriteString(`<w:trPr><w:tblHeader/></w:trPr>`)
+	}
+	for i, cell := range cells {
+		shading := ""
+		if header {
+			shading = `<w:shd w:val="clear" w:color="auto" w:fill="D9E2F3"/>`
+		}
+		fmt.Fprintf(&b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>%s</w:tcPr>%s</w:tc>`,
+			docxTableWidth*widths[i]/100, shading, docxParagraph(cell, header, 16))
+	}
+	b.WriteString(`</w:tr>`)
+	return b.String()
+}
+
+// packageDOCX wraps the body of a landscape A4 Word document in its package
+func packageDOCX(body string) ([]byte, error) {
+	body += `<w:sectPr><w:pgSz w:w="16838" w:h="11906" w:orient="landscape"/>` +
+		`<w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/></w:sectPr>`
+
+	parts := []struct{ Name, Content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+			`</Relationships>`},
+		{"word/document.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			body + `</w:body></w:document>`},
+	}
+
+	var out bytes.Buffer
+	archive := zip.NewWriter(&out)
+	for _, part := range parts {
+		w, err := archive.Create(part.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.Content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}